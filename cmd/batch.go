@@ -21,6 +21,8 @@ type batchFlags struct {
 	backupDir   string
 	inPlace     bool
 	summaryOnly bool
+	profile     string
+	deep        bool
 }
 
 func newBatchCmd(root *rootFlags) *cobra.Command {
@@ -47,6 +49,7 @@ func newBatchCmd(root *rootFlags) *cobra.Command {
 			"  ebm-cli batch validate ./books",
 			"  ebm-cli batch validate ./library --ext .epub --jobs 8",
 			"  ebm-cli batch validate ./books/*.pdf --format json",
+			"  ebm-cli batch validate ./books --ext .epub --profile kobo",
 		}, "\n"),
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -67,7 +70,9 @@ func newBatchCmd(root *rootFlags) *cobra.Command {
 				Progress:    flags.progress,
 				SummaryOnly: flags.summaryOnly,
 				OutputPath:  root.output,
+				Validate:    validateOptions(root, flags.profile),
 			}
+			batchOptions.Validate.Deep = flags.deep
 
 			result, err := cli.RunBatchValidate(ctx, args, batchOptions, options, filter, cmd.OutOrStdout())
 			if err != nil {
@@ -111,6 +116,7 @@ func newBatchCmd(root *rootFlags) *cobra.Command {
 					InPlace:   flags.inPlace,
 					Backup:    flags.backup,
 					BackupDir: flags.backupDir,
+					Validate:  validateOptions(root, ""),
 				},
 			}
 
@@ -122,6 +128,8 @@ func newBatchCmd(root *rootFlags) *cobra.Command {
 		},
 	}
 
+	validateCmd.Flags().StringVar(&flags.profile, "profile", "", "Validation profile for EPUB or PDF files")
+	validateCmd.Flags().BoolVar(&flags.deep, "deep", false, "Decode every PDF stream and check its /Length (slower)")
 	repairCmd.Flags().BoolVar(&flags.inPlace, "in-place", false, "Repair files in place using atomic replace")
 	repairCmd.Flags().BoolVar(&flags.backup, "backup", false, "Create backup before in-place repair")
 	repairCmd.Flags().StringVar(&flags.backupDir, "backup-dir", "", "Directory to place backups")
@@ -25,6 +25,10 @@ func newExamplesCmd() *cobra.Command {
 				"  ebm-cli repair broken.pdf --output repaired.pdf",
 				"  ebm-cli repair broken.epub --backup backup.epub",
 				"",
+				"Optimize a PDF and report the size saved:",
+				"  ebm-cli optimize document.pdf --output small.pdf",
+				"  ebm-cli optimize scan.pdf --image-dpi 150 --in-place --backup",
+				"",
 				"Batch validate (glob + directory):",
 				"  ebm-cli batch validate \"library/**/*.epub\"",
 				"  ebm-cli batch validate ./samples --max-depth 2 --ext .epub --ext .pdf",
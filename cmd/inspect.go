@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/reporter"
+	"github.com/petergi/ebook-mechanic-lib/internal/cli"
+)
+
+func newInspectCmd(root *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect <file>",
+		Short: "Print an EPUB content inventory",
+		Long: strings.Join([]string{
+			"Print word and character counts, reading time and page estimates, images,",
+			"fonts, the largest resources, TOC shape, languages, version and rendition",
+			"of an EPUB. The file is not validated. Use --verbose for per-document counts.",
+		}, "\n"),
+		Example: strings.Join([]string{
+			"  ebm-cli inspect book.epub",
+			"  ebm-cli inspect book.epub --format json",
+			"  ebm-cli inspect book.epub --format markdown --output inventory.md",
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inventory, err := cli.InspectFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			options, _, err := buildReportOptions(root)
+			if err != nil {
+				return err
+			}
+			rep, err := reporter.NewInventoryReporter(options.Format)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if root.output != "" {
+				if err := os.MkdirAll(filepath.Dir(root.output), 0750); err != nil {
+					return err
+				}
+				file, err := os.Create(root.output)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					_ = file.Close()
+				}()
+				out = file
+			}
+
+			return rep.WriteInventory(cmd.Context(), inventory, out, options)
+		},
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/cli"
+	"github.com/petergi/ebook-mechanic-lib/pkg/ebmlib"
+)
+
+type metaSetFlags struct {
+	output               string
+	backup               bool
+	backupDir            string
+	title                string
+	creators             []string
+	identifiers          []string
+	language             string
+	publisher            string
+	series               string
+	subjects             []string
+	description          string
+	accessModes          []string
+	accessModeSufficient []string
+	features             []string
+	hazards              []string
+	summary              string
+	conformsTo           []string
+	cover                string
+}
+
+func newMetaCmd(root *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Read or edit EPUB metadata",
+		Long:  "Print or change the package metadata of an EPUB without unpacking it.",
+	}
+
+	cmd.AddCommand(newMetaGetCmd(root))
+	cmd.AddCommand(newMetaSetCmd())
+	return cmd
+}
+
+func newMetaGetCmd(root *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <file>",
+		Short: "Print EPUB metadata",
+		Example: strings.Join([]string{
+			"  ebm-cli meta get book.epub",
+			"  ebm-cli meta get book.epub --format json",
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metadata, err := ebmlib.ReadEPUBMetadata(args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if root.output != "" {
+				if err := os.MkdirAll(filepath.Dir(root.output), 0750); err != nil {
+					return err
+				}
+				file, err := os.Create(root.output)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					_ = file.Close()
+				}()
+				out = file
+			}
+
+			if strings.EqualFold(root.format, "json") {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(metadata)
+			}
+			writeMetadataText(out, metadata)
+			return nil
+		},
+	}
+}
+
+func newMetaSetCmd() *cobra.Command {
+	flags := &metaSetFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "set <file>",
+		Short: "Change EPUB metadata",
+		Long: strings.Join([]string{
+			"Change EPUB metadata in place, or write a copy with --output. Only the given",
+			"fields change; dcterms:modified is refreshed. Repeatable flags replace every",
+			"existing entry of their kind, and an empty value removes them.",
+		}, "\n"),
+		Example: strings.Join([]string{
+			`  ebm-cli meta set book.epub --creator "Jane Doe|Doe, Jane|aut"`,
+			`  ebm-cli meta set book.epub --title "The Title" --series "The Cases|2"`,
+			`  ebm-cli meta set book.epub --identifier "9780000000002|ISBN" --subject Fiction --subject Mystery`,
+			`  ebm-cli meta set book.epub --cover cover.jpg --output fixed.epub`,
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.backupDir != "" && !flags.backup {
+				return fmt.Errorf("--backup-dir requires --backup")
+			}
+
+			changes, err := metadataChanges(cmd, flags, args[0])
+			if err != nil {
+				return err
+			}
+
+			outputPath, backupPath, err := cli.UpdateMetadata(args[0], changes, cli.MetadataOptions{
+				OutputPath: flags.output,
+				Backup:     flags.backup,
+				BackupDir:  flags.backupDir,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Updated: %s\n", outputPath)
+			if backupPath != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Backup: %s\n", backupPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "Write the edited EPUB here instead of editing in place")
+	cmd.Flags().BoolVar(&flags.backup, "backup", false, "Create backup before editing in place")
+	cmd.Flags().StringVar(&flags.backupDir, "backup-dir", "", "Directory to place backups")
+	cmd.Flags().StringVar(&flags.title, "title", "", "Main title (other titles are kept)")
+	cmd.Flags().StringArrayVar(&flags.creators, "creator", nil, `Creator as "name|file-as|role,role" (repeatable)`)
+	cmd.Flags().StringArrayVar(&flags.identifiers, "identifier", nil, `Identifier as "value|scheme" (repeatable); the first keeps the unique-identifier id`)
+	cmd.Flags().StringVar(&flags.language, "language", "", "Language tag, e.g. en-US")
+	cmd.Flags().StringVar(&flags.publisher, "publisher", "", "Publisher")
+	cmd.Flags().StringVar(&flags.series, "series", "", `Series as "name|position"`)
+	cmd.Flags().StringArrayVar(&flags.subjects, "subject", nil, "Subject (repeatable)")
+	cmd.Flags().StringVar(&flags.description, "description", "", "Description")
+	cmd.Flags().StringArrayVar(&flags.accessModes, "access-mode", nil, "schema:accessMode value (repeatable)")
+	cmd.Flags().StringArrayVar(&flags.accessModeSufficient, "access-mode-sufficient", nil, "schema:accessModeSufficient value (repeatable)")
+	cmd.Flags().StringArrayVar(&flags.features, "accessibility-feature", nil, "schema:accessibilityFeature value (repeatable)")
+	cmd.Flags().StringArrayVar(&flags.hazards, "accessibility-hazard", nil, "schema:accessibilityHazard value (repeatable)")
+	cmd.Flags().StringVar(&flags.summary, "accessibility-summary", "", "schema:accessibilitySummary")
+	cmd.Flags().StringArrayVar(&flags.conformsTo, "conforms-to", nil, "dcterms:conformsTo claim (repeatable)")
+	cmd.Flags().StringVar(&flags.cover, "cover", "", "Cover image file to add as the cover")
+
+	return cmd
+}
+
+func metadataChanges(cmd *cobra.Command, flags *metaSetFlags, filePath string) (ebmlib.MetadataChanges, error) {
+	changed := cmd.Flags().Changed
+	changes := ebmlib.MetadataChanges{}
+
+	if changed("title") {
+		changes.Title = &flags.title
+	}
+	if changed("creator") {
+		changes.Creators = make([]ebmlib.Creator, 0, len(flags.creators))
+		for _, raw := range flags.creators {
+			fields := splitField(raw, 3)
+			if fields[0] == "" {
+				continue
+			}
+			creator := ebmlib.Creator{Name: fields[0], FileAs: fields[1]}
+			for _, role := range strings.Split(fields[2], ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					creator.Roles = append(creator.Roles, role)
+				}
+			}
+			changes.Creators = append(changes.Creators, creator)
+		}
+	}
+	if changed("identifier") {
+		changes.Identifiers = make([]ebmlib.Identifier, 0, len(flags.identifiers))
+		for _, raw := range flags.identifiers {
+			fields := splitField(raw, 2)
+			changes.Identifiers = append(changes.Identifiers, ebmlib.Identifier{Value: fields[0], Scheme: fields[1]})
+		}
+	}
+	if changed("language") {
+		changes.Language = &flags.language
+	}
+	if changed("publisher") {
+		changes.Publisher = &flags.publisher
+	}
+	if changed("series") {
+		fields := splitField(flags.series, 2)
+		changes.Series = &ebmlib.Series{Name: fields[0], Position: fields[1]}
+	}
+	if changed("subject") {
+		changes.Subjects = append([]string{}, flags.subjects...)
+	}
+	if changed("description") {
+		changes.Description = &flags.description
+	}
+
+	accessibilityChanged := false
+	for _, name := range []string{"access-mode", "access-mode-sufficient", "accessibility-feature",
+		"accessibility-hazard", "accessibility-summary", "conforms-to"} {
+		accessibilityChanged = accessibilityChanged || changed(name)
+	}
+	if accessibilityChanged {
+		current, err := ebmlib.ReadEPUBMetadata(filePath)
+		if err != nil {
+			return changes, err
+		}
+		accessibility := current.Accessibility
+		if changed("access-mode") {
+			accessibility.AccessModes = flags.accessModes
+		}
+		if changed("access-mode-sufficient") {
+			accessibility.AccessModeSufficient = flags.accessModeSufficient
+		}
+		if changed("accessibility-feature") {
+			accessibility.AccessibilityFeatures = flags.features
+		}
+		if changed("accessibility-hazard") {
+			accessibility.AccessibilityHazards = flags.hazards
+		}
+		if changed("accessibility-summary") {
+			accessibility.AccessibilitySummary = flags.summary
+		}
+		if changed("conforms-to") {
+			accessibility.ConformanceClaims = flags.conformsTo
+		}
+		changes.Accessibility = &accessibility
+	}
+
+	if changed("cover") {
+		data, err := os.ReadFile(flags.cover)
+		if err != nil {
+			return changes, fmt.Errorf("read cover image: %w", err)
+		}
+		changes.Cover = &ebmlib.CoverImage{
+			Href: "images/" + filepath.Base(flags.cover),
+			Data: data,
+		}
+	}
+
+	if changes.IsEmpty() {
+		return changes, fmt.Errorf("no metadata changes given (see ebm-cli meta set --help)")
+	}
+	return changes, nil
+}
+
+// splitField splits a "a|b|c" flag value into exactly n trimmed fields.
+func splitField(raw string, n int) []string {
+	fields := strings.SplitN(raw, "|", n)
+	for len(fields) < n {
+		fields = append(fields, "")
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+func writeMetadataText(w io.Writer, metadata *ebmlib.Metadata) {
+	line := func(label, value string) {
+		if value != "" {
+			_, _ = fmt.Fprintf(w, "%-15s %s\n", label+":", value)
+		}
+	}
+
+	for _, title := range metadata.Titles {
+		value := title.Value
+		if title.Type != "" {
+			value += " (" + title.Type + ")"
+		}
+		line("Title", value)
+	}
+	for _, creator := range metadata.Creators {
+		line("Creator", describeCreator(creator))
+	}
+	for _, contributor := range metadata.Contributors {
+		line("Contributor", describeCreator(contributor))
+	}
+	for _, identifier := range metadata.Identifiers {
+		value := identifier.Value
+		if identifier.Scheme != "" {
+			value += " (" + identifier.Scheme + ")"
+		}
+		if identifier.Value == metadata.UniqueIdentifier {
+			value += " [unique]"
+		}
+		line("Identifier", value)
+	}
+	line("Language", strings.Join(metadata.Languages, ", "))
+	line("Publisher", metadata.Publisher)
+	line("Date", metadata.Date)
+	line("Modified", metadata.Modified)
+	for _, series := range metadata.Series {
+		value := series.Name
+		if series.Position != "" {
+			value += " #" + series.Position
+		}
+		line("Series", value)
+	}
+	line("Subjects", strings.Join(metadata.Subjects, ", "))
+	line("Rights", metadata.Rights)
+	line("Cover", metadata.Cover)
+	line("Description", metadata.Description)
+
+	accessibility := metadata.Accessibility
+	line("Access modes", strings.Join(accessibility.AccessModes, ", "))
+	line("Sufficient", strings.Join(accessibility.AccessModeSufficient, "; "))
+	line("Features", strings.Join(accessibility.AccessibilityFeatures, ", "))
+	line("Hazards", strings.Join(accessibility.AccessibilityHazards, ", "))
+	line("Summary", accessibility.AccessibilitySummary)
+	line("Conforms to", strings.Join(accessibility.ConformanceClaims, ", "))
+}
+
+func describeCreator(creator ebmlib.Creator) string {
+	var notes []string
+	if len(creator.Roles) > 0 {
+		notes = append(notes, strings.Join(creator.Roles, ", "))
+	}
+	if creator.FileAs != "" {
+		notes = append(notes, "file-as: "+creator.FileAs)
+	}
+	if len(notes) == 0 {
+		return creator.Name
+	}
+	return creator.Name + " (" + strings.Join(notes, "; ") + ")"
+}
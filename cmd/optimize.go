@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/cli"
+	"github.com/petergi/ebook-mechanic-lib/pkg/ebmlib"
+)
+
+type optimizeFlags struct {
+	output          string
+	inPlace         bool
+	backup          bool
+	backupDir       string
+	linearize       bool
+	noObjectStreams bool
+	noDedupe        bool
+	noCompress      bool
+	imageDPI        float64
+	imageQuality    int
+}
+
+func newOptimizeCmd(root *rootFlags) *cobra.Command {
+	flags := &optimizeFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "optimize <file>",
+		Short: "Shrink a PDF file",
+		Long: strings.Join([]string{
+			"Rewrite a PDF without unreferenced objects, merging duplicate streams and fonts,",
+			"Flate-compressing uncompressed streams and packing objects into object streams,",
+			"and print the size before and after. --image-dpi also downsamples images placed",
+			"above that resolution. --linearize writes the file for Fast Web View instead",
+			"of using object streams. Encrypted PDFs are rejected, and digital signatures",
+			"do not survive the rewrite.",
+		}, "\n"),
+		Example: strings.Join([]string{
+			"  ebm-cli optimize document.pdf",
+			"  ebm-cli optimize scan.pdf --image-dpi 150 --output small.pdf",
+			"  ebm-cli optimize document.pdf --linearize --in-place --backup",
+			"  ebm-cli optimize document.pdf --format json",
+		}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.inPlace && flags.output != "" {
+				return fmt.Errorf("--in-place and --output cannot be used together")
+			}
+			if flags.backupDir != "" && !flags.backup {
+				return fmt.Errorf("--backup-dir requires --backup")
+			}
+
+			ctx, cancel := withSignalContext(cmd.Context())
+			defer cancel()
+
+			result, outputPath, backupPath, err := cli.OptimizeFile(ctx, args[0], cli.OptimizeOptions{
+				OutputPath: flags.output,
+				InPlace:    flags.inPlace,
+				Backup:     flags.backup,
+				BackupDir:  flags.backupDir,
+				Optimize: ebmlib.OptimizeOptions{
+					Deduplicate:   !flags.noDedupe,
+					Compress:      !flags.noCompress,
+					ObjectStreams: !flags.noObjectStreams && !flags.linearize,
+					Linearize:     flags.linearize,
+					ImageDPI:      flags.imageDPI,
+					ImageQuality:  flags.imageQuality,
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			if strings.EqualFold(root.format, "json") {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(struct {
+					Output string `json:"output"`
+					Backup string `json:"backup,omitempty"`
+					*ebmlib.OptimizeResult
+				}{outputPath, backupPath, result})
+			}
+			writeOptimizeText(cmd.OutOrStdout(), result, outputPath, backupPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "Output path for optimized file")
+	cmd.Flags().BoolVar(&flags.inPlace, "in-place", false, "Optimize file in place using atomic replace")
+	cmd.Flags().BoolVar(&flags.backup, "backup", false, "Create backup before in-place optimization")
+	cmd.Flags().StringVar(&flags.backupDir, "backup-dir", "", "Directory to place backups")
+	cmd.Flags().BoolVar(&flags.linearize, "linearize", false, "Write a linearized file for Fast Web View (no object streams)")
+	cmd.Flags().BoolVar(&flags.noObjectStreams, "no-object-streams", false, "Keep a cross-reference table and the original PDF version")
+	cmd.Flags().BoolVar(&flags.noDedupe, "no-dedupe", false, "Keep duplicate streams and fonts")
+	cmd.Flags().BoolVar(&flags.noCompress, "no-compress", false, "Leave uncompressed streams uncompressed")
+	cmd.Flags().Float64Var(&flags.imageDPI, "image-dpi", 0, "Downsample images placed above this resolution (0 = keep images)")
+	cmd.Flags().IntVar(&flags.imageQuality, "image-quality", 0, "JPEG quality of downsampled JPEG images, 1-100 (0 = 85)")
+
+	return cmd
+}
+
+func writeOptimizeText(w io.Writer, result *ebmlib.OptimizeResult, outputPath, backupPath string) {
+	saved := result.Saved()
+	percent := 0.0
+	if result.OriginalSize > 0 {
+		percent = float64(saved) / float64(result.OriginalSize) * 100
+	}
+
+	_, _ = fmt.Fprintf(w, "Output: %s\n", outputPath)
+	if backupPath != "" {
+		_, _ = fmt.Fprintf(w, "Backup: %s\n", backupPath)
+	}
+	_, _ = fmt.Fprintf(w, "Before: %s (%d bytes)\n", formatSize(result.OriginalSize), result.OriginalSize)
+	_, _ = fmt.Fprintf(w, "After:  %s (%d bytes)\n", formatSize(result.OptimizedSize), result.OptimizedSize)
+	if saved >= 0 {
+		_, _ = fmt.Fprintf(w, "Saved:  %s (%.1f%%)\n", formatSize(saved), percent)
+	} else {
+		_, _ = fmt.Fprintf(w, "Grew:   %s (%.1f%%)\n", formatSize(-saved), -percent)
+	}
+	_, _ = fmt.Fprintf(w, "Objects: %d -> %d\n", result.ObjectsBefore, result.ObjectsAfter)
+
+	counts := []struct {
+		label string
+		count int
+	}{
+		{"Streams deduplicated", result.StreamsDeduplicated},
+		{"Fonts deduplicated", result.FontsDeduplicated},
+		{"Streams compressed", result.StreamsCompressed},
+		{"Images downsampled", result.ImagesDownsampled},
+		{"Object streams", result.ObjectStreams},
+	}
+	for _, c := range counts {
+		if c.count > 0 {
+			_, _ = fmt.Fprintf(w, "%s: %d\n", c.label, c.count)
+		}
+	}
+	if result.Linearized {
+		_, _ = fmt.Fprintln(w, "Linearized: yes")
+	}
+}
+
+// formatSize renders a size with a binary unit, e.g. "1.5 MiB".
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -50,6 +50,7 @@ func newRepairCmd(root *rootFlags) *cobra.Command {
 				InPlace:    flags.inPlace,
 				Backup:     flags.backup,
 				BackupDir:  flags.backupDir,
+				Validate:   validateOptions(root, ""),
 			})
 			if err != nil {
 				return err
@@ -27,7 +27,10 @@ type rootFlags struct {
 	color       bool
 	minSeverity string
 	severities  []string
+	categories  []string
 	maxErrors   int
+	config      string
+	noConfig    bool
 }
 
 func newRootCmd() *cobra.Command {
@@ -50,7 +53,7 @@ func newRootCmd() *cobra.Command {
 			ctx, cancel := withSignalContext(context.Background())
 			defer cancel()
 
-			report, err := cli.ValidateFile(ctx, args[0])
+			report, err := cli.ValidateFileWithOptions(ctx, args[0], validateOptions(flags, ""))
 			if err != nil {
 				return err
 			}
@@ -68,11 +71,17 @@ func newRootCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&flags.color, "color", true, "Enable colorized output")
 	cmd.PersistentFlags().StringVar(&flags.minSeverity, "min-severity", "", "Minimum severity to include (info, warning, error)")
 	cmd.PersistentFlags().StringSliceVar(&flags.severities, "severity", nil, "Include only specific severities (repeatable)")
+	cmd.PersistentFlags().StringSliceVar(&flags.categories, "category", nil, "Include only findings in specific categories, such as safety (repeatable)")
 	cmd.PersistentFlags().IntVar(&flags.maxErrors, "max-errors", 0, "Limit number of errors per report (0 = unlimited)")
+	cmd.PersistentFlags().StringVar(&flags.config, "config", "", "Rule configuration file (default: nearest .ebmrc.yaml, then ~/.ebmrc.yaml)")
+	cmd.PersistentFlags().BoolVar(&flags.noConfig, "no-config", false, "Ignore rule configuration files")
 
 	cmd.AddCommand(newValidateCmd(flags))
 	cmd.AddCommand(newRepairCmd(flags))
+	cmd.AddCommand(newOptimizeCmd(flags))
 	cmd.AddCommand(newBatchCmd(flags))
+	cmd.AddCommand(newMetaCmd(flags))
+	cmd.AddCommand(newInspectCmd(flags))
 	cmd.AddCommand(newExamplesCmd())
 
 	cmd.SetOut(os.Stdout)
@@ -91,6 +100,12 @@ func buildReportOptions(flags *rootFlags) (*ports.ReportOptions, *reporter.Filte
 	if err != nil {
 		return nil, nil, err
 	}
+	if len(flags.categories) > 0 {
+		if filter == nil {
+			filter = reporter.NewFilter()
+		}
+		filter.Categories = flags.categories
+	}
 
 	return &ports.ReportOptions{
 		Format:          format,
@@ -102,6 +117,14 @@ func buildReportOptions(flags *rootFlags) (*ports.ReportOptions, *reporter.Filte
 	}, filter, nil
 }
 
+func validateOptions(flags *rootFlags, profile string) cli.ValidateOptions {
+	return cli.ValidateOptions{
+		Profile:    profile,
+		ConfigPath: flags.config,
+		NoConfig:   flags.noConfig,
+	}
+}
+
 func withSignalContext(parent context.Context) (context.Context, context.CancelFunc) {
 	ctx, cancel := context.WithCancel(parent)
 	ch := make(chan os.Signal, 1)
@@ -141,7 +164,10 @@ func normalizeArgs(cmd *cobra.Command, args []string) {
 	subcommands := map[string]struct{}{
 		"validate":   {},
 		"repair":     {},
+		"optimize":   {},
 		"batch":      {},
+		"meta":       {},
+		"inspect":    {},
 		"examples":   {},
 		"help":       {},
 		"completion": {},
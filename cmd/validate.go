@@ -14,10 +14,14 @@ import (
 
 	"github.com/petergi/ebook-mechanic-lib/internal/cli"
 	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/pkg/ebmlib"
 )
 
 type validateFlags struct {
 	fileType string
+	profile  string
+	deep     bool
+	password string
 }
 
 func writeValidationReport(ctx context.Context, cmd *cobra.Command, root *rootFlags, report *domain.ValidationReport) error {
@@ -43,7 +47,7 @@ func writeValidationReport(ctx context.Context, cmd *cobra.Command, root *rootFl
 		}
 	}
 
-	return cli.ExitWithReport(report)
+	return cli.ExitWithReport(cli.FilterCategories(report, root.categories))
 }
 
 func newValidateCmd(root *rootFlags) *cobra.Command {
@@ -57,6 +61,11 @@ func newValidateCmd(root *rootFlags) *cobra.Command {
 			"  ebm-cli validate book.epub",
 			"  ebm-cli validate document.pdf --format json",
 			"  cat book.epub | ebm-cli validate - --type epub",
+			"  ebm-cli validate book.epub --profile apple",
+			"  ebm-cli validate book.epub --profile ./profiles/house-style.yaml",
+			"  ebm-cli validate book.pdf --profile print",
+			"  ebm-cli validate document.pdf --deep",
+			"  ebm-cli validate protected.pdf --password secret",
 		}, "\n"),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -64,6 +73,9 @@ func newValidateCmd(root *rootFlags) *cobra.Command {
 			defer cancel()
 
 			target := args[0]
+			opts := validateOptions(root, flags.profile)
+			opts.Deep = flags.deep
+			opts.Password = flags.password
 			var err error
 			var report *domain.ValidationReport
 
@@ -75,12 +87,12 @@ func newValidateCmd(root *rootFlags) *cobra.Command {
 				if err != nil {
 					return fmt.Errorf("read stdin: %w", err)
 				}
-				report, err = cli.ValidateReader(ctx, bytes.NewReader(data), int64(len(data)), flags.fileType)
+				report, err = cli.ValidateReaderWithOptions(ctx, bytes.NewReader(data), int64(len(data)), flags.fileType, opts)
 				if err != nil {
 					return err
 				}
 			} else {
-				report, err = cli.ValidateFile(ctx, target)
+				report, err = cli.ValidateFileWithOptions(ctx, target, opts)
 				if err != nil {
 					return err
 				}
@@ -91,5 +103,8 @@ func newValidateCmd(root *rootFlags) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&flags.fileType, "type", "", "Specify file type when reading from stdin (epub, pdf)")
+	cmd.Flags().StringVar(&flags.profile, "profile", "", "Validation profile for EPUB or PDF files ("+strings.Join(ebmlib.Profiles(), ", ")+", or a YAML/JSON file)")
+	cmd.Flags().BoolVar(&flags.deep, "deep", false, "Decode every PDF stream and check its /Length (slower)")
+	cmd.Flags().StringVar(&flags.password, "password", "", "Password that opens an encrypted PDF")
 	return cmd
 }
@@ -115,11 +115,12 @@ func validateAndDisplay(validator *epub.AccessibilityValidator, name string, con
 
 	fmt.Println("Score Breakdown:")
 	fmt.Printf("  ├─ Language Declaration:  %2d/%2d\n", result.Score.LanguageDeclaration, 5)
-	fmt.Printf("  ├─ Semantic Structure:    %2d/%2d\n", result.Score.SemanticStructure, 25)
+	fmt.Printf("  ├─ Semantic Structure:    %2d/%2d\n", result.Score.SemanticStructure, 20)
 	fmt.Printf("  ├─ ARIA Compliance:       %2d/%2d\n", result.Score.ARIACompliance, 20)
 	fmt.Printf("  ├─ Alt Text Completeness: %2d/%2d\n", result.Score.AltTextCompleteness, 25)
 	fmt.Printf("  ├─ Heading Hierarchy:     %2d/%2d\n", result.Score.HeadingHierarchy, 15)
-	fmt.Printf("  └─ Reading Order:         %2d/%2d\n\n", result.Score.ReadingOrder, 10)
+	fmt.Printf("  ├─ Reading Order:         %2d/%2d\n", result.Score.ReadingOrder, 10)
+	fmt.Printf("  └─ Math Accessibility:    %2d/%2d\n\n", result.Score.MathAccessibility, 5)
 
 	if len(result.Errors) > 0 {
 		fmt.Printf("Errors (%d):\n", len(result.Errors))
@@ -39,12 +39,13 @@ const (
 	MinimumScore       = 0
 	MaximumScore       = 100
 	PassingScore       = 80
-	SemanticWeight     = 25
+	SemanticWeight     = 20
 	ARIAWeight         = 20
 	AltTextWeight      = 25
 	HeadingWeight      = 15
 	ReadingOrderWeight = 10
 	LangWeight         = 5
+	MathWeight         = 5
 )
 
 // Valid ARIA roles (common subset).
@@ -105,6 +106,7 @@ type AccessibilityScore struct {
 	HeadingHierarchy    int                    `json:"heading_hierarchy"`
 	ReadingOrder        int                    `json:"reading_order"`
 	LanguageDeclaration int                    `json:"language_declaration"`
+	MathAccessibility   int                    `json:"math_accessibility"`
 	Details             map[string]interface{} `json:"details"`
 }
 
@@ -126,6 +128,7 @@ type AccessibilityValidationResult struct {
 	Valid                  bool
 	Errors                 []ValidationError
 	Warnings               []ValidationError
+	Suppressed             []ValidationError
 	Score                  AccessibilityScore
 	Metadata               AccessibilityMetadata
 	HasLanguageDeclaration bool
@@ -134,6 +137,9 @@ type AccessibilityValidationResult struct {
 	ImagesWithAlt          int
 	ImagesWithoutAlt       int
 	TotalImages            int
+	MathMLCount            int
+	MathWithAlt            int
+	TotalMath              int
 	HeadingStructure       []HeadingInfo
 	MediaOverlays          []MediaOverlayInfo
 	ReadingOrderIssues     int
@@ -224,6 +230,8 @@ func (v *AccessibilityValidator) Validate(reader io.Reader) (*AccessibilityValid
 	v.validateForms(doc, result)
 	v.validateMediaElements(doc, result)
 	v.validateLandmarks(doc, result)
+	v.validateMath(doc, result)
+	v.applySuppressions(doc, result)
 
 	v.calculateScore(result)
 	v.generateMetadata(result)
@@ -248,6 +256,7 @@ func (v *AccessibilityValidator) validateLanguageDeclaration(doc *html.Node, res
 			Code:    ErrorCodeA11YMissingLang,
 			Message: "HTML element must have lang and/or xml:lang attribute for language declaration",
 			Details: map[string]interface{}{},
+			node:    htmlNode,
 		})
 		return
 	}
@@ -266,6 +275,7 @@ func (v *AccessibilityValidator) validateLanguageDeclaration(doc *html.Node, res
 			Details: map[string]interface{}{
 				"language_code": langValue,
 			},
+			node: htmlNode,
 		})
 	}
 }
@@ -327,6 +337,7 @@ func (v *AccessibilityValidator) validateARIA(doc *html.Node, result *Accessibil
 							"role":    role,
 							"element": n.Data,
 						},
+						node: n,
 					})
 				}
 
@@ -339,6 +350,7 @@ func (v *AccessibilityValidator) validateARIA(doc *html.Node, result *Accessibil
 							"role":    role,
 							"element": n.Data,
 						},
+						node: n,
 					})
 				}
 			}
@@ -356,6 +368,7 @@ func (v *AccessibilityValidator) validateARIA(doc *html.Node, result *Accessibil
 								"attribute": attr.Key,
 								"element":   n.Data,
 							},
+							node: n,
 						})
 					}
 				}
@@ -405,6 +418,7 @@ func (v *AccessibilityValidator) validateImageAltText(doc *html.Node, result *Ac
 					Details: map[string]interface{}{
 						"src": src,
 					},
+					node: n,
 				})
 			case strings.TrimSpace(alt) == "":
 				result.ImagesWithAlt++
@@ -414,6 +428,7 @@ func (v *AccessibilityValidator) validateImageAltText(doc *html.Node, result *Ac
 					Details: map[string]interface{}{
 						"src": src,
 					},
+					node: n,
 				})
 			default:
 				result.ImagesWithAlt++
@@ -430,8 +445,67 @@ func (v *AccessibilityValidator) validateImageAltText(doc *html.Node, result *Ac
 	result.Score.Details["total_images"] = result.TotalImages
 }
 
+// validateMath counts the math in the document, as MathML or as images, and
+// how much of it has a text alternative. Findings are reported by
+// MathMLValidator; this only feeds the score.
+func (v *AccessibilityValidator) validateMath(doc *html.Node, result *AccessibilityValidationResult) {
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "math":
+				result.MathMLCount++
+				result.TotalMath++
+				if v.mathHasAlternative(n) {
+					result.MathWithAlt++
+				}
+				return
+			case n.Data == "img" && isMathImage(v.getAttribute(n, "src"), v.getAttribute(n, "class"), v.getAttribute(n, "role")):
+				result.TotalMath++
+				if strings.TrimSpace(v.getAttribute(n, "alt")) != "" {
+					result.MathWithAlt++
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+
+	result.Score.Details["mathml_count"] = result.MathMLCount
+	result.Score.Details["math_with_alt"] = result.MathWithAlt
+	result.Score.Details["total_math"] = result.TotalMath
+}
+
+// mathHasAlternative reports whether a math element has alttext, an ARIA
+// label or a text annotation.
+func (v *AccessibilityValidator) mathHasAlternative(n *html.Node) bool {
+	for _, key := range []string{"alttext", "aria-label", "aria-labelledby"} {
+		if strings.TrimSpace(v.getAttribute(n, key)) != "" {
+			return true
+		}
+	}
+
+	var found func(*html.Node) bool
+	found = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && (n.Data == "annotation" ||
+			n.Data == "annotation-xml" && isHTMLEncoding(v.getAttribute(n, "encoding"))) {
+			return strings.TrimSpace(v.extractText(n)) != ""
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if found(c) {
+				return true
+			}
+		}
+		return false
+	}
+	return found(n)
+}
+
 func (v *AccessibilityValidator) validateHeadingHierarchy(doc *html.Node, result *AccessibilityValidationResult) {
 	headings := make([]HeadingInfo, 0)
+	headingNodes := make([]*html.Node, 0)
 	var traverse func(*html.Node)
 	traverse = func(n *html.Node) {
 		if n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
@@ -444,6 +518,7 @@ func (v *AccessibilityValidator) validateHeadingHierarchy(doc *html.Node, result
 				Text:    text,
 				IsEmpty: isEmpty,
 			})
+			headingNodes = append(headingNodes, n)
 
 			if isEmpty {
 				result.Errors = append(result.Errors, ValidationError{
@@ -452,6 +527,7 @@ func (v *AccessibilityValidator) validateHeadingHierarchy(doc *html.Node, result
 					Details: map[string]interface{}{
 						"level": level,
 					},
+					node: n,
 				})
 			}
 		}
@@ -471,6 +547,7 @@ func (v *AccessibilityValidator) validateHeadingHierarchy(doc *html.Node, result
 				Details: map[string]interface{}{
 					"first_heading_level": headings[0].Level,
 				},
+				node: headingNodes[0],
 			})
 		}
 
@@ -484,6 +561,7 @@ func (v *AccessibilityValidator) validateHeadingHierarchy(doc *html.Node, result
 						"to_level":   headings[i].Level,
 						"text":       headings[i].Text,
 					},
+					node: headingNodes[i],
 				})
 			}
 		}
@@ -513,6 +591,7 @@ func (v *AccessibilityValidator) validateReadingOrder(doc *html.Node, result *Ac
 							"tabindex": tabIndex,
 							"element":  n.Data,
 						},
+						node: n,
 					})
 				}
 			}
@@ -568,6 +647,7 @@ func (v *AccessibilityValidator) validateTables(doc *html.Node, result *Accessib
 					Code:    ErrorCodeA11YMissingTableHeaders,
 					Message: "Data table missing header cells (<th>) or headers attribute",
 					Details: map[string]interface{}{},
+					node:    n,
 				})
 			}
 		}
@@ -609,6 +689,7 @@ func (v *AccessibilityValidator) validateForms(doc *html.Node, result *Accessibi
 							"element": n.Data,
 							"type":    inputType,
 						},
+						node: n,
 					})
 				}
 			}
@@ -675,6 +756,7 @@ func (v *AccessibilityValidator) validateMediaElements(doc *html.Node, result *A
 						Details: map[string]interface{}{
 							"src": src,
 						},
+						node: n,
 					})
 				}
 
@@ -685,6 +767,7 @@ func (v *AccessibilityValidator) validateMediaElements(doc *html.Node, result *A
 						Details: map[string]interface{}{
 							"src": src,
 						},
+						node: n,
 					})
 				}
 
@@ -697,6 +780,7 @@ func (v *AccessibilityValidator) validateMediaElements(doc *html.Node, result *A
 						Details: map[string]interface{}{
 							"element": n.Data,
 						},
+						node: n,
 					})
 				}
 			}
@@ -760,6 +844,18 @@ func (v *AccessibilityValidator) validateLandmarks(doc *html.Node, result *Acces
 	result.Score.Details["landmarks"] = landmarks
 }
 
+// applySuppressions moves findings covered by ebm-ignore comments into
+// result.Suppressed.
+func (v *AccessibilityValidator) applySuppressions(doc *html.Node, result *AccessibilityValidationResult) {
+	s := collectHTMLSuppressions(doc)
+
+	var suppressed []ValidationError
+	result.Errors, suppressed = s.partition(result.Errors, "error")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Warnings, suppressed = s.partition(result.Warnings, "warning")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+}
+
 func (v *AccessibilityValidator) calculateScore(result *AccessibilityValidationResult) {
 	langScore := 0
 	if result.HasLanguageDeclaration {
@@ -803,6 +899,11 @@ func (v *AccessibilityValidator) calculateScore(result *AccessibilityValidationR
 		headingScore = 0
 	}
 
+	mathScore := MathWeight
+	if result.TotalMath > 0 {
+		mathScore = (result.MathWithAlt * MathWeight) / result.TotalMath
+	}
+
 	readingOrderScore := ReadingOrderWeight
 	if result.ReadingOrderIssues > 0 {
 		readingOrderScore -= result.ReadingOrderIssues
@@ -817,7 +918,8 @@ func (v *AccessibilityValidator) calculateScore(result *AccessibilityValidationR
 	result.Score.AltTextCompleteness = altTextScore
 	result.Score.HeadingHierarchy = headingScore
 	result.Score.ReadingOrder = readingOrderScore
-	result.Score.Total = langScore + semanticScore + ariaScore + altTextScore + headingScore + readingOrderScore
+	result.Score.MathAccessibility = mathScore
+	result.Score.Total = langScore + semanticScore + ariaScore + altTextScore + headingScore + readingOrderScore + mathScore
 
 	if result.Score.Total < MinimumScore {
 		result.Score.Total = MinimumScore
@@ -846,6 +948,14 @@ func (v *AccessibilityValidator) generateMetadata(result *AccessibilityValidatio
 		result.Metadata.AccessibilityFeatures = append(result.Metadata.AccessibilityFeatures, "ARIA")
 	}
 
+	if result.MathMLCount > 0 {
+		result.Metadata.AccessibilityFeatures = append(result.Metadata.AccessibilityFeatures, "MathML")
+	}
+
+	if result.TotalMath > 0 && result.MathWithAlt == result.TotalMath {
+		result.Metadata.AccessibilityFeatures = append(result.Metadata.AccessibilityFeatures, "describedMath")
+	}
+
 	if len(result.HeadingStructure) > 0 {
 		result.Metadata.AccessibilityFeatures = append(result.Metadata.AccessibilityFeatures, "tableOfContents")
 	}
@@ -871,6 +981,9 @@ func (v *AccessibilityValidator) generateMetadata(result *AccessibilityValidatio
 	if result.TotalImages > 0 {
 		summary += fmt.Sprintf("%d of %d images have alternative text. ", result.ImagesWithAlt, result.TotalImages)
 	}
+	if result.TotalMath > 0 {
+		summary += fmt.Sprintf("%d of %d math expressions have alternative text. ", result.MathWithAlt, result.TotalMath)
+	}
 
 	result.Metadata.AccessibilitySummary = strings.TrimSpace(summary)
 }
@@ -0,0 +1,302 @@
+package epub
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ZIP container safety error codes.
+const (
+	ErrorCodeZIPUnsafePath       = "EPUB-CONTAINER-006"
+	ErrorCodeZIPDuplicateEntry   = "EPUB-CONTAINER-007"
+	ErrorCodeZIPCaseCollision    = "EPUB-CONTAINER-008"
+	ErrorCodeZIPCompressionRatio = "EPUB-CONTAINER-009"
+	ErrorCodeZIPTooLarge         = "EPUB-CONTAINER-010"
+	ErrorCodeZIPEncrypted        = "EPUB-CONTAINER-011"
+	ErrorCodeZIP64               = "EPUB-CONTAINER-012"
+	ErrorCodeZIPDataDescriptor   = "EPUB-CONTAINER-013"
+	ErrorCodeZIPNonUTF8Name      = "EPUB-CONTAINER-014"
+	ErrorCodeOCFFileName         = "EPUB-CONTAINER-015"
+)
+
+// ErrEntryTooLarge is returned when a ZIP entry exceeds the configured
+// ContainerLimits.MaxEntrySize.
+var ErrEntryTooLarge = errors.New("ZIP entry exceeds size limit")
+
+// ContainerLimits bounds the resources an EPUB container may claim. Zero
+// values disable the corresponding check.
+type ContainerLimits struct {
+	// MaxEntrySize is the largest uncompressed size read from one entry.
+	MaxEntrySize int64
+	// MaxTotalSize is the largest total uncompressed size of all entries.
+	MaxTotalSize int64
+	// MaxCompressionRatio is the largest uncompressed/compressed ratio
+	// accepted for entries of at least MinRatioCheckSize bytes.
+	MaxCompressionRatio float64
+	MinRatioCheckSize   int64
+}
+
+// DefaultContainerLimits returns limits suited to untrusted uploads.
+func DefaultContainerLimits() ContainerLimits {
+	return ContainerLimits{
+		MaxEntrySize:        256 << 20,
+		MaxTotalSize:        2 << 30,
+		MaxCompressionRatio: 100,
+		MinRatioCheckSize:   1 << 20,
+	}
+}
+
+const (
+	zipFlagEncrypted      = 0x1
+	zipFlagDataDescriptor = 0x8
+	zip64ExtraID          = 0x0001
+	maxOCFNameBytes       = 255
+	maxOCFPathBytes       = 65535
+)
+
+// validateSafety checks the archive layout before any entry is read. Entries
+// that exceed the size or compression limits make the container unusable;
+// the other findings are reported without stopping validation.
+func (v *ContainerValidator) validateSafety(zipReader *zip.Reader, result *ValidationResult) {
+	limits := v.Limits
+	seen := make(map[string]bool, len(zipReader.File))
+	folded := make(map[string]string, len(zipReader.File))
+	var total uint64
+
+	for _, f := range zipReader.File {
+		name := f.Name
+
+		if reason := unsafeZipPath(name); reason != "" {
+			v.addError(result, ErrorCodeZIPUnsafePath,
+				fmt.Sprintf("ZIP entry '%s' %s", name, reason), name, nil)
+		}
+
+		if seen[name] {
+			v.addError(result, ErrorCodeZIPDuplicateEntry,
+				fmt.Sprintf("ZIP entry '%s' appears more than once", name), name, nil)
+		} else {
+			seen[name] = true
+			key := strings.ToLower(name)
+			if other, exists := folded[key]; exists {
+				v.addError(result, ErrorCodeZIPCaseCollision,
+					fmt.Sprintf("ZIP entries '%s' and '%s' differ only by case", other, name), name,
+					map[string]interface{}{"conflicts_with": other})
+			} else {
+				folded[key] = name
+			}
+		}
+
+		if f.Flags&zipFlagEncrypted != 0 {
+			v.addError(result, ErrorCodeZIPEncrypted,
+				fmt.Sprintf("ZIP entry '%s' uses ZIP encryption, which OCF forbids", name), name, nil)
+		}
+
+		if f.NonUTF8 || !utf8.ValidString(name) {
+			v.addError(result, ErrorCodeZIPNonUTF8Name,
+				fmt.Sprintf("ZIP entry name '%s' is not UTF-8 encoded", name), name, nil)
+		} else if reason := ocfFileNameProblem(name); reason != "" {
+			v.addError(result, ErrorCodeOCFFileName,
+				fmt.Sprintf("File name '%s' %s", name, reason), name, nil)
+		}
+
+		if usesZIP64(f) {
+			v.addWarning(result, ErrorCodeZIP64,
+				fmt.Sprintf("ZIP entry '%s' uses ZIP64 extensions, which some reading systems do not support", name), name, nil)
+		}
+		// Deflate streams mark their own end; stored entries need the sizes
+		// from the local header to be read without the central directory.
+		if f.Flags&zipFlagDataDescriptor != 0 && f.Method == zip.Store {
+			v.addInfo(result, ErrorCodeZIPDataDescriptor,
+				fmt.Sprintf("Stored ZIP entry '%s' keeps its sizes in a trailing data descriptor, which streaming readers cannot follow", name), name, nil)
+		}
+
+		if limits.MaxCompressionRatio > 0 && f.UncompressedSize64 >= uint64(limits.MinRatioCheckSize) { //nolint:gosec
+			compressed := f.CompressedSize64
+			if compressed == 0 {
+				compressed = 1
+			}
+			ratio := float64(f.UncompressedSize64) / float64(compressed)
+			if ratio > limits.MaxCompressionRatio {
+				result.Valid = false
+				v.addError(result, ErrorCodeZIPCompressionRatio,
+					fmt.Sprintf("ZIP entry '%s' has a compression ratio of %.0f:1, above the limit of %.0f:1", name, ratio, limits.MaxCompressionRatio),
+					name, map[string]interface{}{
+						"compressed_size":   f.CompressedSize64,
+						"uncompressed_size": f.UncompressedSize64,
+						"ratio":             ratio,
+						"limit":             limits.MaxCompressionRatio,
+					})
+			}
+		}
+		if limits.MaxEntrySize > 0 && f.UncompressedSize64 > uint64(limits.MaxEntrySize) {
+			result.Valid = false
+			v.addError(result, ErrorCodeZIPTooLarge,
+				fmt.Sprintf("ZIP entry '%s' is %d bytes uncompressed, above the limit of %d", name, f.UncompressedSize64, limits.MaxEntrySize),
+				name, map[string]interface{}{
+					"uncompressed_size": f.UncompressedSize64,
+					"limit":             limits.MaxEntrySize,
+				})
+		}
+
+		total += f.UncompressedSize64
+	}
+
+	if limits.MaxTotalSize > 0 && total > uint64(limits.MaxTotalSize) {
+		result.Valid = false
+		v.addError(result, ErrorCodeZIPTooLarge,
+			fmt.Sprintf("EPUB expands to %d bytes, above the limit of %d", total, limits.MaxTotalSize),
+			"", map[string]interface{}{
+				"uncompressed_size": total,
+				"limit":             limits.MaxTotalSize,
+			})
+	}
+}
+
+func (v *ContainerValidator) addError(result *ValidationResult, code, message, entry string, details map[string]interface{}) {
+	result.Errors = append(result.Errors, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: withEntry(details, entry),
+	})
+}
+
+func (v *ContainerValidator) addWarning(result *ValidationResult, code, message, entry string, details map[string]interface{}) {
+	result.Warnings = append(result.Warnings, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: withEntry(details, entry),
+	})
+}
+
+func (v *ContainerValidator) addInfo(result *ValidationResult, code, message, entry string, details map[string]interface{}) {
+	result.Info = append(result.Info, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: withEntry(details, entry),
+	})
+}
+
+func withEntry(details map[string]interface{}, entry string) map[string]interface{} {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	if entry != "" {
+		details["entry"] = entry
+	}
+	return details
+}
+
+// unsafeZipPath explains why name would escape the extraction directory.
+func unsafeZipPath(name string) string {
+	normalized := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(normalized, "/") {
+		return "has an absolute path"
+	}
+	if len(normalized) >= 2 && normalized[1] == ':' {
+		return "has a drive-qualified path"
+	}
+	for _, segment := range strings.Split(normalized, "/") {
+		if segment == ".." {
+			return "has a path that escapes the container with '..'"
+		}
+	}
+	return ""
+}
+
+// ocfFileNameProblem explains why name violates the OCF file name
+// restrictions, or returns "".
+func ocfFileNameProblem(name string) string {
+	if len(name) > maxOCFPathBytes {
+		return fmt.Sprintf("is longer than %d bytes", maxOCFPathBytes)
+	}
+	segments := strings.Split(strings.TrimSuffix(name, "/"), "/")
+	for _, segment := range segments {
+		if len(segment) > maxOCFNameBytes {
+			return fmt.Sprintf("has a path segment longer than %d bytes", maxOCFNameBytes)
+		}
+		if strings.HasSuffix(segment, ".") && segment != "." && segment != ".." {
+			return "has a path segment ending in '.'"
+		}
+	}
+	for _, r := range name {
+		if ocfForbiddenRune(r) {
+			return fmt.Sprintf("contains the character %U, which OCF forbids", r)
+		}
+	}
+	return ""
+}
+
+func ocfForbiddenRune(r rune) bool {
+	switch {
+	case r <= 0x1F, r == 0x7F:
+		return true
+	case strings.ContainsRune(`"*:<>?\`, r):
+		return true
+	case r >= 0xE000 && r <= 0xF8FF: // private use area
+		return true
+	case r >= 0xFDD0 && r <= 0xFDEF: // non-characters
+		return true
+	case r >= 0xFFF0 && r <= 0xFFFF: // specials
+		return true
+	case r >= 0xE0000 && r <= 0xE0FFF: // tags and variation selectors supplement
+		return true
+	case r >= 0xF0000: // supplementary private use areas
+		return true
+	case r&0xFFFE == 0xFFFE: // plane-final non-characters
+		return true
+	}
+	return false
+}
+
+// usesZIP64 reports whether the central directory record carries a ZIP64
+// extended information field.
+func usesZIP64(f *zip.File) bool {
+	extra := f.Extra
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra[0:2])
+		size := int(binary.LittleEndian.Uint16(extra[2:4]))
+		if id == zip64ExtraID {
+			return true
+		}
+		if len(extra) < 4+size {
+			break
+		}
+		extra = extra[4+size:]
+	}
+	return f.UncompressedSize64 >= 0xFFFFFFFF || f.CompressedSize64 >= 0xFFFFFFFF
+}
+
+// readZipEntryLimited reads a whole entry, failing with ErrEntryTooLarge
+// instead of reading more than limit bytes. A limit of zero or less reads
+// without bound.
+func readZipEntryLimited(f *zip.File, limit int64) ([]byte, error) {
+	if limit > 0 && f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%w: %s is %d bytes", ErrEntryTooLarge, f.Name, f.UncompressedSize64)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	var reader io.Reader = rc
+	if limit > 0 {
+		reader = io.LimitReader(rc, limit+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrEntryTooLarge, f.Name, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,279 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+const safetyContainerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+type safetyEntry struct {
+	header  zip.FileHeader
+	content string
+	stored  bool
+}
+
+// writeStoredEntry writes an uncompressed entry whose sizes and CRC are in
+// the local header instead of a data descriptor.
+func writeStoredEntry(zipWriter *zip.Writer, name string, data []byte) error {
+	w, err := zipWriter.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(data),
+		CompressedSize64:   uint64(len(data)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// buildSafetyTestZIP writes a stored mimetype and container.xml followed by
+// the given entries.
+func buildSafetyTestZIP(t *testing.T, entries ...safetyEntry) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+
+	if err := writeStoredEntry(zipWriter, MimetypeFilename, []byte(ExpectedMimetype)); err != nil {
+		t.Fatalf("Failed to write mimetype: %v", err)
+	}
+	if err := writeStoredEntry(zipWriter, ContainerXMLPath, []byte(safetyContainerXML)); err != nil {
+		t.Fatalf("Failed to write container.xml: %v", err)
+	}
+
+	for _, entry := range entries {
+		header := entry.header
+		header.Method = zip.Deflate
+		if entry.stored {
+			header.Method = zip.Store
+		}
+		w, err := zipWriter.CreateHeader(&header)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", header.Name, err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", header.Name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func findingCodes(findings []ValidationError) map[string][]ValidationError {
+	codes := make(map[string][]ValidationError)
+	for _, finding := range findings {
+		codes[finding.Code] = append(codes[finding.Code], finding)
+	}
+	return codes
+}
+
+func TestContainerValidator_Safety_CleanArchive(t *testing.T) {
+	result, err := NewContainerValidator().ValidateBytes(buildSafetyTestZIP(t,
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/content.opf"}, content: "<package/>"},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/Text/chapter one.xhtml"}, content: "<html/>"},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Valid || len(result.Errors) != 0 || len(result.Warnings) != 0 {
+		t.Errorf("expected a clean container, got errors %v and warnings %v", result.Errors, result.Warnings)
+	}
+}
+
+func TestContainerValidator_Safety_Naming(t *testing.T) {
+	result, err := NewContainerValidator().ValidateBytes(buildSafetyTestZIP(t,
+		safetyEntry{header: zip.FileHeader{Name: "../evil.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "/etc/passwd"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/a.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/a.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/Chapter.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/chapter.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/what?.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/trailing./x.xhtml"}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/\xff.xhtml"}},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	codes := findingCodes(result.Errors)
+	expected := map[string][]string{
+		ErrorCodeZIPUnsafePath:     {"../evil.xhtml", "/etc/passwd"},
+		ErrorCodeZIPDuplicateEntry: {"OEBPS/a.xhtml"},
+		ErrorCodeZIPCaseCollision:  {"OEBPS/chapter.xhtml"},
+		ErrorCodeOCFFileName:       {"OEBPS/what?.xhtml", "OEBPS/trailing./x.xhtml"},
+		ErrorCodeZIPNonUTF8Name:    {"OEBPS/\xff.xhtml"},
+	}
+	for code, entries := range expected {
+		if len(codes[code]) != len(entries) {
+			t.Errorf("expected %d %s findings, got %v", len(entries), code, codes[code])
+			continue
+		}
+		for i, entry := range entries {
+			if got := codes[code][i].Details["entry"]; got != entry {
+				t.Errorf("%s: expected entry %q, got %v", code, entry, got)
+			}
+		}
+	}
+	if conflict := codes[ErrorCodeZIPCaseCollision][0].Details["conflicts_with"]; conflict != "OEBPS/Chapter.xhtml" {
+		t.Errorf("expected case collision with OEBPS/Chapter.xhtml, got %v", conflict)
+	}
+
+	if !result.Valid {
+		t.Errorf("naming findings should not stop container validation: %v", result.Errors)
+	}
+	if len(result.Rootfiles) != 1 {
+		t.Errorf("expected container.xml to still be parsed, got %d rootfiles", len(result.Rootfiles))
+	}
+}
+
+func TestContainerValidator_Safety_EntryFlags(t *testing.T) {
+	result, err := NewContainerValidator().ValidateBytes(buildSafetyTestZIP(t,
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/locked.xhtml", Flags: zipFlagEncrypted}},
+		safetyEntry{header: zip.FileHeader{Name: "OEBPS/stored.xhtml"}, content: "<html/>", stored: true},
+	))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errorCodes := findingCodes(result.Errors)
+	if len(errorCodes[ErrorCodeZIPEncrypted]) != 1 {
+		t.Errorf("expected an encrypted entry error, got %v", result.Errors)
+	}
+
+	if len(findingCodes(result.Warnings)[ErrorCodeZIPDataDescriptor]) != 0 {
+		t.Errorf("expected data descriptors not to be warnings, got %v", result.Warnings)
+	}
+	descriptors := findingCodes(result.Info)[ErrorCodeZIPDataDescriptor]
+	if len(descriptors) != 1 || descriptors[0].Details["entry"] != "OEBPS/stored.xhtml" {
+		t.Errorf("expected a data descriptor note for the stored entry only, got %v", result.Info)
+	}
+}
+
+func TestContainerValidator_Safety_Limits(t *testing.T) {
+	bomb := strings.Repeat("0", 64<<10)
+
+	tests := []struct {
+		name     string
+		limits   ContainerLimits
+		code     string
+		expected int
+	}{
+		{
+			name:     "compression ratio",
+			limits:   ContainerLimits{MaxCompressionRatio: 10, MinRatioCheckSize: 1 << 10},
+			code:     ErrorCodeZIPCompressionRatio,
+			expected: 2,
+		},
+		{
+			name:     "ratio below minimum size",
+			limits:   ContainerLimits{MaxCompressionRatio: 10, MinRatioCheckSize: 1 << 20},
+			code:     ErrorCodeZIPCompressionRatio,
+			expected: 0,
+		},
+		{
+			name:     "entry size",
+			limits:   ContainerLimits{MaxEntrySize: 32 << 10},
+			code:     ErrorCodeZIPTooLarge,
+			expected: 2,
+		},
+		{
+			name:     "total size",
+			limits:   ContainerLimits{MaxTotalSize: 100 << 10},
+			code:     ErrorCodeZIPTooLarge,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewContainerValidator()
+			validator.Limits = tt.limits
+
+			result, err := validator.ValidateBytes(buildSafetyTestZIP(t,
+				safetyEntry{header: zip.FileHeader{Name: "OEBPS/a.txt"}, content: bomb},
+				safetyEntry{header: zip.FileHeader{Name: "OEBPS/b.txt"}, content: bomb},
+			))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			found := findingCodes(result.Errors)[tt.code]
+			if len(found) != tt.expected {
+				t.Fatalf("expected %d %s findings, got %v", tt.expected, tt.code, result.Errors)
+			}
+			if tt.expected > 0 {
+				if result.Valid {
+					t.Error("expected limit violations to invalidate the container")
+				}
+				if len(result.Rootfiles) != 0 {
+					t.Error("expected validation to stop before container.xml is read")
+				}
+			}
+		})
+	}
+}
+
+func TestReadZipEntryLimited(t *testing.T) {
+	data := buildSafetyTestZIP(t, safetyEntry{header: zip.FileHeader{Name: "OEBPS/big.txt"}, content: strings.Repeat("x", 100)})
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := zipReader.File[2]
+
+	if got, err := readZipEntryLimited(f, 100); err != nil || len(got) != 100 {
+		t.Errorf("expected entry at the limit to be read, got %d bytes: %v", len(got), err)
+	}
+	if got, err := readZipEntryLimited(f, 0); err != nil || len(got) != 100 {
+		t.Errorf("expected unbounded read, got %d bytes: %v", len(got), err)
+	}
+	if _, err := readZipEntryLimited(f, 99); !errors.Is(err, ErrEntryTooLarge) {
+		t.Errorf("expected ErrEntryTooLarge, got %v", err)
+	}
+
+	// A central directory that understates the size must not bypass the
+	// limit.
+	f.UncompressedSize64 = 10
+	if _, err := readZipEntryLimited(f, 50); err == nil {
+		t.Error("expected an error when the entry is larger than declared")
+	}
+}
+
+func TestEPUBValidator_ContainerSafetyFindings(t *testing.T) {
+	validator := NewEPUBValidator()
+	report, err := validator.ValidateReader(context.Background(), bytes.NewReader(buildSafetyTestZIP(t,
+		safetyEntry{header: zip.FileHeader{Name: "../evil.xhtml"}},
+	)), 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, e := range report.Errors {
+		if e.Code != ErrorCodeZIPUnsafePath {
+			continue
+		}
+		if e.Location == nil || e.Location.Path != "../evil.xhtml" {
+			t.Errorf("expected finding located at the entry, got %+v", e.Location)
+		}
+		return
+	}
+	t.Errorf("expected %s in report, got %v", ErrorCodeZIPUnsafePath, report.Errors)
+}
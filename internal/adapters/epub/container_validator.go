@@ -9,6 +9,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"golang.org/x/net/html"
 )
 
 // Container validation error codes.
@@ -45,21 +47,33 @@ type ValidationError struct {
 	Code    string
 	Message string
 	Details map[string]interface{}
+
+	// node and element locate the finding in its document so inline
+	// ebm-ignore directives can be matched; see suppression.go.
+	node    *html.Node
+	element string
 }
 
 // ValidationResult aggregates container validation findings.
 type ValidationResult struct {
+	// Valid reports whether the container can be processed further. ZIP
+	// naming and layout findings are listed in Errors without clearing it.
 	Valid     bool
 	Errors    []ValidationError
+	Warnings  []ValidationError
+	Info      []ValidationError
 	Rootfiles []Rootfile
 }
 
 // ContainerValidator validates EPUB container structure.
-type ContainerValidator struct{}
+type ContainerValidator struct {
+	// Limits bounds entry and archive sizes; see DefaultContainerLimits.
+	Limits ContainerLimits
+}
 
 // NewContainerValidator returns a new container validator.
 func NewContainerValidator() *ContainerValidator {
-	return &ContainerValidator{}
+	return &ContainerValidator{Limits: DefaultContainerLimits()}
 }
 
 // ValidateFile validates an EPUB file on disk.
@@ -100,6 +114,11 @@ func (v *ContainerValidator) Validate(reader io.ReaderAt, size int64) (*Validati
 		return result, nil //nolint:nilerr
 	}
 
+	v.validateSafety(zipReader, result)
+	if !result.Valid {
+		return result, nil
+	}
+
 	if err := v.validateMimetype(zipReader, result); err != nil {
 		return nil, err
 	}
@@ -146,15 +165,7 @@ func (v *ContainerValidator) validateMimetype(zipReader *zip.Reader, result *Val
 		})
 	}
 
-	rc, err := firstFile.Open()
-	if err != nil {
-		return fmt.Errorf("failed to open mimetype file: %w", err)
-	}
-	defer func() {
-		_ = rc.Close()
-	}()
-
-	mimetypeBytes, err := io.ReadAll(rc)
+	mimetypeBytes, err := readZipEntryLimited(firstFile, v.Limits.MaxEntrySize)
 	if err != nil {
 		return fmt.Errorf("failed to read mimetype file: %w", err)
 	}
@@ -196,15 +207,7 @@ func (v *ContainerValidator) validateContainerXML(zipReader *zip.Reader, result
 		return nil
 	}
 
-	rc, err := containerFile.Open()
-	if err != nil {
-		return fmt.Errorf("failed to open container.xml: %w", err)
-	}
-	defer func() {
-		_ = rc.Close()
-	}()
-
-	containerBytes, err := io.ReadAll(rc)
+	containerBytes, err := readZipEntryLimited(containerFile, v.Limits.MaxEntrySize)
 	if err != nil {
 		return fmt.Errorf("failed to read container.xml: %w", err)
 	}
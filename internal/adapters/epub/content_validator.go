@@ -1,6 +1,7 @@
 package epub
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ type ContentValidationResult struct {
 	HasHead    bool
 	HasBody    bool
 	Namespace  string
+	Suppressed []ValidationError
 }
 
 // ContentValidator validates XHTML content documents.
@@ -72,7 +74,39 @@ func (v *ContentValidator) Validate(reader io.Reader) (*ContentValidationResult,
 		Errors: make([]ValidationError, 0),
 	}
 
-	tokenizer := html.NewTokenizer(reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodeContentNotWellFormed,
+			Message: "Content document is not well-formed XHTML",
+			Details: map[string]interface{}{
+				"error": err.Error(),
+			},
+		})
+		return result, nil
+	}
+
+	// The tree locates findings for ebm-ignore directives; the checks run on
+	// tokens because the parser adds missing html, head and body elements.
+	var htmlNode *html.Node
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		doc = nil
+	} else {
+		htmlNode = findLanguageElement(doc, "html")
+	}
+
+	v.validateTokens(data, htmlNode, result)
+	v.applySuppressions(doc, result)
+
+	return result, nil
+}
+
+// validateTokens checks the document structure. Findings about the doctype
+// and the root element are attached to htmlNode, the parsed html element.
+func (v *ContentValidator) validateTokens(data []byte, htmlNode *html.Node, result *ContentValidationResult) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(data))
 
 	foundHTML := false
 	foundHead := false
@@ -96,7 +130,7 @@ func (v *ContentValidator) Validate(reader io.Reader) (*ContentValidationResult,
 					"error": err.Error(),
 				},
 			})
-			return result, nil
+			return
 		}
 
 		switch tokenType {
@@ -114,6 +148,7 @@ func (v *ContentValidator) Validate(reader io.Reader) (*ContentValidationResult,
 						"expected": "<!DOCTYPE html>",
 						"found":    token.Data,
 					},
+					node: htmlNode,
 				})
 			}
 
@@ -146,6 +181,7 @@ func (v *ContentValidator) Validate(reader io.Reader) (*ContentValidationResult,
 							"expected": XHTMLNamespace,
 							"found":    htmlNamespace,
 						},
+						node: htmlNode,
 					})
 				}
 			case "head":
@@ -199,6 +235,20 @@ func (v *ContentValidator) Validate(reader io.Reader) (*ContentValidationResult,
 			Details: map[string]interface{}{},
 		})
 	}
+}
 
-	return result, nil
+// applySuppressions moves findings covered by ebm-ignore comments in doc
+// into result.Suppressed.
+func (v *ContentValidator) applySuppressions(doc *html.Node, result *ContentValidationResult) {
+	if len(result.Errors) == 0 || doc == nil {
+		return
+	}
+
+	var suppressed []ValidationError
+	result.Errors, suppressed = collectHTMLSuppressions(doc).partition(result.Errors, "error")
+	if len(suppressed) == 0 {
+		return
+	}
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Valid = len(result.Errors) == 0
 }
@@ -0,0 +1,273 @@
+package epub
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Duplicate content error codes.
+const (
+	ErrorCodeContentDuplicate     = "EPUB-CONTENT-009"
+	ErrorCodeContentNearDuplicate = "EPUB-CONTENT-010"
+)
+
+// shingleSize is the number of consecutive words hashed into one shingle
+// when comparing documents.
+const shingleSize = 5
+
+// SpineDocument is a spine content document submitted for duplicate analysis.
+type SpineDocument struct {
+	ManifestID string
+	Path       string
+	Data       []byte
+}
+
+// DuplicateContentResult lists duplicate content findings. Every finding
+// names the later document of a pair in Details["path"]; the earlier one, in
+// spine order, is Details["duplicate_of"].
+type DuplicateContentResult struct {
+	Warnings   []ValidationError
+	Suppressed []ValidationError
+}
+
+// DuplicateContentDetector compares the normalized text of spine documents
+// to find chapters shipped twice, either as the same file referenced by
+// several spine entries or as copies under different names.
+type DuplicateContentDetector struct {
+	// SimilarityThreshold is the shingle similarity, from 0 to 1, at or above
+	// which two documents are reported as near-duplicates.
+	SimilarityThreshold float64
+	// MinWords is the number of words a document needs before it is
+	// compared. Shorter documents, such as blank or image-only pages, repeat
+	// legitimately.
+	MinWords int
+}
+
+// NewDuplicateContentDetector returns a detector with the default threshold
+// and minimum document length.
+func NewDuplicateContentDetector() *DuplicateContentDetector {
+	return &DuplicateContentDetector{
+		SimilarityThreshold: 0.9,
+		MinWords:            50,
+	}
+}
+
+type fingerprintedDocument struct {
+	SpineDocument
+	hash     string
+	shingles map[uint64]struct{}
+	ignore   *suppressions
+}
+
+// Detect reports spine documents whose text repeats an earlier one, exactly
+// or above SimilarityThreshold. Documents that fail to parse are skipped; the
+// content validator reports them.
+func (d *DuplicateContentDetector) Detect(docs []SpineDocument) *DuplicateContentResult {
+	result := &DuplicateContentResult{
+		Warnings: make([]ValidationError, 0),
+	}
+
+	seenPaths := make(map[string]SpineDocument, len(docs))
+	var candidates []*fingerprintedDocument
+
+	for _, doc := range docs {
+		if first, seen := seenPaths[doc.Path]; seen {
+			d.add(result, nil, ValidationError{
+				Code:    ErrorCodeContentDuplicate,
+				Message: fmt.Sprintf("Spine references %s more than once (manifest ids %s and %s)", doc.Path, first.ManifestID, doc.ManifestID),
+				Details: map[string]interface{}{
+					"path":            doc.Path,
+					"manifest_id":     doc.ManifestID,
+					"duplicate_of":    first.Path,
+					"duplicate_of_id": first.ManifestID,
+					"similarity":      1.0,
+					"same_file":       true,
+				},
+			})
+			continue
+		}
+		seenPaths[doc.Path] = doc
+
+		parsed, err := html.Parse(bytes.NewReader(doc.Data))
+		if err != nil {
+			continue
+		}
+		words := normalizedWords(parsed)
+		if len(words) < d.MinWords {
+			continue
+		}
+		sum := sha256.Sum256([]byte(strings.Join(words, " ")))
+		candidates = append(candidates, &fingerprintedDocument{
+			SpineDocument: doc,
+			hash:          hex.EncodeToString(sum[:]),
+			shingles:      wordShingles(words),
+			ignore:        collectHTMLSuppressions(parsed),
+		})
+	}
+
+	firstByHash := make(map[string]*fingerprintedDocument, len(candidates))
+	var distinct []*fingerprintedDocument
+	for _, doc := range candidates {
+		first, seen := firstByHash[doc.hash]
+		if !seen {
+			firstByHash[doc.hash] = doc
+			distinct = append(distinct, doc)
+			continue
+		}
+		d.add(result, doc.ignore, ValidationError{
+			Code:    ErrorCodeContentDuplicate,
+			Message: fmt.Sprintf("Content document %s has the same text as %s", doc.Path, first.Path),
+			Details: map[string]interface{}{
+				"path":            doc.Path,
+				"manifest_id":     doc.ManifestID,
+				"duplicate_of":    first.Path,
+				"duplicate_of_id": first.ManifestID,
+				"similarity":      1.0,
+				"content_hash":    doc.hash,
+			},
+		})
+	}
+
+	for j, doc := range distinct {
+		for _, earlier := range distinct[:j] {
+			// Jaccard similarity cannot exceed the ratio of the set sizes.
+			smaller, larger := float64(len(earlier.shingles)), float64(len(doc.shingles))
+			if smaller > larger {
+				smaller, larger = larger, smaller
+			}
+			if smaller/larger < d.SimilarityThreshold {
+				continue
+			}
+			similarity := shingleSimilarity(earlier.shingles, doc.shingles)
+			if similarity < d.SimilarityThreshold {
+				continue
+			}
+			d.add(result, doc.ignore, ValidationError{
+				Code: ErrorCodeContentNearDuplicate,
+				Message: fmt.Sprintf("Content document %s is %.0f%% similar to %s",
+					doc.Path, math.Floor(similarity*100), earlier.Path),
+				Details: map[string]interface{}{
+					"path":            doc.Path,
+					"manifest_id":     doc.ManifestID,
+					"duplicate_of":    earlier.Path,
+					"duplicate_of_id": earlier.ManifestID,
+					"similarity":      math.Round(similarity*1000) / 1000,
+					"threshold":       d.SimilarityThreshold,
+				},
+			})
+			break
+		}
+	}
+
+	return result
+}
+
+func (d *DuplicateContentDetector) add(result *DuplicateContentResult, ignore *suppressions, finding ValidationError) {
+	kept, suppressed := ignore.partition([]ValidationError{finding}, "warning")
+	result.Warnings = append(result.Warnings, kept...)
+	result.Suppressed = append(result.Suppressed, suppressed...)
+}
+
+// normalizedWords returns the lower-cased words of the document body, so
+// markup, punctuation and whitespace differences do not hide a copy.
+func normalizedWords(doc *html.Node) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inBody bool) {
+		breaksWords := false
+		if n.Type == html.ElementNode {
+			name := strings.ToLower(n.Data)
+			switch name {
+			case "script", "style", "head":
+				return
+			case "body":
+				inBody = true
+			}
+			breaksWords = !inlineElements[name]
+		}
+		if breaksWords {
+			flush()
+		}
+		if n.Type == html.TextNode && inBody {
+			for _, r := range n.Data {
+				if unicode.IsLetter(r) || unicode.IsDigit(r) {
+					current.WriteRune(unicode.ToLower(r))
+				} else {
+					flush()
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+		if breaksWords {
+			flush()
+		}
+	}
+	walk(doc, false)
+	flush()
+	return words
+}
+
+// inlineElements do not separate words, so "<b>W</b>ord" reads as one word.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true,
+	"code": true, "dfn": true, "em": true, "i": true, "kbd": true, "mark": true,
+	"q": true, "s": true, "samp": true, "small": true, "span": true,
+	"strong": true, "sub": true, "sup": true, "u": true, "var": true,
+}
+
+// wordShingles hashes every run of shingleSize consecutive words.
+func wordShingles(words []string) map[uint64]struct{} {
+	shingles := make(map[uint64]struct{}, len(words))
+	if len(words) < shingleSize {
+		shingles[hashWords(words)] = struct{}{}
+		return shingles
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		shingles[hashWords(words[i:i+shingleSize])] = struct{}{}
+	}
+	return shingles
+}
+
+func hashWords(words []string) uint64 {
+	h := fnv.New64a()
+	for _, word := range words {
+		_, _ = h.Write([]byte(word))
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// shingleSimilarity returns the Jaccard similarity of two shingle sets.
+func shingleSimilarity(a, b map[uint64]struct{}) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
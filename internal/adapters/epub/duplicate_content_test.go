@@ -0,0 +1,207 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// duplicateTestWords returns n distinct words seeded by prefix.
+func duplicateTestWords(prefix string, n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return words
+}
+
+func duplicateTestDocument(head string, words []string) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter</title>%s</head>
+<body><p>%s</p></body>
+</html>`, head, strings.Join(words, " ")))
+}
+
+func TestDuplicateContentDetector_Detect(t *testing.T) {
+	chapter := duplicateTestWords("alpha", 100)
+
+	edited := append([]string(nil), chapter...)
+	edited[50] = "changed"
+
+	reworked := append([]string(nil), chapter...)
+	for i := 0; i < len(reworked); i += 4 {
+		reworked[i] = "changed"
+	}
+
+	reformatted := []byte(`<html><head><title>Other title</title></head><body><h1>` +
+		strings.ToUpper(strings.Join(chapter[:10], ", ")) + `</h1><div><p>` +
+		strings.Join(chapter[10:], "\n  ") + `</p></div></body></html>`)
+
+	tests := []struct {
+		name       string
+		docs       []SpineDocument
+		code       string
+		similarity float64
+	}{
+		{
+			name: "same file twice",
+			docs: []SpineDocument{
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+			},
+			code:       ErrorCodeContentDuplicate,
+			similarity: 1.0,
+		},
+		{
+			name: "copy with different markup",
+			docs: []SpineDocument{
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+				{ManifestID: "ch1-copy", Path: "OEBPS/ch1-copy.xhtml", Data: reformatted},
+			},
+			code:       ErrorCodeContentDuplicate,
+			similarity: 1.0,
+		},
+		{
+			name: "one word changed",
+			docs: []SpineDocument{
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+				{ManifestID: "ch2", Path: "OEBPS/ch2.xhtml", Data: duplicateTestDocument("", edited)},
+			},
+			code:       ErrorCodeContentNearDuplicate,
+			similarity: 0.901,
+		},
+		{
+			name: "below threshold",
+			docs: []SpineDocument{
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+				{ManifestID: "ch2", Path: "OEBPS/ch2.xhtml", Data: duplicateTestDocument("", reworked)},
+			},
+		},
+		{
+			name: "short pages repeat",
+			docs: []SpineDocument{
+				{ManifestID: "blank1", Path: "OEBPS/blank1.xhtml", Data: duplicateTestDocument("", []string{"intentionally", "blank"})},
+				{ManifestID: "blank2", Path: "OEBPS/blank2.xhtml", Data: duplicateTestDocument("", []string{"intentionally", "blank"})},
+			},
+		},
+		{
+			name: "distinct chapters",
+			docs: []SpineDocument{
+				{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+				{ManifestID: "ch2", Path: "OEBPS/ch2.xhtml", Data: duplicateTestDocument("", duplicateTestWords("beta", 100))},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewDuplicateContentDetector().Detect(tt.docs)
+
+			if tt.code == "" {
+				if len(result.Warnings) != 0 {
+					t.Errorf("expected no findings, got %v", result.Warnings)
+				}
+				return
+			}
+			if len(result.Warnings) != 1 {
+				t.Fatalf("expected one finding, got %v", result.Warnings)
+			}
+
+			finding := result.Warnings[0]
+			if finding.Code != tt.code {
+				t.Errorf("expected %s, got %s", tt.code, finding.Code)
+			}
+			if finding.Details["path"] != tt.docs[1].Path || finding.Details["duplicate_of"] != tt.docs[0].Path {
+				t.Errorf("expected %s reported as a copy of %s, got %v", tt.docs[1].Path, tt.docs[0].Path, finding.Details)
+			}
+			if finding.Details["similarity"] != tt.similarity {
+				t.Errorf("expected similarity %v, got %v", tt.similarity, finding.Details["similarity"])
+			}
+		})
+	}
+}
+
+func TestDuplicateContentDetector_Suppression(t *testing.T) {
+	chapter := duplicateTestWords("alpha", 60)
+	result := NewDuplicateContentDetector().Detect([]SpineDocument{
+		{ManifestID: "ch1", Path: "OEBPS/ch1.xhtml", Data: duplicateTestDocument("", chapter)},
+		{ManifestID: "ch1-reprise", Path: "OEBPS/ch1-reprise.xhtml",
+			Data: duplicateTestDocument("<!-- ebm-ignore-file EPUB-CONTENT-009 reprinted on purpose -->", chapter)},
+	})
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected the duplicate to be suppressed, got %v", result.Warnings)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Code != ErrorCodeContentDuplicate {
+		t.Errorf("expected one suppressed %s, got %v", ErrorCodeContentDuplicate, result.Suppressed)
+	}
+}
+
+func TestEPUBValidator_DuplicateContent(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Duplicate Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+	chapter := string(duplicateTestDocument("", duplicateTestWords("alpha", 60)))
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Contents</h1>
+    <ol><li><a href="ch1.xhtml">Chapter 1</a></li><li><a href="ch2.xhtml">Chapter 2</a></li></ol>
+  </nav>
+</body>
+</html>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+		{path: "OEBPS/ch1.xhtml", content: chapter},
+		{path: "OEBPS/ch2.xhtml", content: chapter},
+	})
+
+	report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var duplicates []domain.ValidationError
+	for _, warning := range report.Warnings {
+		if warning.Code == ErrorCodeContentDuplicate {
+			duplicates = append(duplicates, warning)
+		}
+	}
+	if len(duplicates) != 2 {
+		t.Fatalf("expected two duplicate warnings, got %v", report.Warnings)
+	}
+	for _, warning := range duplicates {
+		if warning.Location == nil || warning.Location.Path == "" {
+			t.Errorf("expected warning located at a content document, got %+v", warning.Location)
+		}
+	}
+	if !report.IsValid {
+		t.Errorf("duplicate content should not invalidate the publication: %v", report.Errors)
+	}
+}
@@ -28,6 +28,10 @@ type validatorImpl struct {
 	opfValidator       *OPFValidator
 	navValidator       *NavValidator
 	contentValidator   *ContentValidator
+	languageValidator  *LanguageValidator
+	duplicateDetector  *DuplicateContentDetector
+	svgValidator       *SVGValidator
+	mathMLValidator    *MathMLValidator
 }
 
 // NewEPUBValidator returns a new EPUB validator.
@@ -37,6 +41,10 @@ func NewEPUBValidator() ports.EPUBValidator {
 		opfValidator:       NewOPFValidator(),
 		navValidator:       NewNavValidator(),
 		contentValidator:   NewContentValidator(),
+		languageValidator:  NewLanguageValidator(),
+		duplicateDetector:  NewDuplicateContentDetector(),
+		svgValidator:       NewSVGValidator(),
+		mathMLValidator:    NewMathMLValidator(),
 	}
 }
 
@@ -238,53 +246,72 @@ func (v *validatorImpl) validateEPUB(reader io.ReaderAt, size int64, filePath st
 		return report, nil
 	}
 
-	opfDir := path.Dir(opfPath)
-	v.validateManifestItems(zipReader, opfResult.Package, opfDir, report)
+	v.validateManifestItems(newArchiveIndex(zipReader.File), opfResult.Package, opfPath, report)
 
 	report.IsValid = len(report.Errors) == 0
 	return report, nil
 }
 
-func (v *validatorImpl) validateManifestItems(zipReader *zip.Reader, pkg *Package, opfDir string, report *domain.ValidationReport) {
-	fileMap := make(map[string]*zip.File)
-	for _, f := range zipReader.File {
-		fileMap[f.Name] = f
-	}
+func (v *validatorImpl) validateManifestItems(index *archiveIndex, pkg *Package, opfPath string, report *domain.ValidationReport) {
+	opfDir := path.Dir(opfPath)
 
-	var navPath string
+	var navHref string
 	for _, item := range pkg.Manifest.Items {
 		if strings.Contains(item.Properties, "nav") {
-			navPath = item.Href
+			navHref = item.Href
 			break
 		}
 	}
 
-	if navPath != "" {
-		fullNavPath := v.resolvePath(opfDir, navPath)
-		navData, err := v.readFileFromZip(zipReader, fullNavPath)
+	if navHref != "" {
+		fullNavPath := resolveHref(opfDir, navHref).Path
+		navData, navFile, err := v.readReference(index, fullNavPath, navHref, opfPath, report)
 		if err != nil {
 			v.addError(report, ErrorCodeOPFFileNotFound,
 				fmt.Sprintf("Navigation document referenced but not found: %s", fullNavPath),
 				fullNavPath, nil)
 		} else {
-			navResult, err := v.navValidator.ValidateBytes(navData)
+			fullNavPath = navFile.Name
+			navResult, err := v.navValidator.ValidateBytesAt(navData, fullNavPath)
 			if err != nil {
 				v.addError(report, ErrorCodeNavNotWellFormed,
 					fmt.Sprintf("Failed to validate navigation document: %s", err.Error()),
 					fullNavPath, nil)
 			} else {
 				v.aggregateNavErrors(navResult, fullNavPath, report)
+				v.validateNavTargets(index, navResult, fullNavPath, report)
 			}
 		}
 	}
 
 	spineIDs := make(map[string]bool)
+	spineProperties := make(map[string]string)
 	for _, spineItem := range pkg.Spine.Items {
 		spineIDs[spineItem.IDRef] = true
+		spineProperties[spineItem.IDRef] += " " + spineItem.Properties
 	}
+	layout := renditionLayout(pkg)
+
+	pubLanguage := PublicationLanguage{
+		PageProgressionDirection: pkg.Spine.PageProgressionDirection,
+	}
+	for _, language := range pkg.Metadata.Languages {
+		pubLanguage.Languages = append(pubLanguage.Languages, language.Value)
+	}
+
+	spineDocs := make(map[string]SpineDocument)
 
 	for _, item := range pkg.Manifest.Items {
-		if !v.isContentDocument(item.MediaType) {
+		if isStylesheet(item.MediaType) {
+			stylePath := resolveHref(opfDir, item.Href).Path
+			if styleData, styleFile, err := v.readReference(index, stylePath, item.Href, opfPath, report); err == nil {
+				v.validateReferences(index, cssReferences(string(styleData)), styleFile.Name, item.ID, report)
+			}
+			continue
+		}
+
+		isSVG := isSVGDocument(item.MediaType)
+		if !v.isContentDocument(item.MediaType) && !isSVG {
 			continue
 		}
 
@@ -292,8 +319,8 @@ func (v *validatorImpl) validateManifestItems(zipReader *zip.Reader, pkg *Packag
 			continue
 		}
 
-		fullItemPath := v.resolvePath(opfDir, item.Href)
-		itemData, err := v.readFileFromZip(zipReader, fullItemPath)
+		fullItemPath := resolveHref(opfDir, item.Href).Path
+		itemData, itemFile, err := v.readReference(index, fullItemPath, item.Href, opfPath, report)
 		if err != nil {
 			v.addError(report, ErrorCodeOPFFileNotFound,
 				fmt.Sprintf("Content document %s (id=%s) not found in EPUB", fullItemPath, item.ID),
@@ -303,11 +330,26 @@ func (v *validatorImpl) validateManifestItems(zipReader *zip.Reader, pkg *Packag
 				})
 			continue
 		}
+		fullItemPath = itemFile.Name
 
 		if strings.Contains(item.Properties, "nav") {
 			continue
 		}
 
+		svgContext := SVGDocumentContext{
+			BaseDir:     path.Dir(fullItemPath),
+			FixedLayout: itemLayout(layout, spineProperties[item.ID]) == "pre-paginated",
+			Properties:  item.Properties,
+		}
+		if isSVG {
+			if svgResult, err := v.svgValidator.ValidateBytes(itemData, svgContext); err == nil {
+				v.aggregateSVGFindings(svgResult, fullItemPath, item.ID, report)
+			}
+			continue
+		}
+
+		v.validateReferences(index, htmlReferences(itemData), fullItemPath, item.ID, report)
+
 		contentResult, err := v.contentValidator.ValidateBytes(itemData)
 		if err != nil {
 			v.addError(report, ErrorCodeContentNotWellFormed,
@@ -318,7 +360,31 @@ func (v *validatorImpl) validateManifestItems(zipReader *zip.Reader, pkg *Packag
 		} else {
 			v.aggregateContentErrors(contentResult, fullItemPath, item.ID, report)
 		}
+
+		if languageResult, err := v.languageValidator.ValidateBytes(itemData, pubLanguage); err == nil {
+			v.aggregateLanguageFindings(languageResult, fullItemPath, item.ID, report)
+		}
+
+		if svgResult, err := v.svgValidator.ValidateEmbeddedBytes(itemData, svgContext); err == nil {
+			v.aggregateSVGFindings(svgResult, fullItemPath, item.ID, report)
+		}
+
+		if mathResult, err := v.mathMLValidator.ValidateBytes(itemData, item.Properties); err == nil {
+			v.aggregateMathMLFindings(mathResult, fullItemPath, item.ID, report)
+		}
+
+		if _, seen := spineDocs[item.ID]; !seen {
+			spineDocs[item.ID] = SpineDocument{ManifestID: item.ID, Path: fullItemPath, Data: itemData}
+		}
+	}
+
+	readingOrder := make([]SpineDocument, 0, len(pkg.Spine.Items))
+	for _, spineItem := range pkg.Spine.Items {
+		if doc, ok := spineDocs[spineItem.IDRef]; ok {
+			readingOrder = append(readingOrder, doc)
+		}
 	}
+	v.aggregateDuplicateFindings(v.duplicateDetector.Detect(readingOrder), report)
 }
 
 func (v *validatorImpl) isContentDocument(mediaType string) bool {
@@ -327,11 +393,110 @@ func (v *validatorImpl) isContentDocument(mediaType string) bool {
 		strings.HasPrefix(mediaType, "application/xhtml")
 }
 
-func (v *validatorImpl) resolvePath(base, relative string) string {
-	if base == "" || base == "." {
-		return relative
+func isSVGDocument(mediaType string) bool {
+	return strings.EqualFold(strings.TrimSpace(mediaType), "image/svg+xml")
+}
+
+func isStylesheet(mediaType string) bool {
+	return strings.EqualFold(strings.TrimSpace(mediaType), "text/css")
+}
+
+// itemLayout returns the rendition layout of a spine item: the package
+// layout unless the itemref overrides it.
+func itemLayout(packageLayout, itemrefProperties string) string {
+	switch {
+	case hasPropertyToken(itemrefProperties, "rendition:layout-pre-paginated"):
+		return "pre-paginated"
+	case hasPropertyToken(itemrefProperties, "rendition:layout-reflowable"):
+		return "reflowable"
+	}
+	return packageLayout
+}
+
+// readReference reads the entry an href in referrer resolves to. An href
+// that matches an entry only by case is reported with
+// EPUB-OPF-018 and then read, so the document is still validated.
+func (v *validatorImpl) readReference(index *archiveIndex, target, href, referrer string, report *domain.ValidationReport) ([]byte, *zip.File, error) {
+	f, ok := index.file(target)
+	if !ok {
+		variant, found := index.caseVariant(target)
+		if !found || target == "" {
+			return nil, nil, fmt.Errorf("file not found: %s", target)
+		}
+		v.addHrefCaseMismatch(report, href, variant.Name, referrer)
+		f = variant
+	}
+
+	data, err := readZipEntryLimited(f, v.containerValidator.Limits.MaxEntrySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, f, nil
+}
+
+func (v *validatorImpl) addHrefCaseMismatch(report *domain.ValidationReport, href, entry, referrer string) {
+	v.addError(report, ErrorCodeOPFHrefCaseMismatch,
+		fmt.Sprintf("Reference '%s' matches ZIP entry '%s' only when case is ignored", href, entry),
+		referrer, map[string]interface{}{
+			"href":  href,
+			"entry": entry,
+		})
+}
+
+// validateNavTargets checks that the navigation document's links lead to
+// files in the container. Links the navigation validator already rejected
+// are skipped.
+func (v *validatorImpl) validateNavTargets(index *archiveIndex, navResult *NavValidationResult, navPath string, report *domain.ValidationReport) {
+	navDir := path.Dir(navPath)
+	links := append(append([]NavLink{}, navResult.TOCLinks...), navResult.LandmarkLinks...)
+	for _, link := range links {
+		target := resolveHref(navDir, link.Href)
+		if target.Path == "" || target.External || target.Escapes || strings.HasPrefix(strings.TrimSpace(link.Href), "/") {
+			continue
+		}
+		if _, ok := index.file(target.Path); ok {
+			continue
+		}
+		if variant, ok := index.caseVariant(target.Path); ok {
+			v.addHrefCaseMismatch(report, link.Href, variant.Name, navPath)
+			continue
+		}
+		v.addError(report, ErrorCodeNavInvalidLinks,
+			fmt.Sprintf("Navigation link target not found: %s", link.Href),
+			navPath, map[string]interface{}{
+				"href": link.Href,
+				"path": target.Path,
+				"text": link.Text,
+			})
+	}
+}
+
+// validateReferences checks that the references found in the content
+// document or stylesheet at docPath lead to files in the container.
+// External URLs and references that leave the container are skipped.
+func (v *validatorImpl) validateReferences(index *archiveIndex, refs []documentReference, docPath, manifestID string, report *domain.ValidationReport) {
+	docDir := path.Dir(docPath)
+	for _, ref := range refs {
+		target := resolveHref(docDir, ref.Href)
+		if target.Path == "" || target.External || target.Escapes {
+			continue
+		}
+		if _, ok := index.file(target.Path); ok {
+			continue
+		}
+		if variant, ok := index.caseVariant(target.Path); ok {
+			v.addHrefCaseMismatch(report, ref.Href, variant.Name, docPath)
+			continue
+		}
+		v.addError(report, ErrorCodeContentReferenceNotFound,
+			fmt.Sprintf("Referenced file not found: %s", ref.Href),
+			docPath, map[string]interface{}{
+				"manifest_id": manifestID,
+				"href":        ref.Href,
+				"path":        target.Path,
+				"source":      ref.Source,
+			})
 	}
-	return path.Join(base, relative)
 }
 
 func (v *validatorImpl) readFileFromZip(zipReader *zip.Reader, filePath string) ([]byte, error) {
@@ -339,19 +504,7 @@ func (v *validatorImpl) readFileFromZip(zipReader *zip.Reader, filePath string)
 
 	for _, f := range zipReader.File {
 		if f.Name == filePath {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to open file: %w", err)
-			}
-			defer func() {
-				_ = rc.Close()
-			}()
-
-			data, err := io.ReadAll(rc)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read file: %w", err)
-			}
-			return data, nil
+			return readZipEntryLimited(f, v.containerValidator.Limits.MaxEntrySize)
 		}
 	}
 
@@ -373,33 +526,106 @@ func (v *validatorImpl) createReport(filePath string) *domain.ValidationReport {
 
 func (v *validatorImpl) aggregateContainerErrors(result *ValidationResult, report *domain.ValidationReport) {
 	for _, err := range result.Errors {
-		v.addError(report, err.Code, err.Message, "mimetype / META-INF/container.xml", err.Details)
+		v.addError(report, err.Code, err.Message, containerFindingPath(err), err.Details)
+	}
+	for _, warning := range result.Warnings {
+		v.addWarning(report, warning.Code, warning.Message, containerFindingPath(warning), warning.Details)
 	}
+	for _, info := range result.Info {
+		v.addInfo(report, info.Code, info.Message, containerFindingPath(info), info.Details)
+	}
+}
+
+// containerFindingPath locates a container finding at the ZIP entry it names.
+func containerFindingPath(err ValidationError) string {
+	if entry, ok := err.Details["entry"].(string); ok && entry != "" {
+		return entry
+	}
+	return "mimetype / META-INF/container.xml"
 }
 
 func (v *validatorImpl) aggregateOPFErrors(result *OPFValidationResult, opfPath string, report *domain.ValidationReport) {
 	for _, err := range result.Errors {
 		v.addError(report, err.Code, err.Message, opfPath, err.Details)
 	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, opfPath, suppressed.Details)
+	}
 }
 
 func (v *validatorImpl) aggregateNavErrors(result *NavValidationResult, navPath string, report *domain.ValidationReport) {
 	for _, err := range result.Errors {
 		v.addError(report, err.Code, err.Message, navPath, err.Details)
 	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, navPath, suppressed.Details)
+	}
 }
 
 func (v *validatorImpl) aggregateContentErrors(result *ContentValidationResult, contentPath string, manifestID string, report *domain.ValidationReport) {
 	for _, err := range result.Errors {
-		details := err.Details
-		if details == nil {
-			details = make(map[string]interface{})
-		}
-		details["manifest_id"] = manifestID
-		v.addError(report, err.Code, err.Message, contentPath, details)
+		v.addError(report, err.Code, err.Message, contentPath, withManifestID(err.Details, manifestID))
+	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, contentPath, withManifestID(suppressed.Details, manifestID))
+	}
+}
+
+func (v *validatorImpl) aggregateLanguageFindings(result *LanguageValidationResult, contentPath string, manifestID string, report *domain.ValidationReport) {
+	for _, err := range result.Errors {
+		v.addError(report, err.Code, err.Message, contentPath, withManifestID(err.Details, manifestID))
+	}
+	for _, warning := range result.Warnings {
+		v.addWarning(report, warning.Code, warning.Message, contentPath, withManifestID(warning.Details, manifestID))
+	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, contentPath, withManifestID(suppressed.Details, manifestID))
 	}
 }
 
+func (v *validatorImpl) aggregateSVGFindings(result *SVGValidationResult, contentPath string, manifestID string, report *domain.ValidationReport) {
+	for _, err := range result.Errors {
+		v.addError(report, err.Code, err.Message, contentPath, withManifestID(err.Details, manifestID))
+	}
+	for _, warning := range result.Warnings {
+		v.addWarning(report, warning.Code, warning.Message, contentPath, withManifestID(warning.Details, manifestID))
+	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, contentPath, withManifestID(suppressed.Details, manifestID))
+	}
+}
+
+func (v *validatorImpl) aggregateMathMLFindings(result *MathMLValidationResult, contentPath string, manifestID string, report *domain.ValidationReport) {
+	for _, err := range result.Errors {
+		v.addError(report, err.Code, err.Message, contentPath, withManifestID(err.Details, manifestID))
+	}
+	for _, warning := range result.Warnings {
+		v.addWarning(report, warning.Code, warning.Message, contentPath, withManifestID(warning.Details, manifestID))
+	}
+	for _, suppressed := range result.Suppressed {
+		v.addInfo(report, suppressed.Code, suppressed.Message, contentPath, withManifestID(suppressed.Details, manifestID))
+	}
+}
+
+func (v *validatorImpl) aggregateDuplicateFindings(result *DuplicateContentResult, report *domain.ValidationReport) {
+	for _, warning := range result.Warnings {
+		contentPath, _ := warning.Details["path"].(string)
+		v.addWarning(report, warning.Code, warning.Message, contentPath, warning.Details)
+	}
+	for _, suppressed := range result.Suppressed {
+		contentPath, _ := suppressed.Details["path"].(string)
+		v.addInfo(report, suppressed.Code, suppressed.Message, contentPath, suppressed.Details)
+	}
+}
+
+func withManifestID(details map[string]interface{}, manifestID string) map[string]interface{} {
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["manifest_id"] = manifestID
+	return details
+}
+
 func (v *validatorImpl) addError(report *domain.ValidationReport, code, message, file string, details map[string]interface{}) {
 	filename := filepath.Base(file)
 
@@ -417,3 +643,33 @@ func (v *validatorImpl) addError(report *domain.ValidationReport, code, message,
 
 	report.Errors = append(report.Errors, validationError)
 }
+
+func (v *validatorImpl) addWarning(report *domain.ValidationReport, code, message, file string, details map[string]interface{}) {
+	report.Warnings = append(report.Warnings, domain.ValidationError{
+		Code:      code,
+		Message:   message,
+		Severity:  domain.SeverityWarning,
+		Timestamp: time.Now(),
+		Location: &domain.ErrorLocation{
+			File: filepath.Base(file),
+			Path: file,
+		},
+		Details: details,
+	})
+}
+
+// addInfo records an informational finding, or one that an inline
+// ebm-ignore directive suppressed.
+func (v *validatorImpl) addInfo(report *domain.ValidationReport, code, message, file string, details map[string]interface{}) {
+	report.Info = append(report.Info, domain.ValidationError{
+		Code:      code,
+		Message:   message,
+		Severity:  domain.SeverityInfo,
+		Timestamp: time.Now(),
+		Location: &domain.ErrorLocation{
+			File: filepath.Base(file),
+			Path: file,
+		},
+		Details: details,
+	})
+}
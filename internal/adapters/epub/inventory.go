@@ -0,0 +1,228 @@
+package epub
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// Inspector builds the inventory of a Publication.
+type Inspector struct {
+	// WordsPerMinute is the reading speed used for the reading time estimate.
+	WordsPerMinute int
+	// WordsPerPage is the page size used for the page count estimate.
+	WordsPerPage int
+	// LargestResources is the number of resources listed by size.
+	LargestResources int
+}
+
+// NewInspector returns an inspector with common trade estimates: 250 words
+// per minute and 250 words per printed page.
+func NewInspector() *Inspector {
+	return &Inspector{
+		WordsPerMinute:   250,
+		WordsPerPage:     250,
+		LargestResources: 10,
+	}
+}
+
+// Inspect collects the inventory of pub. Spine documents that cannot be read
+// or parsed count as empty; the validators report them.
+func (i *Inspector) Inspect(pub *Publication) *domain.Inventory {
+	inventory := &domain.Inventory{
+		FileType:    "EPUB",
+		Title:       pub.Metadata.MainTitle(),
+		Version:     pub.Package.Version,
+		Rendition:   renditionLayout(pub.Package),
+		Languages:   pub.Metadata.Languages,
+		InspectedAt: time.Now(),
+	}
+
+	seenLanguages := make(map[string]bool)
+	for _, item := range pub.Spine {
+		stats := domain.DocumentStats{Path: item.Path}
+		var languages []string
+		if item.IsContentDocument() {
+			if doc, err := item.Document(); err == nil {
+				stats.Words, stats.Chars, languages = documentText(doc)
+			}
+		}
+		for _, language := range languages {
+			if key := strings.ToLower(language); !seenLanguages[key] {
+				seenLanguages[key] = true
+				inventory.DetectedLanguages = append(inventory.DetectedLanguages, language)
+			}
+		}
+		inventory.Documents = append(inventory.Documents, stats)
+		inventory.TotalWords += stats.Words
+		inventory.TotalChars += stats.Chars
+	}
+	if i.WordsPerMinute > 0 {
+		inventory.ReadingTime = time.Duration(inventory.TotalWords) * time.Minute / time.Duration(i.WordsPerMinute)
+	}
+	if i.WordsPerPage > 0 {
+		inventory.EstimatedPages = (inventory.TotalWords + i.WordsPerPage - 1) / i.WordsPerPage
+	}
+
+	images := make(map[string]*domain.MediaTypeStats)
+	var resources []domain.ResourceInfo
+	seenPaths := make(map[string]bool)
+	for _, item := range pub.Manifest {
+		if !item.Exists() || seenPaths[item.Path] {
+			continue
+		}
+		seenPaths[item.Path] = true
+
+		info := domain.ResourceInfo{Path: item.Path, MediaType: item.MediaType, Size: item.Size()}
+		resources = append(resources, info)
+		inventory.TotalBytes += info.Size
+
+		mediaType := strings.ToLower(strings.TrimSpace(item.MediaType))
+		switch {
+		case strings.HasPrefix(mediaType, "image/"):
+			stats, ok := images[mediaType]
+			if !ok {
+				stats = &domain.MediaTypeStats{MediaType: mediaType}
+				images[mediaType] = stats
+			}
+			stats.Count++
+			stats.Bytes += info.Size
+			inventory.ImageCount++
+			inventory.ImageBytes += info.Size
+		case isFontResource(mediaType, item.Path):
+			inventory.Fonts = append(inventory.Fonts, info)
+		}
+	}
+	for _, stats := range images {
+		inventory.Images = append(inventory.Images, *stats)
+	}
+	sort.Slice(inventory.Images, func(a, b int) bool {
+		return inventory.Images[a].MediaType < inventory.Images[b].MediaType
+	})
+
+	sort.SliceStable(resources, func(a, b int) bool {
+		return resources[a].Size > resources[b].Size
+	})
+	if i.LargestResources >= 0 && len(resources) > i.LargestResources {
+		resources = resources[:i.LargestResources]
+	}
+	inventory.Largest = resources
+
+	inventory.TOCEntries, inventory.TOCDepth = tocShape(pub.TOC)
+
+	return inventory
+}
+
+// renditionLayout returns the global rendition:layout of the package, which
+// defaults to reflowable.
+func renditionLayout(pkg *Package) string {
+	for _, meta := range pkg.Metadata.Meta {
+		if meta.Property == "rendition:layout" {
+			if layout := strings.TrimSpace(meta.Value); layout != "" {
+				return layout
+			}
+		}
+	}
+	return "reflowable"
+}
+
+// isFontResource reports whether a manifest item is a font, by media type or,
+// for the many EPUBs that declare fonts as application/octet-stream, by file
+// extension.
+func isFontResource(mediaType, resourcePath string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "font/"),
+		strings.HasPrefix(mediaType, "application/font-"),
+		strings.HasPrefix(mediaType, "application/x-font-"),
+		mediaType == "application/vnd.ms-opentype":
+		return true
+	}
+	switch strings.ToLower(path.Ext(resourcePath)) {
+	case ".otf", ".ttf", ".woff", ".woff2":
+		return true
+	}
+	return false
+}
+
+// documentText counts the words and non-whitespace characters of the
+// document body and collects its lang attributes. Ideographic and kana
+// characters count as one word each, since those scripts do not separate
+// words with spaces.
+func documentText(doc *html.Node) (words, chars int, languages []string) {
+	inWord, hasText := false, false
+	endWord := func() {
+		if inWord && hasText {
+			words++
+		}
+		inWord, hasText = false, false
+	}
+
+	seen := make(map[string]bool)
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inBody bool) {
+		breaksWords := false
+		if n.Type == html.ElementNode {
+			name := strings.ToLower(n.Data)
+			switch name {
+			case "script", "style":
+				return
+			case "body":
+				inBody = true
+			}
+			breaksWords = !inlineElements[name]
+			for _, attr := range n.Attr {
+				if (attr.Key != "lang" && attr.Key != "xml:lang") || attr.Val == "" || seen[attr.Val] {
+					continue
+				}
+				seen[attr.Val] = true
+				languages = append(languages, attr.Val)
+			}
+		}
+		if breaksWords {
+			endWord()
+		}
+		if n.Type == html.TextNode && inBody {
+			for _, r := range n.Data {
+				switch {
+				case unicode.IsSpace(r):
+					endWord()
+				case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+					endWord()
+					words++
+					chars++
+				default:
+					inWord = true
+					hasText = hasText || unicode.IsLetter(r) || unicode.IsDigit(r)
+					chars++
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inBody)
+		}
+		if breaksWords {
+			endWord()
+		}
+	}
+	walk(doc, false)
+	endWord()
+	return words, chars, languages
+}
+
+// tocShape returns the number of entries and the nesting depth of a TOC tree.
+func tocShape(entries []*TOCEntry) (count, depth int) {
+	for _, entry := range entries {
+		childCount, childDepth := tocShape(entry.Children)
+		count += 1 + childCount
+		if childDepth+1 > depth {
+			depth = childDepth + 1
+		}
+	}
+	return count, depth
+}
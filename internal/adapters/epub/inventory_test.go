@@ -0,0 +1,179 @@
+package epub
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+func TestDocumentText(t *testing.T) {
+	tests := []struct {
+		name      string
+		markup    string
+		words     int
+		chars     int
+		languages []string
+	}{
+		{
+			name:   "plain paragraphs",
+			markup: `<p>The quick brown fox.</p><p>Jumps over.</p>`,
+			words:  6,
+			chars:  27,
+		},
+		{
+			name:   "inline markup inside a word",
+			markup: `<p><b>W</b>ord and <em>more</em> words</p>`,
+			words:  4,
+			chars:  16,
+		},
+		{
+			name:   "punctuation is not a word",
+			markup: `<p>Wait — what?</p>`,
+			words:  2,
+			chars:  10,
+		},
+		{
+			name:   "script and style skipped",
+			markup: `<style>p { color: red }</style><p>One</p><script>var two = 2;</script>`,
+			words:  1,
+			chars:  3,
+		},
+		{
+			name:   "ideographs count individually",
+			markup: `<p>日本語の本</p>`,
+			words:  5,
+			chars:  5,
+		},
+		{
+			name:      "languages",
+			markup:    `<p lang="fr">Bonjour</p><p xml:lang="de">Hallo</p><p lang="fr">Salut</p>`,
+			words:     3,
+			chars:     17,
+			languages: []string{"fr", "de"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(`<html><head><title>Ignored title</title></head><body>` + tt.markup + `</body></html>`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			words, chars, languages := documentText(doc)
+			if words != tt.words || chars != tt.chars {
+				t.Errorf("documentText() = %d words, %d chars, want %d words, %d chars", words, chars, tt.words, tt.chars)
+			}
+			if strings.Join(languages, ",") != strings.Join(tt.languages, ",") {
+				t.Errorf("languages = %v, want %v", languages, tt.languages)
+			}
+		})
+	}
+}
+
+func TestInspector_Inspect(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Inventory Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+    <meta property="rendition:layout">pre-paginated</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="ch2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="cover" href="images/cover.jpg" media-type="image/jpeg" properties="cover-image"/>
+    <item id="fig1" href="images/fig1.png" media-type="image/png"/>
+    <item id="fig2" href="images/fig2.png" media-type="image/png"/>
+    <item id="serif" href="fonts/serif.otf" media-type="font/otf"/>
+    <item id="sans" href="fonts/sans.ttf" media-type="application/octet-stream"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+    <itemref idref="ch2"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <ol>
+      <li><a href="ch1.xhtml">Chapter 1</a>
+        <ol><li><a href="ch1.xhtml#s1">Section 1.1</a></li></ol>
+      </li>
+      <li><a href="ch2.xhtml">Chapter 2</a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	chapter := func(lang string, words int) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="` + lang + `" xml:lang="` + lang + `">
+<head><title>Chapter</title></head>
+<body><p>` + strings.TrimSpace(strings.Repeat("word ", words)) + `</p></body>
+</html>`
+	}
+
+	data := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+		{path: "OEBPS/ch1.xhtml", content: chapter("en", 300)},
+		{path: "OEBPS/ch2.xhtml", content: chapter("en-GB", 200)},
+		{path: "OEBPS/images/cover.jpg", content: strings.Repeat("j", 5000)},
+		{path: "OEBPS/images/fig1.png", content: strings.Repeat("p", 1000)},
+		{path: "OEBPS/images/fig2.png", content: strings.Repeat("p", 500)},
+		{path: "OEBPS/fonts/serif.otf", content: strings.Repeat("f", 2000)},
+		{path: "OEBPS/fonts/sans.ttf", content: strings.Repeat("f", 100)},
+	})
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewPublication() error = %v", err)
+	}
+
+	inspector := NewInspector()
+	inspector.LargestResources = 2
+	inventory := inspector.Inspect(pub)
+
+	if inventory.Title != "Inventory Test" || inventory.Version != "3.0" || inventory.Rendition != "pre-paginated" {
+		t.Errorf("unexpected identification: %q %q %q", inventory.Title, inventory.Version, inventory.Rendition)
+	}
+	if strings.Join(inventory.DetectedLanguages, ",") != "en,en-GB" {
+		t.Errorf("DetectedLanguages = %v", inventory.DetectedLanguages)
+	}
+
+	if len(inventory.Documents) != 2 || inventory.Documents[0].Words != 300 || inventory.Documents[1].Words != 200 {
+		t.Errorf("Documents = %+v", inventory.Documents)
+	}
+	if inventory.TotalWords != 500 || inventory.TotalChars != 2000 {
+		t.Errorf("totals = %d words, %d chars", inventory.TotalWords, inventory.TotalChars)
+	}
+	if inventory.ReadingTime != 2*time.Minute || inventory.EstimatedPages != 2 {
+		t.Errorf("estimates = %s, %d pages", inventory.ReadingTime, inventory.EstimatedPages)
+	}
+
+	if inventory.ImageCount != 3 || inventory.ImageBytes != 6500 || len(inventory.Images) != 2 {
+		t.Fatalf("images = %d (%d bytes) %+v", inventory.ImageCount, inventory.ImageBytes, inventory.Images)
+	}
+	if png := inventory.Images[1]; png.MediaType != "image/png" || png.Count != 2 || png.Bytes != 1500 {
+		t.Errorf("png stats = %+v", png)
+	}
+	if len(inventory.Fonts) != 2 || inventory.Fonts[0].Path != "OEBPS/fonts/serif.otf" || inventory.Fonts[1].Path != "OEBPS/fonts/sans.ttf" {
+		t.Errorf("Fonts = %+v", inventory.Fonts)
+	}
+	if len(inventory.Largest) != 2 || inventory.Largest[0].Path != "OEBPS/images/cover.jpg" || inventory.Largest[1].Path != "OEBPS/fonts/serif.otf" {
+		t.Errorf("Largest = %+v", inventory.Largest)
+	}
+
+	if inventory.TOCEntries != 3 || inventory.TOCDepth != 2 {
+		t.Errorf("TOC = %d entries, depth %d", inventory.TOCEntries, inventory.TOCDepth)
+	}
+}
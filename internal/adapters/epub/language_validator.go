@@ -0,0 +1,232 @@
+package epub
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Language validation error codes.
+const (
+	ErrorCodeLangDocumentMismatch = "EPUB-LANG-001"
+	ErrorCodeLangDirConflict      = "EPUB-LANG-002"
+	ErrorCodeLangAttrMismatch     = "EPUB-LANG-003"
+)
+
+// Page progression direction values from the OPF spine.
+const (
+	PageProgressionLTR     = "ltr"
+	PageProgressionRTL     = "rtl"
+	PageProgressionDefault = "default"
+)
+
+// PublicationLanguage captures the publication-wide language settings that
+// content documents are compared against.
+type PublicationLanguage struct {
+	Languages                []string
+	PageProgressionDirection string
+}
+
+// LanguageValidationResult contains language consistency findings for a single content document.
+type LanguageValidationResult struct {
+	Valid            bool
+	Errors           []ValidationError
+	Warnings         []ValidationError
+	Suppressed       []ValidationError
+	DocumentLanguage string
+	Direction        string
+}
+
+// LanguageValidator checks content document language and text direction
+// against the publication metadata.
+type LanguageValidator struct{}
+
+// NewLanguageValidator returns a new language validator.
+func NewLanguageValidator() *LanguageValidator {
+	return &LanguageValidator{}
+}
+
+// ValidateBytes validates a content document from in-memory data.
+func (v *LanguageValidator) ValidateBytes(data []byte, pub PublicationLanguage) (*LanguageValidationResult, error) {
+	return v.Validate(strings.NewReader(string(data)), pub)
+}
+
+// Validate validates a content document from an io.Reader.
+func (v *LanguageValidator) Validate(reader io.Reader, pub PublicationLanguage) (*LanguageValidationResult, error) {
+	result := &LanguageValidationResult{
+		Valid:    true,
+		Errors:   make([]ValidationError, 0),
+		Warnings: make([]ValidationError, 0),
+	}
+
+	doc, err := html.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse content document: %w", err)
+	}
+
+	v.validateAttributePairs(doc, result)
+
+	htmlNode := findLanguageElement(doc, "html")
+	bodyNode := findLanguageElement(doc, "body")
+
+	v.validateDocumentLanguage(htmlNode, bodyNode, pub, result)
+	v.validateDirection(htmlNode, bodyNode, pub, result)
+
+	s := collectHTMLSuppressions(doc)
+	var suppressed []ValidationError
+	result.Errors, suppressed = s.partition(result.Errors, "error")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Warnings, suppressed = s.partition(result.Warnings, "warning")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// validateAttributePairs reports elements whose lang and xml:lang values differ.
+func (v *LanguageValidator) validateAttributePairs(doc *html.Node, result *LanguageValidationResult) {
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			lang, hasLang := languageAttribute(n, "lang")
+			xmlLang, hasXMLLang := languageAttribute(n, "xml:lang")
+			if hasLang && hasXMLLang && !strings.EqualFold(strings.TrimSpace(lang), strings.TrimSpace(xmlLang)) {
+				result.Errors = append(result.Errors, ValidationError{
+					Code:    ErrorCodeLangAttrMismatch,
+					Message: fmt.Sprintf("<%s> has lang=%q and xml:lang=%q; both attributes must have the same value", n.Data, lang, xmlLang),
+					Details: map[string]interface{}{
+						"element":  n.Data,
+						"lang":     lang,
+						"xml_lang": xmlLang,
+					},
+					node: n,
+				})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	traverse(doc)
+}
+
+// validateDocumentLanguage compares the document's effective language with the
+// OPF dc:language values. A declaration on <body> is treated as a local override
+// of the <html> declaration, so only the innermost document-level value is compared.
+func (v *LanguageValidator) validateDocumentLanguage(htmlNode, bodyNode *html.Node, pub PublicationLanguage, result *LanguageValidationResult) {
+	docLang, declaredOn := elementLanguage(htmlNode), htmlNode
+	if bodyLang := elementLanguage(bodyNode); bodyLang != "" {
+		docLang, declaredOn = bodyLang, bodyNode
+	}
+	result.DocumentLanguage = docLang
+
+	pubLanguages := make([]string, 0, len(pub.Languages))
+	for _, lang := range pub.Languages {
+		if trimmed := strings.TrimSpace(lang); trimmed != "" {
+			pubLanguages = append(pubLanguages, trimmed)
+		}
+	}
+
+	if docLang == "" || len(pubLanguages) == 0 {
+		return
+	}
+
+	for _, lang := range pubLanguages {
+		if primaryLanguageSubtag(lang) == primaryLanguageSubtag(docLang) {
+			return
+		}
+	}
+
+	result.Warnings = append(result.Warnings, ValidationError{
+		Code:    ErrorCodeLangDocumentMismatch,
+		Message: fmt.Sprintf("Content document language '%s' does not match publication dc:language %v", docLang, pubLanguages),
+		Details: map[string]interface{}{
+			"document_language":     docLang,
+			"publication_languages": pubLanguages,
+		},
+		node: declaredOn,
+	})
+}
+
+// validateDirection reports a document base direction that contradicts the
+// spine page-progression-direction.
+func (v *LanguageValidator) validateDirection(htmlNode, bodyNode *html.Node, pub PublicationLanguage, result *LanguageValidationResult) {
+	progression := strings.ToLower(strings.TrimSpace(pub.PageProgressionDirection))
+
+	for _, node := range []*html.Node{htmlNode, bodyNode} {
+		if node == nil {
+			continue
+		}
+		dir := strings.ToLower(strings.TrimSpace(getLanguageAttr(node, "dir")))
+		if dir == "" {
+			continue
+		}
+		result.Direction = dir
+
+		conflict := (progression == PageProgressionRTL && dir == "ltr") ||
+			(progression == PageProgressionLTR && dir == "rtl")
+		if !conflict {
+			continue
+		}
+
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodeLangDirConflict,
+			Message: fmt.Sprintf("<%s dir=\"%s\"> conflicts with spine page-progression-direction '%s'", node.Data, dir, progression),
+			Details: map[string]interface{}{
+				"element":                    node.Data,
+				"dir":                        dir,
+				"page_progression_direction": progression,
+			},
+			node: node,
+		})
+	}
+}
+
+func findLanguageElement(n *html.Node, tagName string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tagName {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findLanguageElement(c, tagName); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func elementLanguage(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	if lang := strings.TrimSpace(getLanguageAttr(n, "xml:lang")); lang != "" {
+		return lang
+	}
+	return strings.TrimSpace(getLanguageAttr(n, "lang"))
+}
+
+func getLanguageAttr(n *html.Node, key string) string {
+	value, _ := languageAttribute(n, key)
+	return value
+}
+
+func languageAttribute(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if attr.Key == key && attr.Namespace == "" {
+			return attr.Val, true
+		}
+		if key == "xml:lang" && attr.Key == "lang" && attr.Namespace == "xml" {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func primaryLanguageSubtag(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if idx := strings.IndexAny(lang, "-_"); idx != -1 {
+		return lang[:idx]
+	}
+	return lang
+}
@@ -0,0 +1,238 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestLanguageValidator_DocumentLanguage(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		pub         PublicationLanguage
+		wantWarning bool
+	}{
+		{
+			name: "matching language",
+			html: `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en-US" xml:lang="en-US">
+<head><title>Test</title></head>
+<body><p>Content</p></body>
+</html>`,
+			pub:         PublicationLanguage{Languages: []string{"en"}},
+			wantWarning: false,
+		},
+		{
+			name: "mismatched language",
+			html: `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="fr" xml:lang="fr">
+<head><title>Test</title></head>
+<body><p>Contenu</p></body>
+</html>`,
+			pub:         PublicationLanguage{Languages: []string{"en"}},
+			wantWarning: true,
+		},
+		{
+			name: "body override matches publication",
+			html: `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en">
+<head><title>Test</title></head>
+<body lang="ar"><p>محتوى</p></body>
+</html>`,
+			pub:         PublicationLanguage{Languages: []string{"ar"}},
+			wantWarning: false,
+		},
+		{
+			name: "matches secondary publication language",
+			html: `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="ja">
+<head><title>Test</title></head>
+<body><p>内容</p></body>
+</html>`,
+			pub:         PublicationLanguage{Languages: []string{"en", "ja"}},
+			wantWarning: false,
+		},
+		{
+			name: "no document language inherits publication language",
+			html: `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Test</title></head>
+<body><p>Content</p></body>
+</html>`,
+			pub:         PublicationLanguage{Languages: []string{"he"}},
+			wantWarning: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewLanguageValidator()
+			result, err := validator.ValidateBytes([]byte(tt.html), tt.pub)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found := hasLanguageCode(result.Warnings, ErrorCodeLangDocumentMismatch)
+			if found != tt.wantWarning {
+				t.Errorf("document mismatch warning = %v, want %v (warnings: %v)", found, tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestLanguageValidator_Direction(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		progression string
+		wantWarning bool
+	}{
+		{
+			name:        "rtl document in rtl publication",
+			html:        `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml" lang="he" dir="rtl"><head><title>T</title></head><body></body></html>`,
+			progression: PageProgressionRTL,
+			wantWarning: false,
+		},
+		{
+			name:        "ltr document in rtl publication",
+			html:        `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml" lang="ar" dir="ltr"><head><title>T</title></head><body></body></html>`,
+			progression: PageProgressionRTL,
+			wantWarning: true,
+		},
+		{
+			name:        "rtl body in ltr publication",
+			html:        `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml" lang="en"><head><title>T</title></head><body dir="rtl"></body></html>`,
+			progression: PageProgressionLTR,
+			wantWarning: true,
+		},
+		{
+			name:        "default progression accepts any direction",
+			html:        `<!DOCTYPE html><html xmlns="http://www.w3.org/1999/xhtml" lang="en" dir="rtl"><head><title>T</title></head><body></body></html>`,
+			progression: PageProgressionDefault,
+			wantWarning: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewLanguageValidator()
+			result, err := validator.ValidateBytes([]byte(tt.html), PublicationLanguage{PageProgressionDirection: tt.progression})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found := hasLanguageCode(result.Warnings, ErrorCodeLangDirConflict)
+			if found != tt.wantWarning {
+				t.Errorf("direction conflict warning = %v, want %v (warnings: %v)", found, tt.wantWarning, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestLanguageValidator_AttributeMismatch(t *testing.T) {
+	html := `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en-GB">
+<head><title>Test</title></head>
+<body>
+  <p lang="de" xml:lang="DE">Groß- und Kleinschreibung ist egal.</p>
+  <p lang="fr" xml:lang="it">Mismatch</p>
+</body>
+</html>`
+
+	validator := NewLanguageValidator()
+	result, err := validator.ValidateBytes([]byte(html), PublicationLanguage{Languages: []string{"en"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Valid {
+		t.Error("expected invalid result for mismatched lang and xml:lang")
+	}
+
+	count := 0
+	for _, e := range result.Errors {
+		if e.Code == ErrorCodeLangAttrMismatch {
+			count++
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected 2 attribute mismatch errors, got %d: %v", count, result.Errors)
+	}
+}
+
+func TestEPUBValidator_LanguageConsistency(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Arabic Test Book</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>ar</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine page-progression-direction="rtl">
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="ar" dir="rtl">
+<head><title>Navigation</title></head>
+<body><nav epub:type="toc"><ol><li><a href="chapter1.xhtml">1</a></li></ol></nav></body>
+</html>`
+
+	chapter := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en" dir="ltr">
+<head><title>Chapter 1</title></head>
+<body><p>English text in an Arabic book.</p></body>
+</html>`
+
+	epubData := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+		{path: "OEBPS/chapter1.xhtml", content: chapter},
+	})
+
+	validator := NewEPUBValidator()
+	report, err := validator.ValidateReader(context.Background(), bytes.NewReader(epubData), int64(len(epubData)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !report.IsValid {
+		t.Errorf("expected language warnings not to invalidate report, got errors: %v", report.Errors)
+	}
+
+	warningCodes := make(map[string]bool)
+	for _, w := range report.Warnings {
+		warningCodes[w.Code] = true
+	}
+	if !warningCodes[ErrorCodeLangDocumentMismatch] {
+		t.Errorf("expected %s warning, got %v", ErrorCodeLangDocumentMismatch, report.Warnings)
+	}
+	if !warningCodes[ErrorCodeLangDirConflict] {
+		t.Errorf("expected %s warning, got %v", ErrorCodeLangDirConflict, report.Warnings)
+	}
+
+	for _, w := range report.Warnings {
+		if w.Location == nil || w.Location.Path != "OEBPS/chapter1.xhtml" {
+			t.Errorf("expected warning located in chapter1.xhtml, got %+v", w.Location)
+		}
+		if w.Details["manifest_id"] != "chapter1" {
+			t.Errorf("expected manifest_id detail, got %v", w.Details)
+		}
+	}
+}
+
+func hasLanguageCode(findings []ValidationError, code string) bool {
+	for _, f := range findings {
+		if f.Code == code {
+			return true
+		}
+	}
+	return false
+}
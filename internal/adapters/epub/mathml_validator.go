@@ -0,0 +1,272 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MathML validation error codes.
+const (
+	ErrorCodeMathInvalidNamespace    = "EPUB-MATH-001"
+	ErrorCodeMathMissingAlternative  = "EPUB-MATH-002"
+	ErrorCodeMathContentOnly         = "EPUB-MATH-003"
+	ErrorCodeMathUndeclaredProperty  = "EPUB-MATH-004"
+	ErrorCodeMathImageMissingAltText = "EPUB-MATH-005"
+)
+
+const MathMLNamespace = "http://www.w3.org/1998/Math/MathML"
+
+// contentMathElements are the Content MathML elements that give an
+// expression its structure; operator elements such as <plus/> only occur
+// inside them.
+var contentMathElements = map[string]bool{
+	"apply": true, "bind": true, "ci": true, "cn": true, "cs": true,
+	"csymbol": true, "cbytes": true, "cerror": true, "share": true,
+	"piecewise": true, "lambda": true, "bvar": true, "interval": true,
+	"set": true, "list": true, "vector": true, "matrix": true,
+	"matrixrow": true, "declare": true, "reln": true, "fn": true,
+}
+
+// presentationMathElements are the Presentation MathML elements.
+var presentationMathElements = map[string]bool{
+	"mi": true, "mn": true, "mo": true, "mtext": true, "mspace": true,
+	"ms": true, "mrow": true, "mfrac": true, "msqrt": true, "mroot": true,
+	"mstyle": true, "merror": true, "mpadded": true, "mphantom": true,
+	"mfenced": true, "menclose": true, "msub": true, "msup": true,
+	"msubsup": true, "munder": true, "mover": true, "munderover": true,
+	"mmultiscripts": true, "mtable": true, "mtr": true, "mtd": true,
+	"mlabeledtr": true, "maction": true, "mstack": true, "mlongdiv": true,
+	"mglyph": true,
+}
+
+var mathImagePattern = regexp.MustCompile(`(?i)(math|equation|formula|(^|[^a-z])eqn?[-_]?\d)`)
+
+// MathMLValidationResult contains the MathML findings for a single content
+// document.
+type MathMLValidationResult struct {
+	Valid      bool
+	Errors     []ValidationError
+	Warnings   []ValidationError
+	Suppressed []ValidationError
+	// MathCount is the number of top-level math elements.
+	MathCount int
+	// PresentationCount and ContentCount are the number of math elements
+	// using Presentation and Content MathML markup. An element with a
+	// Content MathML annotation counts in both.
+	PresentationCount int
+	ContentCount      int
+	// MathImages is the number of images that appear to render math.
+	MathImages int
+}
+
+// MathMLValidator checks the MathML embedded in XHTML content documents and
+// images used in its place.
+type MathMLValidator struct{}
+
+// NewMathMLValidator returns a new MathML validator.
+func NewMathMLValidator() *MathMLValidator {
+	return &MathMLValidator{}
+}
+
+// Validate validates a content document from an io.Reader. properties is
+// the manifest item properties attribute.
+func (v *MathMLValidator) Validate(reader io.Reader, properties string) (*MathMLValidationResult, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content document: %w", err)
+	}
+	return v.ValidateBytes(data, properties)
+}
+
+// ValidateBytes validates a content document from in-memory data.
+func (v *MathMLValidator) ValidateBytes(data []byte, properties string) (*MathMLValidationResult, error) {
+	result := &MathMLValidationResult{
+		Valid:    true,
+		Errors:   make([]ValidationError, 0),
+		Warnings: make([]ValidationError, 0),
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	type frame struct {
+		path   string
+		counts map[string]int
+	}
+	stack := []frame{{counts: make(map[string]int)}}
+
+	// State of the current top-level math element; mathDepth is its stack
+	// depth, or 0 outside math.
+	var (
+		mathDepth       int
+		mathPath        string
+		annotationDepth int
+		annotationText  bool
+		hasAlternative  bool
+		hasPresentation bool
+		hasContent      bool
+		contentOnly     string
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			parent := &stack[len(stack)-1]
+			index := parent.counts[t.Name.Local]
+			parent.counts[t.Name.Local]++
+			elementPath := xmlElementPath(parent.path, t.Name.Local, index)
+			depth := len(stack)
+			stack = append(stack, frame{path: elementPath, counts: make(map[string]int)})
+
+			if mathDepth == 0 {
+				switch t.Name.Local {
+				case "math":
+					result.MathCount++
+					if t.Name.Space != MathMLNamespace {
+						result.Errors = append(result.Errors, ValidationError{
+							Code:    ErrorCodeMathInvalidNamespace,
+							Message: fmt.Sprintf("math element is not in the MathML namespace (%s)", MathMLNamespace),
+							Details: map[string]interface{}{
+								"namespace": t.Name.Space,
+							},
+							element: elementPath,
+						})
+					}
+					if result.MathCount == 1 && !hasPropertyToken(properties, "mathml") {
+						result.Errors = append(result.Errors, ValidationError{
+							Code:    ErrorCodeMathUndeclaredProperty,
+							Message: "Content document contains MathML but its manifest item does not declare the mathml property",
+							Details: map[string]interface{}{
+								"properties": properties,
+							},
+							element: elementPath,
+						})
+					}
+					mathDepth, mathPath = depth, elementPath
+					annotationDepth = 0
+					hasPresentation, hasContent, contentOnly = false, false, ""
+					hasAlternative = strings.TrimSpace(attrValue(t, "", "alttext")) != "" ||
+						strings.TrimSpace(attrValue(t, "", "aria-label")) != "" ||
+						strings.TrimSpace(attrValue(t, "", "aria-labelledby")) != ""
+				case "img":
+					if !isMathImage(attrValue(t, "", "src"), attrValue(t, "", "class"), attrValue(t, "", "role")) {
+						continue
+					}
+					result.MathImages++
+					if alt := attrValue(t, "", "alt"); strings.TrimSpace(alt) == "" {
+						result.Errors = append(result.Errors, ValidationError{
+							Code:    ErrorCodeMathImageMissingAltText,
+							Message: fmt.Sprintf("Image rendering math has no alt text: %s", attrValue(t, "", "src")),
+							Details: map[string]interface{}{
+								"src": attrValue(t, "", "src"),
+							},
+							element: elementPath,
+						})
+					}
+				}
+				continue
+			}
+
+			switch {
+			case t.Name.Local == "annotation" || t.Name.Local == "annotation-xml":
+				if annotationDepth == 0 {
+					annotationDepth = depth
+					annotationText = t.Name.Local == "annotation" || isHTMLEncoding(attrValue(t, "", "encoding"))
+				}
+			case contentMathElements[t.Name.Local]:
+				hasContent = true
+				if annotationDepth == 0 && contentOnly == "" {
+					contentOnly = t.Name.Local
+				}
+			case presentationMathElements[t.Name.Local]:
+				if annotationDepth == 0 {
+					hasPresentation = true
+				}
+			}
+		case xml.CharData:
+			if annotationDepth > 0 && annotationText && len(bytes.TrimSpace(t)) > 0 {
+				hasAlternative = true
+			}
+		case xml.EndElement:
+			depth := len(stack) - 1
+			if depth == annotationDepth {
+				annotationDepth = 0
+			}
+			if depth == mathDepth {
+				if hasPresentation {
+					result.PresentationCount++
+				}
+				if hasContent {
+					result.ContentCount++
+				}
+				if contentOnly != "" {
+					result.Errors = append(result.Errors, ValidationError{
+						Code:    ErrorCodeMathContentOnly,
+						Message: fmt.Sprintf("Content MathML <%s> must be placed in an annotation-xml element alongside Presentation MathML", contentOnly),
+						Details: map[string]interface{}{
+							"element": contentOnly,
+						},
+						element: mathPath,
+					})
+				}
+				if !hasAlternative {
+					result.Warnings = append(result.Warnings, ValidationError{
+						Code:    ErrorCodeMathMissingAlternative,
+						Message: "math element has no alttext or text annotation for readers without MathML support",
+						element: mathPath,
+					})
+				}
+				mathDepth = 0
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	s := collectXMLSuppressions(data)
+	var suppressed []ValidationError
+	result.Errors, suppressed = s.partition(result.Errors, "error")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Warnings, suppressed = s.partition(result.Warnings, "warning")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// isHTMLEncoding reports whether an annotation-xml encoding carries markup
+// that reading systems can render as text.
+func isHTMLEncoding(encoding string) bool {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "application/xhtml+xml", "text/html":
+		return true
+	}
+	return false
+}
+
+// isMathImage reports whether an img element appears to render an equation,
+// judging by its role, class names or file name.
+func isMathImage(src, class, role string) bool {
+	if strings.EqualFold(strings.TrimSpace(role), "math") {
+		return true
+	}
+	for _, name := range strings.Fields(class) {
+		if mathImagePattern.MatchString(name) {
+			return true
+		}
+	}
+	return src != "" && mathImagePattern.MatchString(path.Base(src))
+}
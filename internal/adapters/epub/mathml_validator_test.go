@@ -0,0 +1,243 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func mathDocument(body string) []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Math</title></head>
+<body>` + body + `</body>
+</html>`)
+}
+
+func TestMathMLValidator(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		properties   string
+		wantErrors   []string
+		wantWarnings []string
+		presentation int
+		content      int
+	}{
+		{
+			name: "no math",
+			body: `<p>Plain&nbsp;text<br></p><img src="images/photo.jpg" alt=""/>`,
+		},
+		{
+			name:         "presentation with alttext",
+			body:         `<math xmlns="http://www.w3.org/1998/Math/MathML" alttext="x squared"><msup><mi>x</mi><mn>2</mn></msup></math>`,
+			properties:   "mathml",
+			presentation: 1,
+		},
+		{
+			name: "annotation fallback",
+			body: `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics>
+  <msup><mi>x</mi><mn>2</mn></msup>
+  <annotation encoding="application/x-tex">x^2</annotation>
+</semantics></math>`,
+			properties:   "mathml",
+			presentation: 1,
+		},
+		{
+			name: "content annotation is allowed",
+			body: `<math xmlns="http://www.w3.org/1998/Math/MathML" alttext="x squared"><semantics>
+  <msup><mi>x</mi><mn>2</mn></msup>
+  <annotation-xml encoding="MathML-Content"><apply><power/><ci>x</ci><cn>2</cn></apply></annotation-xml>
+</semantics></math>`,
+			properties:   "mathml",
+			presentation: 1,
+			content:      1,
+		},
+		{
+			name:         "content MathML only",
+			body:         `<math xmlns="http://www.w3.org/1998/Math/MathML" alttext="x squared"><apply><power/><ci>x</ci><cn>2</cn></apply></math>`,
+			properties:   "mathml",
+			wantErrors:   []string{ErrorCodeMathContentOnly},
+			presentation: 0,
+			content:      1,
+		},
+		{
+			name:         "missing alternative",
+			body:         `<math xmlns="http://www.w3.org/1998/Math/MathML"><mi>x</mi></math>`,
+			properties:   "mathml",
+			wantWarnings: []string{ErrorCodeMathMissingAlternative},
+			presentation: 1,
+		},
+		{
+			name:         "missing namespace",
+			body:         `<math alttext="x"><mi>x</mi></math>`,
+			properties:   "mathml",
+			wantErrors:   []string{ErrorCodeMathInvalidNamespace},
+			presentation: 1,
+		},
+		{
+			name:         "undeclared property reported once",
+			body:         `<math xmlns="http://www.w3.org/1998/Math/MathML" alttext="x"><mi>x</mi></math><math xmlns="http://www.w3.org/1998/Math/MathML" alttext="y"><mi>y</mi></math>`,
+			properties:   "svg",
+			wantErrors:   []string{ErrorCodeMathUndeclaredProperty},
+			presentation: 2,
+		},
+		{
+			name: "math images without alt text",
+			body: `<img src="images/eq1.png"/>
+<img src="images/figure.png" class="display-equation" alt=" "/>
+<img src="images/quadratic.png" role="math" alt="x equals minus b plus or minus the square root of b squared minus 4 a c, all over 2 a"/>
+<img src="images/sequence1.png" alt=""/>`,
+			wantErrors: []string{ErrorCodeMathImageMissingAltText, ErrorCodeMathImageMissingAltText},
+		},
+	}
+
+	validator := NewMathMLValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(mathDocument(tt.body), tt.properties)
+			if err != nil {
+				t.Fatalf("ValidateBytes() error = %v", err)
+			}
+
+			errors, warnings := svgCodes(result.Errors), svgCodes(result.Warnings)
+			if len(errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %v, want %v", errors, tt.wantErrors)
+			}
+			for i, code := range tt.wantErrors {
+				if errors[i] != code {
+					t.Errorf("errors[%d] = %s, want %s", i, errors[i], code)
+				}
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+			if result.PresentationCount != tt.presentation || result.ContentCount != tt.content {
+				t.Errorf("presentation/content = %d/%d, want %d/%d",
+					result.PresentationCount, result.ContentCount, tt.presentation, tt.content)
+			}
+		})
+	}
+}
+
+func TestMathMLValidator_Suppression(t *testing.T) {
+	body := `<!-- ebm-ignore EPUB-MATH-002 described in the surrounding text -->
+<math xmlns="http://www.w3.org/1998/Math/MathML"><mi>x</mi></math>
+<math xmlns="http://www.w3.org/1998/Math/MathML"><mi>y</mi></math>`
+
+	result, err := NewMathMLValidator().ValidateBytes(mathDocument(body), "mathml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 1 || len(result.Suppressed) != 1 {
+		t.Errorf("expected one reported and one suppressed warning, got %v / %v", result.Warnings, result.Suppressed)
+	}
+}
+
+func TestAccessibilityValidator_Math(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantScore   int
+		wantTotal   int
+		wantFeature bool
+	}{
+		{
+			name:      "no math scores full marks",
+			body:      `<p>Text</p>`,
+			wantScore: MathWeight,
+		},
+		{
+			name: "described math",
+			body: `<math xmlns="http://www.w3.org/1998/Math/MathML" alttext="x"><mi>x</mi></math>
+<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><mi>y</mi><annotation encoding="application/x-tex">y</annotation></semantics></math>`,
+			wantScore:   MathWeight,
+			wantTotal:   2,
+			wantFeature: true,
+		},
+		{
+			name: "undescribed math",
+			body: `<math xmlns="http://www.w3.org/1998/Math/MathML"><mi>x</mi></math>
+<img src="images/equation-2.png" alt="y"/>
+<img src="images/equation-3.png"/>
+<img src="images/equation-4.png" alt="z"/>`,
+			wantScore: (2 * MathWeight) / 4,
+			wantTotal: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewAccessibilityValidator().ValidateBytes(mathDocument(tt.body))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Score.MathAccessibility != tt.wantScore || result.TotalMath != tt.wantTotal {
+				t.Errorf("math score = %d of %d expressions, want %d of %d",
+					result.Score.MathAccessibility, result.TotalMath, tt.wantScore, tt.wantTotal)
+			}
+
+			described := false
+			for _, feature := range result.Metadata.AccessibilityFeatures {
+				if feature == "describedMath" {
+					described = true
+				}
+			}
+			if described != tt.wantFeature {
+				t.Errorf("describedMath feature = %v, want %v", described, tt.wantFeature)
+			}
+		})
+	}
+}
+
+func TestEPUBValidator_MathML(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>MathML Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Contents</h1>
+    <ol><li><a href="ch1.xhtml">Chapter 1</a></li></ol>
+  </nav>
+</body>
+</html>`
+
+	chapter := string(mathDocument(`<p><math xmlns="http://www.w3.org/1998/Math/MathML"><mi>x</mi></math></p>`))
+	data := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+		{path: "OEBPS/ch1.xhtml", content: chapter},
+	})
+
+	report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, finding := range append(report.Errors, report.Warnings...) {
+		if finding.Location != nil && finding.Location.Path == "OEBPS/ch1.xhtml" {
+			found[finding.Code] = true
+		}
+	}
+	if !found[ErrorCodeMathUndeclaredProperty] || !found[ErrorCodeMathMissingAlternative] {
+		t.Errorf("expected MathML findings on ch1.xhtml, got %v", found)
+	}
+}
@@ -0,0 +1,920 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MetadataChanges describes edits to the package metadata. Nil fields are left
+// untouched; a non-nil field replaces every existing entry of its kind, and an
+// empty value or slice removes them.
+type MetadataChanges struct {
+	// Title replaces the text of the main title only, keeping its id and
+	// refinements; subtitles and other titles are left alone.
+	Title *string
+	// Creators replace the existing creators in order, reusing their ids.
+	Creators    []Creator
+	Identifiers []Identifier
+	Language    *string
+	Publisher   *string
+	Series      *Series
+	Subjects    []string
+	Description *string
+	// Accessibility replaces the schema.org accessibility metadata. Only the
+	// access modes, features, hazards, summary, conformance claims and
+	// certifier are written.
+	Accessibility *AccessibilityMetadata
+	Cover         *CoverImage
+}
+
+// CoverImage is a cover image to store in the EPUB and mark as the cover.
+type CoverImage struct {
+	// Href is the image's archive path relative to the package document,
+	// such as "images/my cover.jpg". It is a file path, not a URL: it is
+	// not percent-decoded, and the manifest href is encoded from it.
+	Href string
+	// MediaType defaults to the type registered for the Href extension.
+	MediaType string
+	// Data is the image content. It may be empty when Href names an image
+	// already in the EPUB.
+	Data []byte
+}
+
+// IsEmpty reports whether no change is requested.
+func (c MetadataChanges) IsEmpty() bool {
+	return c.Title == nil && c.Creators == nil && c.Identifiers == nil &&
+		c.Language == nil && c.Publisher == nil && c.Series == nil &&
+		c.Subjects == nil && c.Description == nil && c.Accessibility == nil &&
+		c.Cover == nil
+}
+
+// WriteMetadata applies changes to the package document of the EPUB at
+// filePath and writes the result to outputPath, or back to filePath when
+// outputPath is empty. dcterms:modified is refreshed. Other archive entries
+// are copied without recompression, and XML outside the edited elements is
+// preserved byte for byte.
+func WriteMetadata(filePath, outputPath string, changes MetadataChanges) error {
+	if outputPath == "" {
+		outputPath = filePath
+	}
+	data, err := os.ReadFile(filePath) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to read EPUB: %w", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to read EPUB as ZIP: %w", err)
+	}
+
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	opfData, err := pub.ReadFile(pub.PackagePath)
+	if err != nil {
+		return err
+	}
+
+	var cover *CoverImage
+	coverPath := ""
+	if changes.Cover != nil {
+		coverHref, err := coverRelPath(changes.Cover)
+		if err != nil {
+			return err
+		}
+		coverPath = path.Join(path.Dir(pub.PackagePath), coverHref)
+		if coverPath == ".." || strings.HasPrefix(coverPath, "../") {
+			return fmt.Errorf("cover image %s is outside the container", changes.Cover.Href)
+		}
+		if len(changes.Cover.Data) > 0 {
+			cover = changes.Cover
+		} else if !pub.HasFile(coverPath) {
+			return fmt.Errorf("cover image %s has no data and is not in the EPUB", coverPath)
+		}
+	}
+
+	edited, err := editPackageMetadata(opfData, changes, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to edit %s: %w", pub.PackagePath, err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(outputPath), "ebm-lib-meta-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		_ = os.Remove(tempPath)
+	}()
+	if info, err := os.Stat(filePath); err == nil {
+		_ = tempFile.Chmod(info.Mode().Perm())
+	}
+
+	if err := writeEditedArchive(tempFile, zipReader, pub.PackagePath, edited, coverPath, cover); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	if err := os.Rename(tempPath, outputPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", outputPath, err)
+	}
+	return nil
+}
+
+func writeEditedArchive(w io.Writer, zipReader *zip.Reader, opfPath string, opfData []byte, coverPath string, cover *CoverImage) error {
+	zipWriter := zip.NewWriter(w)
+	for _, f := range zipReader.File {
+		switch f.Name {
+		case opfPath:
+			writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+				Name:     f.Name,
+				Method:   zip.Deflate,
+				Modified: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", f.Name, err)
+			}
+			if _, err := writer.Write(opfData); err != nil {
+				return fmt.Errorf("failed to write %s: %w", f.Name, err)
+			}
+		case coverPath:
+			if cover != nil {
+				continue
+			}
+			if err := zipWriter.Copy(f); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", f.Name, err)
+			}
+		default:
+			if err := zipWriter.Copy(f); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", f.Name, err)
+			}
+		}
+	}
+
+	if cover != nil {
+		writer, err := zipWriter.Create(coverPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", coverPath, err)
+		}
+		if _, err := writer.Write(cover.Data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", coverPath, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish EPUB: %w", err)
+	}
+	return nil
+}
+
+// opfElement is an element of the package document located by byte offsets.
+type opfElement struct {
+	metadataElement
+	start, end int
+	removed    bool
+}
+
+// opfScan is the layout of a package document needed to edit it in place.
+type opfScan struct {
+	version      string
+	uniqueID     string
+	prefixes     map[string]string // namespace URI -> prefix
+	ids          map[string]bool
+	metadata     []opfElement
+	metadataEnd  int // offset of </metadata>
+	items        []opfElement
+	manifestEnd  int // offset of </manifest>
+	childIndent  string
+	manifestItem string
+}
+
+func scanPackageDocument(data []byte) (*opfScan, error) {
+	scan := &opfScan{
+		prefixes:    make(map[string]string),
+		ids:         make(map[string]bool),
+		metadataEnd: -1,
+		manifestEnd: -1,
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	var stack []string
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OPF: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					if _, seen := scan.prefixes[attr.Value]; !seen {
+						scan.prefixes[attr.Value] = attr.Name.Local
+					}
+				case attr.Name.Local == "id":
+					scan.ids[attr.Value] = true
+				}
+			}
+
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if parent == "metadata" || (parent == "manifest" && t.Name.Local == "item") {
+				element := opfElement{start: offset}
+				if err := decoder.DecodeElement(&element.metadataElement, &t); err != nil {
+					return nil, fmt.Errorf("failed to parse OPF: %w", err)
+				}
+				element.end = int(decoder.InputOffset())
+				element.Value = strings.TrimSpace(element.Value)
+				if id := element.attr("id"); id != "" {
+					scan.ids[id] = true
+				}
+				if parent == "metadata" {
+					scan.metadata = append(scan.metadata, element)
+				} else {
+					scan.items = append(scan.items, element)
+				}
+				continue
+			}
+
+			if len(stack) == 0 && t.Name.Local == "package" {
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "version":
+						scan.version = strings.TrimSpace(attr.Value)
+					case "unique-identifier":
+						scan.uniqueID = strings.TrimSpace(attr.Value)
+					}
+				}
+			}
+			stack = append(stack, t.Name.Local)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			switch stack[len(stack)-1] {
+			case "metadata":
+				if scan.metadataEnd == -1 {
+					scan.metadataEnd = offset
+				}
+			case "manifest":
+				if scan.manifestEnd == -1 {
+					scan.manifestEnd = offset
+				}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if scan.metadataEnd == -1 {
+		return nil, errors.New("package document has no metadata element")
+	}
+	scan.childIndent = "    "
+	if len(scan.metadata) > 0 {
+		if indent, ok := lineIndent(data, scan.metadata[0].start); ok {
+			scan.childIndent = indent
+		}
+	}
+	scan.manifestItem = scan.childIndent
+	if len(scan.items) > 0 {
+		if indent, ok := lineIndent(data, scan.items[0].start); ok {
+			scan.manifestItem = indent
+		}
+	}
+	return scan, nil
+}
+
+// lineIndent returns the whitespace before offset when nothing else precedes
+// it on its line.
+func lineIndent(data []byte, offset int) (string, bool) {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	indent := data[lineStart:offset]
+	if len(bytes.TrimSpace(indent)) != 0 {
+		return "", false
+	}
+	return string(indent), true
+}
+
+func (s *opfScan) isEPUB3() bool {
+	return !strings.HasPrefix(s.version, "2")
+}
+
+func (s *opfScan) newID(base string) string {
+	id := base
+	for n := 2; s.ids[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	s.ids[id] = true
+	return id
+}
+
+// qualified returns the tag name for local in namespace, and a namespace
+// declaration to add when the document does not declare a prefix for it.
+func (s *opfScan) qualified(namespace, preferred, local string) (string, string) {
+	if prefix, ok := s.prefixes[namespace]; ok {
+		return prefix + ":" + local, ""
+	}
+	return preferred + ":" + local, fmt.Sprintf(` xmlns:%s="%s"`, preferred, namespace)
+}
+
+// metadataGroup selects the metadata elements replaced by one change.
+type metadataGroup struct {
+	matches  func(e *opfElement) bool
+	elements []string
+}
+
+// editPackageMetadata applies changes to an OPF document, replacing only the
+// affected <metadata> children (with their refinements) and manifest items.
+func editPackageMetadata(data []byte, changes MetadataChanges, now time.Time) ([]byte, error) {
+	scan, err := scanPackageDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	isDC := func(local string) func(e *opfElement) bool {
+		return func(e *opfElement) bool {
+			return e.XMLName.Space == DCNamespace && e.XMLName.Local == local
+		}
+	}
+	isMeta := func(properties ...string) func(e *opfElement) bool {
+		return func(e *opfElement) bool {
+			if e.XMLName.Local != "meta" || e.attr("refines") != "" {
+				return false
+			}
+			for _, property := range properties {
+				if e.attr("property") == property || e.attr("name") == property {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	var groups []metadataGroup
+	var edits []textEdit
+	if changes.Title != nil {
+		title := strings.TrimSpace(*changes.Title)
+		main := scan.mainTitle()
+		switch {
+		case main == nil:
+			groups = append(groups, metadataGroup{func(*opfElement) bool { return false }, scan.dcElements("title", title)})
+		case title == "":
+			groups = append(groups, metadataGroup{func(e *opfElement) bool { return e == main }, nil})
+		default:
+			edits = append(edits, replaceElementText(data, main, title))
+		}
+	}
+	if changes.Creators != nil {
+		groups = append(groups, metadataGroup{isDC("creator"), scan.creatorElements(changes.Creators)})
+	}
+	if changes.Identifiers != nil {
+		groups = append(groups, metadataGroup{isDC("identifier"), scan.identifierElements(changes.Identifiers)})
+	}
+	if changes.Language != nil {
+		groups = append(groups, metadataGroup{isDC("language"), scan.dcElements("language", *changes.Language)})
+	}
+	if changes.Publisher != nil {
+		groups = append(groups, metadataGroup{isDC("publisher"), scan.dcElements("publisher", *changes.Publisher)})
+	}
+	if changes.Description != nil {
+		groups = append(groups, metadataGroup{isDC("description"), scan.dcElements("description", *changes.Description)})
+	}
+	if changes.Subjects != nil {
+		groups = append(groups, metadataGroup{isDC("subject"), scan.dcElements("subject", changes.Subjects...)})
+	}
+	if changes.Series != nil {
+		groups = append(groups, metadataGroup{
+			isMeta("belongs-to-collection", "calibre:series", "calibre:series_index"),
+			scan.seriesElements(*changes.Series),
+		})
+	}
+	if changes.Accessibility != nil {
+		properties := make([]string, 0, len(accessibilityProperties))
+		for property := range accessibilityProperties {
+			properties = append(properties, property)
+		}
+		groups = append(groups, metadataGroup{isMeta(properties...), scan.accessibilityElements(changes.Accessibility)})
+	}
+
+	if changes.Cover != nil {
+		coverEdits, coverMeta, err := scan.coverEdits(data, changes.Cover)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, coverEdits...)
+		groups = append(groups, metadataGroup{isMeta("cover"), []string{coverMeta}})
+	}
+
+	hasModified := false
+	for i := range scan.metadata {
+		if isMeta(DCTermsProperty)(&scan.metadata[i]) {
+			hasModified = true
+		}
+	}
+	if scan.isEPUB3() || hasModified {
+		groups = append(groups, metadataGroup{
+			isMeta(DCTermsProperty),
+			[]string{fmt.Sprintf(`<meta property="%s">%s</meta>`, DCTermsProperty, now.UTC().Format("2006-01-02T15:04:05Z"))},
+		})
+	}
+
+	edits = append(edits, scan.metadataEdits(data, groups)...)
+	return applyTextEdits(data, edits), nil
+}
+
+// metadataEdits removes the elements of each group, with the refinements
+// pointing at them, and inserts the group's replacements where its first
+// element was, or at the end of <metadata>.
+func (s *opfScan) metadataEdits(data []byte, groups []metadataGroup) []textEdit {
+	var edits []textEdit
+	for order, group := range groups {
+		removed := make(map[string]bool)
+		insertAt := -1
+		lineBased := true
+		for i := range s.metadata {
+			element := &s.metadata[i]
+			if element.removed || !group.matches(element) {
+				continue
+			}
+			element.removed = true
+			if id := element.attr("id"); id != "" {
+				removed[id] = true
+			}
+			start, end, ownLine := elementLine(data, element.start, element.end)
+			if insertAt == -1 {
+				insertAt, lineBased = start, ownLine
+			}
+			edits = append(edits, textEdit{start: start, end: end})
+		}
+
+		// Refinements may refine other refinements, so repeat until stable.
+		for changed := true; changed; {
+			changed = false
+			for i := range s.metadata {
+				element := &s.metadata[i]
+				refines := strings.TrimPrefix(element.attr("refines"), "#")
+				if element.XMLName.Local != "meta" || refines == "" || !removed[refines] {
+					continue
+				}
+				id := element.attr("id")
+				if id != "" && removed[id] {
+					continue
+				}
+				if id != "" {
+					removed[id] = true
+					changed = true
+				}
+				if !element.removed {
+					element.removed = true
+					start, end, _ := elementLine(data, element.start, element.end)
+					edits = append(edits, textEdit{start: start, end: end})
+				}
+			}
+		}
+
+		if len(group.elements) == 0 {
+			continue
+		}
+		if insertAt == -1 {
+			insertAt, lineBased = s.metadataEnd, false
+			if _, ok := lineIndent(data, s.metadataEnd); ok {
+				insertAt = bytes.LastIndexByte(data[:s.metadataEnd], '\n') + 1
+				lineBased = true
+			}
+		}
+
+		var text strings.Builder
+		for _, element := range group.elements {
+			if lineBased {
+				text.WriteString(s.childIndent + element + "\n")
+			} else {
+				text.WriteString(element)
+			}
+		}
+		edits = append(edits, textEdit{start: insertAt, end: insertAt, text: text.String(), order: order})
+	}
+	return edits
+}
+
+// elementLine widens [start, end) to whole lines when the element is alone on
+// its line, so removing it leaves no blank line behind.
+func elementLine(data []byte, start, end int) (int, int, bool) {
+	lineStart := bytes.LastIndexByte(data[:start], '\n') + 1
+	if len(bytes.TrimSpace(data[lineStart:start])) != 0 {
+		return start, end, false
+	}
+	lineEnd := bytes.IndexByte(data[end:], '\n')
+	if lineEnd == -1 || len(bytes.TrimSpace(data[end:end+lineEnd])) != 0 {
+		return start, end, false
+	}
+	return lineStart, end + lineEnd + 1, true
+}
+
+func (s *opfScan) dcElements(local string, values ...string) []string {
+	name, xmlns := s.qualified(DCNamespace, "dc", local)
+	var elements []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		elements = append(elements, fmt.Sprintf("<%s%s>%s</%s>", name, xmlns, escapeXML(value), name))
+	}
+	return elements
+}
+
+// mainTitle returns the title refined as title-type main, or the first
+// title.
+func (s *opfScan) mainTitle() *opfElement {
+	mainIDs := make(map[string]bool)
+	for i := range s.metadata {
+		element := &s.metadata[i]
+		if element.XMLName.Local == "meta" && element.attr("property") == "title-type" && element.Value == "main" {
+			mainIDs[strings.TrimPrefix(element.attr("refines"), "#")] = true
+		}
+	}
+	var first *opfElement
+	for i := range s.metadata {
+		element := &s.metadata[i]
+		if element.XMLName.Space != DCNamespace || element.XMLName.Local != "title" {
+			continue
+		}
+		if id := element.attr("id"); id != "" && mainIDs[id] {
+			return element
+		}
+		if first == nil {
+			first = element
+		}
+	}
+	return first
+}
+
+// replaceElementText replaces the content of an element, keeping its start
+// tag byte for byte.
+func replaceElementText(data []byte, element *opfElement, value string) textEdit {
+	raw := data[element.start:element.end]
+	tagEnd := -1
+	var quote byte
+	for i, c := range raw {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			tagEnd = i
+		}
+		if tagEnd != -1 {
+			break
+		}
+	}
+	if tagEnd > 0 && raw[tagEnd-1] == '/' {
+		// <dc:title id="t"/> becomes <dc:title id="t">value</dc:title>.
+		name := strings.FieldsFunc(string(raw[1:tagEnd]), func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '/'
+		})[0]
+		open := strings.TrimRight(string(raw[:tagEnd-1]), " \t\r\n")
+		return textEdit{
+			start: element.start,
+			end:   element.end,
+			text:  fmt.Sprintf("%s>%s</%s>", open, escapeXML(value), name),
+		}
+	}
+	closeStart := bytes.LastIndex(raw, []byte("</"))
+	return textEdit{start: element.start + tagEnd + 1, end: element.start + closeStart, text: escapeXML(value)}
+}
+
+// creatorElements builds the replacement creators. The nth creator keeps
+// the id of the nth existing creator so references to it stay valid.
+func (s *opfScan) creatorElements(creators []Creator) []string {
+	name, xmlns := s.qualified(DCNamespace, "dc", "creator")
+	var ids []string
+	for i := range s.metadata {
+		element := &s.metadata[i]
+		if element.XMLName.Space == DCNamespace && element.XMLName.Local == "creator" {
+			ids = append(ids, element.attr("id"))
+		}
+	}
+
+	var elements []string
+	for _, creator := range creators {
+		creatorName := strings.TrimSpace(creator.Name)
+		if creatorName == "" {
+			continue
+		}
+		fileAs := strings.TrimSpace(creator.FileAs)
+		id := ""
+		if len(ids) > 0 {
+			id, ids = ids[0], ids[1:]
+		}
+
+		if !s.isEPUB3() {
+			attrs := xmlns
+			if id != "" {
+				attrs = fmt.Sprintf(` id="%s"`, escapeXML(id)) + attrs
+			}
+			if len(creator.Roles) > 0 {
+				attrs += s.opfAttr("role", creator.Roles[0])
+			}
+			if fileAs != "" {
+				attrs += s.opfAttr("file-as", fileAs)
+			}
+			elements = append(elements, fmt.Sprintf("<%s%s>%s</%s>", name, attrs, escapeXML(creatorName), name))
+			continue
+		}
+
+		if id == "" && len(creator.Roles) == 0 && fileAs == "" {
+			elements = append(elements, fmt.Sprintf("<%s%s>%s</%s>", name, xmlns, escapeXML(creatorName), name))
+			continue
+		}
+		if id == "" {
+			id = s.newID("creator")
+		}
+		elements = append(elements, fmt.Sprintf(`<%s id="%s"%s>%s</%s>`, name, escapeXML(id), xmlns, escapeXML(creatorName), name))
+		for _, role := range creator.Roles {
+			elements = append(elements, refineMeta(id, "role", role, ` scheme="marc:relators"`))
+		}
+		if fileAs != "" {
+			elements = append(elements, refineMeta(id, "file-as", fileAs, ""))
+		}
+	}
+	return elements
+}
+
+func (s *opfScan) identifierElements(identifiers []Identifier) []string {
+	name, xmlns := s.qualified(DCNamespace, "dc", "identifier")
+
+	keepsUniqueID := false
+	for _, identifier := range identifiers {
+		if identifier.ID != "" && identifier.ID == s.uniqueID {
+			keepsUniqueID = true
+		}
+	}
+
+	var elements []string
+	for i, identifier := range identifiers {
+		value := strings.TrimSpace(identifier.Value)
+		if value == "" {
+			continue
+		}
+		id := identifier.ID
+		if i == 0 && !keepsUniqueID && s.uniqueID != "" {
+			id = s.uniqueID
+		}
+		scheme := strings.TrimSpace(identifier.Scheme)
+		if id == "" && scheme != "" && s.isEPUB3() {
+			id = s.newID("identifier")
+		}
+
+		attrs := ""
+		if id != "" {
+			attrs = fmt.Sprintf(` id="%s"`, escapeXML(id))
+		}
+		attrs += xmlns
+		if scheme != "" && !s.isEPUB3() {
+			attrs += s.opfAttr("scheme", scheme)
+		}
+		elements = append(elements, fmt.Sprintf("<%s%s>%s</%s>", name, attrs, escapeXML(value), name))
+		if scheme != "" && s.isEPUB3() {
+			elements = append(elements, refineMeta(id, "identifier-type", scheme, ""))
+		}
+	}
+	return elements
+}
+
+func (s *opfScan) seriesElements(series Series) []string {
+	seriesName := strings.TrimSpace(series.Name)
+	if seriesName == "" {
+		return nil
+	}
+	position := strings.TrimSpace(series.Position)
+
+	if !s.isEPUB3() {
+		elements := []string{nameMeta("calibre:series", seriesName)}
+		if position != "" {
+			elements = append(elements, nameMeta("calibre:series_index", position))
+		}
+		return elements
+	}
+
+	collectionType := strings.TrimSpace(series.Type)
+	if collectionType == "" {
+		collectionType = "series"
+	}
+	id := s.newID("series")
+	elements := []string{
+		fmt.Sprintf(`<meta property="belongs-to-collection" id="%s">%s</meta>`, id, escapeXML(seriesName)),
+		refineMeta(id, "collection-type", collectionType, ""),
+	}
+	if position != "" {
+		elements = append(elements, refineMeta(id, "group-position", position, ""))
+	}
+	return elements
+}
+
+func (s *opfScan) accessibilityElements(metadata *AccessibilityMetadata) []string {
+	var elements []string
+	add := func(property string, values ...string) {
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value == "" {
+				continue
+			}
+			if s.isEPUB3() {
+				elements = append(elements, fmt.Sprintf(`<meta property="%s">%s</meta>`, property, escapeXML(value)))
+			} else {
+				elements = append(elements, nameMeta(property, value))
+			}
+		}
+	}
+	add("schema:accessMode", metadata.AccessModes...)
+	add("schema:accessModeSufficient", metadata.AccessModeSufficient...)
+	add("schema:accessibilityFeature", metadata.AccessibilityFeatures...)
+	add("schema:accessibilityHazard", metadata.AccessibilityHazards...)
+	add("schema:accessibilitySummary", metadata.AccessibilitySummary)
+	add("dcterms:conformsTo", metadata.ConformanceClaims...)
+	add("a11y:certifiedBy", metadata.CertifiedBy)
+	add("a11y:certifierCredential", metadata.CertifierCredential)
+	return elements
+}
+
+var propertiesAttr = regexp.MustCompile(`\sproperties\s*=\s*("[^"]*"|'[^']*')`)
+
+// coverEdits marks the manifest item for cover as the cover image, adding it
+// when needed, and removes the cover-image property from other items. It
+// returns the EPUB 2 <meta name="cover"> for the item.
+func (s *opfScan) coverEdits(data []byte, cover *CoverImage) ([]textEdit, string, error) {
+	href, err := coverRelPath(cover)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType := cover.MediaType
+	if mediaType == "" {
+		mediaType, _, _ = strings.Cut(mime.TypeByExtension(path.Ext(href)), ";")
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, "", fmt.Errorf("cover image %s is not an image", href)
+	}
+	if s.manifestEnd == -1 {
+		return nil, "", errors.New("package document has no manifest element")
+	}
+
+	var edits []textEdit
+	coverID := ""
+	for i := range s.items {
+		item := &s.items[i]
+		properties := strings.Fields(item.attr("properties"))
+		isTarget := resolveHref("", item.attr("href")).Path == href
+		if isTarget {
+			coverID = item.attr("id")
+		}
+
+		kept := make([]string, 0, len(properties)+1)
+		for _, property := range properties {
+			if property != "cover-image" {
+				kept = append(kept, property)
+			}
+		}
+		if isTarget && s.isEPUB3() {
+			kept = append(kept, "cover-image")
+		}
+		propertiesChanged := strings.Join(kept, " ") != strings.Join(properties, " ")
+		mediaTypeChanged := isTarget && item.attr("media-type") != mediaType
+		if !propertiesChanged && !mediaTypeChanged {
+			continue
+		}
+
+		tag := string(data[item.start:item.end])
+		if isTarget {
+			tag = setAttr(tag, "media-type", mediaType)
+		}
+		if len(kept) == 0 {
+			tag = propertiesAttr.ReplaceAllString(tag, "")
+		} else {
+			tag = setAttr(tag, "properties", strings.Join(kept, " "))
+		}
+		edits = append(edits, textEdit{start: item.start, end: item.end, text: tag})
+	}
+
+	if coverID == "" {
+		coverID = s.newID("cover-image")
+		properties := ""
+		if s.isEPUB3() {
+			properties = ` properties="cover-image"`
+		}
+		item := fmt.Sprintf(`<item id="%s" href="%s" media-type="%s"%s/>`, coverID, escapeXML(hrefForPath(href)), mediaType, properties)
+		insertAt := s.manifestEnd
+		if _, ok := lineIndent(data, s.manifestEnd); ok {
+			insertAt = bytes.LastIndexByte(data[:s.manifestEnd], '\n') + 1
+			item = s.manifestItem + item + "\n"
+		}
+		edits = append(edits, textEdit{start: insertAt, end: insertAt, text: item})
+	}
+
+	return edits, nameMeta("cover", coverID), nil
+}
+
+// coverRelPath returns the cover's archive path relative to the package
+// document, cleaned and NFC-normalized so it compares equal to resolved
+// manifest hrefs.
+func coverRelPath(cover *CoverImage) (string, error) {
+	href := strings.TrimPrefix(strings.TrimSpace(cover.Href), "/")
+	if href == "" {
+		return "", errors.New("cover image has no href")
+	}
+	return norm.NFC.String(path.Clean(href)), nil
+}
+
+// setAttr sets attribute name in the start tag, replacing its value or
+// appending it before the tag end.
+func setAttr(tag, name, value string) string {
+	attr := regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `\s*=\s*)("[^"]*"|'[^']*')`)
+	quoted := `"` + escapeXML(value) + `"`
+	if attr.MatchString(tag) {
+		return attr.ReplaceAllLiteralString(tag, " "+name+"="+quoted)
+	}
+	end := strings.LastIndex(tag, "/>")
+	if end == -1 {
+		end = strings.Index(tag, ">")
+	}
+	return tag[:end] + " " + name + "=" + quoted + tag[end:]
+}
+
+func (s *opfScan) opfAttr(local, value string) string {
+	if prefix, ok := s.prefixes[OPFNamespace]; ok && prefix != "" {
+		return fmt.Sprintf(` %s:%s="%s"`, prefix, local, escapeXML(value))
+	}
+	return fmt.Sprintf(` xmlns:opf="%s" opf:%s="%s"`, OPFNamespace, local, escapeXML(value))
+}
+
+func refineMeta(id, property, value, extra string) string {
+	return fmt.Sprintf(`<meta refines="#%s" property="%s"%s>%s</meta>`, escapeXML(id), property, extra, escapeXML(strings.TrimSpace(value)))
+}
+
+func nameMeta(name, content string) string {
+	return fmt.Sprintf(`<meta name="%s" content="%s"/>`, name, escapeXML(content))
+}
+
+func escapeXML(value string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// textEdit replaces data[start:end] with text. Insertions (start == end) go
+// before a replacement at the same offset and are applied in ascending order.
+type textEdit struct {
+	start, end int
+	text       string
+	order      int
+}
+
+func applyTextEdits(data []byte, edits []textEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		iInsert, jInsert := edits[i].start == edits[i].end, edits[j].start == edits[j].end
+		if iInsert != jInsert {
+			return iInsert
+		}
+		return edits[i].order < edits[j].order
+	})
+
+	var out bytes.Buffer
+	pos := 0
+	for _, edit := range edits {
+		if edit.start < pos {
+			continue
+		}
+		out.Write(data[pos:edit.start])
+		out.WriteString(edit.text)
+		pos = edit.end
+	}
+	out.Write(data[pos:])
+	return out.Bytes()
+}
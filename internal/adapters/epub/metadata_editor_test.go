@@ -0,0 +1,355 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const editorEPUB3OPF = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- keep this comment -->
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid" prefix="calibre: https://calibre-ebook.com">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title id="t1">Teh Title</dc:title>
+    <meta refines="#t1" property="title-type">main</meta>
+    <dc:creator id="c1">Jane Deo</dc:creator>
+    <meta refines="#c1" property="role" scheme="marc:relators">aut</meta>
+    <dc:identifier id="uid">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>
+    <dc:language>en</dc:language>
+    <dc:subject>Old</dc:subject>
+    <meta   property="custom:untouched"  >value &amp; more</meta>
+    <meta property="dcterms:modified">2020-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="old-cover" href="images/old.jpg" media-type="image/jpeg" properties="cover-image"/>
+  </manifest>
+  <spine>
+    <itemref idref="nav"/>
+  </spine>
+</package>
+`
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestEditPackageMetadata_EPUB3(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	edited, err := editPackageMetadata([]byte(editorEPUB3OPF), MetadataChanges{
+		Title:     stringPtr("The Title"),
+		Creators:  []Creator{{Name: "Jane Doe", FileAs: "Doe, Jane", Roles: []string{"aut"}}},
+		Publisher: stringPtr("Example & Sons"),
+		Series:    &Series{Name: "The Cases", Position: "2"},
+		Subjects:  []string{"Fiction", "Mystery"},
+		Accessibility: &AccessibilityMetadata{
+			AccessModes:          []string{"textual"},
+			AccessibilitySummary: "No known hazards.",
+		},
+		Cover: &CoverImage{Href: "images/cover.png"},
+	}, now)
+	if err != nil {
+		t.Fatalf("editPackageMetadata failed: %v", err)
+	}
+	out := string(edited)
+
+	for _, unchanged := range []string{
+		"<!-- keep this comment -->",
+		`prefix="calibre: https://calibre-ebook.com"`,
+		`    <meta   property="custom:untouched"  >value &amp; more</meta>`,
+		`<dc:identifier id="uid">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>`,
+		`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>`,
+	} {
+		if !strings.Contains(out, unchanged) {
+			t.Errorf("expected untouched XML %q in:\n%s", unchanged, out)
+		}
+	}
+	for _, removed := range []string{"Teh Title", "Jane Deo", "Old", "2020-01-01"} {
+		if strings.Contains(out, removed) {
+			t.Errorf("expected %q to be removed:\n%s", removed, out)
+		}
+	}
+
+	wantLines := []string{
+		"    <dc:title id=\"t1\">The Title</dc:title>\n    <meta refines=\"#t1\" property=\"title-type\">main</meta>\n    <dc:creator id=\"c1\">Jane Doe</dc:creator>\n",
+		`    <meta refines="#c1" property="role" scheme="marc:relators">aut</meta>`,
+		`    <meta refines="#c1" property="file-as">Doe, Jane</meta>`,
+		`    <dc:subject>Fiction</dc:subject>` + "\n" + `    <dc:subject>Mystery</dc:subject>`,
+		`    <dc:publisher>Example &amp; Sons</dc:publisher>`,
+		`    <meta property="belongs-to-collection" id="series">The Cases</meta>`,
+		`    <meta refines="#series" property="group-position">2</meta>`,
+		`    <meta property="schema:accessMode">textual</meta>`,
+		`    <meta property="dcterms:modified">2024-05-06T07:08:09Z</meta>`,
+		`    <item id="old-cover" href="images/old.jpg" media-type="image/jpeg"/>`,
+		`    <item id="cover-image" href="images/cover.png" media-type="image/png" properties="cover-image"/>` + "\n  </manifest>",
+		`    <meta name="cover" content="cover-image"/>`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\n\n") {
+		t.Errorf("expected no blank lines left behind:\n%s", out)
+	}
+
+	metadata := parsePublicationMetadata(edited, "uid")
+	if metadata.MainTitle() != "The Title" || !reflect.DeepEqual(metadata.Authors(), []string{"Jane Doe"}) {
+		t.Errorf("edited metadata does not parse back: %+v", metadata)
+	}
+	if metadata.Creators[0].FileAs != "Doe, Jane" || metadata.Series[0].Position != "2" {
+		t.Errorf("unexpected refinements: %+v", metadata)
+	}
+	if metadata.Accessibility.AccessibilitySummary != "No known hazards." {
+		t.Errorf("unexpected accessibility metadata: %+v", metadata.Accessibility)
+	}
+}
+
+func TestEditPackageMetadata_TitlesAndCreators(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title id="sub">A Subtitle</dc:title>
+    <meta refines="#sub" property="title-type">subtitle</meta>
+    <meta refines="#sub" property="display-seq">2</meta>
+    <dc:title id="main">Teh Title</dc:title>
+    <meta refines="#main" property="title-type">main</meta>
+    <meta refines="#main" property="display-seq" id="seq">1</meta>
+    <meta refines="#seq" property="alternate-script" xml:lang="fr">un</meta>
+    <dc:title id="coll">The Collection</dc:title>
+    <meta refines="#coll" property="title-type">collection</meta>
+    <dc:creator id="aut1">Jane Deo</dc:creator>
+    <meta refines="#aut1" property="role" scheme="marc:relators">aut</meta>
+    <dc:creator id="ill1">Rick Roe</dc:creator>
+    <meta refines="#ill1" property="role" scheme="marc:relators">ill</meta>
+    <dc:identifier id="uid">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>
+    <dc:language>en</dc:language>
+  </metadata>
+  <manifest/>
+  <spine/>
+</package>`
+
+	edited, err := editPackageMetadata([]byte(opf), MetadataChanges{
+		Title:    stringPtr("The Title"),
+		Creators: []Creator{{Name: "Jane Doe", Roles: []string{"aut"}}, {Name: "Rick Roe", Roles: []string{"ill"}}},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("editPackageMetadata failed: %v", err)
+	}
+	out := string(edited)
+
+	for _, want := range []string{
+		`<dc:title id="sub">A Subtitle</dc:title>`,
+		`<meta refines="#sub" property="title-type">subtitle</meta>`,
+		`<dc:title id="main">The Title</dc:title>`,
+		`<meta refines="#main" property="display-seq" id="seq">1</meta>`,
+		`<meta refines="#seq" property="alternate-script" xml:lang="fr">un</meta>`,
+		`<dc:title id="coll">The Collection</dc:title>`,
+		`<dc:creator id="aut1">Jane Doe</dc:creator>`,
+		`<meta refines="#aut1" property="role" scheme="marc:relators">aut</meta>`,
+		`<dc:creator id="ill1">Rick Roe</dc:creator>`,
+		`<meta refines="#ill1" property="role" scheme="marc:relators">ill</meta>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Teh Title") || strings.Contains(out, "Jane Deo") {
+		t.Errorf("expected the old title and creator to be replaced:\n%s", out)
+	}
+
+	metadata := parsePublicationMetadata(edited, "uid")
+	if metadata.MainTitle() != "The Title" || len(metadata.Titles) != 3 {
+		t.Errorf("unexpected titles: %+v", metadata.Titles)
+	}
+	if !reflect.DeepEqual(metadata.Authors(), []string{"Jane Doe"}) || len(metadata.Creators) != 2 || metadata.Creators[1].Roles[0] != "ill" {
+		t.Errorf("unexpected creators: %+v", metadata.Creators)
+	}
+
+	// Without a refined main title the first title is replaced.
+	edited, err = editPackageMetadata([]byte(strings.Replace(opf, `<meta refines="#main" property="title-type">main</meta>`, "", 1)), MetadataChanges{Title: stringPtr("New")}, time.Now())
+	if err != nil {
+		t.Fatalf("editPackageMetadata failed: %v", err)
+	}
+	if !strings.Contains(string(edited), `<dc:title id="sub">New</dc:title>`) || !strings.Contains(string(edited), "Teh Title") {
+		t.Errorf("expected the first title to be replaced:\n%s", edited)
+	}
+}
+
+func TestEditPackageMetadata_EPUB2(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <dc:title>Title</dc:title>
+    <dc:creator opf:role="aut">Old Name</dc:creator>
+    <dc:identifier id="uid" opf:scheme="UUID">12345678-1234-1234-1234-123456789012</dc:identifier>
+    <meta name="calibre:series" content="Old Series"/>
+  </metadata>
+  <manifest>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+	edited, err := editPackageMetadata([]byte(opf), MetadataChanges{
+		Creators:    []Creator{{Name: "New Name", FileAs: "Name, New", Roles: []string{"aut"}}},
+		Identifiers: []Identifier{{Value: "9780000000002", Scheme: "ISBN"}},
+		Series:      &Series{Name: "New Series", Position: "1"},
+		Cover:       &CoverImage{Href: "cover.jpg"},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("editPackageMetadata failed: %v", err)
+	}
+	out := string(edited)
+
+	for _, want := range []string{
+		`<dc:creator opf:role="aut" opf:file-as="Name, New">New Name</dc:creator>`,
+		`<dc:identifier id="uid" opf:scheme="ISBN">9780000000002</dc:identifier>`,
+		`<meta name="calibre:series" content="New Series"/>`,
+		`<meta name="calibre:series_index" content="1"/>`,
+		`<item id="cover-image" href="cover.jpg" media-type="image/jpeg"/>`,
+		`<meta name="cover" content="cover-image"/>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Old Name", "Old Series", "dcterms:modified", "properties="} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("did not expect %q in:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestEditPackageMetadata_CoverHrefIsPath(t *testing.T) {
+	tests := []struct {
+		href string
+		want string
+	}{
+		{href: "images/my cover.jpg", want: `href="images/my%20cover.jpg"`},
+		{href: "images/a%20b.jpg", want: `href="images/a%2520b.jpg"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.href, func(t *testing.T) {
+			edited, err := editPackageMetadata([]byte(editorEPUB3OPF), MetadataChanges{Cover: &CoverImage{Href: tt.href}}, time.Now())
+			if err != nil {
+				t.Fatalf("editPackageMetadata failed: %v", err)
+			}
+			if !strings.Contains(string(edited), tt.want) {
+				t.Errorf("expected %s in:\n%s", tt.want, edited)
+			}
+		})
+	}
+}
+
+func TestEditPackageMetadata_Errors(t *testing.T) {
+	if _, err := editPackageMetadata([]byte(`<package version="3.0"><manifest/></package>`), MetadataChanges{}, time.Now()); err == nil {
+		t.Error("expected error for a package without metadata")
+	}
+	if _, err := editPackageMetadata([]byte(editorEPUB3OPF), MetadataChanges{Cover: &CoverImage{Href: "cover.txt"}}, time.Now()); err == nil {
+		t.Error("expected error for a cover that is not an image")
+	}
+}
+
+func TestWriteMetadata(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "book.epub")
+	if err := os.WriteFile(input, createCompleteValidEPUB(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "edited.epub")
+
+	err := WriteMetadata(input, output, MetadataChanges{
+		Title: stringPtr("Edited Title"),
+		Cover: &CoverImage{Href: "images/cover.png", Data: []byte("png")},
+	})
+	if err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+
+	pub, err := OpenPublication(output)
+	if err != nil {
+		t.Fatalf("OpenPublication failed: %v", err)
+	}
+	defer pub.Close()
+
+	if got := pub.Metadata.MainTitle(); got != "Edited Title" {
+		t.Errorf("MainTitle = %q", got)
+	}
+	if pub.Metadata.Cover != "OEBPS/images/cover.png" {
+		t.Errorf("Cover = %q", pub.Metadata.Cover)
+	}
+	if data, err := pub.ReadFile("OEBPS/images/cover.png"); err != nil || string(data) != "png" {
+		t.Errorf("unexpected cover data %q: %v", data, err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := zipReader.File[0]; first.Name != MimetypeFilename || first.Method != zip.Store {
+		t.Errorf("expected stored mimetype first, got %s (method %d)", first.Name, first.Method)
+	}
+
+	original, err := OpenPublication(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer original.Close()
+	if original.Metadata.MainTitle() == "Edited Title" {
+		t.Error("expected the input file to be left unchanged")
+	}
+}
+
+func TestWriteMetadata_CoverWithSpace(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "book.epub")
+	if err := os.WriteFile(input, createCompleteValidEPUB(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "edited.epub")
+
+	err := WriteMetadata(input, output, MetadataChanges{
+		Cover: &CoverImage{Href: "images/my cover.jpg", Data: []byte("jpg")},
+	})
+	if err != nil {
+		t.Fatalf("WriteMetadata failed: %v", err)
+	}
+
+	pub, err := OpenPublication(output)
+	if err != nil {
+		t.Fatalf("OpenPublication failed: %v", err)
+	}
+	defer pub.Close()
+
+	if pub.Metadata.Cover != "OEBPS/images/my cover.jpg" {
+		t.Errorf("Cover = %q", pub.Metadata.Cover)
+	}
+	if data, err := pub.ReadFile("OEBPS/images/my cover.jpg"); err != nil || string(data) != "jpg" {
+		t.Errorf("unexpected cover data %q: %v", data, err)
+	}
+	found := false
+	for _, resource := range pub.Manifest {
+		if resource.Path == "OEBPS/images/my cover.jpg" {
+			found = true
+			if resource.Href != "images/my%20cover.jpg" {
+				t.Errorf("manifest href = %q", resource.Href)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a manifest item for the cover")
+	}
+}
@@ -31,6 +31,8 @@ const (
 type NavLink struct {
 	Href string
 	Text string
+
+	node *html.Node
 }
 
 // NavValidationResult contains navigation validation details.
@@ -41,6 +43,7 @@ type NavValidationResult struct {
 	HasLandmarks  bool
 	TOCLinks      []NavLink
 	LandmarkLinks []NavLink
+	Suppressed    []ValidationError
 }
 
 // NavValidator validates EPUB navigation documents.
@@ -69,8 +72,19 @@ func (v *NavValidator) ValidateBytes(data []byte) (*NavValidationResult, error)
 	return v.Validate(strings.NewReader(string(data)))
 }
 
-// Validate validates a nav document from an io.Reader.
+// ValidateBytesAt validates a nav document stored at navPath in the
+// container, so that relative links are resolved against its directory.
+func (v *NavValidator) ValidateBytesAt(data []byte, navPath string) (*NavValidationResult, error) {
+	return v.validate(strings.NewReader(string(data)), path.Dir(navPath))
+}
+
+// Validate validates a nav document from an io.Reader. Links are resolved
+// as if the document were at the container root.
 func (v *NavValidator) Validate(reader io.Reader) (*NavValidationResult, error) {
+	return v.validate(reader, "")
+}
+
+func (v *NavValidator) validate(reader io.Reader, navDir string) (*NavValidationResult, error) {
 	result := &NavValidationResult{
 		Valid:         true,
 		Errors:        make([]ValidationError, 0),
@@ -91,6 +105,13 @@ func (v *NavValidator) Validate(reader io.Reader) (*NavValidationResult, error)
 		return result, nil //nolint:nilerr
 	}
 
+	v.validateDocument(doc, navDir, result)
+	v.applySuppressions(doc, result)
+
+	return result, nil
+}
+
+func (v *NavValidator) validateDocument(doc *html.Node, navDir string, result *NavValidationResult) {
 	navElements := v.findNavElements(doc)
 
 	if len(navElements) == 0 {
@@ -100,7 +121,7 @@ func (v *NavValidator) Validate(reader io.Reader) (*NavValidationResult, error)
 			Message: "Navigation document must contain at least one <nav> element",
 			Details: map[string]interface{}{},
 		})
-		return result, nil
+		return
 	}
 
 	tocFound := false
@@ -112,10 +133,10 @@ func (v *NavValidator) Validate(reader io.Reader) (*NavValidationResult, error)
 		case NavTypeTOC:
 			tocFound = true
 			result.HasTOC = true
-			v.validateTOCNav(navNode, result)
+			v.validateTOCNav(navNode, navDir, result)
 		case NavTypeLandmarks:
 			result.HasLandmarks = true
-			v.validateLandmarksNav(navNode, result)
+			v.validateLandmarksNav(navNode, navDir, result)
 		}
 	}
 
@@ -127,8 +148,18 @@ func (v *NavValidator) Validate(reader io.Reader) (*NavValidationResult, error)
 			Details: map[string]interface{}{},
 		})
 	}
+}
 
-	return result, nil
+// applySuppressions moves findings covered by ebm-ignore comments into
+// result.Suppressed.
+func (v *NavValidator) applySuppressions(doc *html.Node, result *NavValidationResult) {
+	var suppressed []ValidationError
+	result.Errors, suppressed = collectHTMLSuppressions(doc).partition(result.Errors, "error")
+	if len(suppressed) == 0 {
+		return
+	}
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Valid = len(result.Errors) == 0
 }
 
 func (v *NavValidator) findNavElements(n *html.Node) []*html.Node {
@@ -157,7 +188,7 @@ func (v *NavValidator) getEpubType(n *html.Node) string {
 	return ""
 }
 
-func (v *NavValidator) validateTOCNav(navNode *html.Node, result *NavValidationResult) {
+func (v *NavValidator) validateTOCNav(navNode *html.Node, navDir string, result *NavValidationResult) {
 	olNode := v.findFirstChild(navNode, "ol")
 
 	if olNode == nil {
@@ -166,6 +197,7 @@ func (v *NavValidator) validateTOCNav(navNode *html.Node, result *NavValidationR
 			Code:    ErrorCodeNavInvalidTOCStructure,
 			Message: "TOC <nav> element must contain an <ol> element",
 			Details: map[string]interface{}{},
+			node:    navNode,
 		})
 		return
 	}
@@ -174,7 +206,7 @@ func (v *NavValidator) validateTOCNav(navNode *html.Node, result *NavValidationR
 	result.TOCLinks = links
 
 	for _, link := range links {
-		if !v.isValidRelativeLink(link.Href) {
+		if !v.isValidRelativeLink(navDir, link.Href) {
 			result.Valid = false
 			result.Errors = append(result.Errors, ValidationError{
 				Code:    ErrorCodeNavInvalidLinks,
@@ -183,12 +215,13 @@ func (v *NavValidator) validateTOCNav(navNode *html.Node, result *NavValidationR
 					"href": link.Href,
 					"text": link.Text,
 				},
+				node: link.node,
 			})
 		}
 	}
 }
 
-func (v *NavValidator) validateLandmarksNav(navNode *html.Node, result *NavValidationResult) {
+func (v *NavValidator) validateLandmarksNav(navNode *html.Node, navDir string, result *NavValidationResult) {
 	olNode := v.findFirstChild(navNode, "ol")
 
 	if olNode == nil {
@@ -197,6 +230,7 @@ func (v *NavValidator) validateLandmarksNav(navNode *html.Node, result *NavValid
 			Code:    ErrorCodeNavInvalidLandmarks,
 			Message: "Landmarks <nav> element must contain an <ol> element",
 			Details: map[string]interface{}{},
+			node:    navNode,
 		})
 		return
 	}
@@ -205,7 +239,7 @@ func (v *NavValidator) validateLandmarksNav(navNode *html.Node, result *NavValid
 	result.LandmarkLinks = links
 
 	for _, link := range links {
-		if !v.isValidRelativeLink(link.Href) {
+		if !v.isValidRelativeLink(navDir, link.Href) {
 			result.Valid = false
 			result.Errors = append(result.Errors, ValidationError{
 				Code:    ErrorCodeNavInvalidLinks,
@@ -214,6 +248,7 @@ func (v *NavValidator) validateLandmarksNav(navNode *html.Node, result *NavValid
 					"href": link.Href,
 					"text": link.Text,
 				},
+				node: link.node,
 			})
 		}
 	}
@@ -252,6 +287,7 @@ func (v *NavValidator) extractLinks(n *html.Node) []NavLink {
 			links = append(links, NavLink{
 				Href: href,
 				Text: strings.TrimSpace(text),
+				node: node,
 			})
 		}
 
@@ -281,23 +317,17 @@ func (v *NavValidator) extractText(n *html.Node) string {
 	return text
 }
 
-func (v *NavValidator) isValidRelativeLink(href string) bool {
+// isValidRelativeLink reports whether href, found in a nav document in
+// navDir, is a relative link that stays inside the container.
+func (v *NavValidator) isValidRelativeLink(navDir, href string) bool {
 	if href == "" {
 		return false
 	}
 
-	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
-		return false
-	}
-
-	if strings.HasPrefix(href, "//") {
-		return false
-	}
-
-	if strings.HasPrefix(href, "/") {
+	if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
 		return false
 	}
 
-	cleaned := path.Clean(href)
-	return !strings.HasPrefix(cleaned, "..")
+	target := resolveHref(navDir, href)
+	return !target.External && !target.Escapes
 }
@@ -688,10 +688,44 @@ func TestNavValidator_LinkValidation_EdgeCases(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := validator.isValidRelativeLink(tt.href)
+			result := validator.isValidRelativeLink("", tt.href)
 			if result != tt.expectValid {
 				t.Errorf("For href '%s': expected valid=%v, got valid=%v", tt.href, tt.expectValid, result)
 			}
 		})
 	}
 }
+
+func TestNavValidator_ValidateBytesAt(t *testing.T) {
+	validator := NewNavValidator()
+	nav := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Navigation</title></head>
+<body>
+<nav epub:type="toc"><ol><li><a href="../text/chapter1.xhtml">Chapter 1</a></li></ol></nav>
+</body>
+</html>`)
+
+	tests := []struct {
+		name        string
+		navPath     string
+		expectValid bool
+	}{
+		{"Nav in subdirectory", "OEBPS/nav/nav.xhtml", true},
+		{"Nav one level deep", "OEBPS/nav.xhtml", true},
+		{"Nav at container root", "nav.xhtml", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytesAt(nav, tt.navPath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Valid != tt.expectValid {
+				t.Errorf("For nav at '%s': expected valid=%v, got errors %v", tt.navPath, tt.expectValid, result.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// ErrorCodeOPFHrefCaseMismatch is reported when a reference matches a
+	// ZIP entry only when case is ignored. Case-insensitive file systems
+	// resolve it; conforming reading systems do not.
+	ErrorCodeOPFHrefCaseMismatch = "EPUB-OPF-018"
+	// ErrorCodeContentReferenceNotFound is reported when a link, image,
+	// stylesheet or CSS url() in a content document or stylesheet refers to
+	// a file that is not in the container.
+	ErrorCodeContentReferenceNotFound = "EPUB-CONTENT-011"
+)
+
+// hrefTarget is an href resolved to the archive path it refers to.
+type hrefTarget struct {
+	// Path is the percent-decoded, NFC-normalized archive path, or "" for
+	// external URLs and same-document references.
+	Path string
+	// Fragment is the percent-decoded fragment identifier, without '#'.
+	Fragment string
+	// External is set for URLs with a scheme or a network host.
+	External bool
+	// Escapes is set when the path climbs above the container root.
+	Escapes bool
+}
+
+// resolveHref resolves href against baseDir, the archive directory of the
+// document that contains it, as OCF defines for relative URLs: the query and
+// fragment are dropped from the path, percent-escapes are decoded, and the
+// result is NFC-normalized so it can be compared with entry names. A
+// path-absolute href is resolved against the container root.
+func resolveHref(baseDir, href string) hrefTarget {
+	var target hrefTarget
+
+	ref := strings.TrimSpace(href)
+	if idx := strings.Index(ref, "#"); idx != -1 {
+		target.Fragment = unescapeHref(ref[idx+1:])
+		ref = ref[:idx]
+	}
+	if idx := strings.Index(ref, "?"); idx != -1 {
+		ref = ref[:idx]
+	}
+	if ref == "" {
+		return target
+	}
+	if hasURLScheme(ref) || strings.HasPrefix(ref, "//") {
+		target.External = true
+		return target
+	}
+
+	decoded := unescapeHref(ref)
+	var resolved string
+	switch {
+	case strings.HasPrefix(decoded, "/"):
+		resolved = path.Clean(decoded)[1:]
+	case baseDir == "" || baseDir == "." || baseDir == "/":
+		resolved = path.Clean(decoded)
+	default:
+		resolved = path.Join(baseDir, decoded)
+	}
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		target.Escapes = true
+	}
+	target.Path = norm.NFC.String(resolved)
+	return target
+}
+
+// unescapeHref percent-decodes s, leaving it unchanged when an escape is
+// malformed.
+func unescapeHref(s string) string {
+	decoded, err := url.PathUnescape(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// hasURLScheme reports whether ref starts with an RFC 3986 scheme.
+func hasURLScheme(ref string) bool {
+	for i, r := range ref {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.'):
+		case i > 0 && r == ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// archiveIndex finds ZIP entries by archive path. Names are compared in NFC,
+// so an href written precomposed finds an entry stored decomposed, as macOS
+// tools write them. The first of several same-named entries wins.
+type archiveIndex struct {
+	byName map[string]*zip.File
+	folded map[string]*zip.File
+}
+
+func newArchiveIndex(files []*zip.File) *archiveIndex {
+	index := &archiveIndex{
+		byName: make(map[string]*zip.File, len(files)),
+		folded: make(map[string]*zip.File, len(files)),
+	}
+	for _, f := range files {
+		name := norm.NFC.String(f.Name)
+		if _, seen := index.byName[name]; !seen {
+			index.byName[name] = f
+		}
+		if _, seen := index.folded[strings.ToLower(name)]; !seen {
+			index.folded[strings.ToLower(name)] = f
+		}
+	}
+	return index
+}
+
+// file returns the entry stored at name.
+func (ix *archiveIndex) file(name string) (*zip.File, bool) {
+	f, ok := ix.byName[norm.NFC.String(strings.TrimPrefix(name, "/"))]
+	return f, ok
+}
+
+// caseVariant returns the entry whose name differs from name only by case,
+// for a name that has no exact match.
+func (ix *archiveIndex) caseVariant(name string) (*zip.File, bool) {
+	name = norm.NFC.String(strings.TrimPrefix(name, "/"))
+	if _, exact := ix.byName[name]; exact {
+		return nil, false
+	}
+	f, ok := ix.folded[strings.ToLower(name)]
+	return f, ok
+}
+
+// hrefForPath returns the relative URL that refers to the archive path p,
+// percent-encoding characters that would otherwise be read as URL syntax.
+func hrefForPath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// documentReference is a URL that a content document or stylesheet refers
+// to, with the construct it was found in.
+type documentReference struct {
+	Href string
+	// Source is "a", "img" or "link" for attributes and "url()" for CSS.
+	Source string
+}
+
+// referenceAttributes lists the attribute read from each referencing element.
+var referenceAttributes = map[string]string{
+	"a":    "href",
+	"img":  "src",
+	"link": "href",
+}
+
+// cssURLPattern matches CSS url() tokens, quoted or not.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)`)
+
+// htmlReferences returns the references in an XHTML content document:
+// a@href, img@src and link@href, and url() in style elements and
+// attributes.
+func htmlReferences(data []byte) []documentReference {
+	var refs []documentReference
+	inStyle := false
+
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return refs
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			inStyle = token.Data == "style" && token.Type == html.StartTagToken
+			for _, attr := range token.Attr {
+				switch {
+				case attr.Key == referenceAttributes[token.Data]:
+					refs = append(refs, documentReference{Href: attr.Val, Source: token.Data})
+				case attr.Key == "style":
+					refs = append(refs, cssReferences(attr.Val)...)
+				}
+			}
+		case html.TextToken:
+			if inStyle {
+				refs = append(refs, cssReferences(string(z.Text()))...)
+			}
+		case html.EndTagToken:
+			inStyle = false
+		}
+	}
+}
+
+// cssReferences returns the url() references in CSS text.
+func cssReferences(css string) []documentReference {
+	var refs []documentReference
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		refs = append(refs, documentReference{Href: match[1] + match[2] + match[3], Source: "url()"})
+	}
+	return refs
+}
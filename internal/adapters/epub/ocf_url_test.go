@@ -0,0 +1,367 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+func TestResolveHref(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseDir string
+		href    string
+		want    hrefTarget
+	}{
+		{"relative", "OEBPS", "text/ch1.xhtml", hrefTarget{Path: "OEBPS/text/ch1.xhtml"}},
+		{"root package", ".", "ch1.xhtml", hrefTarget{Path: "ch1.xhtml"}},
+		{"percent-encoded space", "OEBPS", "chapter%201.xhtml", hrefTarget{Path: "OEBPS/chapter 1.xhtml"}},
+		{"encoded non-ASCII", "OEBPS", "caf%C3%A9.xhtml", hrefTarget{Path: "OEBPS/caf\u00e9.xhtml"}},
+		{"decomposed to NFC", "OEBPS", "cafe\u0301.xhtml", hrefTarget{Path: "OEBPS/caf\u00e9.xhtml"}},
+		{"fragment", "OEBPS", "ch1.xhtml#sec%201", hrefTarget{Path: "OEBPS/ch1.xhtml", Fragment: "sec 1"}},
+		{"query", "OEBPS", "ch1.xhtml?v=2#top", hrefTarget{Path: "OEBPS/ch1.xhtml", Fragment: "top"}},
+		{"fragment only", "OEBPS", "#top", hrefTarget{Fragment: "top"}},
+		{"parent directory", "OEBPS/text", "../images/a.png", hrefTarget{Path: "OEBPS/images/a.png"}},
+		{"path-absolute", "OEBPS", "/images/a.png", hrefTarget{Path: "images/a.png"}},
+		{"escapes container", "OEBPS", "../../a.png", hrefTarget{Path: "../a.png", Escapes: true}},
+		{"encoded dot segments", ".", "%2E%2E/a.png", hrefTarget{Path: "../a.png", Escapes: true}},
+		{"malformed escape", "OEBPS", "100%.xhtml", hrefTarget{Path: "OEBPS/100%.xhtml"}},
+		{"http", "OEBPS", "https://example.com/a.png", hrefTarget{External: true}},
+		{"mailto", "OEBPS", "mailto:someone@example.com", hrefTarget{External: true}},
+		{"protocol-relative", "OEBPS", "//example.com/a.png", hrefTarget{External: true}},
+		{"colon in path segment", "OEBPS", "text/a:b.xhtml", hrefTarget{Path: "OEBPS/text/a:b.xhtml"}},
+		{"empty", "OEBPS", "  ", hrefTarget{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveHref(tt.baseDir, tt.href); got != tt.want {
+				t.Errorf("resolveHref(%q, %q) = %+v, want %+v", tt.baseDir, tt.href, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHrefForPath(t *testing.T) {
+	for p, want := range map[string]string{
+		"chapter1.xhtml":       "chapter1.xhtml",
+		"text/chapter 1.xhtml": "text/chapter%201.xhtml",
+		"100%#1.xhtml":         "100%25%231.xhtml",
+	} {
+		got := hrefForPath(p)
+		if got != want {
+			t.Errorf("hrefForPath(%q) = %q, want %q", p, got, want)
+		}
+		if back := resolveHref("", got).Path; back != p {
+			t.Errorf("resolveHref(%q) = %q, want %q", got, back, p)
+		}
+	}
+}
+
+func TestArchiveIndex(t *testing.T) {
+	index := newArchiveIndex([]*zip.File{
+		{FileHeader: zip.FileHeader{Name: "OEBPS/cafe\u0301.xhtml"}},
+		{FileHeader: zip.FileHeader{Name: "OEBPS/Chapter1.xhtml"}},
+	})
+
+	if f, ok := index.file("OEBPS/caf\u00e9.xhtml"); !ok || f.Name != "OEBPS/cafe\u0301.xhtml" {
+		t.Errorf("expected NFC lookup to find the decomposed entry, got %v", f)
+	}
+	if _, ok := index.file("/OEBPS/Chapter1.xhtml"); !ok {
+		t.Error("expected a leading slash to be ignored")
+	}
+	if _, ok := index.file("OEBPS/chapter1.xhtml"); ok {
+		t.Error("expected lookups to be case-sensitive")
+	}
+	if f, ok := index.caseVariant("OEBPS/chapter1.xhtml"); !ok || f.Name != "OEBPS/Chapter1.xhtml" {
+		t.Errorf("expected case variant OEBPS/Chapter1.xhtml, got %v", f)
+	}
+	if _, ok := index.caseVariant("OEBPS/Chapter1.xhtml"); ok {
+		t.Error("expected no case variant for an exact match")
+	}
+}
+
+func buildHrefTestEPUB(t *testing.T, manifestHref, navHref string, files []testFile) []byte {
+	t.Helper()
+
+	opf := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Href Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="%s" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`, manifestHref)
+
+	nav := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Contents</h1>
+    <ol><li><a href="%s">Chapter 1</a></li></ol>
+  </nav>
+</body>
+</html>`, navHref)
+
+	return buildEPUBWithOPFAndFiles(t, opf, nav, files)
+}
+
+func hrefTestChapter() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter 1</title></head>
+<body><h1 id="start">Chapter 1</h1><p>Text.</p></body>
+</html>`
+}
+
+func reportCodes(report *domain.ValidationReport) map[string]int {
+	codes := make(map[string]int)
+	for _, e := range report.Errors {
+		codes[e.Code]++
+	}
+	return codes
+}
+
+func TestEPUBValidator_HrefResolution(t *testing.T) {
+	tests := []struct {
+		name         string
+		manifestHref string
+		navHref      string
+		entry        string
+		expected     map[string]int
+	}{
+		{
+			name:         "percent-encoded space",
+			manifestHref: "chapter%201.xhtml",
+			navHref:      "chapter%201.xhtml#start",
+			entry:        "OEBPS/chapter 1.xhtml",
+			expected:     map[string]int{},
+		},
+		{
+			name:         "NFC href for NFD entry",
+			manifestHref: "caf\u00e9.xhtml",
+			navHref:      "caf%C3%A9.xhtml",
+			entry:        "OEBPS/cafe\u0301.xhtml",
+			expected:     map[string]int{},
+		},
+		{
+			name:         "query string",
+			manifestHref: "chapter1.xhtml?v=1",
+			navHref:      "chapter1.xhtml",
+			entry:        "OEBPS/chapter1.xhtml",
+			expected:     map[string]int{},
+		},
+		{
+			name:         "case mismatch",
+			manifestHref: "chapter1.xhtml",
+			navHref:      "chapter1.xhtml",
+			entry:        "OEBPS/Chapter1.xhtml",
+			expected:     map[string]int{ErrorCodeOPFHrefCaseMismatch: 2},
+		},
+		{
+			name:         "missing nav target",
+			manifestHref: "chapter1.xhtml",
+			navHref:      "chapter2.xhtml",
+			entry:        "OEBPS/chapter1.xhtml",
+			expected:     map[string]int{ErrorCodeNavInvalidLinks: 1},
+		},
+		{
+			name:         "literal percent in entry name",
+			manifestHref: "chapter%201.xhtml",
+			navHref:      "chapter%201.xhtml",
+			entry:        "OEBPS/chapter%201.xhtml",
+			expected:     map[string]int{ErrorCodeOPFFileNotFound: 1, ErrorCodeNavInvalidLinks: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildHrefTestEPUB(t, tt.manifestHref, tt.navHref, []testFile{
+				{path: tt.entry, content: hrefTestChapter()},
+			})
+
+			report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			codes := reportCodes(report)
+			for code, count := range tt.expected {
+				if codes[code] != count {
+					t.Errorf("expected %d %s, got %d: %v", count, code, codes[code], report.Errors)
+				}
+			}
+			if sum(tt.expected) != len(report.Errors) {
+				t.Errorf("unexpected errors: %v", report.Errors)
+			}
+		})
+	}
+}
+
+func sum(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+func TestHTMLReferences(t *testing.T) {
+	doc := []byte(`<html><head>
+<link rel="stylesheet" href="css/style%201.css"/>
+<style>body { background: url("../images/bg.png"); }</style>
+</head><body>
+<p style="background-image: url(images/dot.png)">Text <a href="chapter2.xhtml#top">next</a></p>
+<img src="images/caf%C3%A9.jpg" alt=""/>
+<a name="anchor">no href</a>
+</body></html>`)
+
+	want := []documentReference{
+		{Href: "css/style%201.css", Source: "link"},
+		{Href: "../images/bg.png", Source: "url()"},
+		{Href: "images/dot.png", Source: "url()"},
+		{Href: "chapter2.xhtml#top", Source: "a"},
+		{Href: "images/caf%C3%A9.jpg", Source: "img"},
+	}
+	got := htmlReferences(doc)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("htmlReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestCSSReferences(t *testing.T) {
+	css := `@font-face { src: url('../fonts/a.woff') format("woff"); }
+body { background: url( "bg%20image.png" ) no-repeat; }
+p { background: url(dot.png); }`
+
+	want := []documentReference{
+		{Href: "../fonts/a.woff", Source: "url()"},
+		{Href: "bg%20image.png", Source: "url()"},
+		{Href: "dot.png", Source: "url()"},
+	}
+	got := cssReferences(css)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("cssReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestEPUBValidator_ContentReferences(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Reference Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="styles/book.css" media-type="text/css"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Contents</h1>
+    <ol><li><a href="text/chapter%201.xhtml">Chapter 1</a></li></ol>
+  </nav>
+</body>
+</html>`
+
+	chapter := func(body string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter 1</title><link rel="stylesheet" type="text/css" href="../styles/book.css"/></head>
+<body><h1 id="start">Chapter 1</h1>` + body + `</body>
+</html>`
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		css      string
+		expected map[string]int
+	}{
+		{
+			name: "resolvable references",
+			body: `<p><a href="chapter%201.xhtml#start">Top</a> <a href="../nav.xhtml">Contents</a> <a href="https://example.com/">Site</a></p>
+<img src="../images/caf%C3%A9.png" alt="Cafe"/>`,
+			css:      `body { background: url("../images/caf%C3%A9.png"); }`,
+			expected: map[string]int{},
+		},
+		{
+			name:     "missing image",
+			body:     `<img src="../images/missing.png" alt="Missing"/>`,
+			expected: map[string]int{ErrorCodeContentReferenceNotFound: 1},
+		},
+		{
+			name:     "missing link target",
+			body:     `<p><a href="chapter2.xhtml#start">Next</a></p>`,
+			expected: map[string]int{ErrorCodeContentReferenceNotFound: 1},
+		},
+		{
+			name:     "case mismatch in content document",
+			body:     `<img src="../Images/caf%C3%A9.png" alt="Cafe"/>`,
+			expected: map[string]int{ErrorCodeOPFHrefCaseMismatch: 1},
+		},
+		{
+			name:     "missing CSS url resolved against the stylesheet",
+			css:      `body { background: url(images/cafe.png); }`,
+			expected: map[string]int{ErrorCodeContentReferenceNotFound: 1},
+		},
+		{
+			name:     "inline style url",
+			body:     `<p style="background: url('../images/none.png')">Text</p>`,
+			expected: map[string]int{ErrorCodeContentReferenceNotFound: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+				{path: "OEBPS/text/chapter 1.xhtml", content: chapter(tt.body)},
+				{path: "OEBPS/styles/book.css", content: tt.css},
+				{path: "OEBPS/images/café.png", content: "png"},
+			})
+
+			report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			codes := reportCodes(report)
+			for code, count := range tt.expected {
+				if codes[code] != count {
+					t.Errorf("expected %d %s, got %d: %v", count, code, codes[code], report.Errors)
+				}
+			}
+			if sum(tt.expected) != len(report.Errors) {
+				t.Errorf("unexpected errors: %v", report.Errors)
+			}
+		})
+	}
+}
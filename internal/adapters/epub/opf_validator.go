@@ -95,22 +95,25 @@ type ManifestItem struct {
 
 // Spine describes the reading order.
 type Spine struct {
-	XMLName xml.Name    `xml:"spine"`
-	Toc     string      `xml:"toc,attr,omitempty"`
-	Items   []SpineItem `xml:"itemref"`
+	XMLName                  xml.Name    `xml:"spine"`
+	Toc                      string      `xml:"toc,attr,omitempty"`
+	PageProgressionDirection string      `xml:"page-progression-direction,attr,omitempty"`
+	Items                    []SpineItem `xml:"itemref"`
 }
 
 // SpineItem references a manifest item in the spine.
 type SpineItem struct {
-	XMLName xml.Name `xml:"itemref"`
-	IDRef   string   `xml:"idref,attr"`
+	XMLName    xml.Name `xml:"itemref"`
+	IDRef      string   `xml:"idref,attr"`
+	Properties string   `xml:"properties,attr,omitempty"`
 }
 
 // OPFValidationResult aggregates OPF validation findings.
 type OPFValidationResult struct {
-	Valid   bool
-	Errors  []ValidationError
-	Package *Package
+	Valid      bool
+	Errors     []ValidationError
+	Package    *Package
+	Suppressed []ValidationError
 }
 
 // OPFValidator validates OPF package documents.
@@ -216,6 +219,13 @@ func (v *OPFValidator) ValidateBytes(data []byte) (*OPFValidationResult, error)
 	v.validateManifest(&pkg.Manifest, result)
 	v.validateSpine(&pkg.Spine, &pkg.Manifest, result)
 
+	var suppressed []ValidationError
+	result.Errors, suppressed = collectXMLSuppressions(data).partition(result.Errors, "error")
+	if len(suppressed) > 0 {
+		result.Suppressed = suppressed
+		result.Valid = len(result.Errors) == 0
+	}
+
 	return result, nil
 }
 
@@ -226,6 +236,7 @@ func (v *OPFValidator) validatePackage(pkg *Package, result *OPFValidationResult
 			Code:    ErrorCodeOPFInvalidPackage,
 			Message: "Package element must have a version attribute",
 			Details: map[string]interface{}{},
+			element: opfElementPath("package", "", -1),
 		})
 	}
 
@@ -235,6 +246,7 @@ func (v *OPFValidator) validatePackage(pkg *Package, result *OPFValidationResult
 			Code:    ErrorCodeOPFInvalidPackage,
 			Message: "Package element must have a unique-identifier attribute",
 			Details: map[string]interface{}{},
+			element: opfElementPath("package", "", -1),
 		})
 	}
 }
@@ -253,6 +265,7 @@ func (v *OPFValidator) validateMetadata(metadata *Metadata, pkg *Package, result
 			Code:    ErrorCodeOPFMissingTitle,
 			Message: "OPF metadata must contain at least one dc:title element",
 			Details: map[string]interface{}{},
+			element: opfElementPath("metadata", "", -1),
 		})
 	}
 
@@ -272,6 +285,7 @@ func (v *OPFValidator) validateMetadata(metadata *Metadata, pkg *Package, result
 			Code:    ErrorCodeOPFMissingIdentifier,
 			Message: "OPF metadata must contain at least one dc:identifier element",
 			Details: map[string]interface{}{},
+			element: opfElementPath("metadata", "", -1),
 		})
 	}
 
@@ -283,6 +297,7 @@ func (v *OPFValidator) validateMetadata(metadata *Metadata, pkg *Package, result
 			Details: map[string]interface{}{
 				"unique_identifier": pkg.UniqueID,
 			},
+			element: opfElementPath("metadata", "", -1),
 		})
 	}
 
@@ -299,6 +314,7 @@ func (v *OPFValidator) validateMetadata(metadata *Metadata, pkg *Package, result
 			Code:    ErrorCodeOPFMissingLanguage,
 			Message: "OPF metadata must contain at least one dc:language element",
 			Details: map[string]interface{}{},
+			element: opfElementPath("metadata", "", -1),
 		})
 	}
 
@@ -317,6 +333,7 @@ func (v *OPFValidator) validateMetadata(metadata *Metadata, pkg *Package, result
 			Details: map[string]interface{}{
 				"property": DCTermsProperty,
 			},
+			element: opfElementPath("metadata", "", -1),
 		})
 	}
 }
@@ -328,6 +345,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 			Code:    ErrorCodeOPFMissingManifest,
 			Message: "OPF manifest must contain at least one item",
 			Details: map[string]interface{}{},
+			element: opfElementPath("manifest", "", -1),
 		})
 		return
 	}
@@ -344,6 +362,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 				Details: map[string]interface{}{
 					"item_index": i,
 				},
+				element: opfElementPath("manifest", "item", i),
 			})
 		} else {
 			if seenIDs[item.ID] {
@@ -355,6 +374,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 						"id":         item.ID,
 						"item_index": i,
 					},
+					element: opfElementPath("manifest", "item", i),
 				})
 			}
 			seenIDs[item.ID] = true
@@ -369,6 +389,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 					"item_index": i,
 					"id":         item.ID,
 				},
+				element: opfElementPath("manifest", "item", i),
 			})
 		}
 
@@ -381,6 +402,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 					"item_index": i,
 					"id":         item.ID,
 				},
+				element: opfElementPath("manifest", "item", i),
 			})
 		}
 
@@ -395,6 +417,7 @@ func (v *OPFValidator) validateManifest(manifest *Manifest, result *OPFValidatio
 			Code:    ErrorCodeOPFMissingNavDocument,
 			Message: "OPF manifest must contain at least one item with properties='nav'",
 			Details: map[string]interface{}{},
+			element: opfElementPath("manifest", "", -1),
 		})
 	}
 }
@@ -406,6 +429,7 @@ func (v *OPFValidator) validateSpine(spine *Spine, manifest *Manifest, result *O
 			Code:    ErrorCodeOPFMissingSpine,
 			Message: "OPF spine must contain at least one itemref",
 			Details: map[string]interface{}{},
+			element: opfElementPath("spine", "", -1),
 		})
 		return
 	}
@@ -424,6 +448,7 @@ func (v *OPFValidator) validateSpine(spine *Spine, manifest *Manifest, result *O
 				Details: map[string]interface{}{
 					"item_index": i,
 				},
+				element: opfElementPath("spine", "itemref", i),
 			})
 		} else if !manifestIDs[item.IDRef] {
 			result.Valid = false
@@ -434,6 +459,7 @@ func (v *OPFValidator) validateSpine(spine *Spine, manifest *Manifest, result *O
 					"item_index": i,
 					"idref":      item.IDRef,
 				},
+				element: opfElementPath("spine", "itemref", i),
 			})
 		}
 	}
@@ -461,6 +487,7 @@ func (v *OPFValidator) validateNCXReference(spine *Spine, manifest *Manifest, re
 			Details: map[string]interface{}{
 				"media_type": "application/x-dtbncx+xml",
 			},
+			element: opfElementPath("manifest", "", -1),
 		})
 		return
 	}
@@ -476,6 +503,7 @@ func (v *OPFValidator) validateNCXReference(spine *Spine, manifest *Manifest, re
 				"ncx_id":  ncxIDs[0],
 				"ncx_ids": ncxIDs,
 			},
+			element: opfElementPath("spine", "", -1),
 		})
 	}
 }
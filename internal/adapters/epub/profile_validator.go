@@ -0,0 +1,452 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for cover dimensions
+	_ "image/jpeg" // register JPEG decoder for cover dimensions
+	_ "image/png"  // register PNG decoder for cover dimensions
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// Retailer profile error codes.
+const (
+	ErrorCodeProfileFileTooLarge     = "EPUB-PROFILE-001"
+	ErrorCodeProfileResourceTooLarge = "EPUB-PROFILE-002"
+	ErrorCodeProfileCoverMissing     = "EPUB-PROFILE-003"
+	ErrorCodeProfileCoverTooSmall    = "EPUB-PROFILE-004"
+	ErrorCodeProfileMissingMetadata  = "EPUB-PROFILE-005"
+	ErrorCodeProfileMissingFile      = "EPUB-PROFILE-006"
+	ErrorCodeProfileBannedProperty   = "EPUB-PROFILE-007"
+	ErrorCodeProfileBannedMediaType  = "EPUB-PROFILE-008"
+
+	// Advisory checks, reported as warnings.
+	ErrorCodeProfileRecommendedMetadata = "EPUB-PROFILE-009"
+	ErrorCodeProfileRecommendedFile     = "EPUB-PROFILE-010"
+	ErrorCodeProfileCoverBelowIdeal     = "EPUB-PROFILE-011"
+)
+
+// ProfileRules declares the retailer-specific EPUB requirements checked on
+// top of the base validators. Zero values disable the corresponding rule.
+type ProfileRules struct {
+	// MaxFileSize is the maximum size of the EPUB container in bytes.
+	MaxFileSize int64 `yaml:"max_file_size,omitempty" json:"max_file_size,omitempty"`
+	// MaxResourceSize is the maximum uncompressed size of any manifest item in bytes.
+	MaxResourceSize int64 `yaml:"max_resource_size,omitempty" json:"max_resource_size,omitempty"`
+	// Cover configures cover image requirements.
+	Cover *CoverRule `yaml:"cover,omitempty" json:"cover,omitempty"`
+	// RequiredMetadata lists metadata that must be present, either as a
+	// Dublin Core element ("dc:creator") or a meta property or name ("ibooks:version").
+	RequiredMetadata []string `yaml:"required_metadata,omitempty" json:"required_metadata,omitempty"`
+	// RequiredFiles lists container paths that must exist.
+	RequiredFiles []string `yaml:"required_files,omitempty" json:"required_files,omitempty"`
+	// RecommendedMetadata lists metadata the retailer uses when present;
+	// missing entries are warnings.
+	RecommendedMetadata []string `yaml:"recommended_metadata,omitempty" json:"recommended_metadata,omitempty"`
+	// RecommendedFiles lists container paths the retailer uses when present;
+	// missing files are warnings.
+	RecommendedFiles []string `yaml:"recommended_files,omitempty" json:"recommended_files,omitempty"`
+	// BannedProperties lists manifest item properties the retailer rejects.
+	BannedProperties []string `yaml:"banned_properties,omitempty" json:"banned_properties,omitempty"`
+	// BannedMediaTypes lists manifest media types the retailer rejects.
+	BannedMediaTypes []string `yaml:"banned_media_types,omitempty" json:"banned_media_types,omitempty"`
+}
+
+// CoverRule describes cover image requirements. A cover smaller than the
+// minimum is an error; one smaller than the recommended size is a warning.
+type CoverRule struct {
+	Required          bool `yaml:"required,omitempty" json:"required,omitempty"`
+	MinWidth          int  `yaml:"min_width,omitempty" json:"min_width,omitempty"`
+	MinHeight         int  `yaml:"min_height,omitempty" json:"min_height,omitempty"`
+	RecommendedWidth  int  `yaml:"recommended_width,omitempty" json:"recommended_width,omitempty"`
+	RecommendedHeight int  `yaml:"recommended_height,omitempty" json:"recommended_height,omitempty"`
+}
+
+// ProfileValidationResult aggregates retailer profile findings. Findings that
+// relate to a file inside the container carry its path in Details["path"].
+// Warnings hold the advisory checks and do not affect Valid.
+type ProfileValidationResult struct {
+	Valid    bool
+	Errors   []ValidationError
+	Warnings []ValidationError
+}
+
+// ProfileValidator checks an EPUB against retailer profile rules.
+type ProfileValidator struct {
+	containerValidator *ContainerValidator
+	opfValidator       *OPFValidator
+}
+
+// NewProfileValidator returns a new profile validator.
+func NewProfileValidator() *ProfileValidator {
+	return &ProfileValidator{
+		containerValidator: NewContainerValidator(),
+		opfValidator:       NewOPFValidator(),
+	}
+}
+
+// ValidateBytes validates in-memory EPUB data against rules.
+func (v *ProfileValidator) ValidateBytes(data []byte, rules ProfileRules) (*ProfileValidationResult, error) {
+	return v.Validate(bytes.NewReader(data), int64(len(data)), rules)
+}
+
+// Validate validates EPUB data from a reader against rules. Structural
+// problems that prevent locating the package document are left to the base
+// validators and produce no profile findings.
+func (v *ProfileValidator) Validate(reader io.ReaderAt, size int64, rules ProfileRules) (*ProfileValidationResult, error) {
+	result := &ProfileValidationResult{
+		Valid:    true,
+		Errors:   make([]ValidationError, 0),
+		Warnings: make([]ValidationError, 0),
+	}
+
+	if rules.MaxFileSize > 0 && size > rules.MaxFileSize {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodeProfileFileTooLarge,
+			Message: fmt.Sprintf("EPUB is %d bytes; the profile allows at most %d bytes", size, rules.MaxFileSize),
+			Details: map[string]interface{}{
+				"size":  size,
+				"limit": rules.MaxFileSize,
+			},
+		})
+	}
+
+	containerResult, err := v.containerValidator.Validate(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("container validation failed: %w", err)
+	}
+	if len(containerResult.Rootfiles) == 0 {
+		result.Valid = len(result.Errors) == 0
+		return result, nil
+	}
+
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB as ZIP: %w", err)
+	}
+
+	files := newArchiveIndex(zipReader.File)
+
+	v.validateRequiredFiles(files, rules, result)
+
+	opfPath := containerResult.Rootfiles[0].FullPath
+	opfFile, ok := files.file(opfPath)
+	if !ok {
+		result.Valid = len(result.Errors) == 0
+		return result, nil
+	}
+	opfData, err := readZipEntryLimited(opfFile, DefaultContainerLimits().MaxEntrySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OPF file: %w", err)
+	}
+
+	opfResult, err := v.opfValidator.ValidateBytes(opfData)
+	if err != nil {
+		return nil, fmt.Errorf("OPF validation failed: %w", err)
+	}
+	if opfResult.Package == nil {
+		result.Valid = len(result.Errors) == 0
+		return result, nil
+	}
+
+	declared := scanDeclaredMetadata(opfData)
+	opfDir := path.Dir(opfPath)
+
+	v.validateRequiredMetadata(declared, opfPath, rules, result)
+	v.validateManifest(files, opfResult.Package, opfDir, rules, result)
+	v.validateCover(files, opfResult.Package, declared, opfDir, rules, result)
+
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+func (v *ProfileValidator) validateRequiredFiles(files *archiveIndex, rules ProfileRules, result *ProfileValidationResult) {
+	result.Errors = append(result.Errors, missingFiles(files, rules.RequiredFiles, ErrorCodeProfileMissingFile, "Required")...)
+	result.Warnings = append(result.Warnings, missingFiles(files, rules.RecommendedFiles, ErrorCodeProfileRecommendedFile, "Recommended")...)
+}
+
+func missingFiles(files *archiveIndex, paths []string, code, kind string) []ValidationError {
+	var missing []ValidationError
+	for _, p := range paths {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		if _, ok := files.file(p); ok {
+			continue
+		}
+		missing = append(missing, ValidationError{
+			Code:    code,
+			Message: fmt.Sprintf("%s file %s is missing", kind, p),
+			Details: map[string]interface{}{
+				"path": p,
+			},
+		})
+	}
+	return missing
+}
+
+func (v *ProfileValidator) validateRequiredMetadata(declared declaredMetadata, opfPath string, rules ProfileRules, result *ProfileValidationResult) {
+	result.Errors = append(result.Errors, missingMetadata(declared, opfPath, rules.RequiredMetadata, ErrorCodeProfileMissingMetadata, "Required")...)
+	result.Warnings = append(result.Warnings, missingMetadata(declared, opfPath, rules.RecommendedMetadata, ErrorCodeProfileRecommendedMetadata, "Recommended")...)
+}
+
+func missingMetadata(declared declaredMetadata, opfPath string, names []string, code, kind string) []ValidationError {
+	var missing []ValidationError
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || declared.names[strings.ToLower(name)] {
+			continue
+		}
+		missing = append(missing, ValidationError{
+			Code:    code,
+			Message: fmt.Sprintf("%s metadata %s is missing from the package document", kind, name),
+			Details: map[string]interface{}{
+				"path":     opfPath,
+				"metadata": name,
+			},
+		})
+	}
+	return missing
+}
+
+func (v *ProfileValidator) validateManifest(files *archiveIndex, pkg *Package, opfDir string, rules ProfileRules, result *ProfileValidationResult) {
+	for _, item := range pkg.Manifest.Items {
+		itemPath := resolveHref(opfDir, item.Href).Path
+
+		for _, banned := range rules.BannedProperties {
+			for _, property := range strings.Fields(item.Properties) {
+				if strings.EqualFold(property, banned) {
+					result.Errors = append(result.Errors, ValidationError{
+						Code:    ErrorCodeProfileBannedProperty,
+						Message: fmt.Sprintf("Manifest item %s uses property '%s', which the profile does not allow", item.ID, property),
+						Details: map[string]interface{}{
+							"path":        itemPath,
+							"manifest_id": item.ID,
+							"property":    property,
+						},
+					})
+				}
+			}
+		}
+
+		for _, banned := range rules.BannedMediaTypes {
+			if strings.EqualFold(strings.TrimSpace(item.MediaType), banned) {
+				result.Errors = append(result.Errors, ValidationError{
+					Code:    ErrorCodeProfileBannedMediaType,
+					Message: fmt.Sprintf("Manifest item %s has media type %s, which the profile does not allow", item.ID, item.MediaType),
+					Details: map[string]interface{}{
+						"path":        itemPath,
+						"manifest_id": item.ID,
+						"media_type":  item.MediaType,
+					},
+				})
+			}
+		}
+
+		if rules.MaxResourceSize <= 0 {
+			continue
+		}
+		f, ok := files.file(itemPath)
+		if !ok || int64(f.UncompressedSize64) <= rules.MaxResourceSize { //nolint:gosec
+			continue
+		}
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodeProfileResourceTooLarge,
+			Message: fmt.Sprintf("Resource %s is %d bytes; the profile allows at most %d bytes per resource", itemPath, f.UncompressedSize64, rules.MaxResourceSize),
+			Details: map[string]interface{}{
+				"path":        itemPath,
+				"manifest_id": item.ID,
+				"size":        f.UncompressedSize64,
+				"limit":       rules.MaxResourceSize,
+			},
+		})
+	}
+}
+
+func (v *ProfileValidator) validateCover(files *archiveIndex, pkg *Package, declared declaredMetadata, opfDir string, rules ProfileRules, result *ProfileValidationResult) {
+	if rules.Cover == nil {
+		return
+	}
+
+	cover := findCoverItem(pkg, declared.coverID)
+	if cover == nil {
+		if rules.Cover.Required {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodeProfileCoverMissing,
+				Message: "No cover image is declared (expected a manifest item with properties=\"cover-image\")",
+				Details: map[string]interface{}{},
+			})
+		}
+		return
+	}
+
+	if rules.Cover.MinWidth <= 0 && rules.Cover.MinHeight <= 0 &&
+		rules.Cover.RecommendedWidth <= 0 && rules.Cover.RecommendedHeight <= 0 {
+		return
+	}
+
+	coverPath := resolveHref(opfDir, cover.Href).Path
+	f, ok := files.file(coverPath)
+	if !ok {
+		return
+	}
+	data, err := readZipEntryLimited(f, DefaultContainerLimits().MaxEntrySize)
+	if err != nil {
+		return
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		// Formats without a registered decoder (e.g. SVG) cannot be measured.
+		return
+	}
+
+	if config.Width >= rules.Cover.MinWidth && config.Height >= rules.Cover.MinHeight {
+		if config.Width >= rules.Cover.RecommendedWidth && config.Height >= rules.Cover.RecommendedHeight {
+			return
+		}
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code: ErrorCodeProfileCoverBelowIdeal,
+			Message: fmt.Sprintf("Cover image is %dx%d pixels; the profile recommends at least %dx%d",
+				config.Width, config.Height, rules.Cover.RecommendedWidth, rules.Cover.RecommendedHeight),
+			Details: map[string]interface{}{
+				"path":               coverPath,
+				"width":              config.Width,
+				"height":             config.Height,
+				"recommended_width":  rules.Cover.RecommendedWidth,
+				"recommended_height": rules.Cover.RecommendedHeight,
+			},
+		})
+		return
+	}
+	result.Errors = append(result.Errors, ValidationError{
+		Code: ErrorCodeProfileCoverTooSmall,
+		Message: fmt.Sprintf("Cover image is %dx%d pixels; the profile requires at least %dx%d",
+			config.Width, config.Height, rules.Cover.MinWidth, rules.Cover.MinHeight),
+		Details: map[string]interface{}{
+			"path":       coverPath,
+			"width":      config.Width,
+			"height":     config.Height,
+			"min_width":  rules.Cover.MinWidth,
+			"min_height": rules.Cover.MinHeight,
+		},
+	})
+}
+
+// AppendTo adds the profile findings to report as errors and warnings
+// located at the file named in each finding's Details["path"].
+func (r *ProfileValidationResult) AppendTo(report *domain.ValidationReport) {
+	for _, err := range r.Errors {
+		report.Errors = append(report.Errors, profileFinding(err, domain.SeverityError))
+	}
+	for _, warning := range r.Warnings {
+		report.Warnings = append(report.Warnings, profileFinding(warning, domain.SeverityWarning))
+	}
+	report.IsValid = len(report.Errors) == 0
+}
+
+func profileFinding(err ValidationError, severity domain.Severity) domain.ValidationError {
+	var location *domain.ErrorLocation
+	if file, ok := err.Details["path"].(string); ok && file != "" {
+		location = &domain.ErrorLocation{
+			File: filepath.Base(file),
+			Path: file,
+		}
+	}
+	return domain.ValidationError{
+		Code:      err.Code,
+		Message:   err.Message,
+		Severity:  severity,
+		Location:  location,
+		Details:   err.Details,
+		Timestamp: time.Now(),
+	}
+}
+
+// declaredMetadata lists the metadata names declared in an OPF document.
+type declaredMetadata struct {
+	// names holds lowercased "dc:<element>" names plus meta property and name values.
+	names map[string]bool
+	// coverID is the manifest id referenced by an EPUB 2 <meta name="cover">.
+	coverID string
+}
+
+func scanDeclaredMetadata(opfData []byte) declaredMetadata {
+	declared := declaredMetadata{names: make(map[string]bool)}
+
+	decoder := xml.NewDecoder(bytes.NewReader(opfData))
+	inMetadata := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "metadata" {
+				inMetadata = true
+				continue
+			}
+			if !inMetadata {
+				continue
+			}
+			if t.Name.Space == DCNamespace {
+				declared.names["dc:"+strings.ToLower(t.Name.Local)] = true
+				continue
+			}
+			if t.Name.Local != "meta" {
+				continue
+			}
+			var name, content string
+			for _, attr := range t.Attr {
+				switch attr.Name.Local {
+				case "property":
+					declared.names[strings.ToLower(strings.TrimSpace(attr.Value))] = true
+				case "name":
+					name = strings.TrimSpace(attr.Value)
+					declared.names[strings.ToLower(name)] = true
+				case "content":
+					content = strings.TrimSpace(attr.Value)
+				}
+			}
+			if name == "cover" && content != "" {
+				declared.coverID = content
+			}
+		case xml.EndElement:
+			if t.Name.Local == "metadata" {
+				inMetadata = false
+			}
+		}
+	}
+
+	return declared
+}
+
+func findCoverItem(pkg *Package, coverID string) *ManifestItem {
+	for i := range pkg.Manifest.Items {
+		for _, property := range strings.Fields(pkg.Manifest.Items[i].Properties) {
+			if property == "cover-image" {
+				return &pkg.Manifest.Items[i]
+			}
+		}
+	}
+	if coverID == "" {
+		return nil
+	}
+	for i := range pkg.Manifest.Items {
+		if pkg.Manifest.Items[i].ID == coverID {
+			return &pkg.Manifest.Items[i]
+		}
+	}
+	return nil
+}
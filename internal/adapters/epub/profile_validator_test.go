@@ -0,0 +1,242 @@
+package epub
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+const profileTestOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Profile Test Book</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <dc:creator>Jane Author</dc:creator>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="chapter1.xhtml" media-type="application/xhtml+xml" properties="scripted"/>
+    <item id="cover" href="images/cover.png" media-type="image/png" properties="cover-image"/>
+    <item id="audio" href="audio/track.mp3" media-type="audio/mpeg"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`
+
+func encodeTestPNG(t *testing.T, width, height int) string {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.String()
+}
+
+func buildProfileTestEPUB(t *testing.T, coverWidth, coverHeight int) []byte {
+	t.Helper()
+
+	nav := `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Nav</title></head><body></body></html>`
+	chapter := `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Chapter</title></head><body></body></html>`
+
+	return buildEPUBWithOPFAndFiles(t, profileTestOPF, nav, []testFile{
+		{path: "OEBPS/chapter1.xhtml", content: chapter},
+		{path: "OEBPS/images/cover.png", content: encodeTestPNG(t, coverWidth, coverHeight)},
+		{path: "OEBPS/audio/track.mp3", content: string(bytes.Repeat([]byte{0xFF}, 2048))},
+	})
+}
+
+func TestProfileValidator_Rules(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     ProfileRules
+		coverSize [2]int
+		wantCodes []string
+	}{
+		{
+			name:      "empty rules",
+			rules:     ProfileRules{},
+			coverSize: [2]int{10, 10},
+			wantCodes: nil,
+		},
+		{
+			name:      "file too large",
+			rules:     ProfileRules{MaxFileSize: 100},
+			coverSize: [2]int{10, 10},
+			wantCodes: []string{ErrorCodeProfileFileTooLarge},
+		},
+		{
+			name:      "resource too large",
+			rules:     ProfileRules{MaxResourceSize: 1024},
+			coverSize: [2]int{10, 10},
+			wantCodes: []string{ErrorCodeProfileResourceTooLarge},
+		},
+		{
+			name:      "cover too small",
+			rules:     ProfileRules{Cover: &CoverRule{Required: true, MinWidth: 100, MinHeight: 150}},
+			coverSize: [2]int{100, 120},
+			wantCodes: []string{ErrorCodeProfileCoverTooSmall},
+		},
+		{
+			name:      "cover large enough",
+			rules:     ProfileRules{Cover: &CoverRule{Required: true, MinWidth: 100, MinHeight: 150}},
+			coverSize: [2]int{100, 150},
+			wantCodes: nil,
+		},
+		{
+			name:      "required metadata",
+			rules:     ProfileRules{RequiredMetadata: []string{"dc:creator", "dc:publisher", "ibooks:version", "dcterms:modified"}},
+			coverSize: [2]int{10, 10},
+			wantCodes: []string{ErrorCodeProfileMissingMetadata, ErrorCodeProfileMissingMetadata},
+		},
+		{
+			name:      "required files",
+			rules:     ProfileRules{RequiredFiles: []string{"META-INF/com.apple.ibooks.display-options.xml", "META-INF/container.xml"}},
+			coverSize: [2]int{10, 10},
+			wantCodes: []string{ErrorCodeProfileMissingFile},
+		},
+		{
+			name:      "banned features",
+			rules:     ProfileRules{BannedProperties: []string{"scripted"}, BannedMediaTypes: []string{"audio/mpeg"}},
+			coverSize: [2]int{10, 10},
+			wantCodes: []string{ErrorCodeProfileBannedProperty, ErrorCodeProfileBannedMediaType},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildProfileTestEPUB(t, tt.coverSize[0], tt.coverSize[1])
+
+			result, err := NewProfileValidator().ValidateBytes(data, tt.rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			gotCodes := make([]string, 0, len(result.Errors))
+			for _, e := range result.Errors {
+				gotCodes = append(gotCodes, e.Code)
+			}
+			if len(gotCodes) != len(tt.wantCodes) {
+				t.Fatalf("got codes %v, want %v", gotCodes, tt.wantCodes)
+			}
+			for i := range gotCodes {
+				if gotCodes[i] != tt.wantCodes[i] {
+					t.Errorf("got codes %v, want %v", gotCodes, tt.wantCodes)
+					break
+				}
+			}
+			if result.Valid != (len(tt.wantCodes) == 0) {
+				t.Errorf("Valid = %v with codes %v", result.Valid, gotCodes)
+			}
+		})
+	}
+}
+
+func TestProfileValidator_EPUB2CoverMeta(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>EPUB 2 Book</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta name="cover" content="cover-img"/>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="cover-img" href="cover.png" media-type="image/png"/>
+  </manifest>
+  <spine toc="ncx"/>
+</package>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, "", []testFile{
+		{path: "OEBPS/cover.png", content: encodeTestPNG(t, 20, 30)},
+	})
+
+	result, err := NewProfileValidator().ValidateBytes(data, ProfileRules{
+		Cover: &CoverRule{Required: true, MinWidth: 40, MinHeight: 40},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Code != ErrorCodeProfileCoverTooSmall {
+		t.Fatalf("expected only %s, got %v", ErrorCodeProfileCoverTooSmall, result.Errors)
+	}
+	if result.Errors[0].Details["path"] != "OEBPS/cover.png" {
+		t.Errorf("expected cover path in details, got %v", result.Errors[0].Details)
+	}
+}
+
+func TestProfileValidator_CoverMissing(t *testing.T) {
+	data := createCompleteValidEPUB(t)
+
+	result, err := NewProfileValidator().ValidateBytes(data, ProfileRules{Cover: &CoverRule{Required: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Code != ErrorCodeProfileCoverMissing {
+		t.Errorf("expected %s, got %v", ErrorCodeProfileCoverMissing, result.Errors)
+	}
+}
+
+func TestProfileValidator_RecommendedRules(t *testing.T) {
+	data := buildProfileTestEPUB(t, 100, 150)
+
+	result, err := NewProfileValidator().ValidateBytes(data, ProfileRules{
+		Cover:               &CoverRule{Required: true, MinWidth: 100, MinHeight: 150, RecommendedWidth: 200, RecommendedHeight: 300},
+		RequiredMetadata:    []string{"dc:title"},
+		RecommendedMetadata: []string{"ibooks:version"},
+		RecommendedFiles:    []string{"META-INF/com.apple.ibooks.display-options.xml", "META-INF/container.xml"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Errorf("expected advisory findings to keep the EPUB valid, got %v", result.Errors)
+	}
+	want := []string{ErrorCodeProfileRecommendedFile, ErrorCodeProfileRecommendedMetadata, ErrorCodeProfileCoverBelowIdeal}
+	if len(result.Warnings) != len(want) {
+		t.Fatalf("got warnings %v, want %v", result.Warnings, want)
+	}
+	for i, code := range want {
+		if result.Warnings[i].Code != code {
+			t.Errorf("warning %d = %s, want %s", i, result.Warnings[i].Code, code)
+		}
+	}
+
+	report := &domain.ValidationReport{IsValid: true}
+	result.AppendTo(report)
+	if !report.IsValid || len(report.Warnings) != 3 || report.Warnings[0].Severity != domain.SeverityWarning {
+		t.Errorf("expected three warnings in a valid report, got %+v", report)
+	}
+}
+
+func TestProfileValidationResult_AppendTo(t *testing.T) {
+	result := &ProfileValidationResult{
+		Errors: []ValidationError{
+			{Code: ErrorCodeProfileCoverMissing, Message: "no cover", Details: map[string]interface{}{}},
+			{Code: ErrorCodeProfileBannedMediaType, Message: "audio", Details: map[string]interface{}{"path": "OEBPS/audio/track.mp3"}},
+		},
+	}
+	report := &domain.ValidationReport{IsValid: true}
+
+	result.AppendTo(report)
+
+	if report.IsValid || len(report.Errors) != 2 {
+		t.Fatalf("expected two errors and an invalid report, got %+v", report)
+	}
+	if report.Errors[0].Location != nil {
+		t.Errorf("expected no location for package-level finding, got %+v", report.Errors[0].Location)
+	}
+	if loc := report.Errors[1].Location; loc == nil || loc.File != "track.mp3" || loc.Path != "OEBPS/audio/track.mp3" {
+		t.Errorf("unexpected location %+v", loc)
+	}
+}
@@ -0,0 +1,324 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrNoPackageDocument is returned by NewPublication when the container does
+// not lead to a readable package document.
+var ErrNoPackageDocument = errors.New("EPUB has no readable package document")
+
+// Publication is a parsed, read-only view of an EPUB: its container, package
+// document, typed metadata, manifest, spine and table of contents. Resource
+// content is read from the archive on demand, and parsed XHTML documents are
+// cached for reuse.
+type Publication struct {
+	// Container is the parsed META-INF/container.xml.
+	Container *ContainerXML
+	// PackagePath is the archive path of the package document (OPF).
+	PackagePath string
+	// Package is the parsed package document.
+	Package *Package
+	// Metadata is the typed package metadata.
+	Metadata PublicationMetadata
+	// Manifest lists the manifest items in document order.
+	Manifest []*Resource
+	// Spine lists the manifest items referenced by the spine, in reading
+	// order. Itemrefs that do not resolve to a manifest item are omitted.
+	Spine []*Resource
+	// TOC is the table of contents tree from the navigation document, or
+	// from the NCX for EPUB 2. It is nil when neither can be read; the
+	// validators report why.
+	TOC []*TOCEntry
+
+	closer io.Closer
+	index  *archiveIndex
+	names  []string
+	byID   map[string]*Resource
+
+	mu        sync.Mutex
+	documents map[string]parsedDocument
+}
+
+type parsedDocument struct {
+	node *html.Node
+	err  error
+}
+
+// Resource is a manifest item with lazy access to its content.
+type Resource struct {
+	ID         string
+	Href       string
+	MediaType  string
+	Properties []string
+	// Path is the archive path of the resource: Href resolved against the
+	// package document directory, percent-decoded and NFC-normalized.
+	Path string
+
+	pub  *Publication
+	file *zip.File
+}
+
+// NewPublication parses the container and package document of the EPUB in
+// reader. It returns ErrNoPackageDocument (wrapped) when the archive has no
+// usable container.xml or package document; such problems are reported by
+// the validators.
+func NewPublication(reader io.ReaderAt, size int64) (*Publication, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB as ZIP: %w", err)
+	}
+
+	pub := &Publication{
+		index:     newArchiveIndex(zipReader.File),
+		names:     make([]string, 0, len(zipReader.File)),
+		byID:      make(map[string]*Resource),
+		documents: make(map[string]parsedDocument),
+	}
+	seen := make(map[string]bool, len(zipReader.File))
+	for _, f := range zipReader.File {
+		if seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		pub.names = append(pub.names, f.Name)
+	}
+	sort.Strings(pub.names)
+
+	containerFile, ok := pub.index.file(ContainerXMLPath)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrNoPackageDocument, ContainerXMLPath)
+	}
+	containerData, err := readZipEntryLimited(containerFile, DefaultContainerLimits().MaxEntrySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ContainerXMLPath, err)
+	}
+	var container ContainerXML
+	if err := xml.Unmarshal(containerData, &container); err != nil || len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("%w: %s has no rootfile", ErrNoPackageDocument, ContainerXMLPath)
+	}
+	pub.Container = &container
+
+	pub.PackagePath = strings.TrimPrefix(container.Rootfiles[0].FullPath, "/")
+	opfFile, ok := pub.index.file(pub.PackagePath)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrNoPackageDocument, pub.PackagePath)
+	}
+	opfData, err := readZipEntryLimited(opfFile, DefaultContainerLimits().MaxEntrySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pub.PackagePath, err)
+	}
+	var pkg Package
+	if err := xml.Unmarshal(opfData, &pkg); err != nil {
+		return nil, fmt.Errorf("%w: %s is not valid XML", ErrNoPackageDocument, pub.PackagePath)
+	}
+	pub.Package = &pkg
+	pub.Metadata = parsePublicationMetadata(opfData, pkg.UniqueID)
+
+	opfDir := path.Dir(pub.PackagePath)
+	for _, item := range pkg.Manifest.Items {
+		itemPath := resolveHref(opfDir, item.Href).Path
+		file, _ := pub.index.file(itemPath)
+		resource := &Resource{
+			ID:         item.ID,
+			Href:       item.Href,
+			MediaType:  item.MediaType,
+			Properties: strings.Fields(item.Properties),
+			Path:       itemPath,
+			pub:        pub,
+			file:       file,
+		}
+		pub.Manifest = append(pub.Manifest, resource)
+		if _, dup := pub.byID[item.ID]; !dup && item.ID != "" {
+			pub.byID[item.ID] = resource
+		}
+	}
+
+	for _, itemref := range pkg.Spine.Items {
+		if resource, ok := pub.byID[itemref.IDRef]; ok {
+			pub.Spine = append(pub.Spine, resource)
+		}
+	}
+
+	if covers := pub.ResourcesWithProperty("cover-image"); len(covers) > 0 {
+		pub.Metadata.Cover = covers[0].Path
+	} else if cover := pub.Resource(pub.Metadata.coverID); cover != nil {
+		pub.Metadata.Cover = cover.Path
+	}
+
+	if toc, err := pub.parseTOC(); err == nil {
+		pub.TOC = toc
+	}
+
+	return pub, nil
+}
+
+// OpenPublication opens the EPUB file at filePath. The file stays open for
+// lazy resource access until Close is called.
+func OpenPublication(filePath string) (*Publication, error) {
+	file, err := os.Open(filePath) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	pub, err := NewPublication(file, info.Size())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	pub.closer = file
+	return pub, nil
+}
+
+// Close releases the file opened by OpenPublication. It is a no-op for
+// publications created with NewPublication.
+func (p *Publication) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	err := p.closer.Close()
+	p.closer = nil
+	return err
+}
+
+// Resource returns the manifest item with the given id, or nil.
+func (p *Publication) Resource(id string) *Resource {
+	return p.byID[id]
+}
+
+// ResourceByPath returns the manifest item stored at the archive path, or nil.
+func (p *Publication) ResourceByPath(name string) *Resource {
+	name = norm.NFC.String(strings.TrimPrefix(name, "/"))
+	for _, resource := range p.Manifest {
+		if resource.Path == name {
+			return resource
+		}
+	}
+	return nil
+}
+
+// ResourcesWithProperty returns the manifest items that declare property.
+func (p *Publication) ResourcesWithProperty(property string) []*Resource {
+	var matches []*Resource
+	for _, resource := range p.Manifest {
+		if resource.HasProperty(property) {
+			matches = append(matches, resource)
+		}
+	}
+	return matches
+}
+
+// Files returns the names of all entries in the archive, sorted.
+func (p *Publication) Files() []string {
+	return append([]string(nil), p.names...)
+}
+
+// HasFile reports whether the archive contains an entry with the given name.
+// Names are compared in Unicode NFC.
+func (p *Publication) HasFile(name string) bool {
+	_, ok := p.index.file(name)
+	return ok
+}
+
+// OpenFile opens any archive entry, including files outside the manifest.
+func (p *Publication) OpenFile(name string) (io.ReadCloser, error) {
+	f, ok := p.index.file(name)
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", name)
+	}
+	return f.Open()
+}
+
+// ReadFile reads an archive entry, including files outside the manifest.
+func (p *Publication) ReadFile(name string) ([]byte, error) {
+	f, ok := p.index.file(name)
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", name)
+	}
+	return readZipEntryLimited(f, DefaultContainerLimits().MaxEntrySize)
+}
+
+// Exists reports whether the resource is present in the archive.
+func (r *Resource) Exists() bool {
+	return r.file != nil
+}
+
+// Size returns the uncompressed size of the resource, or 0 when it is missing.
+func (r *Resource) Size() int64 {
+	if r.file == nil {
+		return 0
+	}
+	return int64(r.file.UncompressedSize64) //nolint:gosec
+}
+
+// HasProperty reports whether the manifest item declares property.
+func (r *Resource) HasProperty(property string) bool {
+	for _, p := range r.Properties {
+		if p == property {
+			return true
+		}
+	}
+	return false
+}
+
+// IsContentDocument reports whether the resource is an XHTML content document.
+func (r *Resource) IsContentDocument() bool {
+	mediaType := strings.ToLower(strings.TrimSpace(r.MediaType))
+	return mediaType == "application/xhtml+xml" || strings.HasPrefix(mediaType, "text/html")
+}
+
+// Open opens the resource content for reading.
+func (r *Resource) Open() (io.ReadCloser, error) {
+	if r.file == nil {
+		return nil, fmt.Errorf("file not found: %s", r.Path)
+	}
+	return r.file.Open()
+}
+
+// ReadAll reads the whole resource content.
+func (r *Resource) ReadAll() ([]byte, error) {
+	if r.file == nil {
+		return nil, fmt.Errorf("file not found: %s", r.Path)
+	}
+	return readZipEntryLimited(r.file, DefaultContainerLimits().MaxEntrySize)
+}
+
+// Document parses the resource as (X)HTML. The parsed tree is cached and
+// shared between callers, so it must not be modified.
+func (r *Resource) Document() (*html.Node, error) {
+	r.pub.mu.Lock()
+	defer r.pub.mu.Unlock()
+
+	if cached, ok := r.pub.documents[r.Path]; ok {
+		return cached.node, cached.err
+	}
+
+	var parsed parsedDocument
+	data, err := r.ReadAll()
+	if err != nil {
+		parsed.err = err
+	} else if parsed.node, err = html.Parse(bytes.NewReader(data)); err != nil {
+		parsed.err = fmt.Errorf("failed to parse %s: %w", r.Path, err)
+	}
+	r.pub.documents[r.Path] = parsed
+	return parsed.node, parsed.err
+}
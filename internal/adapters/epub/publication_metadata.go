@@ -0,0 +1,336 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// PublicationMetadata is the typed package metadata of a Publication. EPUB 3
+// refinements (<meta refines="#id">) and their EPUB 2 attribute equivalents
+// (opf:role, opf:file-as, opf:scheme) are resolved onto the refined entries.
+type PublicationMetadata struct {
+	Titles           []Title      `json:"titles,omitempty"`
+	Creators         []Creator    `json:"creators,omitempty"`
+	Contributors     []Creator    `json:"contributors,omitempty"`
+	Identifiers      []Identifier `json:"identifiers,omitempty"`
+	UniqueIdentifier string       `json:"unique_identifier,omitempty"`
+	Languages        []string     `json:"languages,omitempty"`
+	Subjects         []string     `json:"subjects,omitempty"`
+	Publisher        string       `json:"publisher,omitempty"`
+	Description      string       `json:"description,omitempty"`
+	Date             string       `json:"date,omitempty"`
+	Modified         string       `json:"modified,omitempty"`
+	Rights           string       `json:"rights,omitempty"`
+	Series           []Series     `json:"series,omitempty"`
+	// Accessibility holds the schema.org accessibility metadata.
+	Accessibility AccessibilityMetadata `json:"accessibility"`
+	// Cover is the archive path of the cover image, from the cover-image
+	// manifest property or the EPUB 2 <meta name="cover">.
+	Cover string `json:"cover,omitempty"`
+
+	coverID string
+}
+
+// Title is a dc:title entry.
+type Title struct {
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"` // title-type refinement such as "main" or "subtitle"
+	Language string `json:"language,omitempty"`
+}
+
+// Creator is a dc:creator or dc:contributor entry.
+type Creator struct {
+	Name   string   `json:"name"`
+	FileAs string   `json:"file_as,omitempty"`
+	Roles  []string `json:"roles,omitempty"` // MARC relator codes such as "aut" or "ill"
+}
+
+// Identifier is a dc:identifier entry.
+type Identifier struct {
+	ID     string `json:"id,omitempty"`
+	Value  string `json:"value"`
+	Scheme string `json:"scheme,omitempty"` // identifier-type refinement or opf:scheme
+}
+
+// Series is a collection the publication belongs to, declared with
+// belongs-to-collection or with the calibre:series convention.
+type Series struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`     // collection-type refinement such as "series" or "set"
+	Position string `json:"position,omitempty"` // group-position refinement or calibre:series_index
+}
+
+// MainTitle returns the title refined as "main", or the first title.
+func (m *PublicationMetadata) MainTitle() string {
+	for _, title := range m.Titles {
+		if title.Type == "main" {
+			return title.Value
+		}
+	}
+	if len(m.Titles) > 0 {
+		return m.Titles[0].Value
+	}
+	return ""
+}
+
+// Authors returns the names of creators with the "aut" role, or of all
+// creators when none declares a role.
+func (m *PublicationMetadata) Authors() []string {
+	var authors, all []string
+	for _, creator := range m.Creators {
+		all = append(all, creator.Name)
+		for _, role := range creator.Roles {
+			if role == "aut" {
+				authors = append(authors, creator.Name)
+				break
+			}
+		}
+	}
+	if len(authors) > 0 {
+		return authors
+	}
+	return all
+}
+
+// metadataElement is a direct child of the OPF <metadata> element.
+type metadataElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Value   string     `xml:",chardata"`
+}
+
+func (e *metadataElement) attr(local string) string {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == local {
+			return strings.TrimSpace(attr.Value)
+		}
+	}
+	return ""
+}
+
+// parsePublicationMetadata extracts typed metadata from OPF data.
+func parsePublicationMetadata(opfData []byte, uniqueID string) PublicationMetadata {
+	var elements []metadataElement
+
+	decoder := xml.NewDecoder(bytes.NewReader(opfData))
+	decoder.Strict = false
+	depth := 0
+	inMetadata := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if inMetadata {
+				var element metadataElement
+				if err := decoder.DecodeElement(&element, &t); err != nil {
+					return buildPublicationMetadata(elements, uniqueID)
+				}
+				element.Value = strings.TrimSpace(element.Value)
+				elements = append(elements, element)
+				continue
+			}
+			depth++
+			if t.Name.Local == "metadata" && depth == 2 {
+				inMetadata = true
+			}
+		case xml.EndElement:
+			if inMetadata && t.Name.Local == "metadata" {
+				return buildPublicationMetadata(elements, uniqueID)
+			}
+			depth--
+		}
+	}
+	return buildPublicationMetadata(elements, uniqueID)
+}
+
+func buildPublicationMetadata(elements []metadataElement, uniqueID string) PublicationMetadata {
+	metadata := PublicationMetadata{}
+
+	// Refinements are collected first because <meta refines> may precede
+	// the element it refines.
+	refinements := make(map[string]map[string][]string)
+	named := make(map[string]string)
+	for i := range elements {
+		element := &elements[i]
+		if element.XMLName.Local != "meta" {
+			continue
+		}
+		if name := element.attr("name"); name != "" {
+			named[name] = element.attr("content")
+			continue
+		}
+		refines := strings.TrimPrefix(element.attr("refines"), "#")
+		property := element.attr("property")
+		if refines == "" || property == "" {
+			continue
+		}
+		if refinements[refines] == nil {
+			refinements[refines] = make(map[string][]string)
+		}
+		refinements[refines][property] = append(refinements[refines][property], element.Value)
+	}
+	refined := func(element *metadataElement, property string) []string {
+		id := element.attr("id")
+		if id == "" {
+			return nil
+		}
+		return refinements[id][property]
+	}
+	first := func(values []string) string {
+		if len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	for i := range elements {
+		element := &elements[i]
+		if element.XMLName.Space == DCNamespace {
+			switch element.XMLName.Local {
+			case "title":
+				metadata.Titles = append(metadata.Titles, Title{
+					Value:    element.Value,
+					Type:     first(refined(element, "title-type")),
+					Language: element.attr("lang"),
+				})
+			case "creator", "contributor":
+				creator := Creator{
+					Name:   element.Value,
+					FileAs: element.attr("file-as"),
+				}
+				if fileAs := first(refined(element, "file-as")); fileAs != "" {
+					creator.FileAs = fileAs
+				}
+				if role := element.attr("role"); role != "" {
+					creator.Roles = append(creator.Roles, role)
+				}
+				creator.Roles = append(creator.Roles, refined(element, "role")...)
+				if element.XMLName.Local == "creator" {
+					metadata.Creators = append(metadata.Creators, creator)
+				} else {
+					metadata.Contributors = append(metadata.Contributors, creator)
+				}
+			case "identifier":
+				identifier := Identifier{
+					ID:     element.attr("id"),
+					Value:  element.Value,
+					Scheme: element.attr("scheme"),
+				}
+				if scheme := first(refined(element, "identifier-type")); scheme != "" {
+					identifier.Scheme = scheme
+				}
+				metadata.Identifiers = append(metadata.Identifiers, identifier)
+				if identifier.ID != "" && identifier.ID == uniqueID {
+					metadata.UniqueIdentifier = identifier.Value
+				}
+			case "language":
+				metadata.Languages = append(metadata.Languages, element.Value)
+			case "subject":
+				metadata.Subjects = append(metadata.Subjects, element.Value)
+			case "publisher":
+				metadata.Publisher = firstNonEmpty(metadata.Publisher, element.Value)
+			case "description":
+				metadata.Description = firstNonEmpty(metadata.Description, element.Value)
+			case "date":
+				metadata.Date = firstNonEmpty(metadata.Date, element.Value)
+			case "rights":
+				metadata.Rights = firstNonEmpty(metadata.Rights, element.Value)
+			}
+			continue
+		}
+
+		if element.XMLName.Local != "meta" || element.attr("refines") != "" {
+			continue
+		}
+		if name := element.attr("name"); accessibilityProperties[name] {
+			addAccessibilityMetadata(&metadata.Accessibility, name, element.attr("content"))
+			continue
+		}
+		property := element.attr("property")
+		if accessibilityProperties[property] {
+			addAccessibilityMetadata(&metadata.Accessibility, property, element.Value)
+			continue
+		}
+		switch property {
+		case DCTermsProperty:
+			metadata.Modified = firstNonEmpty(metadata.Modified, element.Value)
+		case "belongs-to-collection":
+			metadata.Series = append(metadata.Series, Series{
+				Name:     element.Value,
+				Type:     first(refined(element, "collection-type")),
+				Position: first(refined(element, "group-position")),
+			})
+		}
+	}
+
+	if name := strings.TrimSpace(named["calibre:series"]); name != "" && !hasSeries(metadata.Series, name) {
+		metadata.Series = append(metadata.Series, Series{
+			Name:     name,
+			Type:     "series",
+			Position: strings.TrimSpace(named["calibre:series_index"]),
+		})
+	}
+
+	metadata.coverID = strings.TrimSpace(named["cover"])
+
+	return metadata
+}
+
+// accessibilityProperties lists the package metadata properties that make up
+// AccessibilityMetadata.
+var accessibilityProperties = map[string]bool{
+	"schema:accessMode":           true,
+	"schema:accessModeSufficient": true,
+	"schema:accessibilityFeature": true,
+	"schema:accessibilityHazard":  true,
+	"schema:accessibilitySummary": true,
+	"dcterms:conformsTo":          true,
+	"a11y:certifiedBy":            true,
+	"a11y:certifierCredential":    true,
+}
+
+func addAccessibilityMetadata(metadata *AccessibilityMetadata, property, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	switch property {
+	case "schema:accessMode":
+		metadata.AccessModes = append(metadata.AccessModes, value)
+	case "schema:accessModeSufficient":
+		metadata.AccessModeSufficient = append(metadata.AccessModeSufficient, value)
+	case "schema:accessibilityFeature":
+		metadata.AccessibilityFeatures = append(metadata.AccessibilityFeatures, value)
+	case "schema:accessibilityHazard":
+		metadata.AccessibilityHazards = append(metadata.AccessibilityHazards, value)
+	case "schema:accessibilitySummary":
+		metadata.AccessibilitySummary = firstNonEmpty(metadata.AccessibilitySummary, value)
+	case "dcterms:conformsTo":
+		metadata.ConformanceClaims = append(metadata.ConformanceClaims, value)
+	case "a11y:certifiedBy":
+		metadata.CertifiedBy = firstNonEmpty(metadata.CertifiedBy, value)
+	case "a11y:certifierCredential":
+		metadata.CertifierCredential = firstNonEmpty(metadata.CertifierCredential, value)
+	}
+}
+
+func hasSeries(series []Series, name string) bool {
+	for _, s := range series {
+		if strings.EqualFold(s.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(current, candidate string) string {
+	if current != "" {
+		return current
+	}
+	return candidate
+}
@@ -0,0 +1,299 @@
+package epub
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewPublication(t *testing.T) {
+	data := createCompleteValidEPUB(t)
+
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewPublication failed: %v", err)
+	}
+
+	if pub.PackagePath != "OEBPS/content.opf" {
+		t.Errorf("PackagePath = %q", pub.PackagePath)
+	}
+	if pub.Package == nil || pub.Package.Version != "3.0" {
+		t.Fatalf("unexpected package %+v", pub.Package)
+	}
+	if len(pub.Manifest) != 3 {
+		t.Fatalf("expected 3 manifest items, got %d", len(pub.Manifest))
+	}
+	if len(pub.Spine) != 2 || pub.Spine[0].ID != "chapter1" || pub.Spine[1].ID != "chapter2" {
+		t.Errorf("unexpected spine %v", pub.Spine)
+	}
+
+	nav := pub.ResourcesWithProperty("nav")
+	if len(nav) != 1 || nav[0].Path != "OEBPS/nav.xhtml" {
+		t.Fatalf("expected nav resource, got %v", nav)
+	}
+
+	chapter := pub.Resource("chapter1")
+	if chapter == nil || !chapter.Exists() || !chapter.IsContentDocument() {
+		t.Fatalf("unexpected chapter resource %+v", chapter)
+	}
+	if pub.ResourceByPath("/OEBPS/chapter1.xhtml") != chapter {
+		t.Error("ResourceByPath did not return the manifest item")
+	}
+
+	doc, err := chapter.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	again, _ := chapter.Document()
+	if doc == nil || doc != again {
+		t.Error("expected the parsed document to be cached")
+	}
+
+	if !pub.HasFile(ContainerXMLPath) {
+		t.Error("expected container.xml in Files")
+	}
+	if _, err := pub.ReadFile("missing.xhtml"); err == nil {
+		t.Error("expected error reading a missing file")
+	}
+}
+
+func TestNewPublication_NoPackageDocument(t *testing.T) {
+	data := createEPUBWithInvalidContainer(t)
+
+	_, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, ErrNoPackageDocument) {
+		t.Errorf("expected ErrNoPackageDocument, got %v", err)
+	}
+}
+
+func TestNewPublication_Metadata(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+    <meta refines="#t1" property="title-type">subtitle</meta>
+    <dc:title id="t1">A Subtitle</dc:title>
+    <dc:title id="t2">The Main Title</dc:title>
+    <meta refines="#t2" property="title-type">main</meta>
+    <dc:creator id="c1">Jane Doe</dc:creator>
+    <meta refines="#c1" property="role" scheme="marc:relators">aut</meta>
+    <meta refines="#c1" property="file-as">Doe, Jane</meta>
+    <dc:creator opf:role="ill" opf:file-as="Roe, Rick">Rick Roe</dc:creator>
+    <dc:contributor id="c3">Ed Itor</dc:contributor>
+    <meta refines="#c3" property="role">edt</meta>
+    <dc:identifier id="uid">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>
+    <dc:identifier id="isbn">9780000000002</dc:identifier>
+    <meta refines="#isbn" property="identifier-type" scheme="onix:codelist5">15</meta>
+    <dc:language>en</dc:language>
+    <dc:subject>Fiction</dc:subject>
+    <dc:subject>Mystery</dc:subject>
+    <dc:publisher>Example Press</dc:publisher>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+    <meta property="belongs-to-collection" id="s1">The Cases</meta>
+    <meta refines="#s1" property="collection-type">series</meta>
+    <meta refines="#s1" property="group-position">2</meta>
+    <meta name="calibre:series" content="Calibre Series"/>
+    <meta name="calibre:series_index" content="3"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+  </manifest>
+  <spine/>
+</package>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, "", nil)
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewPublication failed: %v", err)
+	}
+	metadata := pub.Metadata
+
+	if got := metadata.MainTitle(); got != "The Main Title" {
+		t.Errorf("MainTitle = %q", got)
+	}
+	if len(metadata.Titles) != 2 || metadata.Titles[0].Type != "subtitle" {
+		t.Errorf("unexpected titles %+v", metadata.Titles)
+	}
+
+	wantCreators := []Creator{
+		{Name: "Jane Doe", FileAs: "Doe, Jane", Roles: []string{"aut"}},
+		{Name: "Rick Roe", FileAs: "Roe, Rick", Roles: []string{"ill"}},
+	}
+	if !reflect.DeepEqual(metadata.Creators, wantCreators) {
+		t.Errorf("Creators = %+v", metadata.Creators)
+	}
+	if got := metadata.Authors(); !reflect.DeepEqual(got, []string{"Jane Doe"}) {
+		t.Errorf("Authors = %v", got)
+	}
+	if len(metadata.Contributors) != 1 || !reflect.DeepEqual(metadata.Contributors[0].Roles, []string{"edt"}) {
+		t.Errorf("unexpected contributors %+v", metadata.Contributors)
+	}
+
+	if metadata.UniqueIdentifier != "urn:uuid:12345678-1234-1234-1234-123456789012" {
+		t.Errorf("UniqueIdentifier = %q", metadata.UniqueIdentifier)
+	}
+	if len(metadata.Identifiers) != 2 || metadata.Identifiers[1].Scheme != "15" {
+		t.Errorf("unexpected identifiers %+v", metadata.Identifiers)
+	}
+
+	if !reflect.DeepEqual(metadata.Subjects, []string{"Fiction", "Mystery"}) {
+		t.Errorf("Subjects = %v", metadata.Subjects)
+	}
+	if metadata.Publisher != "Example Press" || metadata.Modified != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected publisher/modified %q %q", metadata.Publisher, metadata.Modified)
+	}
+
+	wantSeries := []Series{
+		{Name: "The Cases", Type: "series", Position: "2"},
+		{Name: "Calibre Series", Type: "series", Position: "3"},
+	}
+	if !reflect.DeepEqual(metadata.Series, wantSeries) {
+		t.Errorf("Series = %+v", metadata.Series)
+	}
+}
+
+func TestNewPublication_TOCFromNav(t *testing.T) {
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Contents</title></head>
+<body>
+  <nav epub:type="landmarks"><ol><li><a href="text/ch1.xhtml">Start</a></li></ol></nav>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="text/ch1.xhtml">Chapter
+        <em>One</em></a>
+        <ol>
+          <li><a href="text/ch1.xhtml#s1">Section 1</a></li>
+        </ol>
+      </li>
+      <li><span>Part Two</span>
+        <ol><li><a href="#top">Back</a></li></ol>
+      </li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>TOC</dc:title>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+  </manifest>
+  <spine/>
+</package>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, nav, nil)
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewPublication failed: %v", err)
+	}
+
+	want := []*TOCEntry{
+		{
+			Title: "Chapter One",
+			Href:  "text/ch1.xhtml",
+			Path:  "OEBPS/text/ch1.xhtml",
+			Children: []*TOCEntry{
+				{Title: "Section 1", Href: "text/ch1.xhtml#s1", Path: "OEBPS/text/ch1.xhtml", Fragment: "s1"},
+			},
+		},
+		{
+			Title: "Part Two",
+			Children: []*TOCEntry{
+				{Title: "Back", Href: "#top", Path: "OEBPS/nav.xhtml", Fragment: "top"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(pub.TOC, want) {
+		t.Errorf("unexpected TOC %s", formatTOC(pub.TOC))
+	}
+}
+
+func TestNewPublication_TOCFromNCX(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="uid">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>NCX</dc:title>
+  </metadata>
+  <manifest>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+	ncx := `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="p1" playOrder="1">
+      <navLabel><text>Chapter 1</text></navLabel>
+      <content src="ch1.xhtml"/>
+      <navPoint id="p2" playOrder="2">
+        <navLabel><text>Scene 2</text></navLabel>
+        <content src="ch1.xhtml#scene2"/>
+      </navPoint>
+    </navPoint>
+  </navMap>
+</ncx>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, "", []testFile{{path: "OEBPS/toc.ncx", content: ncx}})
+	pub, err := NewPublication(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewPublication failed: %v", err)
+	}
+
+	if len(pub.TOC) != 1 || len(pub.TOC[0].Children) != 1 {
+		t.Fatalf("unexpected TOC %s", formatTOC(pub.TOC))
+	}
+	if entry := pub.TOC[0]; entry.Title != "Chapter 1" || entry.Path != "OEBPS/ch1.xhtml" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if child := pub.TOC[0].Children[0]; child.Fragment != "scene2" || child.Path != "OEBPS/ch1.xhtml" {
+		t.Errorf("unexpected child %+v", child)
+	}
+}
+
+func TestOpenPublication(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(filePath, createCompleteValidEPUB(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := OpenPublication(filePath)
+	if err != nil {
+		t.Fatalf("OpenPublication failed: %v", err)
+	}
+	if _, err := pub.Spine[0].ReadAll(); err != nil {
+		t.Errorf("ReadAll failed: %v", err)
+	}
+	if err := pub.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := pub.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+
+	if _, err := OpenPublication(filepath.Join(t.TempDir(), "missing.epub")); err == nil {
+		t.Error("expected error opening a missing file")
+	}
+}
+
+func formatTOC(entries []*TOCEntry) string {
+	var b bytes.Buffer
+	var walk func([]*TOCEntry, string)
+	walk = func(entries []*TOCEntry, indent string) {
+		for _, entry := range entries {
+			b.WriteString("\n" + indent + entry.Title + " -> " + entry.Path + "#" + entry.Fragment)
+			walk(entry.Children, indent+"  ")
+		}
+	}
+	walk(entries, "")
+	return b.String()
+}
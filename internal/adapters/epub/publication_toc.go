@@ -0,0 +1,225 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// NCXMediaType is the media type of an EPUB 2 NCX table of contents.
+const NCXMediaType = "application/x-dtbncx+xml"
+
+// TOCEntry is a node of the table of contents tree.
+type TOCEntry struct {
+	Title string
+	// Href is the link target as written in the navigation document.
+	Href string
+	// Path is the archive path of the target, without fragment. It is empty
+	// for headings that do not link anywhere.
+	Path string
+	// Fragment is the fragment identifier of the target, without "#".
+	Fragment string
+	Children []*TOCEntry
+}
+
+// parseTOC builds the table of contents from the EPUB 3 navigation document,
+// falling back to the EPUB 2 NCX. It returns nil when neither exists.
+func (p *Publication) parseTOC() ([]*TOCEntry, error) {
+	for _, nav := range p.ResourcesWithProperty("nav") {
+		if !nav.Exists() {
+			continue
+		}
+		doc, err := nav.Document()
+		if err != nil {
+			return nil, err
+		}
+		if entries, ok := tocFromNav(doc, nav.Path); ok {
+			return entries, nil
+		}
+	}
+
+	if ncx := p.ncxResource(); ncx != nil && ncx.Exists() {
+		data, err := ncx.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		return tocFromNCX(data, ncx.Path)
+	}
+	return nil, nil
+}
+
+func (p *Publication) ncxResource() *Resource {
+	if p.Package != nil {
+		if toc := p.Resource(strings.TrimSpace(p.Package.Spine.Toc)); toc != nil {
+			return toc
+		}
+	}
+	for _, resource := range p.Manifest {
+		if strings.EqualFold(strings.TrimSpace(resource.MediaType), NCXMediaType) {
+			return resource
+		}
+	}
+	return nil
+}
+
+func tocFromNav(doc *html.Node, docPath string) ([]*TOCEntry, bool) {
+	var tocNav *html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		if tocNav != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "nav" {
+			for _, epubType := range strings.Fields(navEpubType(n)) {
+				if epubType == NavTypeTOC {
+					tocNav = n
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(doc)
+	if tocNav == nil {
+		return nil, false
+	}
+
+	list := firstDescendant(tocNav, "ol")
+	if list == nil {
+		return nil, true
+	}
+	return navListEntries(list, docPath), true
+}
+
+func navEpubType(n *html.Node) string {
+	for _, attr := range n.Attr {
+		if attr.Key == "epub:type" || (attr.Namespace == EPUBNamespace && attr.Key == "type") {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func navListEntries(list *html.Node, docPath string) []*TOCEntry {
+	var entries []*TOCEntry
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.Data != "li" {
+			continue
+		}
+
+		entry := &TOCEntry{}
+		for c := li.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "a":
+				entry.Title = collapseSpace(nodeText(c))
+				for _, attr := range c.Attr {
+					if attr.Key == "href" {
+						entry.setHref(attr.Val, docPath)
+					}
+				}
+			case "span":
+				if entry.Title == "" {
+					entry.Title = collapseSpace(nodeText(c))
+				}
+			case "ol":
+				entry.Children = navListEntries(c, docPath)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ncxDocument models the parts of an NCX used for the table of contents.
+type ncxDocument struct {
+	NavPoints []ncxNavPoint `xml:"navMap>navPoint"`
+}
+
+type ncxNavPoint struct {
+	Label     string        `xml:"navLabel>text"`
+	Content   ncxContent    `xml:"content"`
+	NavPoints []ncxNavPoint `xml:"navPoint"`
+}
+
+type ncxContent struct {
+	Src string `xml:"src,attr"`
+}
+
+func tocFromNCX(data []byte, docPath string) ([]*TOCEntry, error) {
+	var doc ncxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse NCX: %w", err)
+	}
+	return ncxEntries(doc.NavPoints, docPath), nil
+}
+
+func ncxEntries(points []ncxNavPoint, docPath string) []*TOCEntry {
+	var entries []*TOCEntry
+	for _, point := range points {
+		entry := &TOCEntry{
+			Title:    collapseSpace(point.Label),
+			Children: ncxEntries(point.NavPoints, docPath),
+		}
+		entry.setHref(point.Content.Src, docPath)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// setHref records href, resolving it against docPath, the archive path of
+// the document that contains the link.
+func (e *TOCEntry) setHref(href, docPath string) {
+	e.Href = strings.TrimSpace(href)
+	target := resolveHref(path.Dir(docPath), e.Href)
+	e.Fragment = target.Fragment
+	switch {
+	case target.External:
+	case target.Path == "" && e.Fragment != "":
+		e.Path = docPath
+	default:
+		e.Path = target.Path
+	}
+}
+
+func firstDescendant(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data == tag {
+			return c
+		}
+		if found := firstDescendant(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func nodeText(n *html.Node) string {
+	var b bytes.Buffer
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			b.WriteString(node.Data)
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
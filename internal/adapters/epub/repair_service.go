@@ -1010,7 +1010,7 @@ func resolveNavPath(zipReader *zip.Reader, opfPath string) (string, string, bool
 		if strings.HasPrefix(rel, "../") {
 			rel = path.Base(file.Name)
 		}
-		return rel, file.Name, true
+		return hrefForPath(rel), file.Name, true
 	}
 
 	return "nav.xhtml", defaultPath, false
@@ -1036,8 +1036,9 @@ func ensureNavItem(pkg *Package, navHref string) error {
 		}
 	}
 
+	navPath := resolveHref("", navHref).Path
 	for i, item := range pkg.Manifest.Items {
-		if item.Href == navHref {
+		if resolveHref("", item.Href).Path == navPath {
 			pkg.Manifest.Items[i].Properties = addPropertyToken(item.Properties, "nav")
 			return nil
 		}
@@ -1248,7 +1249,7 @@ func collectContentHrefs(zipReader *zip.Reader, opfPath string) []string {
 			continue
 		}
 		seen[rel] = true
-		hrefs = append(hrefs, rel)
+		hrefs = append(hrefs, hrefForPath(rel))
 	}
 
 	if len(hrefs) == 0 {
@@ -1332,11 +1333,7 @@ func (r *RepairServiceImpl) buildAggressiveOPF(opfPath string, contentHrefs []st
 }
 
 func resolveNavPathFromHref(opfPath, navHref string) string {
-	dir := path.Dir(opfPath)
-	if dir == "." || dir == "" {
-		return navHref
-	}
-	return path.Join(dir, navHref)
+	return resolveHref(path.Dir(opfPath), navHref).Path
 }
 
 func buildNavFromSpine(contentHrefs []string) []byte {
@@ -0,0 +1,251 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
+)
+
+// Inline suppression directives, written as XML/HTML comments:
+//
+//	<!-- ebm-ignore EPUB-A11Y-006 -->        suppresses the codes for the next element and its descendants
+//	<!-- ebm-ignore-file EPUB-NAV-006 -->    suppresses the codes for the whole document
+//
+// Several codes may be listed, separated by spaces or commas, and codes may be
+// path.Match patterns such as EPUB-A11Y-*. Text after the codes is treated as
+// a free-form reason.
+//
+// Each validator applies the directives of the document it reads. EPUB
+// validation covers the OPF, nav and content document checks (content,
+// language, SVG, MathML and duplicate content); the EPUB-A11Y checks are
+// suppressed only in AccessibilityValidator results, since EPUB validation
+// does not run them. Container, profile and custom rule findings are not
+// covered.
+const (
+	DirectiveIgnore     = "ebm-ignore"
+	DirectiveIgnoreFile = "ebm-ignore-file"
+)
+
+var directiveCodePattern = regexp.MustCompile(`^[A-Z0-9*?\[\]-]+$`)
+
+// directive is a parsed ebm-ignore comment.
+type directive struct {
+	file  bool
+	codes []string
+}
+
+// parseDirective parses the text of a comment. It reports false when the
+// comment is not an ebm-ignore directive or lists no codes.
+func parseDirective(comment string) (directive, bool) {
+	fields := strings.Fields(strings.ReplaceAll(comment, ",", " "))
+	if len(fields) < 2 {
+		return directive{}, false
+	}
+
+	var d directive
+	switch fields[0] {
+	case DirectiveIgnore:
+	case DirectiveIgnoreFile:
+		d.file = true
+	default:
+		return directive{}, false
+	}
+
+	for _, field := range fields[1:] {
+		if !directiveCodePattern.MatchString(field) {
+			break
+		}
+		if _, err := path.Match(field, ""); err != nil {
+			continue
+		}
+		d.codes = append(d.codes, field)
+	}
+	return d, len(d.codes) > 0
+}
+
+func codeMatches(codes []string, code string) bool {
+	for _, pattern := range codes {
+		if matched, _ := path.Match(pattern, code); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressions holds the directives found in one document. Element-scoped
+// directives are keyed by HTML node for XHTML documents and by element path
+// (see xmlElementPath) for XML documents.
+type suppressions struct {
+	file  []string
+	nodes map[*html.Node][]string
+	paths map[string][]string
+}
+
+// collectHTMLSuppressions gathers the directives in a parsed XHTML document.
+// An element-scoped directive applies to the next sibling element.
+func collectHTMLSuppressions(doc *html.Node) *suppressions {
+	s := &suppressions{nodes: make(map[*html.Node][]string)}
+
+	var traverse func(*html.Node)
+	traverse = func(n *html.Node) {
+		if n.Type == html.CommentNode {
+			if d, ok := parseDirective(n.Data); ok {
+				if d.file {
+					s.file = append(s.file, d.codes...)
+				} else if target := nextElementSibling(n); target != nil {
+					s.nodes[target] = append(s.nodes[target], d.codes...)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			traverse(c)
+		}
+	}
+	if doc != nil {
+		traverse(doc)
+	}
+	return s
+}
+
+func nextElementSibling(n *html.Node) *html.Node {
+	for sibling := n.NextSibling; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type == html.ElementNode {
+			return sibling
+		}
+	}
+	return nil
+}
+
+// collectXMLSuppressions gathers the directives in an XML document such as
+// the OPF. An element-scoped directive applies to the next start element.
+func collectXMLSuppressions(data []byte) *suppressions {
+	s := &suppressions{paths: make(map[string][]string)}
+	if !bytes.Contains(data, []byte(DirectiveIgnore)) {
+		return s
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	type frame struct {
+		path   string
+		counts map[string]int
+	}
+	stack := []frame{{counts: make(map[string]int)}}
+	var pending []string
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.Comment:
+			if d, ok := parseDirective(string(t)); ok {
+				if d.file {
+					s.file = append(s.file, d.codes...)
+				} else {
+					pending = append(pending, d.codes...)
+				}
+			}
+		case xml.StartElement:
+			parent := &stack[len(stack)-1]
+			index := parent.counts[t.Name.Local]
+			parent.counts[t.Name.Local]++
+			elementPath := xmlElementPath(parent.path, t.Name.Local, index)
+			if len(pending) > 0 {
+				s.paths[elementPath] = append(s.paths[elementPath], pending...)
+				pending = nil
+			}
+			stack = append(stack, frame{path: elementPath, counts: make(map[string]int)})
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return s
+}
+
+// xmlElementPath builds the path used to target XML elements, e.g.
+// "package[0]/manifest[0]/item[3]" for the fourth manifest item.
+func xmlElementPath(parent, local string, index int) string {
+	segment := fmt.Sprintf("%s[%d]", local, index)
+	if parent == "" {
+		return segment
+	}
+	return parent + "/" + segment
+}
+
+// opfElementPath returns the path of the index-th child named local under the
+// OPF package section (metadata, manifest or spine). A negative index targets
+// the section element itself, and the "package" section the root element.
+func opfElementPath(section, local string, index int) string {
+	packagePath := xmlElementPath("", "package", 0)
+	if section == "package" {
+		return packagePath
+	}
+	sectionPath := xmlElementPath(packagePath, section, 0)
+	if index < 0 {
+		return sectionPath
+	}
+	return xmlElementPath(sectionPath, local, index)
+}
+
+// match returns the directive that suppresses finding, if any.
+func (s *suppressions) match(finding ValidationError) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	if codeMatches(s.file, finding.Code) {
+		return DirectiveIgnoreFile, true
+	}
+
+	for n := finding.node; n != nil; n = n.Parent {
+		if codeMatches(s.nodes[n], finding.Code) {
+			return DirectiveIgnore, true
+		}
+	}
+
+	for elementPath := finding.element; elementPath != ""; {
+		if codeMatches(s.paths[elementPath], finding.Code) {
+			return DirectiveIgnore, true
+		}
+		idx := strings.LastIndex(elementPath, "/")
+		if idx == -1 {
+			break
+		}
+		elementPath = elementPath[:idx]
+	}
+	return "", false
+}
+
+// partition moves suppressed findings out of findings. The suppressed copies
+// record the directive and the severity they would otherwise have had.
+func (s *suppressions) partition(findings []ValidationError, severity string) (kept, suppressed []ValidationError) {
+	kept = findings[:0]
+	for _, finding := range findings {
+		name, ok := s.match(finding)
+		if !ok {
+			kept = append(kept, finding)
+			continue
+		}
+		details := make(map[string]interface{}, len(finding.Details)+2)
+		for k, v := range finding.Details {
+			details[k] = v
+		}
+		details[rules.DetailSuppressedBy] = name
+		details["original_severity"] = severity
+		finding.Details = details
+		suppressed = append(suppressed, finding)
+	}
+	return kept, suppressed
+}
@@ -0,0 +1,304 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
+)
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		name     string
+		comment  string
+		wantOK   bool
+		wantFile bool
+		want     []string
+	}{
+		{name: "single code", comment: " ebm-ignore EPUB-A11Y-006 ", wantOK: true, want: []string{"EPUB-A11Y-006"}},
+		{name: "file scope", comment: "ebm-ignore-file EPUB-NAV-004", wantOK: true, wantFile: true, want: []string{"EPUB-NAV-004"}},
+		{name: "list with reason", comment: "ebm-ignore EPUB-A11Y-005, EPUB-A11Y-*  decorative ornaments", wantOK: true, want: []string{"EPUB-A11Y-005", "EPUB-A11Y-*"}},
+		{name: "no codes", comment: "ebm-ignore", wantOK: false},
+		{name: "reason only", comment: "ebm-ignore because reasons", wantOK: false},
+		{name: "other comment", comment: "chapter break", wantOK: false},
+		{name: "prefix only", comment: "ebm-ignored EPUB-A11Y-006", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := parseDirective(tt.comment)
+			if ok != tt.wantOK {
+				t.Fatalf("parseDirective(%q) ok = %v, want %v", tt.comment, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if d.file != tt.wantFile {
+				t.Errorf("file = %v, want %v", d.file, tt.wantFile)
+			}
+			if len(d.codes) != len(tt.want) {
+				t.Fatalf("codes = %v, want %v", d.codes, tt.want)
+			}
+			for i := range tt.want {
+				if d.codes[i] != tt.want[i] {
+					t.Errorf("codes[%d] = %q, want %q", i, d.codes[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAccessibilityValidator_InlineSuppression(t *testing.T) {
+	content := `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en">
+<head><title>Test</title></head>
+<body>
+  <main>
+    <!-- ebm-ignore EPUB-A11Y-006 decorative -->
+    <figure><img src="ornament.png" alt=" "/></figure>
+    <img src="divider.png" alt=" "/>
+    <!-- ebm-ignore EPUB-A11Y-005 -->
+    <p>Not the image below</p>
+    <img src="photo.png"/>
+  </main>
+</body>
+</html>`
+
+	result, err := NewAccessibilityValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if len(result.Suppressed) != 1 {
+		t.Fatalf("expected 1 suppressed finding, got %v", result.Suppressed)
+	}
+	suppressed := result.Suppressed[0]
+	if suppressed.Code != ErrorCodeA11YEmptyAltText || suppressed.Details["src"] != "ornament.png" {
+		t.Errorf("unexpected suppressed finding %+v", suppressed)
+	}
+	if suppressed.Details[rules.DetailSuppressedBy] != DirectiveIgnore || suppressed.Details["original_severity"] != "warning" {
+		t.Errorf("unexpected suppression details %v", suppressed.Details)
+	}
+
+	if !hasFinding(result.Warnings, ErrorCodeA11YEmptyAltText) {
+		t.Error("expected the unannotated empty alt warning to remain")
+	}
+	if !hasFinding(result.Errors, ErrorCodeA11YMissingAltText) {
+		t.Error("a directive must only cover the next element, expected missing alt error")
+	}
+}
+
+func TestNavValidator_FileSuppression(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<!-- ebm-ignore-file EPUB-NAV-004 -->
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="https://example.com/extra">Extra</a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	result, err := NewNavValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Errorf("expected suppressed nav to be valid, got %v", result.Errors)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Details[rules.DetailSuppressedBy] != DirectiveIgnoreFile {
+		t.Errorf("expected file-scoped suppression, got %v", result.Suppressed)
+	}
+}
+
+func TestOPFValidator_InlineSuppression(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Test Book</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <!-- ebm-ignore EPUB-OPF-010 legacy font -->
+    <item id="font" href="font.otf" media-type=""/>
+    <item id="image" href="cover.jpg" media-type=""/>
+  </manifest>
+  <spine>
+    <itemref idref="nav"/>
+  </spine>
+</package>`
+
+	result, err := NewOPFValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Details["item_index"] != 1 {
+		t.Fatalf("expected the font item finding suppressed, got %v", result.Suppressed)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Details["item_index"] != 2 {
+		t.Errorf("expected only the image item finding to remain, got %v", result.Errors)
+	}
+	if result.Valid {
+		t.Error("expected OPF to remain invalid")
+	}
+}
+
+func TestContentValidator_InlineSuppression(t *testing.T) {
+	content := `<!DOCTYPE html>
+<!-- ebm-ignore EPUB-CONTENT-007 -->
+<html>
+<head><title>Legacy</title></head>
+<body><p>Text</p></body>
+</html>`
+
+	result, err := NewContentValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Errorf("expected namespace error suppressed, got %v", result.Errors)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Code != ErrorCodeContentInvalidNamespace {
+		t.Errorf("unexpected suppressed findings %v", result.Suppressed)
+	}
+}
+
+func TestContentValidator_InlineSuppressionOfDoctype(t *testing.T) {
+	content := `<!DOCTYPE html PUBLIC "-//W3C//DTD HTML 4.01//EN">
+<!-- ebm-ignore EPUB-CONTENT-003 converted from HTML 4 -->
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>Legacy</title></head>
+<body><p>Text</p></body>
+</html>`
+
+	result, err := NewContentValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Errorf("expected doctype error suppressed, got %v", result.Errors)
+	}
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Code != ErrorCodeContentInvalidDoctype {
+		t.Errorf("unexpected suppressed findings %v", result.Suppressed)
+	}
+}
+
+func TestNavValidator_InlineSuppression(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="chapter1.xhtml">Chapter 1</a></li>
+      <!-- ebm-ignore EPUB-NAV-004 publisher site -->
+      <li><a href="https://example.com/extra">Extra</a></li>
+      <li><a href="https://example.com/more">More</a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	result, err := NewNavValidator().ValidateBytes([]byte(content))
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+
+	if len(result.Suppressed) != 1 || result.Suppressed[0].Details["href"] != "https://example.com/extra" {
+		t.Errorf("expected the first external link suppressed, got %v", result.Suppressed)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Details["href"] != "https://example.com/more" {
+		t.Errorf("expected only the second external link to remain, got %v", result.Errors)
+	}
+}
+
+func TestEPUBValidator_InlineSuppressionReportedAsInfo(t *testing.T) {
+	opfContent := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Test Book</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`
+
+	navContent := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="chapter1.xhtml">Chapter 1</a></li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+	chapter1Content := `<!DOCTYPE html>
+<!-- ebm-ignore-file EPUB-CONTENT-007 converted from EPUB 2 -->
+<html>
+<head><title>Chapter 1</title></head>
+<body><h1>Chapter 1</h1></body>
+</html>`
+
+	data := buildEPUBWithOPFAndFiles(t, opfContent, navContent, []testFile{
+		{path: "OEBPS/chapter1.xhtml", content: chapter1Content},
+	})
+
+	report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+
+	if !report.IsValid {
+		t.Errorf("expected valid report, got errors %v", report.Errors)
+	}
+
+	var info *domain.ValidationError
+	for i := range report.Info {
+		if report.Info[i].Code == ErrorCodeContentInvalidNamespace {
+			info = &report.Info[i]
+		}
+	}
+	if info == nil {
+		t.Fatalf("expected suppressed finding in report.Info, got %v", report.Info)
+	}
+	if info.Severity != domain.SeverityInfo || info.Location.Path != "OEBPS/chapter1.xhtml" {
+		t.Errorf("unexpected info finding %+v", info)
+	}
+	if info.Details["manifest_id"] != "chapter1" || info.Details["original_severity"] != "error" {
+		t.Errorf("unexpected info details %v", info.Details)
+	}
+}
+
+func hasFinding(findings []ValidationError, code string) bool {
+	for _, finding := range findings {
+		if finding.Code == code {
+			return true
+		}
+	}
+	return false
+}
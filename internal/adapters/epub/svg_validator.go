@@ -0,0 +1,370 @@
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SVG validation error codes.
+const (
+	ErrorCodeSVGNotWellFormed        = "EPUB-SVG-001"
+	ErrorCodeSVGInvalidNamespace     = "EPUB-SVG-002"
+	ErrorCodeSVGMissingViewBox       = "EPUB-SVG-003"
+	ErrorCodeSVGExternalReference    = "EPUB-SVG-004"
+	ErrorCodeSVGInvalidForeignObject = "EPUB-SVG-005"
+	ErrorCodeSVGMissingTitle         = "EPUB-SVG-006"
+	ErrorCodeSVGUndeclaredScript     = "EPUB-SVG-007"
+	ErrorCodeSVGUndeclaredProperty   = "EPUB-SVG-008"
+)
+
+const (
+	SVGNamespace   = "http://www.w3.org/2000/svg"
+	XLinkNamespace = "http://www.w3.org/1999/xlink"
+
+	// foreignObjectExtension is the only requiredExtensions value EPUB
+	// allows on foreignObject.
+	foreignObjectExtension = "http://www.idpf.org/2007/ops"
+)
+
+// SVGDocumentContext carries what the package declares about a document that
+// contains SVG.
+type SVGDocumentContext struct {
+	// BaseDir is the archive directory of the document, used to resolve
+	// references.
+	BaseDir string
+	// FixedLayout is set when the document is rendered pre-paginated.
+	FixedLayout bool
+	// Properties is the manifest item properties attribute, e.g. "scripted svg".
+	Properties string
+}
+
+// SVGValidationResult contains the SVG findings for a single document.
+type SVGValidationResult struct {
+	Valid      bool
+	Errors     []ValidationError
+	Warnings   []ValidationError
+	Suppressed []ValidationError
+	// SVGCount is the number of outermost svg elements found.
+	SVGCount int
+}
+
+// SVGValidator checks SVG content documents and SVG embedded in XHTML content
+// documents. Documents are read as XML so that namespaces are resolved the way
+// reading systems resolve them.
+type SVGValidator struct{}
+
+// NewSVGValidator returns a new SVG validator.
+func NewSVGValidator() *SVGValidator {
+	return &SVGValidator{}
+}
+
+// Validate validates an SVG content document from an io.Reader.
+func (v *SVGValidator) Validate(reader io.Reader, ctx SVGDocumentContext) (*SVGValidationResult, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SVG document: %w", err)
+	}
+	return v.ValidateBytes(data, ctx)
+}
+
+// ValidateBytes validates an SVG content document from in-memory data.
+func (v *SVGValidator) ValidateBytes(data []byte, ctx SVGDocumentContext) (*SVGValidationResult, error) {
+	return v.scan(data, ctx, true), nil
+}
+
+// ValidateEmbeddedBytes validates the svg elements embedded in an XHTML
+// content document. Documents without SVG produce an empty result.
+func (v *SVGValidator) ValidateEmbeddedBytes(data []byte, ctx SVGDocumentContext) (*SVGValidationResult, error) {
+	return v.scan(data, ctx, false), nil
+}
+
+// svgScan is the state of one pass over a document.
+type svgScan struct {
+	ctx        SVGDocumentContext
+	standalone bool
+	result     *SVGValidationResult
+
+	// svgDepth and foreignDepth are the stack depths of the current
+	// outermost svg and foreignObject elements, or 0 outside them.
+	svgDepth     int
+	foreignDepth int
+	rootPath     string
+	rootSpace    string
+	hasTitle     bool
+	labelled     bool
+
+	scriptReported bool
+}
+
+func (v *SVGValidator) scan(data []byte, ctx SVGDocumentContext, standalone bool) *SVGValidationResult {
+	result := &SVGValidationResult{
+		Valid:    true,
+		Errors:   make([]ValidationError, 0),
+		Warnings: make([]ValidationError, 0),
+	}
+	s := &svgScan{ctx: ctx, standalone: standalone, result: result}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	if !standalone {
+		// Well-formedness of XHTML is the content validator's concern;
+		// read leniently so that SVG in sloppy markup is still checked.
+		decoder.Strict = false
+		decoder.AutoClose = xml.HTMLAutoClose
+		decoder.Entity = xml.HTMLEntity
+	}
+
+	type frame struct {
+		path   string
+		counts map[string]int
+	}
+	stack := []frame{{counts: make(map[string]int)}}
+	sawRoot := false
+
+scan:
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if standalone && !errors.Is(err, io.EOF) {
+				s.addError(ErrorCodeSVGNotWellFormed,
+					fmt.Sprintf("SVG document is not well-formed XML: %s", err.Error()),
+					"", nil)
+			}
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			parent := &stack[len(stack)-1]
+			index := parent.counts[t.Name.Local]
+			parent.counts[t.Name.Local]++
+			elementPath := xmlElementPath(parent.path, t.Name.Local, index)
+			depth := len(stack)
+			stack = append(stack, frame{path: elementPath, counts: make(map[string]int)})
+
+			switch {
+			case s.foreignDepth > 0:
+				if depth == s.foreignDepth+1 && t.Name.Space != XHTMLNamespace {
+					s.addError(ErrorCodeSVGInvalidForeignObject,
+						fmt.Sprintf("foreignObject contains <%s>, which is not in the XHTML namespace", t.Name.Local),
+						elementPath, map[string]interface{}{
+							"element":   t.Name.Local,
+							"namespace": t.Name.Space,
+						})
+				}
+			case s.svgDepth == 0:
+				if standalone && sawRoot {
+					continue
+				}
+				sawRoot = true
+				if !standalone && t.Name.Local != "svg" {
+					continue
+				}
+				if !s.startRoot(t, elementPath, depth) {
+					break scan
+				}
+			default:
+				if depth == s.svgDepth+1 && t.Name.Local == "title" && t.Name.Space == s.rootSpace {
+					s.hasTitle = true
+				}
+				s.checkElement(t, elementPath)
+				if t.Name.Local == "foreignObject" {
+					s.checkForeignObject(t, elementPath)
+					s.foreignDepth = depth
+				}
+			}
+		case xml.EndElement:
+			depth := len(stack) - 1
+			if depth == s.foreignDepth {
+				s.foreignDepth = 0
+			}
+			if depth == s.svgDepth {
+				s.endRoot()
+			}
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if standalone && !sawRoot && len(result.Errors) == 0 {
+		s.addError(ErrorCodeSVGNotWellFormed, "SVG document contains no root element", "", nil)
+	}
+
+	suppressions := collectXMLSuppressions(data)
+	var suppressed []ValidationError
+	result.Errors, suppressed = suppressions.partition(result.Errors, "error")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+	result.Warnings, suppressed = suppressions.partition(result.Warnings, "warning")
+	result.Suppressed = append(result.Suppressed, suppressed...)
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// startRoot checks an outermost svg element. It reports false when a
+// standalone document is not SVG at all, which ends the scan.
+func (s *svgScan) startRoot(t xml.StartElement, elementPath string, depth int) bool {
+	if t.Name.Local != "svg" || t.Name.Space != SVGNamespace {
+		message := fmt.Sprintf("svg element is not in the SVG namespace (%s)", SVGNamespace)
+		if t.Name.Local != "svg" {
+			message = fmt.Sprintf("SVG document root element is <%s>, expected <svg>", t.Name.Local)
+		}
+		s.addError(ErrorCodeSVGInvalidNamespace, message, elementPath, map[string]interface{}{
+			"element":   t.Name.Local,
+			"namespace": t.Name.Space,
+		})
+		if t.Name.Local != "svg" {
+			return false
+		}
+	}
+
+	s.result.SVGCount++
+	if !s.standalone && s.result.SVGCount == 1 && !hasPropertyToken(s.ctx.Properties, "svg") {
+		s.addError(ErrorCodeSVGUndeclaredProperty,
+			"Content document embeds SVG but its manifest item does not declare the svg property",
+			elementPath, map[string]interface{}{
+				"properties": s.ctx.Properties,
+			})
+	}
+	if s.standalone && s.ctx.FixedLayout && strings.TrimSpace(attrValue(t, "", "viewBox")) == "" {
+		s.addError(ErrorCodeSVGMissingViewBox,
+			"Fixed-layout SVG content document has no viewBox on its root svg element",
+			elementPath, nil)
+	}
+
+	s.svgDepth = depth
+	s.rootPath = elementPath
+	s.rootSpace = t.Name.Space
+	s.hasTitle = false
+	s.labelled = strings.TrimSpace(attrValue(t, "", "aria-label")) != "" ||
+		strings.TrimSpace(attrValue(t, "", "aria-labelledby")) != "" ||
+		attrValue(t, "", "aria-hidden") == "true"
+	switch attrValue(t, "", "role") {
+	case "presentation", "none":
+		s.labelled = true
+	}
+
+	s.checkElement(t, elementPath)
+	return true
+}
+
+// endRoot reports an outermost svg element that has no accessible name.
+// Decorative graphics marked aria-hidden or role="presentation" need none.
+func (s *svgScan) endRoot() {
+	if !s.hasTitle && !s.labelled {
+		s.addWarning(ErrorCodeSVGMissingTitle,
+			"svg element has no <title> child; assistive technology cannot name the graphic",
+			s.rootPath, nil)
+	}
+	s.svgDepth = 0
+}
+
+// checkElement checks the references and scripting of an element inside an
+// svg element.
+func (s *svgScan) checkElement(t xml.StartElement, elementPath string) {
+	scripted := t.Name.Local == "script"
+	for _, attr := range t.Attr {
+		switch {
+		case attr.Name.Local == "href" && (attr.Name.Space == "" || attr.Name.Space == XLinkNamespace || attr.Name.Space == "xlink"):
+			s.checkReference(t, attr.Value, elementPath)
+		case attr.Name.Space == "" && len(attr.Name.Local) > 2 && strings.HasPrefix(strings.ToLower(attr.Name.Local), "on"):
+			scripted = true
+		}
+	}
+
+	if scripted && !s.scriptReported && !hasPropertyToken(s.ctx.Properties, "scripted") {
+		s.scriptReported = true
+		s.addError(ErrorCodeSVGUndeclaredScript,
+			"SVG contains script but the manifest item does not declare the scripted property",
+			elementPath, map[string]interface{}{
+				"element":    t.Name.Local,
+				"properties": s.ctx.Properties,
+			})
+	}
+}
+
+// checkReference reports references that leave the container. Remote
+// resources may not be embedded, though hyperlinks may point anywhere.
+func (s *svgScan) checkReference(t xml.StartElement, href, elementPath string) {
+	target := resolveHref(s.ctx.BaseDir, href)
+	switch {
+	case target.External:
+		if t.Name.Local == "a" || strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "data:") {
+			return
+		}
+		s.addError(ErrorCodeSVGExternalReference,
+			fmt.Sprintf("SVG <%s> references a remote resource: %s", t.Name.Local, href),
+			elementPath, map[string]interface{}{
+				"element": t.Name.Local,
+				"href":    href,
+			})
+	case target.Escapes:
+		s.addError(ErrorCodeSVGExternalReference,
+			fmt.Sprintf("SVG <%s> references a path outside the container: %s", t.Name.Local, href),
+			elementPath, map[string]interface{}{
+				"element": t.Name.Local,
+				"href":    href,
+			})
+	}
+}
+
+// checkForeignObject checks the requiredExtensions attribute of a
+// foreignObject element.
+func (s *svgScan) checkForeignObject(t xml.StartElement, elementPath string) {
+	for _, attr := range t.Attr {
+		if attr.Name.Local != "requiredExtensions" || attr.Name.Space != "" {
+			continue
+		}
+		if strings.TrimSpace(attr.Value) != foreignObjectExtension {
+			s.addError(ErrorCodeSVGInvalidForeignObject,
+				fmt.Sprintf("foreignObject requiredExtensions must be %s", foreignObjectExtension),
+				elementPath, map[string]interface{}{
+					"required_extensions": attr.Value,
+				})
+		}
+	}
+}
+
+func (s *svgScan) addError(code, message, elementPath string, details map[string]interface{}) {
+	s.result.Errors = append(s.result.Errors, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+		element: elementPath,
+	})
+}
+
+func (s *svgScan) addWarning(code, message, elementPath string, details map[string]interface{}) {
+	s.result.Warnings = append(s.result.Warnings, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+		element: elementPath,
+	})
+}
+
+// attrValue returns the value of the attribute with the given namespace and
+// local name, or "".
+func attrValue(t xml.StartElement, space, local string) string {
+	for _, attr := range t.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// hasPropertyToken reports whether a space-separated properties attribute
+// contains token.
+func hasPropertyToken(properties, token string) bool {
+	for _, property := range strings.Fields(properties) {
+		if property == token {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func svgCodes(findings []ValidationError) []string {
+	codes := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSVGValidator_Document(t *testing.T) {
+	tests := []struct {
+		name         string
+		svg          string
+		ctx          SVGDocumentContext
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "valid document",
+			svg: `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 600 800">
+  <title>Page 1</title>
+  <image width="600" height="800" xlink:href="images/page1.jpg"/>
+</svg>`,
+			ctx: SVGDocumentContext{BaseDir: "OEBPS", FixedLayout: true},
+		},
+		{
+			name:       "not well-formed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><title>Broken</title><g></svg>`,
+			wantErrors: []string{ErrorCodeSVGNotWellFormed},
+		},
+		{
+			name:       "missing namespace",
+			svg:        `<svg viewBox="0 0 10 10"><title>No namespace</title></svg>`,
+			wantErrors: []string{ErrorCodeSVGInvalidNamespace},
+		},
+		{
+			name:       "root is not svg",
+			svg:        `<html xmlns="http://www.w3.org/1999/xhtml"><body/></html>`,
+			wantErrors: []string{ErrorCodeSVGInvalidNamespace},
+		},
+		{
+			name:       "fixed layout without viewBox",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg" width="600" height="800"><title>Page</title></svg>`,
+			ctx:        SVGDocumentContext{FixedLayout: true},
+			wantErrors: []string{ErrorCodeSVGMissingViewBox},
+		},
+		{
+			name: "reflowable without viewBox",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg" width="600" height="800"><title>Page</title></svg>`,
+		},
+		{
+			name: "remote image",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Remote</title>
+  <image href="https://example.com/page.jpg" width="10" height="10"/>
+</svg>`,
+			wantErrors: []string{ErrorCodeSVGExternalReference},
+		},
+		{
+			name: "reference escaping the container",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10"><title>Escape</title>
+  <use xlink:href="../../shapes.svg#star"/>
+</svg>`,
+			ctx:        SVGDocumentContext{BaseDir: "OEBPS"},
+			wantErrors: []string{ErrorCodeSVGExternalReference},
+		},
+		{
+			name: "remote hyperlink and data URI allowed",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Links</title>
+  <a href="https://example.com/"><text>Visit</text></a>
+  <image href="data:image/png;base64,iVBORw0KGgo=" width="1" height="1"/>
+</svg>`,
+		},
+		{
+			name: "foreignObject with XHTML content",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Caption</title>
+  <foreignObject width="10" height="10" requiredExtensions="http://www.idpf.org/2007/ops">
+    <p xmlns="http://www.w3.org/1999/xhtml">Once upon a time</p>
+  </foreignObject>
+</svg>`,
+		},
+		{
+			name: "foreignObject misuse",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Caption</title>
+  <foreignObject width="10" height="10" requiredExtensions="http://example.com/ext">
+    <p>Once upon a time</p>
+  </foreignObject>
+</svg>`,
+			wantErrors: []string{ErrorCodeSVGInvalidForeignObject, ErrorCodeSVGInvalidForeignObject},
+		},
+		{
+			name:         "missing title",
+			svg:          `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><desc>A red circle</desc><circle r="5"/></svg>`,
+			wantWarnings: []string{ErrorCodeSVGMissingTitle},
+		},
+		{
+			name: "nested title does not name the graphic",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">
+  <g><title>Group</title><circle r="5"/></g>
+</svg>`,
+			wantWarnings: []string{ErrorCodeSVGMissingTitle},
+		},
+		{
+			name: "aria-label names the graphic",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10" aria-label="A red circle"><circle r="5"/></svg>`,
+		},
+		{
+			name: "script without scripted property",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Script</title>
+  <script>document.title = "x";</script>
+  <circle r="5" onclick="alert(1)"/>
+</svg>`,
+			wantErrors: []string{ErrorCodeSVGUndeclaredScript},
+		},
+		{
+			name: "script with scripted property",
+			svg: `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Script</title>
+  <circle r="5" onclick="alert(1)"/>
+</svg>`,
+			ctx: SVGDocumentContext{Properties: "scripted"},
+		},
+	}
+
+	validator := NewSVGValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes([]byte(tt.svg), tt.ctx)
+			if err != nil {
+				t.Fatalf("ValidateBytes() error = %v", err)
+			}
+
+			errors, warnings := svgCodes(result.Errors), svgCodes(result.Warnings)
+			if len(errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %v, want %v", errors, tt.wantErrors)
+			}
+			for i, code := range tt.wantErrors {
+				if errors[i] != code {
+					t.Errorf("errors[%d] = %s, want %s", i, errors[i], code)
+				}
+			}
+			if len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+			if result.Valid != (len(tt.wantErrors) == 0) {
+				t.Errorf("Valid = %v", result.Valid)
+			}
+		})
+	}
+}
+
+func TestSVGValidator_Embedded(t *testing.T) {
+	document := func(svg string) []byte {
+		return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter</title></head>
+<body><p>Before&nbsp;the picture<br></p>` + svg + `</body>
+</html>`)
+	}
+
+	validator := NewSVGValidator()
+
+	result, err := validator.ValidateEmbeddedBytes(document(""), SVGDocumentContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SVGCount != 0 || len(result.Errors) != 0 || len(result.Warnings) != 0 {
+		t.Errorf("expected no findings for a document without SVG, got %+v", result)
+	}
+
+	valid := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><title>Circle</title><circle r="5"/></svg>`
+	result, _ = validator.ValidateEmbeddedBytes(document(valid), SVGDocumentContext{Properties: "svg"})
+	if result.SVGCount != 1 || !result.Valid || len(result.Warnings) != 0 {
+		t.Errorf("expected valid embedded SVG, got errors %v warnings %v", result.Errors, result.Warnings)
+	}
+
+	result, _ = validator.ValidateEmbeddedBytes(document(valid+valid), SVGDocumentContext{})
+	if codes := svgCodes(result.Errors); len(codes) != 1 || codes[0] != ErrorCodeSVGUndeclaredProperty {
+		t.Errorf("expected one missing svg property error, got %v", codes)
+	}
+
+	// Without its own namespace declaration an svg element inherits the XHTML
+	// namespace and is not rendered as SVG.
+	result, _ = validator.ValidateEmbeddedBytes(document(`<svg viewBox="0 0 10 10"><title>Circle</title></svg>`), SVGDocumentContext{Properties: "svg"})
+	if codes := svgCodes(result.Errors); !containsCode(codes, ErrorCodeSVGInvalidNamespace) {
+		t.Errorf("expected namespace error, got %v", codes)
+	}
+
+	// Inline SVG has no viewBox requirement, even in fixed layouts.
+	result, _ = validator.ValidateEmbeddedBytes(document(`<svg xmlns="http://www.w3.org/2000/svg" aria-hidden="true"><script>run()</script></svg>`),
+		SVGDocumentContext{Properties: "svg", FixedLayout: true})
+	if codes := svgCodes(result.Errors); len(codes) != 1 || codes[0] != ErrorCodeSVGUndeclaredScript {
+		t.Errorf("expected only the undeclared script error, got %v", codes)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("decorative SVG needs no title, got %v", result.Warnings)
+	}
+}
+
+func TestSVGValidator_Suppression(t *testing.T) {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">
+  <title>Suppressed</title>
+  <!-- ebm-ignore EPUB-SVG-004 hosted by the reading app -->
+  <image href="https://example.com/page.jpg" width="10" height="10"/>
+  <image href="https://example.com/other.jpg" width="10" height="10"/>
+</svg>`
+
+	result, err := NewSVGValidator().ValidateBytes([]byte(svg), SVGDocumentContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 1 || len(result.Suppressed) != 1 {
+		t.Fatalf("expected one reported and one suppressed error, got %v / %v", result.Errors, result.Suppressed)
+	}
+	if result.Errors[0].Details["href"] != "https://example.com/other.jpg" {
+		t.Errorf("wrong image suppressed: %v", result.Errors[0].Details)
+	}
+}
+
+func TestEPUBValidator_SVG(t *testing.T) {
+	opf := `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>SVG Test</dc:title>
+    <dc:identifier id="book-id">urn:isbn:123456789</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="page1" href="page1.svg" media-type="image/svg+xml"/>
+    <item id="ch1" href="ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="page1" properties="rendition:layout-pre-paginated"/>
+    <itemref idref="ch1"/>
+  </spine>
+</package>`
+
+	nav := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>Contents</h1>
+    <ol><li><a href="ch1.xhtml">Chapter 1</a></li></ol>
+  </nav>
+</body>
+</html>`
+
+	page := `<svg xmlns="http://www.w3.org/2000/svg" width="600" height="800"><title>Page 1</title></svg>`
+	chapter := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter 1</title></head>
+<body><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><circle r="5"/></svg></body>
+</html>`
+
+	data := buildEPUBWithOPFAndFiles(t, opf, nav, []testFile{
+		{path: "OEBPS/page1.svg", content: page},
+		{path: "OEBPS/ch1.xhtml", content: chapter},
+	})
+
+	report, err := NewEPUBValidator().ValidateReader(context.Background(), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, finding := range append(report.Errors, report.Warnings...) {
+		if finding.Location != nil {
+			found[finding.Code] = finding.Location.Path
+		}
+	}
+	for code, want := range map[string]string{
+		ErrorCodeSVGMissingViewBox:     "OEBPS/page1.svg",
+		ErrorCodeSVGUndeclaredProperty: "OEBPS/ch1.xhtml",
+		ErrorCodeSVGMissingTitle:       "OEBPS/ch1.xhtml",
+	} {
+		if found[code] != want {
+			t.Errorf("expected %s at %s, got %q (findings: %v)", code, want, found[code], found)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package pdf
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Active content and privacy error codes.
+const (
+	ErrorCodePDFSafety001 = "PDF-SAFETY-001"
+	ErrorCodePDFSafety002 = "PDF-SAFETY-002"
+	ErrorCodePDFSafety003 = "PDF-SAFETY-003"
+	ErrorCodePDFSafety004 = "PDF-SAFETY-004"
+	ErrorCodePDFSafety005 = "PDF-SAFETY-005"
+	ErrorCodePDFSafety006 = "PDF-SAFETY-006"
+	ErrorCodePDFSafety007 = "PDF-SAFETY-007"
+	ErrorCodePDFSafety008 = "PDF-SAFETY-008"
+)
+
+// CategorySafety is the Details["category"] of active content and privacy
+// findings, for use with reporter.Filter.Categories.
+const CategorySafety = "safety"
+
+// maxScriptExcerpt caps the JavaScript source quoted in a finding.
+const maxScriptExcerpt = 80
+
+// executableExtensions are embedded file extensions that run code when
+// opened.
+var executableExtensions = map[string]bool{
+	".exe": true, ".com": true, ".bat": true, ".cmd": true, ".scr": true,
+	".pif": true, ".msi": true, ".dll": true, ".vbs": true, ".vbe": true,
+	".js": true, ".jse": true, ".wsf": true, ".ps1": true, ".sh": true,
+	".jar": true, ".app": true, ".hta": true, ".lnk": true,
+}
+
+// executableMediaTypes are embedded file types that run code when opened.
+var executableMediaTypes = map[string]bool{
+	"application/x-msdownload":                      true,
+	"application/x-msdos-program":                   true,
+	"application/x-executable":                      true,
+	"application/x-sh":                              true,
+	"application/java-archive":                      true,
+	"application/javascript":                        true,
+	"application/x-ms-installer":                    true,
+	"application/vnd.microsoft.portable-executable": true,
+}
+
+// actionTrigger is the context in which an action dictionary was found.
+type actionTrigger struct {
+	// name describes the trigger, such as OpenAction or AA/O.
+	name string
+	// automatic is set for actions that run without the reader clicking a
+	// link, such as /OpenAction, /AA and document-level JavaScript.
+	automatic bool
+}
+
+// activeContentScan walks the objects reachable from the catalog.
+type activeContentScan struct {
+	result  *StructureValidationResult
+	visited map[core.PdfObject]bool
+	hosts   map[string]int
+	schemes map[string]int
+	links   int
+}
+
+// validateActiveContent reports JavaScript, automatic and external
+// actions, embedded files and RichMedia reachable from the catalog. Every
+// finding has the safety category.
+func (v *StructureValidator) validateActiveContent(parser *core.PdfParser, result *StructureValidationResult) {
+	trailer := parser.GetTrailer()
+	if trailer == nil {
+		return
+	}
+	scan := &activeContentScan{
+		result:  result,
+		visited: make(map[core.PdfObject]bool),
+		hosts:   make(map[string]int),
+		schemes: make(map[string]int),
+	}
+	scan.walk(trailer.Get("Root"), 0, actionTrigger{})
+
+	if scan.links == 0 {
+		return
+	}
+	details := map[string]interface{}{
+		"category":    CategorySafety,
+		"hosts":       sortedKeys(scan.hosts),
+		"host_counts": scan.hosts,
+		"links":       scan.links,
+	}
+	message := fmt.Sprintf("Document links to %d external host(s)", len(scan.hosts))
+	if len(scan.schemes) > 0 {
+		// Links without a host, such as mailto: links, are counted by scheme.
+		details["schemes"] = sortedKeys(scan.schemes)
+		details["scheme_counts"] = scan.schemes
+		message += fmt.Sprintf(" and has links with %d other scheme(s)", len(scan.schemes))
+	}
+	result.Info = append(result.Info, ValidationError{
+		Code:    ErrorCodePDFSafety008,
+		Message: message,
+		Details: details,
+	})
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// walk visits obj and everything it references. objectNumber is the
+// nearest enclosing indirect object.
+func (s *activeContentScan) walk(obj core.PdfObject, objectNumber int64, trigger actionTrigger) {
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		objectNumber = ref.ObjectNumber
+	}
+	direct := core.TraceToDirectObject(obj)
+	if direct == nil || s.visited[direct] {
+		return
+	}
+
+	switch value := direct.(type) {
+	case *core.PdfObjectArray:
+		s.visited[direct] = true
+		for _, element := range value.Elements() {
+			s.walk(element, objectNumber, trigger)
+		}
+	case *core.PdfObjectStream:
+		s.visited[direct] = true
+		s.walkDict(value.PdfObjectDictionary, objectNumber, trigger)
+	case *core.PdfObjectDictionary:
+		s.visited[direct] = true
+		s.walkDict(value, objectNumber, trigger)
+	}
+}
+
+// walkDict checks a dictionary and walks its entries.
+func (s *activeContentScan) walkDict(dict *core.PdfObjectDictionary, objectNumber int64, trigger actionTrigger) {
+	if dict == nil {
+		return
+	}
+	if _, ok := core.GetNameVal(core.TraceToDirectObject(dict.Get("S"))); ok || dict.Get("JS") != nil {
+		s.checkAction(dict, objectNumber, trigger)
+	}
+	if dict.Get("EF") != nil {
+		s.checkEmbeddedFile(dict, objectNumber)
+	}
+	if subtype, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("Subtype"))); subtype == "RichMedia" {
+		s.report(true, ErrorCodePDFSafety007, "Document contains a RichMedia annotation, which can embed Flash or video that plays in the reader", objectNumber, nil)
+	}
+
+	for _, key := range dict.Keys() {
+		child := trigger
+		switch key {
+		case "Parent", "P":
+			// Back links to the page tree were or will be visited anyway.
+			continue
+		case "OpenAction":
+			child = actionTrigger{name: "OpenAction", automatic: true}
+		case "AA":
+			if actions, ok := core.GetDict(core.TraceToDirectObject(dict.Get(key))); ok {
+				for _, event := range actions.Keys() {
+					s.walk(actions.Get(event), objectNumber, actionTrigger{name: "AA/" + string(event), automatic: true})
+				}
+			}
+			continue
+		case "JavaScript":
+			child = actionTrigger{name: "Names/JavaScript", automatic: true}
+		case "A":
+			child = actionTrigger{name: "A"}
+		case "Next", "Names", "Kids":
+			// Action chains and name tree nodes keep their trigger.
+		default:
+			child = actionTrigger{}
+		}
+		s.walk(dict.Get(key), objectNumber, child)
+	}
+}
+
+// checkAction reports an action dictionary by type.
+func (s *activeContentScan) checkAction(dict *core.PdfObjectDictionary, objectNumber int64, trigger actionTrigger) {
+	actionType, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("S")))
+	details := map[string]interface{}{
+		"action":    actionType,
+		"automatic": trigger.automatic,
+	}
+	if trigger.name != "" {
+		details["trigger"] = trigger.name
+	}
+
+	switch {
+	case actionType == "JavaScript" || dict.Get("JS") != nil:
+		if script := javaScriptSource(dict.Get("JS")); script != "" {
+			if len(script) > maxScriptExcerpt {
+				script = script[:maxScriptExcerpt] + "..."
+			}
+			details["script"] = script
+		}
+		s.report(false, ErrorCodePDFSafety001, "Document contains JavaScript", objectNumber, details)
+	case actionType == "Launch":
+		if file := fileSpecName(dict.Get("F")); file != "" {
+			details["file"] = file
+		}
+		s.report(false, ErrorCodePDFSafety003, "Document contains a Launch action, which can start an external program", objectNumber, details)
+	case actionType == "SubmitForm":
+		target := fileSpecName(dict.Get("F"))
+		details["url"] = target
+		details["host"] = linkHost(target)
+		s.report(true, ErrorCodePDFSafety004, fmt.Sprintf("Document submits form data to %s", linkHost(target)), objectNumber, details)
+	case actionType == "GoToR" || actionType == "GoToE":
+		if file := fileSpecName(dict.Get("F")); file != "" {
+			details["file"] = file
+		}
+		s.report(true, ErrorCodePDFSafety005, fmt.Sprintf("Document contains a %s action, which opens another document", actionType), objectNumber, details)
+	case actionType == "URI" && !trigger.automatic:
+		target, _ := core.GetStringVal(core.TraceToDirectObject(dict.Get("URI")))
+		if host := urlHost(target); host != "" {
+			s.hosts[host]++
+		} else {
+			s.schemes[linkScheme(target)]++
+		}
+		s.links++
+	case actionType == "URI":
+		target, _ := core.GetStringVal(core.TraceToDirectObject(dict.Get("URI")))
+		details["url"] = target
+		details["host"] = linkHost(target)
+		s.report(true, ErrorCodePDFSafety002, fmt.Sprintf("Document opens %s automatically from %s", linkHost(target), trigger.name), objectNumber, details)
+	case trigger.automatic && actionType != "GoTo":
+		s.report(true, ErrorCodePDFSafety002, fmt.Sprintf("Document runs a %s action automatically from %s", actionType, trigger.name), objectNumber, details)
+	}
+}
+
+// checkEmbeddedFile reports a file specification with an embedded file.
+func (s *activeContentScan) checkEmbeddedFile(spec *core.PdfObjectDictionary, objectNumber int64) {
+	name := fileSpecName(spec)
+	mediaType := ""
+	if files, ok := core.GetDict(core.TraceToDirectObject(spec.Get("EF"))); ok {
+		for _, key := range []core.PdfObjectName{"UF", "F"} {
+			if stream, ok := core.GetStream(core.TraceToDirectObject(files.Get(key))); ok {
+				mediaType, _ = core.GetNameVal(core.TraceToDirectObject(stream.Get("Subtype")))
+				break
+			}
+		}
+	}
+
+	details := map[string]interface{}{
+		"file":       name,
+		"media_type": mediaType,
+	}
+	executable := executableExtensions[strings.ToLower(path.Ext(name))] || executableMediaTypes[strings.ToLower(mediaType)]
+	if executable {
+		s.report(false, ErrorCodePDFSafety006, fmt.Sprintf("Document embeds executable file %s", name), objectNumber, details)
+		return
+	}
+	s.report(true, ErrorCodePDFSafety006, fmt.Sprintf("Document embeds file %s", name), objectNumber, details)
+}
+
+// report adds a safety finding.
+func (s *activeContentScan) report(warning bool, code, message string, objectNumber int64, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["category"] = CategorySafety
+	if objectNumber != 0 {
+		details["object"] = objectNumber
+	}
+	finding := ValidationError{Code: code, Message: message, Details: details}
+	if warning {
+		s.result.Warnings = append(s.result.Warnings, finding)
+	} else {
+		s.result.Errors = append(s.result.Errors, finding)
+	}
+}
+
+// javaScriptSource returns the /JS script, stored as a string or a stream.
+func javaScriptSource(obj core.PdfObject) string {
+	switch value := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectString:
+		return value.Decoded()
+	case *core.PdfObjectStream:
+		if decoded, err := core.DecodeStream(value); err == nil {
+			return string(decoded)
+		}
+	}
+	return ""
+}
+
+// fileSpecName returns the file name of a file specification, given as a
+// string or a dictionary.
+func fileSpecName(obj core.PdfObject) string {
+	switch value := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectString:
+		return value.Decoded()
+	case *core.PdfObjectDictionary:
+		for _, key := range []core.PdfObjectName{"UF", "F", "Unix", "DOS", "Mac"} {
+			if name, ok := core.GetString(core.TraceToDirectObject(value.Get(key))); ok {
+				return name.Decoded()
+			}
+		}
+	}
+	return ""
+}
+
+// linkHost returns the lowercase host of a URL, or its scheme for URLs
+// without one, such as mailto links.
+func linkHost(target string) string {
+	if host := urlHost(target); host != "" {
+		return host
+	}
+	switch scheme := linkScheme(target); scheme {
+	case "relative", "invalid":
+		return scheme + " URL"
+	default:
+		return scheme + ":"
+	}
+}
+
+// urlHost returns the lowercase host of a URL, or "" when it has none.
+func urlHost(target string) string {
+	parsed, err := url.Parse(strings.TrimSpace(target))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// linkScheme returns the lowercase scheme of a URL, "relative" for URLs
+// without one and "invalid" for URLs that do not parse.
+func linkScheme(target string) string {
+	parsed, err := url.Parse(strings.TrimSpace(target))
+	if err != nil {
+		return "invalid"
+	}
+	if parsed.Scheme == "" {
+		return "relative"
+	}
+	return strings.ToLower(parsed.Scheme)
+}
@@ -0,0 +1,154 @@
+package pdf
+
+import (
+	"testing"
+)
+
+// annotatedPDF builds a one-page PDF with extra catalog entries and page
+// annotations. Extra objects are numbered from 4.
+func annotatedPDF(catalog, annots string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R " + catalog + " >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Annots [" + annots + "] >>",
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+func TestStructureValidator_ActiveContent(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErrors   []string
+		wantWarnings []string
+		wantInfo     []string
+	}{
+		{
+			name: "no active content",
+			data: annotatedPDF("/OpenAction [3 0 R /Fit]", ""),
+		},
+		{
+			name:       "document-level JavaScript",
+			data:       annotatedPDF("/Names << /JavaScript 4 0 R >>", "", "<< /Names [(init) << /S /JavaScript /JS (app.alert\\('hi'\\)) >>] >>"),
+			wantErrors: []string{ErrorCodePDFSafety001},
+		},
+		{
+			name:       "JavaScript stream in open action",
+			data:       annotatedPDF("/OpenAction << /S /JavaScript /JS 4 0 R >>", "", "<< /Length 11 >>\nstream\nthis.print\nendstream"),
+			wantErrors: []string{ErrorCodePDFSafety001},
+		},
+		{
+			name:         "automatic print",
+			data:         annotatedPDF("/OpenAction << /S /Named /N /Print >>", ""),
+			wantWarnings: []string{ErrorCodePDFSafety002},
+		},
+		{
+			name:         "page open action loads URL",
+			data:         annotatedPDF("/AA << /WC << /S /URI /URI (https://tracker.example.com/pixel) >> >>", ""),
+			wantWarnings: []string{ErrorCodePDFSafety002},
+		},
+		{
+			name:       "launch action",
+			data:       annotatedPDF("", "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /Launch /F (calc.exe) >> >>"),
+			wantErrors: []string{ErrorCodePDFSafety003},
+		},
+		{
+			name:         "submit form",
+			data:         annotatedPDF("/AcroForm << /Fields [4 0 R] >>", "4 0 R", "<< /Type /Annot /Subtype /Widget /FT /Btn /T (send) /Rect [0 0 10 10] /A << /S /SubmitForm /F << /FS /URL /F (https://forms.example.org/submit) >> >> >>"),
+			wantWarnings: []string{ErrorCodePDFSafety004},
+		},
+		{
+			name:         "remote go-to",
+			data:         annotatedPDF("", "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /GoToR /F (other.pdf) /D [0 /Fit] >> >>"),
+			wantWarnings: []string{ErrorCodePDFSafety005},
+		},
+		{
+			name:         "embedded file",
+			data:         annotatedPDF("/Names << /EmbeddedFiles << /Names [(data.csv) 4 0 R] >> >>", "", "<< /Type /Filespec /F (data.csv) /UF (data.csv) /EF << /F 5 0 R >> >>", "<< /Type /EmbeddedFile /Subtype /text#2Fcsv /Length 3 >>\nstream\na,b\nendstream"),
+			wantWarnings: []string{ErrorCodePDFSafety006},
+		},
+		{
+			name:       "embedded executable in attachment annotation",
+			data:       annotatedPDF("", "<< /Type /Annot /Subtype /FileAttachment /Rect [0 0 10 10] /FS << /Type /Filespec /F (setup.exe) /EF << /F 4 0 R >> >> >>", "<< /Type /EmbeddedFile /Length 2 >>\nstream\nMZ\nendstream"),
+			wantErrors: []string{ErrorCodePDFSafety006},
+		},
+		{
+			name:         "RichMedia annotation",
+			data:         annotatedPDF("", "<< /Type /Annot /Subtype /RichMedia /Rect [0 0 10 10] >>"),
+			wantWarnings: []string{ErrorCodePDFSafety007},
+		},
+		{
+			name:     "links",
+			data:     annotatedPDF("", "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://Example.com/a) >> >> << /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://example.com/b) >> >> << /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (mailto:author@example.com) >> >>"),
+			wantInfo: []string{ErrorCodePDFSafety008},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Info); !equalCodes(got, tt.wantInfo) {
+				t.Errorf("info = %v, want %v", got, tt.wantInfo)
+			}
+			for _, finding := range append(append(result.Errors, result.Warnings...), result.Info...) {
+				if finding.Details["category"] != CategorySafety {
+					t.Errorf("Expected %s to have the safety category, got %v", finding.Code, finding.Details["category"])
+				}
+			}
+		})
+	}
+}
+
+func TestStructureValidator_ActiveContent_Details(t *testing.T) {
+	data := annotatedPDF("/Names << /EmbeddedFiles << /Names [(data.csv) 4 0 R] >> >>",
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://Example.com/a) >> >> << /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://example.com/b) >> >> << /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (mailto:author@example.com) >> >>",
+		"<< /Type /Filespec /F (data.csv) /UF (data.csv) /EF << /F 5 0 R >> >>",
+		"<< /Type /EmbeddedFile /Subtype /text#2Fcsv /Length 3 >>\nstream\na,b\nendstream")
+
+	result, err := NewStructureValidator().ValidateBytes(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Expected one embedded file warning, got %v", result.Warnings)
+	}
+	if file := result.Warnings[0].Details["file"]; file != "data.csv" {
+		t.Errorf("file = %v, want data.csv", file)
+	}
+	if mediaType := result.Warnings[0].Details["media_type"]; mediaType != "text/csv" {
+		t.Errorf("media_type = %v, want text/csv", mediaType)
+	}
+
+	if len(result.Info) != 1 {
+		t.Fatalf("Expected one link summary, got %v", result.Info)
+	}
+	hosts, ok := result.Info[0].Details["hosts"].([]string)
+	if !ok || !equalCodes(hosts, []string{"example.com"}) {
+		t.Errorf("hosts = %v, want [example.com]", result.Info[0].Details["hosts"])
+	}
+	schemes, ok := result.Info[0].Details["schemes"].([]string)
+	if !ok || !equalCodes(schemes, []string{"mailto"}) {
+		t.Errorf("schemes = %v, want [mailto]", result.Info[0].Details["schemes"])
+	}
+	if links := result.Info[0].Details["links"]; links != 3 {
+		t.Errorf("links = %v, want 3", links)
+	}
+}
@@ -0,0 +1,220 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Encryption error codes.
+const (
+	ErrorCodePDFEncrypt001 = "PDF-ENCRYPT-001"
+	ErrorCodePDFEncrypt002 = "PDF-ENCRYPT-002"
+	ErrorCodePDFEncrypt003 = "PDF-ENCRYPT-003"
+	ErrorCodePDFEncrypt004 = "PDF-ENCRYPT-004"
+)
+
+// Encryption algorithms reported in EncryptionInfo.
+const (
+	EncryptionRC440   = "RC4-40"
+	EncryptionRC4128  = "RC4-128"
+	EncryptionAES128  = "AES-128"
+	EncryptionAES256  = "AES-256"
+	EncryptionUnknown = "unknown"
+)
+
+// weakEncryption are the algorithms that can be broken in practice.
+var weakEncryption = map[string]bool{
+	EncryptionRC440:  true,
+	EncryptionRC4128: true,
+}
+
+// permissionBits names the user access permissions of the /P entry, in
+// bit order. Revision 2 handlers only define the first four.
+var permissionBits = []struct {
+	bit  uint
+	name string
+}{
+	{3, "print"},
+	{4, "modify"},
+	{5, "copy"},
+	{6, "annotate"},
+	{9, "fill_forms"},
+	{10, "extract_for_accessibility"},
+	{11, "assemble"},
+	{12, "print_high_quality"},
+}
+
+// EncryptionInfo describes the /Encrypt dictionary of an encrypted
+// document.
+type EncryptionInfo struct {
+	// Handler is the security handler named by /Filter, usually Standard.
+	Handler   string
+	Version   int
+	Revision  int
+	Algorithm string
+	// KeyLength is the key length in bits.
+	KeyLength int
+	// Permissions and Restricted list the user permissions granted and
+	// denied by /P.
+	Permissions []string
+	Restricted  []string
+	// OpensWithoutPassword reports whether the empty user password opens
+	// the document.
+	OpensWithoutPassword bool
+	// Authenticated reports whether the document was decrypted, with the
+	// empty password or StructureValidator.Password.
+	Authenticated bool
+}
+
+// validateEncryption reads the /Encrypt dictionary and decrypts the
+// document with the configured password, so the remaining checks can read
+// strings and streams.
+func (v *StructureValidator) validateEncryption(parser *core.PdfParser, result *StructureValidationResult) {
+	encrypted, err := parser.IsEncrypted()
+	if err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFEncrypt003,
+			Message: "Encryption dictionary is invalid",
+			Details: map[string]interface{}{
+				"error": err.Error(),
+			},
+		})
+		return
+	}
+	if !encrypted {
+		return
+	}
+
+	details := map[string]interface{}{}
+	var dict *core.PdfObjectDictionary
+	if obj := parser.GetEncryptObj(); obj != nil {
+		dict, _ = core.GetDict(obj.PdfObject)
+		details["object"] = obj.ObjectNumber
+	} else {
+		dict, _ = core.GetDict(core.TraceToDirectObject(parser.GetTrailer().Get("Encrypt")))
+	}
+	if dict == nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFEncrypt003,
+			Message: "Trailer /Encrypt entry is not a dictionary",
+			Details: details,
+		})
+		return
+	}
+
+	info := readEncryptionInfo(dict)
+	result.Encryption = info
+	details["handler"] = info.Handler
+	details["version"] = info.Version
+	details["revision"] = info.Revision
+	details["algorithm"] = info.Algorithm
+	details["key_length"] = info.KeyLength
+	details["permissions"] = info.Permissions
+	details["restricted"] = info.Restricted
+
+	// The empty user password is tried first, since many documents only
+	// restrict permissions and open without a prompt.
+	opens, _, err := parser.CheckAccessRights(nil)
+	if err == nil {
+		info.OpensWithoutPassword = opens
+		info.Authenticated, err = parser.Decrypt([]byte(v.Password))
+	}
+	details["opens_without_password"] = info.OpensWithoutPassword
+	if err != nil {
+		details["error"] = err.Error()
+	}
+
+	switch {
+	case err != nil:
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFEncrypt003,
+			Message: fmt.Sprintf("Document uses unsupported %s encryption", info.Handler),
+			Details: details,
+		})
+	case !info.Authenticated && v.Password != "":
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFEncrypt001,
+			Message: "The supplied password does not open the document; strings and streams were not checked",
+			Details: details,
+		})
+	case !info.Authenticated:
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFEncrypt001,
+			Message: "Document requires a password to open; supply it to check strings and streams",
+			Details: details,
+		})
+	}
+
+	if weakEncryption[info.Algorithm] {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFEncrypt002,
+			Message: fmt.Sprintf("Document uses weak %s encryption; use AES-256", info.Algorithm),
+			Details: details,
+		})
+	}
+
+	result.Info = append(result.Info, ValidationError{
+		Code:    ErrorCodePDFEncrypt004,
+		Message: fmt.Sprintf("Document is encrypted with %s (%s handler, revision %d)", info.Algorithm, info.Handler, info.Revision),
+		Details: details,
+	})
+}
+
+// readEncryptionInfo derives the algorithm and permissions from an
+// encryption dictionary.
+func readEncryptionInfo(dict *core.PdfObjectDictionary) *EncryptionInfo {
+	info := &EncryptionInfo{Algorithm: EncryptionUnknown}
+	info.Handler, _ = core.GetNameVal(core.TraceToDirectObject(dict.Get("Filter")))
+	info.Version, _ = core.GetIntVal(core.TraceToDirectObject(dict.Get("V")))
+	info.Revision, _ = core.GetIntVal(core.TraceToDirectObject(dict.Get("R")))
+
+	length, ok := core.GetIntVal(core.TraceToDirectObject(dict.Get("Length")))
+	if !ok {
+		length = 40
+	}
+
+	switch info.Version {
+	case 1:
+		info.Algorithm, info.KeyLength = EncryptionRC440, 40
+	case 2:
+		info.Algorithm, info.KeyLength = EncryptionRC4128, length
+		if length <= 40 {
+			info.Algorithm = EncryptionRC440
+		}
+	case 4:
+		// Version 4 names the stream filter in the crypt filter dictionary.
+		method := ""
+		if filters, ok := core.GetDict(core.TraceToDirectObject(dict.Get("CF"))); ok {
+			stmf, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("StmF")))
+			if filter, ok := core.GetDict(core.TraceToDirectObject(filters.Get(core.PdfObjectName(stmf)))); ok {
+				method, _ = core.GetNameVal(core.TraceToDirectObject(filter.Get("CFM")))
+			}
+		}
+		switch method {
+		case "AESV2":
+			info.Algorithm, info.KeyLength = EncryptionAES128, 128
+		case "V2":
+			info.Algorithm, info.KeyLength = EncryptionRC4128, 128
+		}
+	case 5:
+		info.Algorithm, info.KeyLength = EncryptionAES256, 256
+	}
+
+	p, _ := core.GetIntVal(core.TraceToDirectObject(dict.Get("P")))
+	permissions := uint32(int32(p))
+	bits := permissionBits
+	if info.Revision == 2 {
+		bits = bits[:4]
+	}
+	info.Permissions = make([]string, 0, len(bits))
+	info.Restricted = make([]string, 0, len(bits))
+	for _, permission := range bits {
+		if permissions&(1<<(permission.bit-1)) != 0 {
+			info.Permissions = append(info.Permissions, permission.name)
+		} else {
+			info.Restricted = append(info.Restricted, permission.name)
+		}
+	}
+	return info
+}
@@ -0,0 +1,170 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/core/security"
+)
+
+const encryptionID = "0123456789abcdef"
+
+// encryptedPDF builds a one-page PDF 1.7 encrypted by the standard
+// security handler with the given revision, user password and owner
+// password "owner". The objects hold no strings or streams, so they need no
+// encryption themselves.
+func encryptedPDF(t *testing.T, revision int, cf string, user string) []byte {
+	t.Helper()
+
+	params := &security.StdEncryptDict{R: revision, P: security.PermPrinting | security.PermFillForms | 0xFFFFF0C0, EncryptMetadata: true}
+	var handler security.StdHandler
+	var encrypt string
+	switch revision {
+	case 6:
+		handler = security.NewHandlerR6()
+	default:
+		handler = security.NewHandlerR4(encryptionID, 128)
+	}
+	if _, err := handler.GenerateParams(params, []byte("owner"), []byte(user)); err != nil {
+		t.Fatalf("failed to generate encryption parameters: %v", err)
+	}
+
+	switch revision {
+	case 3:
+		encrypt = fmt.Sprintf("<< /Filter /Standard /V 2 /R 3 /Length 128 /O <%x> /U <%x> /P %d >>", params.O, params.U, int32(params.P))
+	case 4:
+		encrypt = fmt.Sprintf("<< /Filter /Standard /V 4 /R 4 /Length 128 /CF << /StdCF << /CFM /%s /Length 16 /AuthEvent /DocOpen >> >> /StmF /StdCF /StrF /StdCF /O <%x> /U <%x> /P %d >>", cf, params.O, params.U, int32(params.P))
+	case 6:
+		encrypt = fmt.Sprintf("<< /Filter /Standard /V 5 /R 6 /Length 256 /CF << /StdCF << /CFM /AESV3 /Length 32 /AuthEvent /DocOpen >> >> /StmF /StdCF /StrF /StdCF /O <%x> /U <%x> /OE <%x> /UE <%x> /Perms <%x> /P %d >>", params.O, params.U, params.OE, params.UE, params.Perms, int32(params.P))
+	}
+
+	data := bytes.Replace(buildPDF(append(revisionObjects[:3:3], encrypt)...), []byte("%PDF-1.4"), []byte("%PDF-1.7"), 1)
+	trailer := fmt.Sprintf("/Root 1 0 R /Encrypt 4 0 R /ID [<%x> <%x>] >>", encryptionID, encryptionID)
+	return bytes.Replace(data, []byte("/Root 1 0 R >>"), []byte(trailer), 1)
+}
+
+func TestStructureValidator_Encryption(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		password      string
+		wantErrors    []string
+		wantWarnings  []string
+		wantAlgorithm string
+		wantOpens     bool
+		wantAuth      bool
+	}{
+		{
+			name:          "RC4 without user password",
+			data:          encryptedPDF(t, 3, "", ""),
+			wantWarnings:  []string{ErrorCodePDFEncrypt002},
+			wantAlgorithm: EncryptionRC4128,
+			wantOpens:     true,
+			wantAuth:      true,
+		},
+		{
+			name:          "AES-128 without user password",
+			data:          encryptedPDF(t, 4, "AESV2", ""),
+			wantAlgorithm: EncryptionAES128,
+			wantOpens:     true,
+			wantAuth:      true,
+		},
+		{
+			name:          "AES-256 requires password",
+			data:          encryptedPDF(t, 6, "", "secret"),
+			wantErrors:    []string{ErrorCodePDFEncrypt001},
+			wantAlgorithm: EncryptionAES256,
+		},
+		{
+			name:          "AES-256 with user password",
+			data:          encryptedPDF(t, 6, "", "secret"),
+			password:      "secret",
+			wantAlgorithm: EncryptionAES256,
+			wantAuth:      true,
+		},
+		{
+			name:          "RC4 with owner password",
+			data:          encryptedPDF(t, 3, "", "secret"),
+			password:      "owner",
+			wantWarnings:  []string{ErrorCodePDFEncrypt002},
+			wantAlgorithm: EncryptionRC4128,
+			wantAuth:      true,
+		},
+		{
+			name:          "wrong password",
+			data:          encryptedPDF(t, 4, "AESV2", "secret"),
+			password:      "guess",
+			wantErrors:    []string{ErrorCodePDFEncrypt001},
+			wantAlgorithm: EncryptionAES128,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewStructureValidator()
+			validator.Password = tt.password
+
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+			if got := errorCodes(result.Info); !equalCodes(got, []string{ErrorCodePDFEncrypt004}) {
+				t.Errorf("info = %v, want [%s]", got, ErrorCodePDFEncrypt004)
+			}
+
+			info := result.Encryption
+			if info == nil {
+				t.Fatal("Expected encryption info")
+			}
+			if info.Algorithm != tt.wantAlgorithm {
+				t.Errorf("algorithm = %s, want %s", info.Algorithm, tt.wantAlgorithm)
+			}
+			if info.OpensWithoutPassword != tt.wantOpens {
+				t.Errorf("opens without password = %v, want %v", info.OpensWithoutPassword, tt.wantOpens)
+			}
+			if info.Authenticated != tt.wantAuth {
+				t.Errorf("authenticated = %v, want %v", info.Authenticated, tt.wantAuth)
+			}
+			if result.PageCount != 1 {
+				t.Errorf("Expected 1 page, got %d", result.PageCount)
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Encryption_Permissions(t *testing.T) {
+	result, err := NewStructureValidator().ValidateBytes(encryptedPDF(t, 4, "AESV2", ""))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Encryption == nil {
+		t.Fatal("Expected encryption info")
+	}
+	if got, want := result.Encryption.Permissions, []string{"print", "fill_forms"}; !equalCodes(got, want) {
+		t.Errorf("permissions = %v, want %v", got, want)
+	}
+	if len(result.Encryption.Restricted) != 6 {
+		t.Errorf("Expected 6 restricted permissions, got %v", result.Encryption.Restricted)
+	}
+}
+
+func TestStructureValidator_Encryption_Unencrypted(t *testing.T) {
+	result, err := NewStructureValidator().ValidateBytes(buildPDF(revisionObjects...))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Encryption != nil {
+		t.Errorf("Expected no encryption info, got %+v", result.Encryption)
+	}
+}
@@ -0,0 +1,302 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Font validation error codes.
+const (
+	ErrorCodePDFFont001 = "PDF-FONT-001"
+	ErrorCodePDFFont002 = "PDF-FONT-002"
+	ErrorCodePDFFont003 = "PDF-FONT-003"
+	ErrorCodePDFFont004 = "PDF-FONT-004"
+	ErrorCodePDFFont005 = "PDF-FONT-005"
+	ErrorCodePDFFont006 = "PDF-FONT-006"
+)
+
+// maxReportedPages caps the pages listed in a single font finding.
+const maxReportedPages = 10
+
+// standard14Fonts are the fonts every PDF reader provides, which need not
+// be embedded.
+var standard14Fonts = map[string]bool{
+	"Times-Roman": true, "Times-Bold": true, "Times-Italic": true, "Times-BoldItalic": true,
+	"Helvetica": true, "Helvetica-Bold": true, "Helvetica-Oblique": true, "Helvetica-BoldOblique": true,
+	"Courier": true, "Courier-Bold": true, "Courier-Oblique": true, "Courier-BoldOblique": true,
+	"Symbol": true, "ZapfDingbats": true,
+}
+
+// standardEncodings are the simple font encodings whose glyph names map to
+// Unicode without a /ToUnicode CMap.
+var standardEncodings = map[string]bool{
+	"WinAnsiEncoding":   true,
+	"MacRomanEncoding":  true,
+	"StandardEncoding":  true,
+	"MacExpertEncoding": true,
+}
+
+var subsetTagPattern = regexp.MustCompile(`^[A-Z]{6}\+.+`)
+
+// FontRules configures the font checks. The zero value applies the checks
+// every PDF should pass; print profiles enable the stricter ones.
+type FontRules struct {
+	// EmbedStandard14 requires the standard 14 fonts to be embedded too.
+	EmbedStandard14 bool `yaml:"embed_standard_14,omitempty" json:"embed_standard_14,omitempty"`
+}
+
+// fontUsage is a font dictionary and the pages that use it.
+type fontUsage struct {
+	dict     *core.PdfObjectDictionary
+	resource string
+	object   int64
+	pages    []int
+}
+
+// validateFonts checks every font in the page resources, including fonts
+// used by form XObjects. Each font is reported once with the pages that use
+// it.
+func (v *StructureValidator) validateFonts(parser *core.PdfParser, pages []pageEntry, result *StructureValidationResult) {
+	fonts := make(map[*core.PdfObjectDictionary]*fontUsage)
+	var order []*fontUsage
+	for i, page := range pages {
+		collectFonts(page.resources, i+1, fonts, &order, make(map[core.PdfObject]bool))
+	}
+
+	// Font files cannot be decoded without the decryption key.
+	decodable := true
+	if encrypted, err := parser.IsEncrypted(); err != nil || (encrypted && !parser.IsAuthenticated()) {
+		decodable = false
+	}
+
+	for _, font := range order {
+		v.checkFont(font, decodable, result)
+	}
+}
+
+// collectFonts adds the fonts of a resource dictionary, and of the form
+// XObjects it names, to fonts.
+func collectFonts(resources core.PdfObject, page int, fonts map[*core.PdfObjectDictionary]*fontUsage, order *[]*fontUsage, visited map[core.PdfObject]bool) {
+	dict, ok := core.GetDict(core.TraceToDirectObject(resources))
+	if !ok || visited[dict] {
+		return
+	}
+	visited[dict] = true
+
+	if fontDict, ok := core.GetDict(core.TraceToDirectObject(dict.Get("Font"))); ok {
+		for _, key := range fontDict.Keys() {
+			obj := fontDict.Get(key)
+			font, ok := core.GetDict(core.TraceToDirectObject(obj))
+			if !ok {
+				continue
+			}
+			usage, ok := fonts[font]
+			if !ok {
+				usage = &fontUsage{dict: font, resource: string(key)}
+				if ref, isRef := obj.(*core.PdfObjectReference); isRef {
+					usage.object = ref.ObjectNumber
+				}
+				fonts[font] = usage
+				*order = append(*order, usage)
+			}
+			if n := len(usage.pages); n == 0 || usage.pages[n-1] != page {
+				usage.pages = append(usage.pages, page)
+			}
+		}
+	}
+
+	if xobjects, ok := core.GetDict(core.TraceToDirectObject(dict.Get("XObject"))); ok {
+		for _, key := range xobjects.Keys() {
+			stream, ok := core.GetStream(core.TraceToDirectObject(xobjects.Get(key)))
+			if !ok {
+				continue
+			}
+			if subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype == "Form" {
+				collectFonts(stream.Get("Resources"), page, fonts, order, visited)
+			}
+		}
+	}
+}
+
+// checkFont validates a single font dictionary.
+func (v *StructureValidator) checkFont(font *fontUsage, decodable bool, result *StructureValidationResult) {
+	dict := font.dict
+	subtype, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("Subtype")))
+	baseFont, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("BaseFont")))
+	name := baseFont
+	if name == "" {
+		name = font.resource
+	}
+
+	report := func(warning bool, code, message string, extra map[string]interface{}) {
+		details := map[string]interface{}{
+			"font":       name,
+			"subtype":    subtype,
+			"resource":   font.resource,
+			"page_count": len(font.pages),
+		}
+		if font.object != 0 {
+			details["object"] = font.object
+		}
+		pages := font.pages
+		if len(pages) > maxReportedPages {
+			pages = pages[:maxReportedPages]
+		}
+		details["pages"] = pages
+		for key, value := range extra {
+			details[key] = value
+		}
+		finding := ValidationError{Code: code, Message: message, Details: details}
+		if warning {
+			result.Warnings = append(result.Warnings, finding)
+		} else {
+			result.Errors = append(result.Errors, finding)
+		}
+	}
+
+	if strings.Contains(baseFont, "+") && !subsetTagPattern.MatchString(baseFont) {
+		report(true, ErrorCodePDFFont002, fmt.Sprintf("Font %s has a malformed subset prefix; expected six uppercase letters and a plus sign", name), nil)
+	}
+
+	descriptorOwner := dict
+	if subtype == "Type0" {
+		descendants, ok := core.GetArray(core.TraceToDirectObject(dict.Get("DescendantFonts")))
+		if !ok || descendants.Len() == 0 {
+			report(false, ErrorCodePDFFont001, fmt.Sprintf("Type 0 font %s has no descendant font", name), nil)
+			return
+		}
+		descendant, ok := core.GetDict(core.TraceToDirectObject(descendants.Get(0)))
+		if !ok {
+			report(false, ErrorCodePDFFont001, fmt.Sprintf("Type 0 font %s has an invalid descendant font", name), nil)
+			return
+		}
+		descriptorOwner = descendant
+	}
+	descriptor, _ := core.GetDict(core.TraceToDirectObject(descriptorOwner.Get("FontDescriptor")))
+
+	if subtype == "Type3" {
+		report(true, ErrorCodePDFFont005, fmt.Sprintf("Font %s is a Type 3 font; its glyphs are drawn by content streams and often rasterize poorly", name), nil)
+		v.checkWidths(dict, name, report)
+	} else {
+		standard := standard14Fonts[baseFont] && subtype != "Type0"
+		embedded := false
+		if descriptor != nil {
+			for _, key := range []core.PdfObjectName{"FontFile", "FontFile2", "FontFile3"} {
+				if obj := descriptor.Get(key); obj != nil {
+					embedded = true
+					if decodable {
+						v.checkFontFile(obj, string(key), report)
+					}
+				}
+			}
+		}
+		switch {
+		case embedded:
+		case standard && !v.Fonts.EmbedStandard14:
+		case standard:
+			report(false, ErrorCodePDFFont001, fmt.Sprintf("Standard 14 font %s is not embedded", name), nil)
+		default:
+			report(false, ErrorCodePDFFont001, fmt.Sprintf("Font %s is not embedded", name), nil)
+		}
+
+		if embedded && subtype != "Type0" && descriptor != nil {
+			if fontName, _ := core.GetNameVal(core.TraceToDirectObject(descriptor.Get("FontName"))); fontName != "" && baseFont != "" && fontName != baseFont {
+				report(true, ErrorCodePDFFont002, fmt.Sprintf("Font %s /FontDescriptor /FontName %s does not match /BaseFont", name, fontName), map[string]interface{}{
+					"font_name": fontName,
+				})
+			}
+		}
+
+		if subtype != "Type0" && !standard {
+			v.checkWidths(dict, name, report)
+		}
+	}
+
+	if dict.Get("ToUnicode") == nil && needsToUnicode(dict, subtype, baseFont, descriptor) {
+		report(true, ErrorCodePDFFont003, fmt.Sprintf("Font %s has no /ToUnicode CMap; copied text and text-to-speech may be wrong", name), nil)
+	}
+}
+
+// checkWidths reports a simple font whose /Widths array is missing or does
+// not cover /FirstChar through /LastChar.
+func (v *StructureValidator) checkWidths(dict *core.PdfObjectDictionary, name string, report func(bool, string, string, map[string]interface{})) {
+	widths, ok := core.GetArray(core.TraceToDirectObject(dict.Get("Widths")))
+	if !ok {
+		report(false, ErrorCodePDFFont004, fmt.Sprintf("Font %s is missing /Widths", name), nil)
+		return
+	}
+	first, firstOK := core.GetIntVal(core.TraceToDirectObject(dict.Get("FirstChar")))
+	last, lastOK := core.GetIntVal(core.TraceToDirectObject(dict.Get("LastChar")))
+	if !firstOK || !lastOK {
+		report(false, ErrorCodePDFFont004, fmt.Sprintf("Font %s has /Widths but no /FirstChar or /LastChar", name), nil)
+		return
+	}
+	if expected := last - first + 1; widths.Len() != expected {
+		report(false, ErrorCodePDFFont004, fmt.Sprintf("Font %s /Widths has %d entries but /FirstChar to /LastChar covers %d", name, widths.Len(), expected), map[string]interface{}{
+			"widths":   widths.Len(),
+			"expected": expected,
+		})
+	}
+}
+
+// checkFontFile reports an embedded font program that is not a stream or
+// fails to decode.
+func (v *StructureValidator) checkFontFile(obj core.PdfObject, key string, report func(bool, string, string, map[string]interface{})) {
+	details := map[string]interface{}{"font_file": key}
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		details["font_file_object"] = ref.ObjectNumber
+	}
+
+	stream, ok := core.GetStream(core.TraceToDirectObject(obj))
+	if !ok {
+		report(false, ErrorCodePDFFont006, fmt.Sprintf("/%s is not a stream", key), details)
+		return
+	}
+	decoded, err := core.DecodeStream(stream)
+	if err != nil {
+		details["error"] = err.Error()
+		report(false, ErrorCodePDFFont006, fmt.Sprintf("/%s stream fails to decode", key), details)
+		return
+	}
+	if len(decoded) == 0 {
+		report(false, ErrorCodePDFFont006, fmt.Sprintf("/%s stream is empty", key), details)
+	}
+}
+
+// needsToUnicode reports whether text shown with a font lacking /ToUnicode
+// cannot be mapped to Unicode reliably.
+func needsToUnicode(dict *core.PdfObjectDictionary, subtype, baseFont string, descriptor *core.PdfObjectDictionary) bool {
+	encoding := core.TraceToDirectObject(dict.Get("Encoding"))
+
+	switch subtype {
+	case "Type3":
+		return true
+	case "Type0":
+		// Predefined CJK CMaps map through their character collection;
+		// Identity and embedded CMaps carry no Unicode information.
+		name, ok := core.GetNameVal(encoding)
+		return !ok || name == "Identity-H" || name == "Identity-V"
+	}
+
+	if baseFont == "Symbol" || baseFont == "ZapfDingbats" {
+		return false
+	}
+	if descriptor != nil {
+		if flags, ok := core.GetIntVal(core.TraceToDirectObject(descriptor.Get("Flags"))); ok && flags&4 != 0 {
+			return true
+		}
+	}
+	if name, ok := core.GetNameVal(encoding); ok {
+		return !standardEncodings[name]
+	}
+	if encodingDict, ok := core.GetDict(encoding); ok {
+		name, ok := core.GetNameVal(core.TraceToDirectObject(encodingDict.Get("BaseEncoding")))
+		return ok && !standardEncodings[name]
+	}
+	// Without /Encoding the font's built-in encoding applies, which is only
+	// known for the standard 14 fonts.
+	return !standard14Fonts[baseFont]
+}
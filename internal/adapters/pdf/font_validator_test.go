@@ -0,0 +1,131 @@
+package pdf
+
+import (
+	"testing"
+)
+
+// fontPDF builds a one-page PDF using font as /F1. Extra objects are
+// numbered from 4.
+func fontPDF(font string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 " + font + " >> >> >>",
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+const fontFile = "<< /Length 4 >>\nstream\nfont\nendstream"
+
+func TestStructureValidator_Fonts(t *testing.T) {
+	const widths = "/FirstChar 32 /LastChar 34 /Widths [250 333 408]"
+
+	tests := []struct {
+		name         string
+		data         []byte
+		strict       bool
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "standard 14 font",
+			data: fontPDF("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"),
+		},
+		{
+			name:       "standard 14 font under strict rules",
+			data:       fontPDF("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"),
+			strict:     true,
+			wantErrors: []string{ErrorCodePDFFont001},
+		},
+		{
+			name: "embedded TrueType font",
+			data: fontPDF("<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Arial /Encoding /WinAnsiEncoding "+widths+" /FontDescriptor 4 0 R >>",
+				"<< /Type /FontDescriptor /FontName /ABCDEF+Arial /Flags 32 /FontFile2 5 0 R >>",
+				fontFile),
+		},
+		{
+			name:       "font not embedded",
+			data:       fontPDF("<< /Type /Font /Subtype /TrueType /BaseFont /Arial /Encoding /WinAnsiEncoding " + widths + " >>"),
+			wantErrors: []string{ErrorCodePDFFont001},
+		},
+		{
+			name: "malformed subset prefix",
+			data: fontPDF("<< /Type /Font /Subtype /TrueType /BaseFont /abc+Arial /Encoding /WinAnsiEncoding "+widths+" /FontDescriptor 4 0 R >>",
+				"<< /Type /FontDescriptor /FontName /abc+Arial /Flags 32 /FontFile2 5 0 R >>",
+				fontFile),
+			wantWarnings: []string{ErrorCodePDFFont002},
+		},
+		{
+			name: "missing ToUnicode for Identity-H",
+			data: fontPDF("<< /Type /Font /Subtype /Type0 /BaseFont /ABCDEF+NotoSans /Encoding /Identity-H /DescendantFonts [4 0 R] >>",
+				"<< /Type /Font /Subtype /CIDFontType2 /BaseFont /ABCDEF+NotoSans /FontDescriptor 5 0 R >>",
+				"<< /Type /FontDescriptor /FontName /ABCDEF+NotoSans /Flags 4 /FontFile2 6 0 R >>",
+				fontFile),
+			wantWarnings: []string{ErrorCodePDFFont003},
+		},
+		{
+			name: "missing Widths",
+			data: fontPDF("<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Arial /Encoding /WinAnsiEncoding /FontDescriptor 4 0 R >>",
+				"<< /Type /FontDescriptor /FontName /ABCDEF+Arial /Flags 32 /FontFile2 5 0 R >>",
+				fontFile),
+			wantErrors: []string{ErrorCodePDFFont004},
+		},
+		{
+			name:         "Type 3 font",
+			data:         fontPDF("<< /Type /Font /Subtype /Type3 /FontBBox [0 0 750 750] /FontMatrix [0.001 0 0 0.001 0 0] /CharProcs << >> /Encoding << /Differences [32 /space] >> " + widths + " >>"),
+			wantWarnings: []string{ErrorCodePDFFont005, ErrorCodePDFFont003},
+		},
+		{
+			name: "font file fails to decode",
+			data: fontPDF("<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Arial /Encoding /WinAnsiEncoding "+widths+" /FontDescriptor 4 0 R >>",
+				"<< /Type /FontDescriptor /FontName /ABCDEF+Arial /Flags 32 /FontFile2 5 0 R >>",
+				"<< /Length 4 /Filter /FlateDecode >>\nstream\nfont\nendstream"),
+			wantErrors: []string{ErrorCodePDFFont006},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewStructureValidator()
+			validator.Fonts.EmbedStandard14 = tt.strict
+
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Fonts_FormXObjectsAndPages(t *testing.T) {
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 6 0 R >> >> >>",
+		"<< /Type /XObject /Subtype /Form /BBox [0 0 100 100] /Resources << /Font << /F1 6 0 R >> >> /Length 0 >>\nstream\n\nendstream",
+		"<< /Type /Font /Subtype /TrueType /BaseFont /Arial /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 32 /Widths [250] >>",
+	)
+
+	result, err := NewStructureValidator().ValidateBytes(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Code != ErrorCodePDFFont001 {
+		t.Fatalf("Expected one PDF-FONT-001 error, got %v", result.Errors)
+	}
+	pages, ok := result.Errors[0].Details["pages"].([]int)
+	if !ok || len(pages) != 2 || pages[0] != 1 || pages[1] != 2 {
+		t.Errorf("Expected pages [1 2], got %v", result.Errors[0].Details["pages"])
+	}
+}
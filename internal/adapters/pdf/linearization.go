@@ -0,0 +1,353 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Linearization error codes.
+const (
+	ErrorCodePDFLinear001 = "PDF-LINEAR-001"
+	ErrorCodePDFLinear002 = "PDF-LINEAR-002"
+	ErrorCodePDFLinear003 = "PDF-LINEAR-003"
+	ErrorCodePDFLinear004 = "PDF-LINEAR-004"
+	ErrorCodePDFLinear005 = "PDF-LINEAR-005"
+)
+
+// Sizes of the fixed headers of the page offset and shared object hint
+// tables, in bytes.
+const (
+	pageOffsetHeaderSize   = 36
+	sharedObjectHeaderSize = 24
+)
+
+var linearizedKeyPattern = regexp.MustCompile(`/Linearized[\s/<>\[\]]`)
+
+// LinearizationInfo describes the linearization dictionary of a file
+// organized for page-at-a-time loading ("Fast Web View").
+type LinearizationInfo struct {
+	// Object is the object number of the linearization dictionary.
+	Object int64
+	// Length is /L, the file length when the file was linearized.
+	Length int64
+	// FirstPage is /O, the object number of the first page.
+	FirstPage int64
+	// FirstPageEnd is /E, the offset of the end of the first page.
+	FirstPageEnd int64
+	// Pages is /N, the number of pages.
+	Pages int
+	// MainXref is /T, the offset of the first entry of the main
+	// cross-reference table.
+	MainXref int64
+	// Hints are the offset and length pairs of /H, the primary hint stream
+	// followed by the optional overflow hint stream.
+	Hints []int64
+	// Updated reports an incremental update after linearization, which
+	// readers treat as not linearized.
+	Updated bool
+}
+
+// linearizationObject is the first object of a file and its extent.
+type linearizationObject struct {
+	number int64
+	offset int64
+	end    int64
+	dict   *core.PdfObjectDictionary
+}
+
+// validateLinearization checks the linearization dictionary against the
+// file: it must be the first object, /L must be the file length, the hint
+// stream must describe the file, and no update may follow. Readers that
+// find a problem load the whole file before showing the first page.
+func (v *StructureValidator) validateLinearization(data []byte, parser *core.PdfParser, result *StructureValidationResult) {
+	first, ok := readFirstObject(data)
+	if !ok || first.dict.Get("Linearized") == nil {
+		if loc := linearizedKeyPattern.FindIndex(data); loc != nil {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFLinear001,
+				Message: "Linearization dictionary is not the first object in the file",
+				Details: map[string]interface{}{
+					"offset": loc[0],
+				},
+			})
+		}
+		return
+	}
+
+	info := &LinearizationInfo{Object: first.number}
+	var problems []string
+	readInt := func(key core.PdfObjectName) int64 {
+		value, ok := core.GetIntVal(first.dict.Get(key))
+		if !ok || value < 0 {
+			problems = append(problems, fmt.Sprintf("/%s is missing or not a non-negative integer", key))
+		}
+		return int64(value)
+	}
+	info.Length = readInt("L")
+	info.FirstPage = readInt("O")
+	info.FirstPageEnd = readInt("E")
+	info.Pages = int(readInt("N"))
+	info.MainXref = readInt("T")
+	if hints, ok := core.GetArray(first.dict.Get("H")); ok && (hints.Len() == 2 || hints.Len() == 4) {
+		values, err := hints.ToInt64Slice()
+		if err != nil {
+			problems = append(problems, "/H is not an array of integers")
+		} else {
+			info.Hints = values
+		}
+	} else {
+		problems = append(problems, "/H is missing or does not have two or four entries")
+	}
+	result.Linearization = info
+
+	fileLength := int64(len(data))
+	if info.FirstPageEnd > fileLength || info.FirstPageEnd < first.end {
+		problems = append(problems, fmt.Sprintf("/E %d is outside the file", info.FirstPageEnd))
+	}
+	if info.MainXref >= fileLength {
+		problems = append(problems, fmt.Sprintf("/T %d is outside the file", info.MainXref))
+	}
+	if page, count, ok := firstPage(parser); ok {
+		if info.FirstPage != page {
+			problems = append(problems, fmt.Sprintf("/O %d is not the first page, object %d", info.FirstPage, page))
+		}
+		if info.Pages != count {
+			problems = append(problems, fmt.Sprintf("/N %d does not match the page count %d", info.Pages, count))
+		}
+	}
+
+	// The first-page cross-reference section follows the dictionary, and
+	// the final startxref points back to it.
+	firstXref := skipWhitespace(data, first.end)
+	if !bytes.HasPrefix(data[firstXref:], []byte("xref")) && objectHeaderPattern.Find(data[firstXref:]) == nil {
+		problems = append(problems, "no cross-reference section follows the linearization dictionary")
+	}
+	if matches := startxrefValuePattern.FindAllSubmatch(data, -1); len(matches) > 0 {
+		if last, err := strconv.ParseInt(string(matches[len(matches)-1][1]), 10, 64); err == nil && last != firstXref {
+			info.Updated = true
+		}
+	}
+
+	if len(problems) > 0 {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFLinear001,
+			Message: "Linearization dictionary does not describe the file",
+			Details: map[string]interface{}{
+				"object":   first.number,
+				"problems": problems,
+			},
+		})
+	}
+
+	switch {
+	case info.Updated:
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFLinear004,
+			Message: "Document was updated after it was linearized",
+			Details: map[string]interface{}{
+				"linearized_length": info.Length,
+				"file_length":       fileLength,
+			},
+		})
+	case info.Length != fileLength:
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFLinear002,
+			Message: fmt.Sprintf("Linearization /L is %d but the file is %d bytes", info.Length, fileLength),
+			Details: map[string]interface{}{
+				"found":    info.Length,
+				"expected": fileLength,
+			},
+		})
+	}
+
+	hintProblems := checkHintStream(data, parser, info)
+	if len(hintProblems) > 0 {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFLinear003,
+			Message: "Linearization hint stream is invalid",
+			Details: map[string]interface{}{
+				"hints":    info.Hints,
+				"problems": hintProblems,
+			},
+		})
+	}
+
+	if len(problems) == 0 && len(hintProblems) == 0 && !info.Updated && info.Length == fileLength {
+		result.Info = append(result.Info, ValidationError{
+			Code:    ErrorCodePDFLinear005,
+			Message: "Document is linearized for Fast Web View",
+			Details: map[string]interface{}{
+				"object":            info.Object,
+				"first_page":        info.FirstPage,
+				"first_page_end":    info.FirstPageEnd,
+				"pages":             info.Pages,
+				"hint_stream":       info.Hints[0],
+				"hint_stream_bytes": info.Hints[1],
+			},
+		})
+	}
+}
+
+// checkHintStream checks that /H points to the primary hint stream and that
+// its page offset and shared object tables locate the first page and the
+// shared objects section where the cross-reference table does.
+func checkHintStream(data []byte, parser *core.PdfParser, info *LinearizationInfo) []string {
+	if len(info.Hints) < 2 {
+		return nil
+	}
+	var problems []string
+	fileLength := int64(len(data))
+	for i := 0; i+1 < len(info.Hints); i += 2 {
+		if info.Hints[i] < 0 || info.Hints[i+1] <= 0 || info.Hints[i]+info.Hints[i+1] > fileLength {
+			problems = append(problems, fmt.Sprintf("hint stream at %d with length %d is outside the file", info.Hints[i], info.Hints[i+1]))
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	offset, length := info.Hints[0], info.Hints[1]
+	stream, err := parseStreamAt(core.NewParserFromString(string(data)), data, offset)
+	if err != nil {
+		return []string{fmt.Sprintf("no hint stream at offset %d: %v", offset, err)}
+	}
+	shared, ok := core.GetIntVal(stream.Get("S"))
+	if !ok {
+		return []string{"hint stream has no /S shared object table offset"}
+	}
+	if encrypted, err := parser.IsEncrypted(); err != nil || encrypted {
+		// Hint streams are encrypted with the rest of the document.
+		return nil
+	}
+	table, err := core.DecodeStream(stream)
+	if err != nil {
+		return []string{fmt.Sprintf("hint stream fails to decode: %v", err)}
+	}
+	if len(table) < pageOffsetHeaderSize {
+		return []string{"hint stream is too short for the page offset hint table"}
+	}
+	if shared < pageOffsetHeaderSize || shared+sharedObjectHeaderSize > len(table) {
+		return []string{fmt.Sprintf("/S %d is outside the hint stream", shared)}
+	}
+	if info.Updated {
+		// The update may have moved objects the tables locate.
+		return nil
+	}
+
+	// Hint table offsets exclude the primary hint stream itself.
+	actual := func(recorded int64) int64 {
+		if recorded >= offset {
+			return recorded + length
+		}
+		return recorded
+	}
+	xref := parser.GetXrefTable().ObjectMap
+
+	recorded := actual(int64(binary.BigEndian.Uint32(table[4:8])))
+	if entry, ok := xref[int(info.FirstPage)]; ok && entry.Offset != recorded {
+		problems = append(problems, fmt.Sprintf("page offset hint table places the first page at %d, but it is at %d", recorded, entry.Offset))
+	}
+
+	sharedTable := table[shared:]
+	firstShared := int(binary.BigEndian.Uint32(sharedTable[0:4]))
+	firstPageEntries := binary.BigEndian.Uint32(sharedTable[8:12])
+	totalEntries := binary.BigEndian.Uint32(sharedTable[12:16])
+	if totalEntries > firstPageEntries {
+		recorded := actual(int64(binary.BigEndian.Uint32(sharedTable[4:8])))
+		if entry, ok := xref[firstShared]; !ok || entry.Offset != recorded {
+			problems = append(problems, fmt.Sprintf("shared object hint table places object %d at %d, which does not match the cross-reference table", firstShared, recorded))
+		}
+	}
+	return problems
+}
+
+// readFirstObject parses the first indirect object after the header.
+func readFirstObject(data []byte) (*linearizationObject, bool) {
+	pos := int64(0)
+	for pos < int64(len(data)) {
+		pos = skipWhitespace(data, pos)
+		if pos >= int64(len(data)) || data[pos] != '%' {
+			break
+		}
+		// Skip the header and comment lines.
+		for pos < int64(len(data)) && data[pos] != '\r' && data[pos] != '\n' {
+			pos++
+		}
+	}
+	if pos >= int64(len(data)) || objectHeaderPattern.Find(data[pos:]) == nil {
+		return nil, false
+	}
+
+	parser := core.NewParserFromString(string(data))
+	parser.SetFileOffset(pos)
+	obj, err := parser.ParseIndirectObject()
+	if err != nil {
+		return nil, false
+	}
+	indirect, ok := obj.(*core.PdfIndirectObject)
+	if !ok {
+		return nil, false
+	}
+	dict, ok := core.GetDict(indirect.PdfObject)
+	if !ok {
+		return nil, false
+	}
+	end := bytes.Index(data[pos:], []byte("endobj"))
+	if end < 0 {
+		return nil, false
+	}
+	return &linearizationObject{
+		number: indirect.ObjectNumber,
+		offset: pos,
+		end:    pos + int64(end) + int64(len("endobj")),
+		dict:   dict,
+	}, true
+}
+
+// firstPage returns the object number of the first leaf page and the page
+// count declared by the page tree root.
+func firstPage(parser *core.PdfParser) (int64, int, bool) {
+	trailer := parser.GetTrailer()
+	if trailer == nil {
+		return 0, 0, false
+	}
+	catalog, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Root")))
+	if !ok {
+		return 0, 0, false
+	}
+	root, ok := core.GetDict(core.TraceToDirectObject(catalog.Get("Pages")))
+	if !ok {
+		return 0, 0, false
+	}
+	count, _ := core.GetIntVal(core.TraceToDirectObject(root.Get("Count")))
+
+	node := root
+	visited := make(map[int64]bool)
+	for {
+		kids, ok := core.GetArray(core.TraceToDirectObject(node.Get("Kids")))
+		if !ok || kids.Len() == 0 {
+			return 0, 0, false
+		}
+		ref, ok := kids.Get(0).(*core.PdfObjectReference)
+		if !ok {
+			return 0, 0, false
+		}
+		if visited[ref.ObjectNumber] {
+			return 0, 0, false
+		}
+		visited[ref.ObjectNumber] = true
+		kid, ok := core.GetDict(core.TraceToDirectObject(ref))
+		if !ok {
+			return 0, 0, false
+		}
+		if name, _ := core.GetNameVal(core.TraceToDirectObject(kid.Get("Type"))); name != "Pages" {
+			return ref.ObjectNumber, count, true
+		}
+		node = kid
+	}
+}
@@ -0,0 +1,195 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var linearizationObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R /Outlines 9 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 6 0 R >> >> /Contents 7 0 R >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 6 0 R /F2 8 0 R >> >> >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F2 8 0 R >> >> >>",
+	"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	"<< /Length 44 >>\nstream\nBT /F1 12 Tf 72 712 Td (First page) Tj ET\nendstream",
+	"<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman >>",
+	"<< /Type /Outlines /Count 0 >>",
+	"<< /Unreferenced true >>",
+}
+
+// linearizePDF linearizes a file built by buildPDF with OptimizeFileWithOptions.
+func linearizePDF(t *testing.T, objects ...string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	service := &RepairServiceImpl{}
+	if _, err := service.OptimizeFileWithOptions(context.Background(), bytes.NewReader(buildPDF(objects...)), &out, OptimizeOptions{Linearize: true}); err != nil {
+		t.Fatalf("OptimizeFileWithOptions failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestStructureValidator_Linearization(t *testing.T) {
+	linearized := linearizePDF(t, linearizationObjects...)
+	length := regexp.MustCompile(`/L \d+`).Find(linearized)
+	wrongLength := append([]byte(nil), length...)
+	wrongLength[len(wrongLength)-1] = '0' + (wrongLength[len(wrongLength)-1]-'0'+1)%10
+	hintPattern := regexp.MustCompile(`/H \[ (\d+)`)
+	hintOffset := hintPattern.FindSubmatch(linearized)[1]
+
+	updated := append([]byte(nil), linearized...)
+	firstXref := bytes.Index(updated, []byte("xref\n"))
+	root := regexp.MustCompile(`/Root \d+ 0 R`).Find(linearized)
+	updated = append(updated, fmt.Sprintf("xref\n0 1\n0000000000 65535 f \ntrailer\n<< /Size 12 %s /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", root, firstXref, len(linearized))...)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantWarnings []string
+		wantInfo     []string
+		wantLinear   bool
+	}{
+		{
+			name: "not linearized",
+			data: buildPDF(linearizationObjects...),
+		},
+		{
+			name:       "linearized",
+			data:       linearized,
+			wantInfo:   []string{ErrorCodePDFLinear005},
+			wantLinear: true,
+		},
+		{
+			name:         "wrong length",
+			data:         bytes.Replace(linearized, length, wrongLength, 1),
+			wantWarnings: []string{ErrorCodePDFLinear002},
+			wantLinear:   true,
+		},
+		{
+			name:         "hint stream offset",
+			data:         bytes.Replace(linearized, []byte("/H [ "+string(hintOffset)), []byte("/H [ "+strings.Repeat("9", len(hintOffset))), 1),
+			wantWarnings: []string{ErrorCodePDFLinear003},
+			wantLinear:   true,
+		},
+		{
+			name:         "updated after linearization",
+			data:         updated,
+			wantWarnings: []string{ErrorCodePDFLinear004},
+			wantInfo:     []string{ErrorCodePDFXref009},
+			wantLinear:   true,
+		},
+		{
+			name: "dictionary not first",
+			data: buildPDF(
+				"<< /Type /Catalog /Pages 2 0 R >>",
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>",
+				"<< /Linearized 1 /L 1000 /H [ 0 0 ] /O 3 /E 0 /N 1 /T 0 >>",
+			),
+			wantWarnings: []string{ErrorCodePDFLinear001},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if len(result.Errors) > 0 {
+				for _, e := range result.Errors {
+					t.Errorf("Unexpected error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Info); !equalCodes(got, tt.wantInfo) {
+				t.Errorf("info = %v, want %v", got, tt.wantInfo)
+			}
+			if (result.Linearization != nil) != tt.wantLinear {
+				t.Errorf("Linearization = %+v, want linearized %v", result.Linearization, tt.wantLinear)
+			}
+		})
+	}
+}
+
+func TestOptimizeFile_Linearizes(t *testing.T) {
+	linearized := linearizePDF(t, linearizationObjects...)
+
+	result, err := NewStructureValidator().ValidateBytes(linearized)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	info := result.Linearization
+	if info == nil {
+		t.Fatal("Expected the optimized file to be linearized")
+	}
+	if info.Length != int64(len(linearized)) || info.Pages != 3 || info.Updated {
+		t.Errorf("Unexpected linearization dictionary: %+v", info)
+	}
+	if result.PageCount != 3 {
+		t.Errorf("Expected 3 pages, got %d", result.PageCount)
+	}
+	if bytes.Contains(linearized, []byte("/Unreferenced")) {
+		t.Error("Expected unreferenced objects to be dropped")
+	}
+	if !bytes.Contains(linearized, []byte("(First page)")) {
+		t.Error("Expected the first page content stream to be kept")
+	}
+}
+
+func TestOptimizeFile_Invalid(t *testing.T) {
+	service := NewRepairService()
+	var out bytes.Buffer
+
+	if err := service.OptimizeFile(context.Background(), strings.NewReader("not a pdf"), &out); err == nil {
+		t.Error("Expected an error for invalid input")
+	}
+	noPages := buildPDF("<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>")
+	linearize := OptimizeOptions{Linearize: true}
+	if _, err := service.(*RepairServiceImpl).OptimizeFileWithOptions(context.Background(), bytes.NewReader(noPages), &out, linearize); err == nil {
+		t.Error("Expected an error for a document without pages")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written on error, got %d bytes", out.Len())
+	}
+}
+
+func TestStructureValidationResult_RevisionCount(t *testing.T) {
+	linearized := linearizePDF(t, linearizationObjects...)
+	firstXref := bytes.Index(linearized, []byte("xref\n"))
+	root := regexp.MustCompile(`/Root \d+ 0 R`).Find(linearized)
+	updated := append(append([]byte(nil), linearized...), fmt.Sprintf("xref\n0 1\n0000000000 65535 f \ntrailer\n<< /Size 12 %s /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", root, firstXref, len(linearized))...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		sections int
+		want     int
+	}{
+		{name: "not linearized", data: buildPDF(linearizationObjects...), sections: 1, want: 1},
+		{name: "linearized", data: linearized, sections: 2, want: 1},
+		{name: "updated after linearization", data: updated, sections: 3, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewStructureValidator().ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if len(result.Revisions) != tt.sections || result.RevisionCount() != tt.want {
+				t.Errorf("got %d sections and %d revisions, want %d and %d", len(result.Revisions), result.RevisionCount(), tt.sections, tt.want)
+			}
+		})
+	}
+}
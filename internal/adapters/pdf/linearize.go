@@ -0,0 +1,728 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// binaryComment follows the header of written files so transfer programs
+// treat them as binary.
+const binaryComment = "%\xe2\xe3\xcf\xd3\n"
+
+// document is the live object graph of a PDF, loaded for rewriting. Objects
+// keep their original numbers until the document is written.
+type document struct {
+	version string
+	// objects holds every object reachable from the trailer, as
+	// *core.PdfIndirectObject or *core.PdfObjectStream.
+	objects map[int64]core.PdfObject
+	// trailer keeps /Root, /Info and /ID of the original trailer.
+	trailer *core.PdfObjectDictionary
+	catalog int64
+	// pages lists the page objects in page order; pageTree holds them and
+	// the intermediate /Pages nodes.
+	pages    []int64
+	pageTree map[int64]bool
+	// sourceObjects counts the objects in the cross-reference table.
+	sourceObjects int
+}
+
+// loadDocument reads the objects reachable from the trailer of data. Objects
+// in object streams are loaded like any other, and references to missing
+// objects become null.
+func loadDocument(data []byte) (*document, error) {
+	parser, err := core.NewParser(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	encrypted, err := parser.IsEncrypted()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	if encrypted {
+		return nil, errors.New("encrypted PDFs cannot be rewritten")
+	}
+	trailer := parser.GetTrailer()
+	if trailer == nil {
+		return nil, errors.New("missing trailer")
+	}
+	root, ok := trailer.Get("Root").(*core.PdfObjectReference)
+	if !ok {
+		return nil, errors.New("trailer /Root is not a reference")
+	}
+
+	doc := &document{
+		version:  "1.4",
+		objects:  make(map[int64]core.PdfObject),
+		trailer:  core.MakeDict(),
+		catalog:  root.ObjectNumber,
+		pageTree: make(map[int64]bool),
+		// Objects in use, counting those in object streams.
+		sourceObjects: len(parser.GetXrefTable().ObjectMap),
+	}
+	if match := headerVersionPattern.FindSubmatch(data); match != nil {
+		doc.version = fmt.Sprintf("%c.%c", match[1][0], match[2][0])
+	}
+	for _, key := range []core.PdfObjectName{"Root", "Info", "ID"} {
+		if value := trailer.Get(key); value != nil {
+			doc.trailer.Set(key, value)
+		}
+	}
+
+	queue := []core.PdfObject{doc.trailer}
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		rewriteReferences(obj, func(ref *core.PdfObjectReference) core.PdfObject {
+			if _, ok := doc.objects[ref.ObjectNumber]; ok {
+				return ref
+			}
+			target, err := parser.LookupByNumber(int(ref.ObjectNumber))
+			if err != nil {
+				return core.MakeNull()
+			}
+			switch value := target.(type) {
+			case *core.PdfObjectStream:
+				// Lengths are written directly, which also drops the
+				// objects that held indirect lengths.
+				value.Set("Length", core.MakeInteger(int64(len(value.Stream))))
+			case *core.PdfIndirectObject:
+			default:
+				return core.MakeNull()
+			}
+			doc.objects[ref.ObjectNumber] = target
+			queue = append(queue, target)
+			return ref
+		})
+	}
+
+	if _, ok := core.GetDict(doc.direct(doc.catalog)); !ok {
+		return nil, errors.New("missing catalog")
+	}
+	catalog, _ := core.GetDict(doc.direct(doc.catalog))
+	if ref, ok := catalog.Get("Pages").(*core.PdfObjectReference); ok {
+		doc.collectPages(ref.ObjectNumber)
+	}
+	return doc, nil
+}
+
+// direct returns the dictionary, array or other value of an object.
+func (d *document) direct(number int64) core.PdfObject {
+	switch obj := d.objects[number].(type) {
+	case *core.PdfIndirectObject:
+		return obj.PdfObject
+	case *core.PdfObjectStream:
+		return obj.PdfObjectDictionary
+	}
+	return nil
+}
+
+// collectPages walks the page tree below node, recording the leaf pages in
+// order.
+func (d *document) collectPages(node int64) {
+	dict, ok := core.GetDict(d.direct(node))
+	if !ok || d.pageTree[node] {
+		return
+	}
+	d.pageTree[node] = true
+	kids, ok := core.GetArray(dict.Get("Kids"))
+	if name, _ := core.GetNameVal(dict.Get("Type")); name == "Page" || !ok {
+		d.pages = append(d.pages, node)
+		return
+	}
+	for _, kid := range kids.Elements() {
+		if ref, ok := kid.(*core.PdfObjectReference); ok {
+			d.collectPages(ref.ObjectNumber)
+		}
+	}
+}
+
+// references returns the objects referenced by an object, in order of
+// appearance. Parent links are skipped when skipParent is set, so walking a
+// page does not climb into the page tree or an annotation's form field.
+func (d *document) references(number int64, skipParent bool) []int64 {
+	var refs []int64
+	var walk func(obj core.PdfObject)
+	walk = func(obj core.PdfObject) {
+		switch value := obj.(type) {
+		case *core.PdfObjectReference:
+			refs = append(refs, value.ObjectNumber)
+		case *core.PdfIndirectObject:
+			walk(value.PdfObject)
+		case *core.PdfObjectStream:
+			walk(value.PdfObjectDictionary)
+		case *core.PdfObjectDictionary:
+			for _, key := range value.Keys() {
+				if skipParent && key == "Parent" {
+					continue
+				}
+				walk(value.Get(key))
+			}
+		case *core.PdfObjectArray:
+			for _, element := range value.Elements() {
+				walk(element)
+			}
+		}
+	}
+	walk(d.objects[number])
+	return refs
+}
+
+// closure returns the objects reachable from start through references,
+// in breadth-first order, without entering objects for which stop reports
+// true. Parent links are not followed.
+func (d *document) closure(start []int64, stop func(int64) bool) []int64 {
+	seen := make(map[int64]bool, len(start))
+	order := make([]int64, 0, len(start))
+	for _, number := range start {
+		if !seen[number] {
+			seen[number] = true
+			order = append(order, number)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		for _, ref := range d.references(order[i], true) {
+			if seen[ref] || stop(ref) {
+				continue
+			}
+			if _, ok := d.objects[ref]; !ok {
+				continue
+			}
+			seen[ref] = true
+			order = append(order, ref)
+		}
+	}
+	return order
+}
+
+// rewriteReferences calls fn for every reference in obj, including those
+// nested in direct dictionaries and arrays, and replaces the reference with
+// the result.
+func rewriteReferences(obj core.PdfObject, fn func(*core.PdfObjectReference) core.PdfObject) {
+	switch value := obj.(type) {
+	case *core.PdfIndirectObject:
+		if ref, ok := value.PdfObject.(*core.PdfObjectReference); ok {
+			value.PdfObject = fn(ref)
+			return
+		}
+		rewriteReferences(value.PdfObject, fn)
+	case *core.PdfObjectStream:
+		rewriteReferences(value.PdfObjectDictionary, fn)
+	case *core.PdfObjectDictionary:
+		for _, key := range value.Keys() {
+			if ref, ok := value.Get(key).(*core.PdfObjectReference); ok {
+				value.Set(key, fn(ref))
+				continue
+			}
+			rewriteReferences(value.Get(key), fn)
+		}
+	case *core.PdfObjectArray:
+		for i, element := range value.Elements() {
+			if ref, ok := element.(*core.PdfObjectReference); ok {
+				_ = value.Set(i, fn(ref))
+				continue
+			}
+			rewriteReferences(element, fn)
+		}
+	}
+}
+
+// renumber replaces every object number with its entry in numbers.
+// References to objects without one become null.
+func (d *document) renumber(numbers map[int64]int64) {
+	fn := func(ref *core.PdfObjectReference) core.PdfObject {
+		if number, ok := numbers[ref.ObjectNumber]; ok {
+			return &core.PdfObjectReference{ObjectNumber: number}
+		}
+		return core.MakeNull()
+	}
+	for _, obj := range d.objects {
+		rewriteReferences(obj, fn)
+	}
+	rewriteReferences(d.trailer, fn)
+
+	objects := make(map[int64]core.PdfObject, len(d.objects))
+	for old, obj := range d.objects {
+		if number, ok := numbers[old]; ok {
+			objects[number] = obj
+		}
+	}
+	d.objects = objects
+}
+
+// serialize writes an object as "n 0 obj ... endobj".
+func (d *document) serialize(number int64) []byte {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%d 0 obj\n", number)
+	switch obj := d.objects[number].(type) {
+	case *core.PdfObjectStream:
+		out.WriteString(obj.PdfObjectDictionary.WriteString())
+		out.WriteString("\nstream\n")
+		out.Write(obj.Stream)
+		out.WriteString("\nendstream")
+	case *core.PdfIndirectObject:
+		if obj.PdfObject == nil {
+			out.WriteString("null")
+		} else {
+			out.WriteString(obj.PdfObject.WriteString())
+		}
+	}
+	out.WriteString("\nendobj\n")
+	return out.Bytes()
+}
+
+// linearization is the object layout of a linearized file, in file order.
+type linearization struct {
+	// documentObjects are the catalog and the objects readers need before
+	// the first page.
+	documentObjects []int64
+	// pageObjects lists the objects of each page; those of the first page
+	// include every object it uses.
+	pageObjects [][]int64
+	// pageShared lists the objects each page uses from the first page or
+	// the shared objects section.
+	pageShared [][]int64
+	// shared are the objects used by several pages other than the first.
+	shared []int64
+	// other are the objects no page uses, such as outlines and the page
+	// tree.
+	other []int64
+}
+
+// plan assigns every object to a part of the linearized file.
+func (d *document) plan() *linearization {
+	plan := &linearization{}
+	assigned := make(map[int64]bool)
+	stopAtPages := func(number int64) bool {
+		return d.pageTree[number] || number == d.catalog
+	}
+
+	first := d.closure([]int64{d.pages[0]}, stopAtPages)
+	plan.pageObjects = append(plan.pageObjects, first)
+	plan.pageShared = append(plan.pageShared, nil)
+	for _, number := range first {
+		assigned[number] = true
+	}
+
+	uses := make(map[int64]int)
+	closures := make([][]int64, len(d.pages))
+	for i := 1; i < len(d.pages); i++ {
+		closures[i] = d.closure([]int64{d.pages[i]}, stopAtPages)
+		for _, number := range closures[i] {
+			if !assigned[number] {
+				uses[number]++
+			}
+		}
+	}
+	sharedSeen := make(map[int64]bool)
+	for i := 1; i < len(d.pages); i++ {
+		var own, shared []int64
+		for _, number := range closures[i] {
+			switch {
+			case assigned[number] || uses[number] > 1:
+				shared = append(shared, number)
+				if !assigned[number] && !sharedSeen[number] {
+					sharedSeen[number] = true
+					plan.shared = append(plan.shared, number)
+				}
+			default:
+				own = append(own, number)
+			}
+		}
+		plan.pageObjects = append(plan.pageObjects, own)
+		plan.pageShared = append(plan.pageShared, shared)
+	}
+	for i := 1; i < len(plan.pageObjects); i++ {
+		for _, number := range plan.pageObjects[i] {
+			assigned[number] = true
+		}
+	}
+	for _, number := range plan.shared {
+		assigned[number] = true
+	}
+
+	// Document-level objects a reader needs to open the document.
+	catalog, _ := core.GetDict(d.direct(d.catalog))
+	start := []int64{d.catalog}
+	keys := []core.PdfObjectName{"ViewerPreferences", "OpenAction", "AcroForm", "Threads"}
+	if mode, _ := core.GetNameVal(catalog.Get("PageMode")); mode == "UseOutlines" {
+		keys = append(keys, "Outlines")
+	}
+	for _, key := range keys {
+		if ref, ok := catalog.Get(key).(*core.PdfObjectReference); ok && !assigned[ref.ObjectNumber] {
+			start = append(start, ref.ObjectNumber)
+		}
+	}
+	stopAtAssigned := func(number int64) bool {
+		return assigned[number] || d.pageTree[number]
+	}
+	plan.documentObjects = d.closure(start[:1], func(number int64) bool { return true })
+	for _, number := range d.closure(start[1:], stopAtAssigned) {
+		if number != d.catalog {
+			plan.documentObjects = append(plan.documentObjects, number)
+		}
+	}
+	for _, number := range plan.documentObjects {
+		assigned[number] = true
+	}
+
+	for number := range d.objects {
+		if !assigned[number] {
+			plan.other = append(plan.other, number)
+		}
+	}
+	sort.Slice(plan.other, func(i, j int) bool { return plan.other[i] < plan.other[j] })
+	return plan
+}
+
+// linearize writes the document as a linearized file (ISO 32000-1 Annex F):
+// the linearization dictionary, the first-page cross-reference section, the
+// document-level objects, the primary hint stream and the first page, then
+// the remaining pages, the shared objects, everything else and the main
+// cross-reference section.
+func (d *document) linearize() ([]byte, error) {
+	if len(d.pages) == 0 {
+		return nil, errors.New("document has no pages")
+	}
+	plan := d.plan()
+
+	// Objects after the first page are numbered from 1 in file order; the
+	// first-page section follows, ending with the hint stream.
+	numbers := make(map[int64]int64, len(d.objects))
+	next := int64(1)
+	number := func(objects []int64) {
+		for _, old := range objects {
+			numbers[old] = next
+			next++
+		}
+	}
+	for _, objects := range plan.pageObjects[1:] {
+		number(objects)
+	}
+	number(plan.shared)
+	number(plan.other)
+	mainCount := next
+	linearizationNumber := next
+	next++
+	number(plan.documentObjects)
+	number(plan.pageObjects[0])
+	hintNumber := next
+	size := hintNumber + 1
+
+	renumbered := func(objects []int64) []int64 {
+		out := make([]int64, len(objects))
+		for i, old := range objects {
+			out[i] = numbers[old]
+		}
+		return out
+	}
+	documentObjects := renumbered(plan.documentObjects)
+	pageObjects := make([][]int64, len(plan.pageObjects))
+	pageShared := make([][]int64, len(plan.pageShared))
+	for i := range plan.pageObjects {
+		pageObjects[i] = renumbered(plan.pageObjects[i])
+		pageShared[i] = renumbered(plan.pageShared[i])
+	}
+	shared := renumbered(plan.shared)
+	other := renumbered(plan.other)
+	d.renumber(numbers)
+
+	bodies := make(map[int64][]byte, len(d.objects))
+	for number := range d.objects {
+		bodies[number] = d.serialize(number)
+	}
+	length := func(objects []int64) int64 {
+		var total int64
+		for _, number := range objects {
+			total += int64(len(bodies[number]))
+		}
+		return total
+	}
+
+	hints := &hintTables{bodies: bodies, pageObjects: pageObjects, pageShared: pageShared, shared: shared}
+	hintBody := func(firstPage, firstShared int64) []byte {
+		table, sharedOffset := hints.encode(firstPage, firstShared)
+		var out bytes.Buffer
+		fmt.Fprintf(&out, "%d 0 obj\n<< /S %d /Length %d >>\nstream\n", hintNumber, sharedOffset, len(table))
+		out.Write(table)
+		out.WriteString("\nendstream\nendobj\n")
+		return out.Bytes()
+	}
+
+	// Lay out the file with fixed-width placeholders for the values that
+	// depend on offsets, then fill them in.
+	const placeholder = int64(9999999999)
+	header := "%PDF-" + d.version + "\n" + binaryComment
+	linearizationBody := func(fileLength, hintOffset, hintLength, firstPageEnd, mainXref int64) string {
+		return fmt.Sprintf("%d 0 obj\n<< /Linearized 1 /L %d /H [ %d %d ] /O %d /E %d /N %d /T %d >>",
+			linearizationNumber, fileLength, hintOffset, hintLength, numbers[d.pages[0]], firstPageEnd, len(d.pages), mainXref)
+	}
+	linearizationWidth := len(linearizationBody(placeholder, placeholder, placeholder, placeholder, placeholder))
+
+	firstTrailer := func(mainXrefOffset int64) string {
+		trailer := core.MakeDict()
+		trailer.Set("Size", core.MakeInteger(size))
+		for _, key := range d.trailer.Keys() {
+			trailer.Set(key, d.trailer.Get(key))
+		}
+		trailer.Set("Prev", core.MakeInteger(mainXrefOffset))
+		return trailer.WriteString()
+	}
+	firstTrailerWidth := len(firstTrailer(placeholder))
+	firstCount := size - linearizationNumber
+	firstXrefLength := int64(len(fmt.Sprintf("xref\n%d %d\n", linearizationNumber, firstCount))) + 20*firstCount +
+		int64(len("trailer\n")+firstTrailerWidth+len("\nstartxref\n0\n%%EOF\n"))
+
+	offsets := make(map[int64]int64, size)
+	pos := int64(len(header))
+	linearizationOffset := pos
+	pos += int64(linearizationWidth + len("\nendobj\n"))
+	firstXrefOffset := pos
+	pos += firstXrefLength
+	place := func(objects []int64) {
+		for _, number := range objects {
+			offsets[number] = pos
+			pos += int64(len(bodies[number]))
+		}
+	}
+	place(documentObjects)
+	hintOffset := pos
+	hintLength := int64(len(hintBody(0, 0)))
+	pos += hintLength
+	for _, objects := range pageObjects {
+		place(objects)
+	}
+	firstPageEnd := offsets[pageObjects[0][0]] + length(pageObjects[0])
+	place(shared)
+	place(other)
+	mainXrefOffset := pos
+	mainXrefHeader := fmt.Sprintf("xref\n0 %d", mainCount)
+	mainXref := mainXrefOffset + int64(len(mainXrefHeader))
+
+	// Hint table offsets are recorded as if the hint stream were absent.
+	var firstSharedOffset int64
+	if len(shared) > 0 {
+		firstSharedOffset = offsets[shared[0]] - hintLength
+	}
+	hintObject := hintBody(offsets[pageObjects[0][0]]-hintLength, firstSharedOffset)
+
+	var tail bytes.Buffer
+	tail.WriteString(mainXrefHeader + "\n")
+	tail.WriteString("0000000000 65535 f \n")
+	for number := int64(1); number < mainCount; number++ {
+		fmt.Fprintf(&tail, "%010d 00000 n \n", offsets[number])
+	}
+	fmt.Fprintf(&tail, "trailer\n<< /Size %d >>\nstartxref\n%d\n%%%%EOF\n", mainCount, firstXrefOffset)
+	fileLength := mainXrefOffset + int64(tail.Len())
+
+	var out bytes.Buffer
+	out.Grow(int(fileLength))
+	out.WriteString(header)
+	out.WriteString(padRight(linearizationBody(fileLength, hintOffset, hintLength, firstPageEnd, mainXref), linearizationWidth))
+	out.WriteString("\nendobj\n")
+
+	offsets[linearizationNumber] = linearizationOffset
+	offsets[hintNumber] = hintOffset
+	fmt.Fprintf(&out, "xref\n%d %d\n", linearizationNumber, firstCount)
+	for number := linearizationNumber; number < size; number++ {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offsets[number])
+	}
+	fmt.Fprintf(&out, "trailer\n%s\nstartxref\n0\n%%%%EOF\n", padRight(firstTrailer(mainXrefOffset), firstTrailerWidth))
+
+	for _, number := range documentObjects {
+		out.Write(bodies[number])
+	}
+	out.Write(hintObject)
+	for _, objects := range pageObjects {
+		for _, number := range objects {
+			out.Write(bodies[number])
+		}
+	}
+	for _, objects := range [][]int64{shared, other} {
+		for _, number := range objects {
+			out.Write(bodies[number])
+		}
+	}
+	out.Write(tail.Bytes())
+
+	if int64(out.Len()) != fileLength {
+		return nil, fmt.Errorf("linearized layout is %d bytes, wrote %d", fileLength, out.Len())
+	}
+	return out.Bytes(), nil
+}
+
+// padRight pads s with spaces to width.
+func padRight(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// hintTables builds the page offset and shared object hint tables of the
+// primary hint stream (ISO 32000-1 F.4).
+type hintTables struct {
+	bodies      map[int64][]byte
+	pageObjects [][]int64
+	pageShared  [][]int64
+	shared      []int64
+}
+
+// encode returns the hint stream data and the offset of the shared object
+// hint table within it. firstPage and firstShared are the recorded offsets
+// of the first page and of the first object in the shared objects section.
+func (h *hintTables) encode(firstPage, firstShared int64) ([]byte, int) {
+	objectLength := func(number int64) uint64 { return uint64(len(h.bodies[number])) }
+
+	// Shared object identifiers index the first page's objects, then the
+	// shared objects section.
+	identifiers := make(map[int64]uint64)
+	var groupLengths []uint64
+	for _, number := range h.pageObjects[0] {
+		identifiers[number] = uint64(len(groupLengths))
+		groupLengths = append(groupLengths, objectLength(number))
+	}
+	for _, number := range h.shared {
+		identifiers[number] = uint64(len(groupLengths))
+		groupLengths = append(groupLengths, objectLength(number))
+	}
+
+	pages := len(h.pageObjects)
+	objectCounts := make([]uint64, pages)
+	pageLengths := make([]uint64, pages)
+	var maxShared uint64
+	for i, objects := range h.pageObjects {
+		objectCounts[i] = uint64(len(objects))
+		for _, number := range objects {
+			pageLengths[i] += objectLength(number)
+		}
+		if n := uint64(len(h.pageShared[i])); n > maxShared {
+			maxShared = n
+		}
+	}
+	leastObjects, objectBits := deltaRange(objectCounts)
+	leastLength, lengthBits := deltaRange(pageLengths)
+	identifierBits := bitsFor(uint64(len(groupLengths)) - 1)
+
+	var w bitWriter
+	w.write(leastObjects, 32)
+	w.write(uint64(firstPage), 32)
+	w.write(uint64(objectBits), 16)
+	w.write(leastLength, 32)
+	w.write(uint64(lengthBits), 16)
+	// Like most writers, describe each page's content as the whole page.
+	w.write(0, 32)
+	w.write(0, 16)
+	w.write(leastLength, 32)
+	w.write(uint64(lengthBits), 16)
+	w.write(uint64(bitsFor(maxShared)), 16)
+	w.write(uint64(identifierBits), 16)
+	w.write(0, 16)
+	w.write(1, 16)
+
+	for _, count := range objectCounts {
+		w.write(count-leastObjects, objectBits)
+	}
+	w.flush()
+	for _, length := range pageLengths {
+		w.write(length-leastLength, lengthBits)
+	}
+	w.flush()
+	for _, refs := range h.pageShared {
+		w.write(uint64(len(refs)), bitsFor(maxShared))
+	}
+	w.flush()
+	for _, refs := range h.pageShared {
+		for _, number := range refs {
+			w.write(identifiers[number], identifierBits)
+		}
+	}
+	w.flush()
+	// Numerators have no bits; content offsets are all zero.
+	for _, length := range pageLengths {
+		w.write(length-leastLength, lengthBits)
+	}
+	w.flush()
+
+	sharedOffset := len(w.bytes())
+	leastGroup, groupBits := deltaRange(groupLengths)
+	var firstSharedNumber int64
+	if len(h.shared) > 0 {
+		firstSharedNumber = h.shared[0]
+	}
+	w.write(uint64(firstSharedNumber), 32)
+	w.write(uint64(firstShared), 32)
+	w.write(uint64(len(h.pageObjects[0])), 32)
+	w.write(uint64(len(groupLengths)), 32)
+	w.write(0, 16)
+	w.write(leastGroup, 32)
+	w.write(uint64(groupBits), 16)
+	for _, length := range groupLengths {
+		w.write(length-leastGroup, groupBits)
+	}
+	w.flush()
+	for range groupLengths {
+		w.write(0, 1)
+	}
+	w.flush()
+	return w.bytes(), sharedOffset
+}
+
+// deltaRange returns the least value and the bits needed for the
+// difference between the greatest and the least.
+func deltaRange(values []uint64) (uint64, int) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	least, greatest := values[0], values[0]
+	for _, value := range values {
+		if value < least {
+			least = value
+		}
+		if value > greatest {
+			greatest = value
+		}
+	}
+	return least, bitsFor(greatest - least)
+}
+
+// bitsFor returns the bits needed to represent value.
+func bitsFor(value uint64) int {
+	return bits.Len64(value)
+}
+
+// bitWriter packs values most significant bit first.
+type bitWriter struct {
+	out     []byte
+	current byte
+	count   int
+}
+
+func (w *bitWriter) write(value uint64, width int) {
+	for i := width - 1; i >= 0; i-- {
+		w.current = w.current<<1 | byte(value>>uint(i)&1)
+		w.count++
+		if w.count == 8 {
+			w.out = append(w.out, w.current)
+			w.current, w.count = 0, 0
+		}
+	}
+}
+
+// flush pads the last byte with zero bits.
+func (w *bitWriter) flush() {
+	if w.count > 0 {
+		w.out = append(w.out, w.current<<uint(8-w.count))
+		w.current, w.count = 0, 0
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	return w.out
+}
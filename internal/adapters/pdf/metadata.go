@@ -0,0 +1,371 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Metadata error codes.
+const (
+	ErrorCodePDFMeta001 = "PDF-META-001"
+	ErrorCodePDFMeta002 = "PDF-META-002"
+	ErrorCodePDFMeta003 = "PDF-META-003"
+	ErrorCodePDFMeta004 = "PDF-META-004"
+)
+
+// XMP namespaces read by the metadata checks.
+const (
+	rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	dcNamespace  = "http://purl.org/dc/elements/1.1/"
+	xmpNamespace = "http://ns.adobe.com/xap/1.0/"
+	xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+)
+
+// pdfDatePattern matches a PDF date string, D:YYYYMMDDHHmmSSOHH'mm'. Every
+// field after the year is optional, and so is the D: prefix in practice.
+var pdfDatePattern = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(?:([Zz])(?:00'?(?:00'?)?)?|([+-])(\d{2})'?(?:(\d{2})'?)?)?$`)
+
+// xmpDateLayouts are the ISO 8601 forms allowed for XMP dates.
+var xmpDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// xmpMetadata holds the XMP properties compared with the Info dictionary.
+type xmpMetadata struct {
+	Title      string
+	Creators   []string
+	CreateDate string
+}
+
+// infoMetadata holds the Info dictionary entries compared with XMP.
+type infoMetadata struct {
+	Title        string
+	Author       string
+	CreationDate string
+}
+
+// validateMetadata compares the Info dictionary with the catalog XMP
+// packet and checks their dates.
+func (v *StructureValidator) validateMetadata(parser *core.PdfParser, result *StructureValidationResult) {
+	trailer := parser.GetTrailer()
+	if trailer == nil {
+		return
+	}
+	// Text strings cannot be read without the decryption key.
+	if result.Encryption != nil && !result.Encryption.Authenticated {
+		return
+	}
+	catalog, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Root")))
+	if !ok {
+		return
+	}
+
+	info := readInfoMetadata(trailer)
+	for _, date := range []struct{ key, value string }{
+		{"CreationDate", info.CreationDate},
+		{"ModDate", readInfoString(trailer, "ModDate")},
+	} {
+		if date.value == "" {
+			continue
+		}
+		if _, ok := parsePDFDate(date.value); !ok {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFMeta002,
+				Message: fmt.Sprintf("/Info /%s is not a valid PDF date", date.key),
+				Details: map[string]interface{}{
+					"field":    date.key,
+					"found":    date.value,
+					"expected": "D:YYYYMMDDHHmmSSOHH'mm'",
+				},
+			})
+		}
+	}
+
+	var xmp *xmpMetadata
+	if obj := catalog.Get("Metadata"); obj != nil {
+		details := map[string]interface{}{}
+		if ref, ok := obj.(*core.PdfObjectReference); ok {
+			details["object"] = ref.ObjectNumber
+		}
+		packet, err := readMetadataStream(obj)
+		if err == nil {
+			xmp, err = parseXMP(packet)
+		}
+		if err != nil {
+			details["error"] = err.Error()
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFMeta003,
+				Message: "Catalog /Metadata is not a well-formed XMP packet",
+				Details: details,
+			})
+		}
+	}
+
+	if xmp != nil {
+		if xmp.CreateDate != "" {
+			if _, ok := parseXMPDate(xmp.CreateDate); !ok {
+				result.Warnings = append(result.Warnings, ValidationError{
+					Code:    ErrorCodePDFMeta002,
+					Message: "XMP xmp:CreateDate is not a valid ISO 8601 date",
+					Details: map[string]interface{}{
+						"field":    "xmp:CreateDate",
+						"found":    xmp.CreateDate,
+						"expected": "YYYY-MM-DDThh:mm:ssTZD",
+					},
+				})
+			}
+		}
+		compareMetadata(info, xmp, result)
+	}
+
+	if prefs, ok := core.GetDict(core.TraceToDirectObject(catalog.Get("ViewerPreferences"))); ok {
+		display, _ := core.GetBoolVal(core.TraceToDirectObject(prefs.Get("DisplayDocTitle")))
+		if display && strings.TrimSpace(info.Title) == "" && (xmp == nil || strings.TrimSpace(xmp.Title) == "") {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFMeta004,
+				Message: "/DisplayDocTitle is set but the document has no title",
+				Details: map[string]interface{}{},
+			})
+		}
+	}
+}
+
+// compareMetadata reports Info entries that disagree with their XMP
+// counterparts.
+func compareMetadata(info infoMetadata, xmp *xmpMetadata, result *StructureValidationResult) {
+	report := func(field, infoKey, xmpKey, infoValue, xmpValue string) {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFMeta001,
+			Message: fmt.Sprintf("/Info /%s does not match XMP %s", infoKey, xmpKey),
+			Details: map[string]interface{}{
+				"field": field,
+				"info":  infoValue,
+				"xmp":   xmpValue,
+			},
+		})
+	}
+
+	if info.Title != xmp.Title {
+		report("title", "Title", "dc:title", info.Title, xmp.Title)
+	}
+	if !authorsMatch(info.Author, xmp.Creators) {
+		report("author", "Author", "dc:creator", info.Author, strings.Join(xmp.Creators, "; "))
+	}
+	if !datesMatch(info.CreationDate, xmp.CreateDate) {
+		report("creation_date", "CreationDate", "xmp:CreateDate", info.CreationDate, xmp.CreateDate)
+	}
+}
+
+// authorsMatch reports whether /Author names the dc:creator entries,
+// separated by semicolons or commas.
+func authorsMatch(author string, creators []string) bool {
+	author = strings.TrimSpace(author)
+	if len(creators) == 0 {
+		return author == ""
+	}
+	for _, separator := range []string{"; ", ", ", ";", ","} {
+		if author == strings.Join(creators, separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// datesMatch reports whether a PDF date and an XMP date are the same
+// instant. Dates that fail to parse are not compared, since PDF-META-002
+// already reports them.
+func datesMatch(pdfDate, xmpDate string) bool {
+	if pdfDate == "" || xmpDate == "" {
+		return pdfDate == xmpDate
+	}
+	left, leftOK := parsePDFDate(pdfDate)
+	right, rightOK := parseXMPDate(xmpDate)
+	if !leftOK || !rightOK {
+		return true
+	}
+	return left.Equal(right)
+}
+
+// readInfoMetadata reads the Info entries compared with XMP.
+func readInfoMetadata(trailer *core.PdfObjectDictionary) infoMetadata {
+	return infoMetadata{
+		Title:        readInfoString(trailer, "Title"),
+		Author:       readInfoString(trailer, "Author"),
+		CreationDate: readInfoString(trailer, "CreationDate"),
+	}
+}
+
+// readInfoString returns a text entry of the trailer's Info dictionary.
+func readInfoString(trailer *core.PdfObjectDictionary, key core.PdfObjectName) string {
+	info, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Info")))
+	if !ok {
+		return ""
+	}
+	value, ok := core.GetString(core.TraceToDirectObject(info.Get(key)))
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(value.Decoded())
+}
+
+// readMetadataStream returns the decoded XMP packet of a /Metadata entry.
+func readMetadataStream(obj core.PdfObject) ([]byte, error) {
+	stream, ok := core.GetStream(core.TraceToDirectObject(obj))
+	if !ok {
+		return nil, errors.New("/Metadata is not a stream")
+	}
+	return core.DecodeStream(stream)
+}
+
+// parseXMP reads the title, creators and creation date of an XMP packet,
+// and fails if the packet is not well-formed XML.
+func parseXMP(packet []byte) (*xmpMetadata, error) {
+	metadata := &xmpMetadata{}
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+
+	var property string
+	var inItem, defaultTitle, titleSet bool
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch {
+			case element.Name.Space == rdfNamespace && element.Name.Local == "Description":
+				for _, attr := range element.Attr {
+					if attr.Name.Space == xmpNamespace && attr.Name.Local == "CreateDate" {
+						metadata.CreateDate = strings.TrimSpace(attr.Value)
+					}
+				}
+			case element.Name.Space == dcNamespace && (element.Name.Local == "title" || element.Name.Local == "creator"):
+				property = element.Name.Local
+			case element.Name.Space == xmpNamespace && element.Name.Local == "CreateDate":
+				property = "CreateDate"
+				text.Reset()
+			case element.Name.Space == rdfNamespace && element.Name.Local == "li" && property != "":
+				inItem = true
+				defaultTitle = false
+				for _, attr := range element.Attr {
+					if attr.Name.Space == xmlNamespace && attr.Name.Local == "lang" && attr.Value == "x-default" {
+						defaultTitle = true
+					}
+				}
+				text.Reset()
+			}
+		case xml.CharData:
+			if inItem || property == "CreateDate" {
+				text.Write(element)
+			}
+		case xml.EndElement:
+			switch {
+			case element.Name.Space == rdfNamespace && element.Name.Local == "li" && inItem:
+				inItem = false
+				value := strings.TrimSpace(text.String())
+				switch property {
+				case "title":
+					if !titleSet || defaultTitle {
+						metadata.Title = value
+						titleSet = true
+					}
+				case "creator":
+					if value != "" {
+						metadata.Creators = append(metadata.Creators, value)
+					}
+				}
+			case element.Name.Space == xmpNamespace && element.Name.Local == "CreateDate":
+				metadata.CreateDate = strings.TrimSpace(text.String())
+				property = ""
+			case element.Name.Space == dcNamespace && (element.Name.Local == "title" || element.Name.Local == "creator"):
+				property = ""
+			}
+		}
+	}
+	return metadata, nil
+}
+
+// parsePDFDate parses a PDF date string. Missing fields default to the
+// start of the period and a missing offset to UTC.
+func parsePDFDate(value string) (time.Time, bool) {
+	match := pdfDatePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return time.Time{}, false
+	}
+	field := func(index, fallback int) int {
+		if match[index] == "" {
+			return fallback
+		}
+		n, _ := strconv.Atoi(match[index])
+		return n
+	}
+	year, month, day := field(1, 0), field(2, 1), field(3, 1)
+	hour, minute, second := field(4, 0), field(5, 0), field(6, 0)
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	location := time.UTC
+	if match[8] != "" {
+		offset := field(9, 0)*3600 + field(10, 0)*60
+		if match[8] == "-" {
+			offset = -offset
+		}
+		location = time.FixedZone("", offset)
+	}
+	date := time.Date(year, time.Month(month), day, hour, minute, second, 0, location)
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// parseXMPDate parses an XMP date, an ISO 8601 subset. A missing offset is
+// read as UTC.
+func parseXMPDate(value string) (time.Time, bool) {
+	for _, layout := range xmpDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatPDFDate formats a date as a PDF date string.
+func formatPDFDate(date time.Time) string {
+	_, offset := date.Zone()
+	if offset == 0 {
+		return date.Format("D:20060102150405Z")
+	}
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%c%02d'%02d'", date.Format("D:20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// formatXMPDate formats a date as an XMP date.
+func formatXMPDate(date time.Time) string {
+	return date.Format(time.RFC3339)
+}
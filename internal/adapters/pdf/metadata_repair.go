@@ -0,0 +1,265 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// syncMetadata makes the Info dictionary agree with the XMP packet, with
+// XMP authoritative. Info values missing from XMP are added to it. The
+// changes are appended as an incremental update, leaving the original
+// revision intact. It reports whether anything changed.
+func syncMetadata(data []byte) ([]byte, bool, error) {
+	parser, err := core.NewParser(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	if encrypted, err := parser.IsEncrypted(); err != nil || encrypted {
+		return nil, false, errors.New("metadata of encrypted PDFs cannot be updated")
+	}
+
+	trailer := parser.GetTrailer()
+	if trailer == nil {
+		return nil, false, errors.New("missing trailer")
+	}
+	catalog, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Root")))
+	if !ok {
+		return nil, false, errors.New("missing catalog")
+	}
+	metadataRef, ok := catalog.Get("Metadata").(*core.PdfObjectReference)
+	if !ok {
+		// Without an XMP packet there is nothing to synchronize with.
+		return data, false, nil
+	}
+	packet, err := readMetadataStream(metadataRef)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read XMP packet: %w", err)
+	}
+	xmp, err := parseXMP(packet)
+	if err != nil {
+		return nil, false, fmt.Errorf("XMP packet is not well-formed: %w", err)
+	}
+
+	info := readInfoMetadata(trailer)
+	infoDict := core.MakeDict()
+	if existing, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Info"))); ok {
+		for _, key := range existing.Keys() {
+			infoDict.Set(key, existing.Get(key))
+		}
+	}
+
+	infoChanged := false
+	setInfo := func(key core.PdfObjectName, value string) {
+		if value == "" {
+			infoDict.Remove(key)
+		} else {
+			infoDict.Set(key, core.MakeEncodedString(value, !isASCII(value)))
+		}
+		infoChanged = true
+	}
+
+	var properties strings.Builder
+	if xmp.Title != "" && xmp.Title != info.Title {
+		setInfo("Title", xmp.Title)
+	} else if xmp.Title == "" && info.Title != "" {
+		writeXMPTitle(&properties, info.Title)
+	}
+
+	if len(xmp.Creators) > 0 && !authorsMatch(info.Author, xmp.Creators) {
+		setInfo("Author", strings.Join(xmp.Creators, "; "))
+	} else if len(xmp.Creators) == 0 && info.Author != "" {
+		writeXMPCreators(&properties, splitAuthors(info.Author))
+	}
+
+	xmpDate, xmpDateOK := parseXMPDate(xmp.CreateDate)
+	infoDate, infoDateOK := parsePDFDate(info.CreationDate)
+	switch {
+	case xmpDateOK && (!infoDateOK || !xmpDate.Equal(infoDate)):
+		setInfo("CreationDate", formatPDFDate(xmpDate))
+	case xmp.CreateDate == "" && infoDateOK:
+		writeXMPCreateDate(&properties, infoDate)
+	case info.CreationDate != "" && !infoDateOK && xmp.CreateDate == "":
+		setInfo("CreationDate", "")
+	}
+	if modDate := readInfoString(trailer, "ModDate"); modDate != "" {
+		if _, ok := parsePDFDate(modDate); !ok {
+			setInfo("ModDate", "")
+		}
+	}
+
+	var update bytes.Buffer
+	xrefEntries := make(map[int64][2]int64)
+	size, _ := core.GetIntVal(core.TraceToDirectObject(trailer.Get("Size")))
+	nextObject := int64(size)
+	writeObject := func(number, generation int64, body string) {
+		xrefEntries[number] = [2]int64{int64(len(data) + update.Len()), generation}
+		fmt.Fprintf(&update, "%d %d obj\n%s\nendobj\n", number, generation, body)
+	}
+
+	if properties.Len() > 0 {
+		updated, err := insertXMPProperties(packet, properties.String())
+		if err != nil {
+			return nil, false, err
+		}
+		writeObject(metadataRef.ObjectNumber, metadataRef.GenerationNumber,
+			fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(updated), updated))
+	}
+
+	var infoRef *core.PdfObjectReference
+	if infoChanged {
+		if ref, ok := trailer.Get("Info").(*core.PdfObjectReference); ok {
+			infoRef = ref
+		} else {
+			infoRef = &core.PdfObjectReference{ObjectNumber: nextObject}
+			nextObject++
+		}
+		writeObject(infoRef.ObjectNumber, infoRef.GenerationNumber, infoDict.WriteString())
+	}
+
+	if len(xrefEntries) == 0 {
+		return data, false, nil
+	}
+
+	out := make([]byte, 0, len(data)+update.Len()+512)
+	out = append(out, data...)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		// Keep object offsets correct by accounting for the separator.
+		out = append(out, '\n')
+		for number, entry := range xrefEntries {
+			xrefEntries[number] = [2]int64{entry[0] + 1, entry[1]}
+		}
+	}
+	out = append(out, update.Bytes()...)
+
+	xrefOffset := len(out)
+	var xref strings.Builder
+	xref.WriteString("xref\n")
+	for _, number := range sortedInt64Keys(xrefEntries) {
+		entry := xrefEntries[number]
+		fmt.Fprintf(&xref, "%d 1\n%010d %05d n \n", number, entry[0], entry[1])
+	}
+
+	newTrailer := core.MakeDict()
+	for _, key := range trailer.Keys() {
+		switch key {
+		case "Prev", "XRefStm", "Type", "W", "Index", "Length", "Filter", "DecodeParms":
+			continue
+		}
+		newTrailer.Set(key, trailer.Get(key))
+	}
+	newTrailer.Set("Size", core.MakeInteger(nextObject))
+	newTrailer.Set("Prev", core.MakeInteger(parser.GetXrefOffset()))
+	if infoRef != nil {
+		newTrailer.Set("Info", infoRef)
+	}
+	fmt.Fprintf(&xref, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", newTrailer.WriteString(), xrefOffset)
+
+	return append(out, xref.String()...), true, nil
+}
+
+// insertXMPProperties adds serialized properties to the first
+// rdf:Description of an XMP packet.
+func insertXMPProperties(packet []byte, properties string) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("XMP packet has no rdf:Description")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Space != rdfNamespace || start.Name.Local != "Description" {
+			continue
+		}
+
+		end := int(decoder.InputOffset())
+		tagStart := bytes.LastIndexByte(packet[:end], '<')
+		name := packet[tagStart+1 : end]
+		if i := bytes.IndexAny(name, " \t\r\n/>"); i >= 0 {
+			name = name[:i]
+		}
+
+		var out bytes.Buffer
+		if bytes.HasSuffix(packet[:end], []byte("/>")) {
+			// Expand a self-closing description to hold the properties.
+			out.Write(packet[:end-2])
+			out.WriteString(">")
+			out.WriteString(properties)
+			fmt.Fprintf(&out, "</%s>", name)
+		} else {
+			out.Write(packet[:end])
+			out.WriteString(properties)
+		}
+		out.Write(packet[end:])
+		return out.Bytes(), nil
+	}
+}
+
+// xmpPropertyNamespaces declares the prefixes used by inserted properties,
+// which may differ from those of the packet.
+const xmpPropertyNamespaces = ` xmlns:rdf="` + rdfNamespace + `"`
+
+func writeXMPTitle(out *strings.Builder, title string) {
+	fmt.Fprintf(out, `<dc:title xmlns:dc="%s"%s><rdf:Alt><rdf:li xml:lang="x-default">%s</rdf:li></rdf:Alt></dc:title>`,
+		dcNamespace, xmpPropertyNamespaces, escapeXMPText(title))
+}
+
+func writeXMPCreators(out *strings.Builder, creators []string) {
+	fmt.Fprintf(out, `<dc:creator xmlns:dc="%s"%s><rdf:Seq>`, dcNamespace, xmpPropertyNamespaces)
+	for _, creator := range creators {
+		fmt.Fprintf(out, "<rdf:li>%s</rdf:li>", escapeXMPText(creator))
+	}
+	out.WriteString("</rdf:Seq></dc:creator>")
+}
+
+func writeXMPCreateDate(out *strings.Builder, date time.Time) {
+	fmt.Fprintf(out, `<xmp:CreateDate xmlns:xmp="%s">%s</xmp:CreateDate>`, xmpNamespace, formatXMPDate(date))
+}
+
+func escapeXMPText(value string) string {
+	var out bytes.Buffer
+	_ = xml.EscapeText(&out, []byte(value))
+	return out.String()
+}
+
+// splitAuthors splits an /Author entry into dc:creator entries. Commas are
+// kept, since they often separate family and given names.
+func splitAuthors(author string) []string {
+	parts := strings.Split(author, ";")
+	creators := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			creators = append(creators, part)
+		}
+	}
+	return creators
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedInt64Keys(entries map[int64][2]int64) []int64 {
+	keys := make([]int64, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
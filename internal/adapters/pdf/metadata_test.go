@@ -0,0 +1,174 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// metadataPDF builds a one-page PDF with the given Info dictionary entries
+// and XMP rdf:Description content. An empty xmp omits /Metadata.
+func metadataPDF(info, xmp, catalog string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R " + catalog + " >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>",
+		"<< " + info + " >>",
+	}
+	if xmp != "" {
+		packet := `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>` +
+			`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+			`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/">` +
+			xmp + `</rdf:Description></rdf:RDF></x:xmpmeta><?xpacket end="w"?>`
+		objects = append(objects, fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(packet), packet))
+		objects[0] = "<< /Type /Catalog /Pages 2 0 R /Metadata 5 0 R " + catalog + " >>"
+	}
+	data := buildPDF(objects...)
+	return bytes.Replace(data, []byte("/Root 1 0 R >>"), []byte("/Root 1 0 R /Info 4 0 R >>"), 1)
+}
+
+const (
+	xmpTitle   = `<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Moby Dick</rdf:li></rdf:Alt></dc:title>`
+	xmpCreator = `<dc:creator><rdf:Seq><rdf:li>Herman Melville</rdf:li></rdf:Seq></dc:creator>`
+	xmpCreated = `<xmp:CreateDate>2024-03-01T10:30:00+01:00</xmp:CreateDate>`
+)
+
+func TestStructureValidator_Metadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "consistent",
+			data: metadataPDF("/Title (Moby Dick) /Author (Herman Melville) /CreationDate (D:20240301103000+01'00')", xmpTitle+xmpCreator+xmpCreated, ""),
+		},
+		{
+			name: "same instant in another offset",
+			data: metadataPDF("/Title (Moby Dick) /Author (Herman Melville) /CreationDate (D:20240301093000Z)", xmpTitle+xmpCreator+xmpCreated, ""),
+		},
+		{
+			name: "Info only",
+			data: metadataPDF("/Title (Moby Dick) /ModDate (D:2024)", "", ""),
+		},
+		{
+			name:         "title mismatch",
+			data:         metadataPDF("/Title (Untitled) /Author (Herman Melville) /CreationDate (D:20240301103000+01'00')", xmpTitle+xmpCreator+xmpCreated, ""),
+			wantWarnings: []string{ErrorCodePDFMeta001},
+		},
+		{
+			name:         "author and date mismatch",
+			data:         metadataPDF("/Title (Moby Dick) /Author (H. Melville) /CreationDate (D:20240302)", xmpTitle+xmpCreator+xmpCreated, ""),
+			wantWarnings: []string{ErrorCodePDFMeta001, ErrorCodePDFMeta001},
+		},
+		{
+			name:         "malformed Info dates",
+			data:         metadataPDF("/CreationDate (March 1, 2024) /ModDate (D:20241301)", "", ""),
+			wantWarnings: []string{ErrorCodePDFMeta002, ErrorCodePDFMeta002},
+		},
+		{
+			name:         "malformed XMP date",
+			data:         metadataPDF("/Title (Moby Dick) /Author (Herman Melville)", xmpTitle+xmpCreator+"<xmp:CreateDate>yesterday</xmp:CreateDate>", ""),
+			wantWarnings: []string{ErrorCodePDFMeta002, ErrorCodePDFMeta001},
+		},
+		{
+			name:       "XMP not well-formed",
+			data:       metadataPDF("/Title (Moby Dick)", "<dc:title><rdf:Alt>", ""),
+			wantErrors: []string{ErrorCodePDFMeta003},
+		},
+		{
+			name:         "DisplayDocTitle without title",
+			data:         metadataPDF("/Author (Herman Melville)", "", "/ViewerPreferences << /DisplayDocTitle true >>"),
+			wantWarnings: []string{ErrorCodePDFMeta004},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Info disagrees with XMP",
+			data: metadataPDF("/Title (Untitled) /Author (H. Melville) /CreationDate (D:20240302)", xmpTitle+xmpCreator+xmpCreated, ""),
+		},
+		{
+			name: "XMP lacks Info values",
+			data: metadataPDF("/Title (Moby Dick) /Author (Herman Melville) /CreationDate (D:20240301103000+01'00')", " ", ""),
+		},
+		{
+			name: "malformed Info date",
+			data: metadataPDF("/Title (Moby Dick) /Author (Herman Melville) /CreationDate (March 1, 2024) /ModDate (bad)", xmpTitle+xmpCreator, ""),
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synced, changed, err := syncMetadata(tt.data)
+			if err != nil {
+				t.Fatalf("syncMetadata failed: %v", err)
+			}
+			if !changed {
+				t.Fatal("Expected metadata to change")
+			}
+			if !bytes.HasPrefix(synced, tt.data) {
+				t.Error("Expected an incremental update of the original file")
+			}
+
+			result, err := validator.ValidateBytes(synced)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !result.Valid {
+				t.Errorf("Expected synchronized PDF to be valid, got %v", errorCodes(result.Errors))
+			}
+			if got := errorCodes(result.Warnings); len(got) != 0 {
+				t.Errorf("Expected no warnings, got %v", got)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if len(result.Revisions) != 2 {
+				t.Errorf("Expected 2 revisions, got %d", len(result.Revisions))
+			}
+		})
+	}
+}
+
+func TestSyncMetadata_Consistent(t *testing.T) {
+	data := metadataPDF("/Title (Moby Dick) /Author (Herman Melville) /CreationDate (D:20240301103000+01'00')", xmpTitle+xmpCreator+xmpCreated, "")
+
+	synced, changed, err := syncMetadata(data)
+	if err != nil {
+		t.Fatalf("syncMetadata failed: %v", err)
+	}
+	if changed || !bytes.Equal(synced, data) {
+		t.Error("Expected consistent metadata to be left unchanged")
+	}
+}
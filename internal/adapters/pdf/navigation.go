@@ -0,0 +1,372 @@
+package pdf
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Outline and link validation error codes.
+const (
+	ErrorCodePDFOutline001 = "PDF-OUTLINE-001"
+	ErrorCodePDFOutline002 = "PDF-OUTLINE-002"
+	ErrorCodePDFOutline003 = "PDF-OUTLINE-003"
+	ErrorCodePDFLink001    = "PDF-LINK-001"
+	ErrorCodePDFLink002    = "PDF-LINK-002"
+)
+
+// destinationIndex resolves destinations against the document's pages and
+// named destinations.
+type destinationIndex struct {
+	// pages maps page object numbers to 1-based page numbers.
+	pages     map[int64]int
+	pageCount int
+	// dests is the catalog /Dests dictionary of PDF 1.1, keyed by name.
+	dests *core.PdfObjectDictionary
+	// names holds the /Names /Dests name tree, keyed by string.
+	names map[string]core.PdfObject
+}
+
+// outlineWalk is the state of a walk over the outline tree.
+type outlineWalk struct {
+	result  *StructureValidationResult
+	index   *destinationIndex
+	visited map[core.PdfObject]bool
+}
+
+// validateNavigation checks the outline tree and resolves the destinations
+// of outline items and link annotations.
+func (v *StructureValidator) validateNavigation(catalog *core.PdfObjectDictionary, pages []pageEntry, result *StructureValidationResult) {
+	// Named destinations are strings, which cannot be read without the
+	// decryption key.
+	if result.Encryption != nil && !result.Encryption.Authenticated {
+		return
+	}
+
+	index := &destinationIndex{
+		pages:     make(map[int64]int, len(pages)),
+		pageCount: len(pages),
+		names:     make(map[string]core.PdfObject),
+	}
+	for i, page := range pages {
+		if page.object != 0 {
+			index.pages[page.object] = i + 1
+		}
+	}
+	index.dests, _ = core.GetDict(core.TraceToDirectObject(catalog.Get("Dests")))
+	if names, ok := core.GetDict(core.TraceToDirectObject(catalog.Get("Names"))); ok {
+		collectNameTree(names.Get("Dests"), index.names, make(map[core.PdfObject]bool))
+	}
+
+	if outlines := catalog.Get("Outlines"); outlines != nil {
+		w := &outlineWalk{result: result, index: index, visited: make(map[core.PdfObject]bool)}
+		w.walkRoot(outlines)
+	}
+
+	for i, page := range pages {
+		annots, ok := core.GetArray(core.TraceToDirectObject(page.dict.Get("Annots")))
+		if !ok {
+			continue
+		}
+		for _, obj := range annots.Elements() {
+			annot, ok := core.GetDict(core.TraceToDirectObject(obj))
+			if !ok {
+				continue
+			}
+			if subtype, _ := core.GetNameVal(core.TraceToDirectObject(annot.Get("Subtype"))); subtype != "Link" {
+				continue
+			}
+			dest := linkDestination(annot)
+			if dest == nil {
+				continue
+			}
+			code, problem, warning := index.check(dest)
+			if code == "" {
+				continue
+			}
+			details := map[string]interface{}{
+				"page": i + 1,
+			}
+			if ref, ok := obj.(*core.PdfObjectReference); ok {
+				details["object"] = ref.ObjectNumber
+			}
+			addFinding(result, warning, code, fmt.Sprintf("Link on page %d: %s", i+1, problem), details)
+		}
+	}
+}
+
+// walkRoot validates the outline dictionary and the items below it.
+func (w *outlineWalk) walkRoot(obj core.PdfObject) {
+	root, ok := core.GetDict(core.TraceToDirectObject(obj))
+	if !ok {
+		if !core.IsNullObject(core.TraceToDirectObject(obj)) {
+			w.addError(ErrorCodePDFOutline001, "Catalog /Outlines is not a dictionary", map[string]interface{}{})
+		}
+		return
+	}
+	w.visited[root] = true
+
+	visible := w.walkChildren(root, objectNumberOf(obj), "")
+	if count, ok := core.GetIntVal(core.TraceToDirectObject(root.Get("Count"))); ok && count != visible {
+		w.result.Warnings = append(w.result.Warnings, ValidationError{
+			Code:    ErrorCodePDFOutline002,
+			Message: fmt.Sprintf("Outline /Count is %d but %d item(s) are visible", count, visible),
+			Details: map[string]interface{}{
+				"object":   objectNumberOf(obj),
+				"declared": count,
+				"actual":   visible,
+			},
+		})
+	}
+}
+
+// walkChildren validates the /First to /Last chain of parent and returns
+// the number of items visible when parent is open. title is the title of
+// parent, empty for the outline root.
+func (w *outlineWalk) walkChildren(parent *core.PdfObjectDictionary, parentNumber int64, title string) int {
+	first, last := parent.Get("First"), parent.Get("Last")
+	if first == nil && last == nil {
+		return 0
+	}
+	if first == nil || last == nil {
+		w.addError(ErrorCodePDFOutline001, "Outline item has only one of /First and /Last", map[string]interface{}{
+			"object": parentNumber,
+			"title":  title,
+		})
+		if first == nil {
+			return 0
+		}
+	}
+
+	visible := 0
+	var previous int64
+	for item := first; item != nil; {
+		dict, ok := core.GetDict(core.TraceToDirectObject(item))
+		if !ok {
+			w.addError(ErrorCodePDFOutline001, "Outline item is not a dictionary", map[string]interface{}{
+				"object": objectNumberOf(item),
+				"parent": parentNumber,
+			})
+			return visible
+		}
+		number := objectNumberOf(item)
+		itemTitle := outlineTitle(dict)
+		if w.visited[dict] {
+			w.addError(ErrorCodePDFOutline003, fmt.Sprintf("Outline item %q is reached more than once (cycle or shared item)", itemTitle), map[string]interface{}{
+				"object": number,
+				"title":  itemTitle,
+			})
+			return visible
+		}
+		w.visited[dict] = true
+
+		if parentNumber != 0 && objectNumberOf(dict.Get("Parent")) != parentNumber {
+			w.addError(ErrorCodePDFOutline001, fmt.Sprintf("Outline item %q has a /Parent that does not refer to its parent", itemTitle), map[string]interface{}{
+				"object":          number,
+				"title":           itemTitle,
+				"expected_parent": parentNumber,
+			})
+		}
+		if objectNumberOf(dict.Get("Prev")) != previous {
+			w.addError(ErrorCodePDFOutline001, fmt.Sprintf("Outline item %q has a /Prev that does not refer to the previous item", itemTitle), map[string]interface{}{
+				"object":        number,
+				"title":         itemTitle,
+				"expected_prev": previous,
+			})
+		}
+
+		if dest := linkDestination(dict); dest != nil {
+			if code, problem, warning := w.index.check(dest); code != "" {
+				addFinding(w.result, warning, code, fmt.Sprintf("Outline item %q: %s", itemTitle, problem), map[string]interface{}{
+					"object": number,
+					"title":  itemTitle,
+				})
+			}
+		}
+
+		descendants := w.walkChildren(dict, number, itemTitle)
+		count, hasCount := core.GetIntVal(core.TraceToDirectObject(dict.Get("Count")))
+		if descendants > 0 && (!hasCount || abs(count) != descendants) {
+			w.result.Warnings = append(w.result.Warnings, ValidationError{
+				Code:    ErrorCodePDFOutline002,
+				Message: fmt.Sprintf("Outline item %q has /Count %s but %d descendant(s) are visible when open", itemTitle, countString(count, hasCount), descendants),
+				Details: map[string]interface{}{
+					"object":   number,
+					"title":    itemTitle,
+					"declared": count,
+					"actual":   descendants,
+				},
+			})
+		}
+		visible++
+		if count > 0 {
+			visible += descendants
+		}
+
+		previous = number
+		item = dict.Get("Next")
+		if item == nil && last != nil && objectNumberOf(last) != number {
+			w.addError(ErrorCodePDFOutline001, "Outline /Last does not refer to the last item in the /Next chain", map[string]interface{}{
+				"object":        parentNumber,
+				"title":         title,
+				"expected_last": number,
+				"found":         objectNumberOf(last),
+			})
+		}
+	}
+	return visible
+}
+
+func (w *outlineWalk) addError(code, message string, details map[string]interface{}) {
+	w.result.Errors = append(w.result.Errors, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// check resolves a destination and describes why it is broken. It returns
+// an empty code for destinations that resolve to a page.
+func (index *destinationIndex) check(dest core.PdfObject) (code, problem string, warning bool) {
+	switch value := core.TraceToDirectObject(dest).(type) {
+	case *core.PdfObjectName:
+		target := index.lookup(string(*value))
+		if target == nil {
+			return ErrorCodePDFLink001, fmt.Sprintf("named destination %q is not defined", string(*value)), false
+		}
+		return index.checkExplicit(target)
+	case *core.PdfObjectString:
+		target := index.lookup(value.Str())
+		if target == nil {
+			return ErrorCodePDFLink001, fmt.Sprintf("named destination %q is not defined", value.Decoded()), false
+		}
+		return index.checkExplicit(target)
+	default:
+		return index.checkExplicit(dest)
+	}
+}
+
+// lookup finds a named destination in the /Dests name tree or the catalog
+// /Dests dictionary, unwrapping the /D entry of dictionary values.
+func (index *destinationIndex) lookup(name string) core.PdfObject {
+	target, ok := index.names[name]
+	if !ok && index.dests != nil {
+		target = index.dests.Get(core.PdfObjectName(name))
+	}
+	if target == nil {
+		return nil
+	}
+	if dict, ok := core.GetDict(core.TraceToDirectObject(target)); ok {
+		return dict.Get("D")
+	}
+	return target
+}
+
+// checkExplicit checks that an explicit destination, [page /Fit ...],
+// points at a page in the page tree.
+func (index *destinationIndex) checkExplicit(dest core.PdfObject) (code, problem string, warning bool) {
+	arr, ok := core.GetArray(core.TraceToDirectObject(dest))
+	if !ok || arr.Len() < 2 {
+		return ErrorCodePDFLink002, "destination is not a valid destination array", false
+	}
+	if _, ok := core.GetNameVal(core.TraceToDirectObject(arr.Get(1))); !ok {
+		return ErrorCodePDFLink002, "destination has no view type such as /Fit or /XYZ", false
+	}
+
+	switch target := arr.Get(0).(type) {
+	case *core.PdfObjectReference:
+		if _, ok := index.pages[target.ObjectNumber]; !ok {
+			return ErrorCodePDFLink002, fmt.Sprintf("destination points at object %d, which is not a page", target.ObjectNumber), false
+		}
+	case *core.PdfObjectInteger:
+		// Page indexes are for remote destinations, but most readers
+		// follow them within the document too.
+		if int(*target) < 0 || int(*target) >= index.pageCount {
+			return ErrorCodePDFLink002, fmt.Sprintf("destination points at page index %d, beyond the last page", int(*target)), false
+		}
+		return ErrorCodePDFLink002, "destination uses a page index instead of a page reference", true
+	default:
+		return ErrorCodePDFLink002, "destination does not start with a page reference", false
+	}
+	return "", "", false
+}
+
+// linkDestination returns the /Dest of a link annotation or outline item,
+// or the /D of its GoTo action.
+func linkDestination(dict *core.PdfObjectDictionary) core.PdfObject {
+	if dest := dict.Get("Dest"); dest != nil {
+		return dest
+	}
+	action, ok := core.GetDict(core.TraceToDirectObject(dict.Get("A")))
+	if !ok {
+		return nil
+	}
+	if actionType, _ := core.GetNameVal(core.TraceToDirectObject(action.Get("S"))); actionType != "GoTo" {
+		return nil
+	}
+	return action.Get("D")
+}
+
+// collectNameTree adds the entries of a name tree to entries, keyed by the
+// raw string.
+func collectNameTree(obj core.PdfObject, entries map[string]core.PdfObject, visited map[core.PdfObject]bool) {
+	node, ok := core.GetDict(core.TraceToDirectObject(obj))
+	if !ok || visited[node] {
+		return
+	}
+	visited[node] = true
+
+	if names, ok := core.GetArray(core.TraceToDirectObject(node.Get("Names"))); ok {
+		for i := 0; i+1 < names.Len(); i += 2 {
+			if key, ok := core.GetString(core.TraceToDirectObject(names.Get(i))); ok {
+				entries[key.Str()] = names.Get(i + 1)
+			}
+		}
+	}
+	if kids, ok := core.GetArray(core.TraceToDirectObject(node.Get("Kids"))); ok {
+		for _, kid := range kids.Elements() {
+			collectNameTree(kid, entries, visited)
+		}
+	}
+}
+
+// addFinding adds a finding as a warning or an error.
+func addFinding(result *StructureValidationResult, warning bool, code, message string, details map[string]interface{}) {
+	finding := ValidationError{Code: code, Message: message, Details: details}
+	if warning {
+		result.Warnings = append(result.Warnings, finding)
+	} else {
+		result.Errors = append(result.Errors, finding)
+	}
+}
+
+// outlineTitle returns the /Title of an outline item.
+func outlineTitle(dict *core.PdfObjectDictionary) string {
+	if title, ok := core.GetString(core.TraceToDirectObject(dict.Get("Title"))); ok {
+		return title.Decoded()
+	}
+	return ""
+}
+
+// objectNumberOf returns the object number of a reference, or 0 for a
+// direct object.
+func objectNumberOf(obj core.PdfObject) int64 {
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		return ref.ObjectNumber
+	}
+	return 0
+}
+
+func countString(count int, ok bool) string {
+	if !ok {
+		return "missing"
+	}
+	return fmt.Sprintf("%d", count)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,198 @@
+package pdf
+
+import (
+	"testing"
+)
+
+// navigationPDF builds a two-page PDF (pages 3 and 4) with extra catalog
+// entries and annotations on the first page. Extra objects are numbered
+// from 5.
+func navigationPDF(catalog, annots string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R " + catalog + " >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Annots [" + annots + "] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>",
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+func TestStructureValidator_Outlines(t *testing.T) {
+	const root = "<< /Type /Outlines /First 6 0 R /Last 7 0 R /Count 2 >>"
+	const first = "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest [3 0 R /Fit] >>"
+	const second = "<< /Title (Two) /Parent 5 0 R /Prev 6 0 R /Dest [4 0 R /XYZ 0 792 0] >>"
+
+	tests := []struct {
+		name         string
+		catalog      string
+		objects      []string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name:    "valid outline",
+			objects: []string{root, first, second},
+		},
+		{
+			name:    "closed item with children",
+			objects: []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /First 8 0 R /Last 8 0 R /Count -1 /Dest [3 0 R /Fit] >>", second, "<< /Title (One.One) /Parent 6 0 R /Dest [3 0 R /FitH 700] >>"},
+		},
+		{
+			name:    "open item with children",
+			objects: []string{"<< /Type /Outlines /First 6 0 R /Last 7 0 R /Count 3 >>", "<< /Title (One) /Parent 5 0 R /Next 7 0 R /First 8 0 R /Last 8 0 R /Count 1 /Dest [3 0 R /Fit] >>", second, "<< /Title (One.One) /Parent 6 0 R /Dest [3 0 R /FitH 700] >>"},
+		},
+		{
+			name:       "last is not the last item",
+			objects:    []string{"<< /Type /Outlines /First 6 0 R /Last 6 0 R /Count 2 >>", first, second},
+			wantErrors: []string{ErrorCodePDFOutline001},
+		},
+		{
+			name:       "missing prev",
+			objects:    []string{root, first, "<< /Title (Two) /Parent 5 0 R /Dest [4 0 R /Fit] >>"},
+			wantErrors: []string{ErrorCodePDFOutline001},
+		},
+		{
+			name:       "wrong parent",
+			objects:    []string{root, first, "<< /Title (Two) /Parent 6 0 R /Prev 6 0 R /Dest [4 0 R /Fit] >>"},
+			wantErrors: []string{ErrorCodePDFOutline001},
+		},
+		{
+			name:         "count mismatch",
+			objects:      []string{"<< /Type /Outlines /First 6 0 R /Last 7 0 R /Count 5 >>", first, second},
+			wantWarnings: []string{ErrorCodePDFOutline002},
+		},
+		{
+			name:         "missing item count",
+			objects:      []string{"<< /Type /Outlines /First 6 0 R /Last 7 0 R /Count 2 >>", "<< /Title (One) /Parent 5 0 R /Next 7 0 R /First 8 0 R /Last 8 0 R /Dest [3 0 R /Fit] >>", second, "<< /Title (One.One) /Parent 6 0 R /Dest [3 0 R /Fit] >>"},
+			wantWarnings: []string{ErrorCodePDFOutline002},
+		},
+		{
+			name:       "cycle",
+			objects:    []string{root, first, "<< /Title (Two) /Parent 5 0 R /Prev 6 0 R /Next 6 0 R /Dest [4 0 R /Fit] >>"},
+			wantErrors: []string{ErrorCodePDFOutline003},
+		},
+		{
+			name:    "named destinations",
+			catalog: "/Dests << /two << /D [4 0 R /Fit] >> >> /Names << /Dests << /Names [(one) [3 0 R /Fit]] >> >>",
+			objects: []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest (one) >>", "<< /Title (Two) /Parent 5 0 R /Prev 6 0 R /A << /S /GoTo /D /two >> >>"},
+		},
+		{
+			name:       "undefined named destination",
+			objects:    []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest (missing) >>", second},
+			wantErrors: []string{ErrorCodePDFLink001},
+		},
+		{
+			name:       "destination is not a page",
+			objects:    []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest [2 0 R /Fit] >>", second},
+			wantErrors: []string{ErrorCodePDFLink002},
+		},
+		{
+			name:       "destination without view",
+			objects:    []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest [3 0 R] >>", second},
+			wantErrors: []string{ErrorCodePDFLink002},
+		},
+		{
+			name:         "page index destination",
+			objects:      []string{root, "<< /Title (One) /Parent 5 0 R /Next 7 0 R /Dest [1 /Fit] >>", second},
+			wantWarnings: []string{ErrorCodePDFLink002},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(navigationPDF("/Outlines 5 0 R "+tt.catalog, "", tt.objects...))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Links(t *testing.T) {
+	tests := []struct {
+		name       string
+		catalog    string
+		annots     string
+		wantErrors []string
+	}{
+		{
+			name:   "explicit destination",
+			annots: "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /Dest [4 0 R /Fit] >>",
+		},
+		{
+			name:    "GoTo action to named destination",
+			catalog: "/Names << /Dests << /Kids [<< /Names [(ch2) 5 0 R] >>] >> >>",
+			annots:  "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /GoTo /D (ch2) >> >>",
+		},
+		{
+			name:   "URI links are not destinations",
+			annots: "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /URI /URI (https://example.com) >> >>",
+		},
+		{
+			name:       "undefined named destination",
+			annots:     "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /A << /S /GoTo /D (ch9) >> >>",
+			wantErrors: []string{ErrorCodePDFLink001},
+		},
+		{
+			name:       "deleted page",
+			annots:     "<< /Type /Annot /Subtype /Link /Rect [0 0 10 10] /Dest [9 0 R /Fit] >>",
+			wantErrors: []string{ErrorCodePDFLink002},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(navigationPDF(tt.catalog, tt.annots, "[4 0 R /Fit]"))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			for _, e := range result.Errors {
+				if page := e.Details["page"]; page != 1 {
+					t.Errorf("page = %v, want 1", page)
+				}
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Outlines_Title(t *testing.T) {
+	data := navigationPDF("/Outlines 5 0 R", "",
+		"<< /Type /Outlines /First 6 0 R /Last 6 0 R /Count 1 >>",
+		"<< /Title (Chapter 1) /Parent 5 0 R /Dest (gone) >>")
+
+	result, err := NewStructureValidator().ValidateBytes(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected one broken destination, got %v", result.Errors)
+	}
+	if title := result.Errors[0].Details["title"]; title != "Chapter 1" {
+		t.Errorf("title = %v, want Chapter 1", title)
+	}
+	if object := result.Errors[0].Details["object"]; object != int64(6) {
+		t.Errorf("object = %v, want 6", object)
+	}
+}
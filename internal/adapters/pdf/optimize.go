@@ -0,0 +1,415 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+const (
+	// objectsPerStream bounds the objects packed into one object stream, so
+	// readers do not decompress a large stream to reach one object.
+	objectsPerStream = 100
+	// defaultImageQuality is the JPEG quality of downsampled JPEG images.
+	defaultImageQuality = 85
+)
+
+// OptimizeOptions configures OptimizeFileWithOptions. Objects unreachable
+// from the trailer are always removed.
+type OptimizeOptions struct {
+	// Deduplicate merges identical streams, fonts, font descriptors,
+	// encodings and width arrays.
+	Deduplicate bool `json:"deduplicate"`
+	// Compress Flate-compresses streams stored without a filter. XMP
+	// metadata streams stay uncompressed so tools can find the packet.
+	Compress bool `json:"compress"`
+	// ObjectStreams packs every object other than streams into compressed
+	// object streams indexed by a cross-reference stream. It raises the
+	// version to PDF 1.5 and cannot be combined with Linearize.
+	ObjectStreams bool `json:"object_streams"`
+	// Linearize writes the file for Fast Web View.
+	Linearize bool `json:"linearize"`
+	// ImageDPI downsamples images placed at a higher effective resolution
+	// to this resolution. Zero leaves images alone.
+	ImageDPI float64 `json:"image_dpi,omitempty"`
+	// ImageQuality is the JPEG quality, 1 to 100, of downsampled JPEG
+	// images. Zero uses 85.
+	ImageQuality int `json:"image_quality,omitempty"`
+}
+
+// DefaultOptimizeOptions returns the options OptimizeFile uses: every
+// lossless optimization, with object streams.
+func DefaultOptimizeOptions() OptimizeOptions {
+	return OptimizeOptions{
+		Deduplicate:   true,
+		Compress:      true,
+		ObjectStreams: true,
+	}
+}
+
+// OptimizeResult reports what an optimization changed.
+type OptimizeResult struct {
+	OriginalSize  int64 `json:"original_size"`
+	OptimizedSize int64 `json:"optimized_size"`
+	// ObjectsBefore counts the objects in the input's cross-reference
+	// table, including object and cross-reference streams.
+	ObjectsBefore int `json:"objects_before"`
+	// ObjectsAfter counts the input objects written, not counting the
+	// object streams, cross-reference stream or linearization objects the
+	// writer adds.
+	ObjectsAfter        int  `json:"objects_after"`
+	StreamsDeduplicated int  `json:"streams_deduplicated"`
+	FontsDeduplicated   int  `json:"fonts_deduplicated"`
+	StreamsCompressed   int  `json:"streams_compressed"`
+	ImagesDownsampled   int  `json:"images_downsampled"`
+	ObjectStreams       int  `json:"object_streams"`
+	Linearized          bool `json:"linearized"`
+}
+
+// Saved returns the bytes saved, negative when the output is larger.
+func (r *OptimizeResult) Saved() int64 {
+	return r.OriginalSize - r.OptimizedSize
+}
+
+// optimize applies opts to data and returns the rewritten file.
+func optimize(data []byte, opts OptimizeOptions) ([]byte, *OptimizeResult, error) {
+	if opts.ObjectStreams && opts.Linearize {
+		return nil, nil, errors.New("object streams cannot be combined with linearization")
+	}
+	if opts.ImageDPI < 0 || opts.ImageQuality < 0 || opts.ImageQuality > 100 {
+		return nil, nil, fmt.Errorf("invalid image options: %g dpi, quality %d", opts.ImageDPI, opts.ImageQuality)
+	}
+
+	doc, err := loadDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := &OptimizeResult{
+		OriginalSize:  int64(len(data)),
+		ObjectsBefore: doc.sourceObjects,
+	}
+
+	// Images are found through the page content, which needs the original
+	// references, so they go before objects are merged.
+	if opts.ImageDPI > 0 {
+		quality := opts.ImageQuality
+		if quality == 0 {
+			quality = defaultImageQuality
+		}
+		result.ImagesDownsampled = doc.downsampleImages(opts.ImageDPI, quality)
+	}
+	if opts.Deduplicate {
+		result.StreamsDeduplicated, result.FontsDeduplicated = doc.deduplicate()
+	}
+	if opts.Compress {
+		result.StreamsCompressed = doc.compressStreams()
+	}
+	result.ObjectsAfter = len(doc.objects)
+
+	var out []byte
+	if opts.Linearize {
+		out, err = doc.linearize()
+		result.Linearized = err == nil
+	} else {
+		out, result.ObjectStreams, err = doc.write(opts.ObjectStreams)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	result.OptimizedSize = int64(len(out))
+	return out, result, nil
+}
+
+// deduplicate merges identical objects until none are left, since merging
+// font files can make their descriptors, and then their fonts, identical.
+// It returns the number of streams and fonts removed.
+func (d *document) deduplicate() (int, int) {
+	var streams, fonts int
+	for {
+		numbers := d.sortedNumbers()
+		first := make(map[[sha256.Size]byte]int64)
+		merged := make(map[int64]int64)
+		for _, number := range numbers {
+			obj := d.objects[number]
+			if !mergeable(obj) {
+				continue
+			}
+			var key bytes.Buffer
+			writeCanonical(&key, obj)
+			sum := sha256.Sum256(key.Bytes())
+			keep, ok := first[sum]
+			if !ok {
+				first[sum] = number
+				continue
+			}
+			merged[number] = keep
+			if _, ok := obj.(*core.PdfObjectStream); ok {
+				streams++
+			} else if dict, ok := core.GetDict(d.direct(number)); ok {
+				if name, _ := core.GetNameVal(dict.Get("Type")); name == "Font" {
+					fonts++
+				}
+			}
+		}
+		if len(merged) == 0 {
+			return streams, fonts
+		}
+
+		fn := func(ref *core.PdfObjectReference) core.PdfObject {
+			if keep, ok := merged[ref.ObjectNumber]; ok {
+				return &core.PdfObjectReference{ObjectNumber: keep}
+			}
+			return ref
+		}
+		for number := range merged {
+			delete(d.objects, number)
+		}
+		for _, obj := range d.objects {
+			rewriteReferences(obj, fn)
+		}
+		rewriteReferences(d.trailer, fn)
+	}
+}
+
+// mergeable reports whether an object may be replaced by an identical one.
+// Other dictionaries can carry identity, such as pages, annotations and
+// structure elements, so only resources are merged.
+func mergeable(obj core.PdfObject) bool {
+	switch value := obj.(type) {
+	case *core.PdfObjectStream:
+		return true
+	case *core.PdfIndirectObject:
+		switch direct := value.PdfObject.(type) {
+		case *core.PdfObjectDictionary:
+			name, _ := core.GetNameVal(direct.Get("Type"))
+			return name == "Font" || name == "FontDescriptor" || name == "Encoding"
+		case *core.PdfObjectArray:
+			// Width arrays.
+			for _, element := range direct.Elements() {
+				if _, err := core.GetNumberAsFloat(element); err != nil {
+					return false
+				}
+			}
+			return direct.Len() > 0
+		}
+	}
+	return false
+}
+
+// writeCanonical writes an object with dictionary keys sorted, so objects
+// that differ only in key order compare equal.
+func writeCanonical(out *bytes.Buffer, obj core.PdfObject) {
+	switch value := obj.(type) {
+	case *core.PdfIndirectObject:
+		writeCanonical(out, value.PdfObject)
+	case *core.PdfObjectStream:
+		writeCanonical(out, value.PdfObjectDictionary)
+		out.WriteString("stream")
+		out.Write(value.Stream)
+	case *core.PdfObjectDictionary:
+		keys := append([]core.PdfObjectName(nil), value.Keys()...)
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		out.WriteString("<<")
+		for _, key := range keys {
+			out.WriteString(key.WriteString())
+			out.WriteByte(' ')
+			writeCanonical(out, value.Get(key))
+			out.WriteByte(' ')
+		}
+		out.WriteString(">>")
+	case *core.PdfObjectArray:
+		out.WriteByte('[')
+		for _, element := range value.Elements() {
+			writeCanonical(out, element)
+			out.WriteByte(' ')
+		}
+		out.WriteByte(']')
+	case nil:
+		out.WriteString("null")
+	default:
+		out.WriteString(obj.WriteString())
+	}
+}
+
+// compressStreams Flate-compresses streams without a filter when that makes
+// them smaller, and returns how many it compressed.
+func (d *document) compressStreams() int {
+	compressed := 0
+	for _, obj := range d.objects {
+		stream, ok := obj.(*core.PdfObjectStream)
+		if !ok || stream.Get("Filter") != nil || len(stream.Stream) == 0 {
+			continue
+		}
+		if name, _ := core.GetNameVal(stream.Get("Type")); name == "Metadata" {
+			continue
+		}
+		encoded, err := deflate(stream.Stream)
+		if err != nil || len(encoded) >= len(stream.Stream) {
+			continue
+		}
+		stream.Stream = encoded
+		stream.Set("Filter", core.MakeName(core.StreamEncodingFilterNameFlate))
+		stream.Remove("DecodeParms")
+		stream.Set("Length", core.MakeInteger(int64(len(encoded))))
+		compressed++
+	}
+	return compressed
+}
+
+// deflate compresses data with zlib at the best compression level.
+func deflate(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w, err := zlib.NewWriterLevel(&out, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// sortedNumbers returns the object numbers in ascending order.
+func (d *document) sortedNumbers() []int64 {
+	numbers := make([]int64, 0, len(d.objects))
+	for number := range d.objects {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// write writes the document with objects numbered from 1 in their original
+// order. With objectStreams, objects other than streams are packed into
+// object streams indexed by a cross-reference stream; otherwise a
+// cross-reference table is written. It returns the file and the number of
+// object streams.
+func (d *document) write(objectStreams bool) ([]byte, int, error) {
+	numbers := make(map[int64]int64, len(d.objects))
+	for i, old := range d.sortedNumbers() {
+		numbers[old] = int64(i + 1)
+	}
+	d.renumber(numbers)
+	count := int64(len(d.objects))
+
+	version := d.version
+	if objectStreams && version < "1.5" {
+		version = "1.5"
+	}
+	var out bytes.Buffer
+	out.WriteString("%PDF-" + version + "\n" + binaryComment)
+
+	if !objectStreams {
+		offsets := make([]int64, count+1)
+		for number := int64(1); number <= count; number++ {
+			offsets[number] = int64(out.Len())
+			out.Write(d.serialize(number))
+		}
+		xref := out.Len()
+		fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", count+1)
+		for number := int64(1); number <= count; number++ {
+			fmt.Fprintf(&out, "%010d 00000 n \n", offsets[number])
+		}
+		trailer := core.MakeDict()
+		trailer.Set("Size", core.MakeInteger(count+1))
+		for _, key := range d.trailer.Keys() {
+			trailer.Set(key, d.trailer.Get(key))
+		}
+		fmt.Fprintf(&out, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer.WriteString(), xref)
+		return out.Bytes(), 0, nil
+	}
+
+	// Cross-reference stream rows: type, offset or object stream, and
+	// generation or index.
+	type row struct {
+		kind         byte
+		field, index int64
+	}
+	rows := make([]row, count+1)
+	rows[0] = row{kind: 0, index: 65535}
+
+	var packed []int64
+	for number := int64(1); number <= count; number++ {
+		if _, ok := d.objects[number].(*core.PdfObjectStream); ok {
+			rows[number] = row{kind: 1, field: int64(out.Len())}
+			out.Write(d.serialize(number))
+			continue
+		}
+		packed = append(packed, number)
+	}
+
+	next := count + 1
+	streams := 0
+	for start := 0; start < len(packed); start += objectsPerStream {
+		end := start + objectsPerStream
+		if end > len(packed) {
+			end = len(packed)
+		}
+		var header, body bytes.Buffer
+		for i, number := range packed[start:end] {
+			fmt.Fprintf(&header, "%d %d ", number, body.Len())
+			if obj := d.direct(number); obj != nil {
+				body.WriteString(obj.WriteString())
+			} else {
+				body.WriteString("null")
+			}
+			body.WriteByte('\n')
+			rows[number] = row{kind: 2, field: next, index: int64(i)}
+		}
+		content := append(header.Bytes(), body.Bytes()...)
+		encoded, err := deflate(content)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to compress object stream: %w", err)
+		}
+		rows = append(rows, row{kind: 1, field: int64(out.Len())})
+		fmt.Fprintf(&out, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d /Filter /FlateDecode /Length %d >>\nstream\n",
+			next, end-start, header.Len(), len(encoded))
+		out.Write(encoded)
+		out.WriteString("\nendstream\nendobj\n")
+		next++
+		streams++
+	}
+
+	xrefNumber := next
+	xref := int64(out.Len())
+	rows = append(rows, row{kind: 1, field: xref})
+	width := 4
+	if xref > 0xffffffff || next > 0xffffffff {
+		width = 8
+	}
+	var table bytes.Buffer
+	for _, r := range rows {
+		table.WriteByte(r.kind)
+		for shift := (width - 1) * 8; shift >= 0; shift -= 8 {
+			table.WriteByte(byte(r.field >> uint(shift)))
+		}
+		table.WriteByte(byte(r.index >> 8))
+		table.WriteByte(byte(r.index))
+	}
+	encoded, err := deflate(table.Bytes())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to compress cross-reference stream: %w", err)
+	}
+
+	dict := core.MakeDict()
+	dict.Set("Type", core.MakeName("XRef"))
+	dict.Set("Size", core.MakeInteger(xrefNumber+1))
+	dict.Set("W", core.MakeArray(core.MakeInteger(1), core.MakeInteger(int64(width)), core.MakeInteger(2)))
+	for _, key := range d.trailer.Keys() {
+		dict.Set(key, d.trailer.Get(key))
+	}
+	dict.Set("Filter", core.MakeName(core.StreamEncodingFilterNameFlate))
+	dict.Set("Length", core.MakeInteger(int64(len(encoded))))
+	fmt.Fprintf(&out, "%d 0 obj\n%s\nstream\n", xrefNumber, dict.WriteString())
+	out.Write(encoded)
+	fmt.Fprintf(&out, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xref)
+	return out.Bytes(), streams, nil
+}
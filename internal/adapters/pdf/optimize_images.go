@@ -0,0 +1,269 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// resampleFilters are the filters whose output downsampleImages can read as
+// raw samples.
+var resampleFilters = map[string]bool{
+	core.StreamEncodingFilterNameFlate:     true,
+	core.StreamEncodingFilterNameLZW:       true,
+	core.StreamEncodingFilterNameASCIIHex:  true,
+	core.StreamEncodingFilterNameASCII85:   true,
+	core.StreamEncodingFilterNameRunLength: true,
+}
+
+// downsampleImages resamples images drawn above dpi so their highest
+// density placement is at dpi, and returns how many it resampled. JPEG
+// images are re-encoded as JPEG at quality; others are Flate-compressed.
+// Only 8-bit gray, RGB and CMYK images are resampled, and an image is kept
+// when resampling does not make it smaller.
+func (d *document) downsampleImages(dpi float64, quality int) int {
+	catalog, ok := core.GetDict(d.direct(d.catalog))
+	if !ok {
+		return 0
+	}
+	pages := NewStructureValidator().validatePageTree(catalog.Get("Pages"), &StructureValidationResult{})
+
+	// The print analysis finds the lowest effective resolution of every
+	// placement of each image, following form XObjects.
+	analysis := &printAnalysis{
+		images:      make(map[*core.PdfObjectStream]*imageUsage),
+		colors:      make(map[string][]int),
+		imageColors: make(map[string][]int),
+		spots:       make(map[string][]int),
+	}
+	for i, page := range pages {
+		analysis.page = i + 1
+		content, err := pageContent(page.dict)
+		if err != nil {
+			continue
+		}
+		analysis.run(content, page.resources, identityMatrix, 0)
+	}
+
+	resampled := 0
+	for stream, usage := range analysis.images {
+		if usage.minPPI <= dpi {
+			continue
+		}
+		scale := dpi / usage.minPPI
+		width := int(math.Max(1, math.Round(float64(usage.width)*scale)))
+		height := int(math.Max(1, math.Round(float64(usage.height)*scale)))
+		if resampleImage(stream, usage.width, usage.height, width, height, quality) {
+			resampled++
+		}
+	}
+	return resampled
+}
+
+// resampleImage replaces the samples of an image XObject with a width ×
+// height version, reporting whether it did.
+func resampleImage(stream *core.PdfObjectStream, srcWidth, srcHeight, width, height, quality int) bool {
+	if mask, _ := core.GetBoolVal(core.TraceToDirectObject(stream.Get("ImageMask"))); mask {
+		return false
+	}
+	if bpc, _ := core.GetIntVal(core.TraceToDirectObject(stream.Get("BitsPerComponent"))); bpc != 8 {
+		return false
+	}
+	// Color key masks name sample values, which resampling blends.
+	if _, ok := core.GetArray(core.TraceToDirectObject(stream.Get("Mask"))); ok {
+		return false
+	}
+	components := imageComponents(stream.Get("ColorSpace"))
+	if components == 0 {
+		return false
+	}
+
+	var filters []string
+	switch filter := core.TraceToDirectObject(stream.Get("Filter")).(type) {
+	case *core.PdfObjectName:
+		filters = []string{string(*filter)}
+	case *core.PdfObjectArray:
+		for _, element := range filter.Elements() {
+			name, ok := core.GetNameVal(core.TraceToDirectObject(element))
+			if !ok {
+				return false
+			}
+			filters = append(filters, name)
+		}
+	}
+
+	jpegImage := len(filters) == 1 && filters[0] == core.StreamEncodingFilterNameDCT
+	var samples []byte
+	if jpegImage {
+		// Go cannot write CMYK JPEGs, and Adobe CMYK JPEGs are often inverted.
+		if components == 4 {
+			return false
+		}
+		samples = decodeJPEGSamples(stream.Stream, components)
+	} else {
+		for _, filter := range filters {
+			if !resampleFilters[filter] {
+				return false
+			}
+		}
+		decoded, err := core.DecodeStream(stream)
+		if err != nil {
+			return false
+		}
+		samples = decoded
+	}
+	if len(samples) < srcWidth*srcHeight*components {
+		return false
+	}
+
+	resampled := resample(samples, srcWidth, srcHeight, components, width, height)
+	var encoded []byte
+	var err error
+	if jpegImage {
+		encoded, err = encodeJPEG(resampled, width, height, components, quality)
+	} else {
+		encoded, err = deflate(resampled)
+	}
+	if err != nil || len(encoded) >= len(stream.Stream) {
+		return false
+	}
+
+	stream.Stream = encoded
+	stream.Set("Width", core.MakeInteger(int64(width)))
+	stream.Set("Height", core.MakeInteger(int64(height)))
+	if !jpegImage {
+		stream.Set("Filter", core.MakeName(core.StreamEncodingFilterNameFlate))
+	}
+	stream.Remove("DecodeParms")
+	stream.Set("Length", core.MakeInteger(int64(len(encoded))))
+	return true
+}
+
+// imageComponents returns the components per sample of a gray, RGB or CMYK
+// color space, or 0 for others, such as indexed spaces, whose samples
+// cannot be averaged.
+func imageComponents(obj core.PdfObject) int {
+	obj = core.TraceToDirectObject(obj)
+	if name, ok := core.GetNameVal(obj); ok {
+		switch name {
+		case "DeviceGray", "G":
+			return 1
+		case "DeviceRGB", "RGB":
+			return 3
+		case "DeviceCMYK", "CMYK":
+			return 4
+		}
+		return 0
+	}
+	array, ok := core.GetArray(obj)
+	if !ok || array.Len() < 2 {
+		return 0
+	}
+	family, _ := core.GetNameVal(core.TraceToDirectObject(array.Get(0)))
+	switch family {
+	case "CalGray":
+		return 1
+	case "CalRGB", "Lab":
+		return 3
+	case "ICCBased":
+		profile, ok := core.GetStream(core.TraceToDirectObject(array.Get(1)))
+		if !ok {
+			return 0
+		}
+		n, _ := core.GetIntVal(core.TraceToDirectObject(profile.Get("N")))
+		if n == 1 || n == 3 || n == 4 {
+			return n
+		}
+	}
+	return 0
+}
+
+// decodeJPEGSamples decodes a gray or color JPEG to interleaved 8-bit
+// samples, or returns nil when its components do not match.
+func decodeJPEGSamples(data []byte, components int) []byte {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	bounds := img.Bounds()
+	samples := make([]byte, 0, bounds.Dx()*bounds.Dy()*components)
+	switch components {
+	case 1:
+		gray, ok := img.(*image.Gray)
+		if !ok {
+			return nil
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			samples = append(samples, gray.Pix[(y-bounds.Min.Y)*gray.Stride:][:bounds.Dx()]...)
+		}
+	case 3:
+		if _, ok := img.(*image.YCbCr); !ok {
+			return nil
+		}
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				samples = append(samples, c.R, c.G, c.B)
+			}
+		}
+	default:
+		return nil
+	}
+	return samples
+}
+
+// encodeJPEG encodes interleaved gray or RGB samples as a JPEG.
+func encodeJPEG(samples []byte, width, height, components, quality int) ([]byte, error) {
+	var img image.Image
+	if components == 1 {
+		img = &image.Gray{Pix: samples, Stride: width, Rect: image.Rect(0, 0, width, height)}
+	} else {
+		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i := 0; i < width*height; i++ {
+			copy(rgba.Pix[i*4:], samples[i*3:i*3+3])
+			rgba.Pix[i*4+3] = 0xff
+		}
+		img = rgba
+	}
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// resample scales interleaved 8-bit samples to width × height, averaging
+// the source pixels each target pixel covers.
+func resample(samples []byte, srcWidth, srcHeight, components, width, height int) []byte {
+	out := make([]byte, width*height*components)
+	sums := make([]int, components)
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+			for c := range sums {
+				sums[c] = 0
+			}
+			for sy := y0; sy < y1; sy++ {
+				row := sy * srcWidth * components
+				for sx := x0; sx < x1; sx++ {
+					for c := range sums {
+						sums[c] += int(samples[row+sx*components+c])
+					}
+				}
+			}
+			count := (y1 - y0) * (x1 - x0)
+			target := (y*width + x) * components
+			for c, sum := range sums {
+				out[target+c] = byte((sum + count/2) / count)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,297 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// fontObjects are a page using two identical embedded fonts written with
+// different key orders, a second page reusing its content stream, an XMP
+// packet and an unreferenced object.
+var fontObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R /Metadata 12 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 9 0 R >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 10 0 R >>",
+	"<< /Type /Font /Subtype /TrueType /BaseFont /ABCDEF+Serif /FontDescriptor 7 0 R /FirstChar 32 /LastChar 33 /Widths [250 333] >>",
+	"<< /Subtype /TrueType /Type /Font /BaseFont /ABCDEF+Serif /Widths [250 333] /FirstChar 32 /LastChar 33 /FontDescriptor 8 0 R >>",
+	"<< /Type /FontDescriptor /FontName /ABCDEF+Serif /Flags 4 /FontBBox [0 0 1000 1000] /ItalicAngle 0 /Ascent 800 /Descent -200 /CapHeight 700 /StemV 80 /FontFile2 11 0 R >>",
+	"<< /Type /FontDescriptor /FontName /ABCDEF+Serif /Flags 4 /FontBBox [0 0 1000 1000] /ItalicAngle 0 /Ascent 800 /Descent -200 /CapHeight 700 /StemV 80 /FontFile2 13 0 R >>",
+	contentStream("", strings.Repeat("BT /F1 12 Tf 72 712 Td (Hello) Tj ET\n", 20)),
+	contentStream("", strings.Repeat("BT /F1 12 Tf 72 712 Td (Hello) Tj ET\n", 20)),
+	"<< /Length 8 >>\nstream\nfontdata\nendstream",
+	"<< /Type /Metadata /Subtype /XML /Length 31 >>\nstream\n<x:xmpmeta xmlns:x=\"adobe:ns\"/>\nendstream",
+	"<< /Length 8 >>\nstream\nfontdata\nendstream",
+	"<< /Unreferenced true >>",
+}
+
+// optimizePDF optimizes data with opts and checks that the output
+// validates without errors.
+func optimizePDF(t *testing.T, data []byte, opts OptimizeOptions) ([]byte, *OptimizeResult) {
+	t.Helper()
+	var out bytes.Buffer
+	service := &RepairServiceImpl{}
+	result, err := service.OptimizeFileWithOptions(context.Background(), bytes.NewReader(data), &out, opts)
+	if err != nil {
+		t.Fatalf("OptimizeFileWithOptions failed: %v", err)
+	}
+	if result.OptimizedSize != int64(out.Len()) || result.OriginalSize != int64(len(data)) {
+		t.Errorf("Sizes = %d -> %d, want %d -> %d", result.OriginalSize, result.OptimizedSize, len(data), out.Len())
+	}
+
+	validation, err := NewStructureValidator().ValidateBytes(out.Bytes())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, e := range validation.Errors {
+		t.Errorf("Unexpected error in optimized file: %s - %s %v", e.Code, e.Message, e.Details)
+	}
+	return out.Bytes(), result
+}
+
+func TestOptimizeFile_Defaults(t *testing.T) {
+	data := buildPDF(fontObjects...)
+	var out bytes.Buffer
+	if err := NewRepairService().OptimizeFile(context.Background(), bytes.NewReader(data), &out); err != nil {
+		t.Fatalf("OptimizeFile failed: %v", err)
+	}
+
+	optimized, result := optimizePDF(t, data, DefaultOptimizeOptions())
+	if !bytes.Equal(optimized, out.Bytes()) {
+		t.Error("Expected OptimizeFile to use the default options")
+	}
+	if result.ObjectsBefore != 14 || result.ObjectsAfter != 9 {
+		t.Errorf("Objects = %d -> %d, want 14 -> 9", result.ObjectsBefore, result.ObjectsAfter)
+	}
+	if result.StreamsDeduplicated != 2 || result.FontsDeduplicated != 1 {
+		t.Errorf("Deduplicated %d stream(s) and %d font(s), want 2 and 1", result.StreamsDeduplicated, result.FontsDeduplicated)
+	}
+	if result.StreamsCompressed != 1 {
+		t.Errorf("Compressed %d stream(s), want 1", result.StreamsCompressed)
+	}
+	if result.ObjectStreams != 1 || result.Linearized {
+		t.Errorf("Expected one object stream and no linearization, got %+v", result)
+	}
+	if result.Saved() <= 0 {
+		t.Errorf("Expected the file to shrink, got %d -> %d bytes", result.OriginalSize, result.OptimizedSize)
+	}
+	if !bytes.HasPrefix(optimized, []byte("%PDF-1.5\n")) {
+		t.Errorf("Expected the version to be raised to 1.5, got %q", optimized[:9])
+	}
+	if !bytes.Contains(optimized, []byte(`<x:xmpmeta xmlns:x="adobe:ns"/>`)) {
+		t.Error("Expected the XMP packet to stay uncompressed")
+	}
+
+	// The pages still draw their text with the merged font.
+	doc, err := loadDocument(optimized)
+	if err != nil {
+		t.Fatalf("Failed to load optimized file: %v", err)
+	}
+	if len(doc.pages) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(doc.pages))
+	}
+	for _, number := range doc.pages {
+		page, _ := core.GetDict(doc.direct(number))
+		content, err := pageContent(page)
+		if err != nil || !bytes.Contains(content, []byte("(Hello) Tj")) {
+			t.Errorf("Page %d content = %q, %v", number, content, err)
+		}
+	}
+}
+
+func TestOptimizeFile_Options(t *testing.T) {
+	data := buildPDF(fontObjects...)
+
+	t.Run("cross-reference table", func(t *testing.T) {
+		optimized, result := optimizePDF(t, data, OptimizeOptions{Deduplicate: true})
+		if result.ObjectStreams != 0 || result.StreamsCompressed != 0 {
+			t.Errorf("Expected no object streams or compression, got %+v", result)
+		}
+		if !bytes.HasPrefix(optimized, []byte("%PDF-1.4\n")) || !bytes.Contains(optimized, []byte("\nxref\n0 10\n")) {
+			t.Error("Expected a PDF 1.4 file with a cross-reference table")
+		}
+	})
+
+	t.Run("linearized", func(t *testing.T) {
+		_, result := optimizePDF(t, data, OptimizeOptions{Deduplicate: true, Compress: true, Linearize: true})
+		if !result.Linearized || result.FontsDeduplicated != 1 {
+			t.Errorf("Expected a linearized file with merged fonts, got %+v", result)
+		}
+	})
+
+	t.Run("object stream input", func(t *testing.T) {
+		_, result := optimizePDF(t, buildXrefStreamPDF(0), DefaultOptimizeOptions())
+		if result.ObjectsAfter != 3 || result.ObjectStreams != 1 {
+			t.Errorf("Expected 3 objects in one object stream, got %+v", result)
+		}
+	})
+}
+
+func TestOptimizeFileWithOptions_Invalid(t *testing.T) {
+	service := &RepairServiceImpl{}
+	data := buildPDF(fontObjects...)
+
+	tests := []struct {
+		name string
+		data []byte
+		opts OptimizeOptions
+	}{
+		{name: "not a pdf", data: []byte("not a pdf"), opts: DefaultOptimizeOptions()},
+		{name: "object streams and linearization", data: data, opts: OptimizeOptions{ObjectStreams: true, Linearize: true}},
+		{name: "image quality", data: data, opts: OptimizeOptions{ImageDPI: 150, ImageQuality: 101}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if _, err := service.OptimizeFileWithOptions(context.Background(), bytes.NewReader(tt.data), &out, tt.opts); err == nil {
+				t.Error("Expected an error")
+			}
+			if out.Len() != 0 {
+				t.Errorf("Expected nothing written on error, got %d bytes", out.Len())
+			}
+		})
+	}
+}
+
+func TestOptimizeFile_MalformedEncrypt(t *testing.T) {
+	data := buildPDF(linearizationObjects...)
+	data = bytes.Replace(data, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 5"), 1)
+
+	var out bytes.Buffer
+	err := NewRepairService().OptimizeFile(context.Background(), bytes.NewReader(data), &out)
+	if err == nil {
+		t.Fatal("Expected an error for a malformed /Encrypt entry")
+	}
+	if !strings.Contains(err.Error(), "failed to parse PDF") || strings.Contains(err.Error(), "encrypted PDFs") {
+		t.Errorf("Expected a parse failure, got %v", err)
+	}
+}
+
+// imagePDF builds a page drawing a size × size image in a 100 pt square,
+// with the image dictionary entries and data given.
+func imagePDF(size int, entries string, data []byte) []byte {
+	image := contentStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8 %s", size, size, entries), string(data))
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im1 5 0 R >> >> /Contents 4 0 R >>",
+		contentStream("", "q 100 0 0 100 72 600 cm /Im1 Do Q"),
+		image,
+	)
+}
+
+func TestOptimizeFile_DownsampleImages(t *testing.T) {
+	// 400 samples in 100 pt is 288 ppi.
+	const size = 400
+	gradient := image.NewRGBA(image.Rect(0, 0, size, size))
+	raw := make([]byte, 0, size*size*3)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 0xff}
+			gradient.Set(x, y, c)
+			raw = append(raw, c.R, c.G, c.B)
+		}
+	}
+	flate, err := deflate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var photo bytes.Buffer
+	if err := jpeg.Encode(&photo, gradient, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		dpi        float64
+		wantWidth  int
+		wantFilter string
+	}{
+		{
+			name:       "flate image",
+			data:       imagePDF(size, "/ColorSpace /DeviceRGB /Filter /FlateDecode", flate),
+			dpi:        144,
+			wantWidth:  200,
+			wantFilter: core.StreamEncodingFilterNameFlate,
+		},
+		{
+			name:       "jpeg image",
+			data:       imagePDF(size, "/ColorSpace /DeviceRGB /Filter /DCTDecode", photo.Bytes()),
+			dpi:        72,
+			wantWidth:  100,
+			wantFilter: core.StreamEncodingFilterNameDCT,
+		},
+		{
+			name:       "below target",
+			data:       imagePDF(size, "/ColorSpace /DeviceRGB /Filter /FlateDecode", flate),
+			dpi:        300,
+			wantWidth:  size,
+			wantFilter: core.StreamEncodingFilterNameFlate,
+		},
+		{
+			name:       "indexed image",
+			data:       imagePDF(size, "/ColorSpace [/Indexed /DeviceRGB 0 <000000>] /Filter /FlateDecode", flate),
+			dpi:        144,
+			wantWidth:  size,
+			wantFilter: core.StreamEncodingFilterNameFlate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			optimized, result := optimizePDF(t, tt.data, OptimizeOptions{ImageDPI: tt.dpi})
+			wantDownsampled := 0
+			if tt.wantWidth != size {
+				wantDownsampled = 1
+			}
+			if result.ImagesDownsampled != wantDownsampled {
+				t.Errorf("Downsampled %d image(s), want %d", result.ImagesDownsampled, wantDownsampled)
+			}
+
+			doc, err := loadDocument(optimized)
+			if err != nil {
+				t.Fatalf("Failed to load optimized file: %v", err)
+			}
+			images := 0
+			for _, obj := range doc.objects {
+				stream, ok := obj.(*core.PdfObjectStream)
+				if !ok || stream.Get("Width") == nil {
+					continue
+				}
+				images++
+				width, _ := core.GetIntVal(stream.Get("Width"))
+				height, _ := core.GetIntVal(stream.Get("Height"))
+				filter, _ := core.GetNameVal(stream.Get("Filter"))
+				if width != tt.wantWidth || height != tt.wantWidth || filter != tt.wantFilter {
+					t.Errorf("Image is %dx%d %s, want %dx%d %s", width, height, filter, tt.wantWidth, tt.wantWidth, tt.wantFilter)
+				}
+				if _, err := core.DecodeStream(stream); err != nil && filter != core.StreamEncodingFilterNameDCT {
+					t.Errorf("Image fails to decode: %v", err)
+				}
+			}
+			if images != 1 {
+				t.Errorf("Expected 1 image in the optimized file, got %d", images)
+			}
+		})
+	}
+}
+
+func TestResample(t *testing.T) {
+	samples := []byte{
+		0, 10, 20, 30,
+		40, 50, 60, 70,
+	}
+	got := resample(samples, 4, 2, 1, 2, 1)
+	if want := []byte{25, 45}; !bytes.Equal(got, want) {
+		t.Errorf("resample = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,323 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Page tree validation error codes.
+const (
+	ErrorCodePDFPages001 = "PDF-PAGES-001"
+	ErrorCodePDFPages002 = "PDF-PAGES-002"
+	ErrorCodePDFPages003 = "PDF-PAGES-003"
+	ErrorCodePDFPages004 = "PDF-PAGES-004"
+	ErrorCodePDFPages005 = "PDF-PAGES-005"
+	ErrorCodePDFPages006 = "PDF-PAGES-006"
+	ErrorCodePDFPages007 = "PDF-PAGES-007"
+)
+
+// pageAttributes are the inheritable page attributes in effect at a node,
+// with the object numbers of the nodes that define them.
+type pageAttributes struct {
+	resources   core.PdfObject
+	mediaBox    []float64
+	mediaOwner  int64
+	cropBox     []float64
+	cropOwner   int64
+	hasMediaBox bool
+}
+
+// pageEntry is a leaf page found by the page tree walk.
+type pageEntry struct {
+	// object is the page's object number, 0 for a direct object.
+	object int64
+	dict   *core.PdfObjectDictionary
+	// resources are the effective /Resources of the page, nil when the
+	// page has none.
+	resources core.PdfObject
+}
+
+// pageTreeWalk is the state of a walk over the page tree.
+type pageTreeWalk struct {
+	result  *StructureValidationResult
+	visited map[int64]bool
+	pages   []pageEntry
+
+	missingMediaBox  []int
+	missingResources []int
+	cropReported     map[[2]int64]bool
+}
+
+// validatePageTree walks the page tree from the catalog /Pages entry,
+// checking node types, /Count, /Parent links, cycles, inherited attributes
+// and page boxes. It returns the pages found, in document order.
+func (v *StructureValidator) validatePageTree(pagesObj core.PdfObject, result *StructureValidationResult) []pageEntry {
+	w := &pageTreeWalk{
+		result:       result,
+		visited:      make(map[int64]bool),
+		cropReported: make(map[[2]int64]bool),
+	}
+	w.walk(pagesObj, 0, pageAttributes{}, true)
+
+	if len(w.missingMediaBox) > 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFPages005,
+			Message: fmt.Sprintf("%d page(s) have no /MediaBox, directly or inherited", len(w.missingMediaBox)),
+			Details: map[string]interface{}{
+				"attribute": "MediaBox",
+				"pages":     w.missingMediaBox,
+			},
+		})
+	}
+	// Resources are required, but viewers treat a missing dictionary as
+	// empty, so this only breaks pages that draw fonts or images.
+	if len(w.missingResources) > 0 {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFPages005,
+			Message: fmt.Sprintf("%d page(s) have no /Resources, directly or inherited", len(w.missingResources)),
+			Details: map[string]interface{}{
+				"attribute": "Resources",
+				"pages":     w.missingResources,
+			},
+		})
+	}
+
+	return w.pages
+}
+
+// walk validates the node obj and its descendants and returns the number of
+// leaf pages below it. parent is the object number of the parent node, or 0
+// for the root.
+func (w *pageTreeWalk) walk(obj core.PdfObject, parent int64, inherited pageAttributes, root bool) int {
+	objectNumber := int64(0)
+	if ref, ok := obj.(*core.PdfObjectReference); ok {
+		objectNumber = ref.ObjectNumber
+	} else if !root {
+		w.addError(ErrorCodePDFPages001, "Page tree /Kids entry is not an indirect reference", map[string]interface{}{
+			"parent": parent,
+		})
+	}
+
+	if objectNumber != 0 {
+		if w.visited[objectNumber] {
+			w.addError(ErrorCodePDFPages004, "Page tree node is reached more than once (cycle or shared node)", map[string]interface{}{
+				"object": objectNumber,
+				"parent": parent,
+			})
+			return 0
+		}
+		w.visited[objectNumber] = true
+	}
+
+	dict, ok := core.GetDict(core.TraceToDirectObject(obj))
+	if !ok {
+		w.addError(ErrorCodePDFPages001, "Page tree node is not a dictionary", map[string]interface{}{
+			"object": objectNumber,
+			"parent": parent,
+		})
+		return 0
+	}
+
+	if !root {
+		w.checkParent(dict, objectNumber, parent)
+	}
+
+	attrs := w.inherit(dict, objectNumber, inherited)
+
+	nodeType, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("Type")))
+	switch nodeType {
+	case "Page":
+		w.visitPage(dict, objectNumber, attrs)
+		return 1
+	case "Pages":
+	default:
+		_, hasKids := core.GetArray(core.TraceToDirectObject(dict.Get("Kids")))
+		w.addError(ErrorCodePDFPages001, "Page tree node /Type must be /Pages or /Page", map[string]interface{}{
+			"object": objectNumber,
+			"found":  nodeType,
+		})
+		if !hasKids {
+			w.visitPage(dict, objectNumber, attrs)
+			return 1
+		}
+	}
+
+	kids, ok := core.GetArray(core.TraceToDirectObject(dict.Get("Kids")))
+	if !ok {
+		w.addError(ErrorCodePDFPages001, "Pages node is missing a /Kids array", map[string]interface{}{
+			"object": objectNumber,
+		})
+		return 0
+	}
+
+	leaves := 0
+	for _, kid := range kids.Elements() {
+		leaves += w.walk(kid, objectNumber, attrs, false)
+	}
+
+	count, ok := core.GetIntVal(core.TraceToDirectObject(dict.Get("Count")))
+	switch {
+	case !ok:
+		w.addError(ErrorCodePDFPages002, "Pages node is missing an integer /Count", map[string]interface{}{
+			"object": objectNumber,
+			"actual": leaves,
+		})
+	case count != leaves:
+		w.addError(ErrorCodePDFPages002, fmt.Sprintf("Pages node /Count is %d but %d page(s) were found", count, leaves), map[string]interface{}{
+			"object":   objectNumber,
+			"declared": count,
+			"actual":   leaves,
+		})
+	}
+	return leaves
+}
+
+// checkParent reports a node whose /Parent does not refer to the node that
+// lists it in /Kids.
+func (w *pageTreeWalk) checkParent(dict *core.PdfObjectDictionary, objectNumber, parent int64) {
+	parentObj := dict.Get("Parent")
+	if parentObj == nil {
+		w.addError(ErrorCodePDFPages003, "Page tree node is missing /Parent", map[string]interface{}{
+			"object":          objectNumber,
+			"expected_parent": parent,
+		})
+		return
+	}
+	ref, ok := parentObj.(*core.PdfObjectReference)
+	if !ok || (parent != 0 && ref.ObjectNumber != parent) {
+		found := parentObj.String()
+		w.addError(ErrorCodePDFPages003, "Page tree node /Parent does not refer to the node that lists it", map[string]interface{}{
+			"object":          objectNumber,
+			"expected_parent": parent,
+			"found":           found,
+		})
+	}
+}
+
+// inherit returns the attributes in effect at a node, validating the boxes
+// and rotation the node itself defines.
+func (w *pageTreeWalk) inherit(dict *core.PdfObjectDictionary, objectNumber int64, inherited pageAttributes) pageAttributes {
+	attrs := inherited
+
+	if resources := core.TraceToDirectObject(dict.Get("Resources")); resources != nil && !core.IsNullObject(resources) {
+		if _, ok := core.GetDict(resources); ok {
+			attrs.resources = resources
+		} else {
+			w.addError(ErrorCodePDFPages005, "/Resources is not a dictionary", map[string]interface{}{
+				"object": objectNumber,
+			})
+		}
+	}
+
+	if obj := dict.Get("MediaBox"); obj != nil {
+		if box, ok := w.parseBox(obj, "MediaBox", objectNumber); ok {
+			attrs.mediaBox, attrs.mediaOwner, attrs.hasMediaBox = box, objectNumber, true
+		}
+	}
+	if obj := dict.Get("CropBox"); obj != nil {
+		if box, ok := w.parseBox(obj, "CropBox", objectNumber); ok {
+			attrs.cropBox, attrs.cropOwner = box, objectNumber
+		}
+	}
+
+	if obj := dict.Get("Rotate"); obj != nil {
+		rotate, ok := core.GetIntVal(core.TraceToDirectObject(obj))
+		if !ok || rotate%90 != 0 {
+			w.addError(ErrorCodePDFPages007, "/Rotate must be an integer multiple of 90", map[string]interface{}{
+				"object": objectNumber,
+				"found":  obj.String(),
+			})
+		}
+	}
+
+	return attrs
+}
+
+// visitPage checks a leaf page against its effective attributes.
+func (w *pageTreeWalk) visitPage(dict *core.PdfObjectDictionary, objectNumber int64, attrs pageAttributes) {
+	w.pages = append(w.pages, pageEntry{object: objectNumber, dict: dict, resources: attrs.resources})
+	page := len(w.pages)
+	if !attrs.hasMediaBox {
+		w.missingMediaBox = append(w.missingMediaBox, page)
+	}
+	if attrs.resources == nil {
+		w.missingResources = append(w.missingResources, page)
+	}
+
+	if attrs.hasMediaBox && attrs.cropBox != nil && !boxWithin(attrs.cropBox, attrs.mediaBox) {
+		key := [2]int64{attrs.cropOwner, attrs.mediaOwner}
+		if !w.cropReported[key] {
+			w.cropReported[key] = true
+			w.result.Warnings = append(w.result.Warnings, ValidationError{
+				Code:    ErrorCodePDFPages006,
+				Message: "/CropBox extends beyond the /MediaBox; viewers clip it to the media box",
+				Details: map[string]interface{}{
+					"object":    objectNumber,
+					"page":      page,
+					"crop_box":  attrs.cropBox,
+					"media_box": attrs.mediaBox,
+				},
+			})
+		}
+	}
+}
+
+// parseBox parses a rectangle, reporting arrays that are malformed or have
+// no area.
+func (w *pageTreeWalk) parseBox(obj core.PdfObject, name string, objectNumber int64) ([]float64, bool) {
+	arr, ok := core.GetArray(core.TraceToDirectObject(obj))
+	var box []float64
+	if ok && arr.Len() == 4 {
+		elements := make([]core.PdfObject, 0, 4)
+		for _, element := range arr.Elements() {
+			elements = append(elements, core.TraceToDirectObject(element))
+		}
+		if values, err := core.GetNumbersAsFloat(elements); err == nil {
+			box = values
+		}
+	}
+	if box == nil {
+		w.addError(ErrorCodePDFPages006, fmt.Sprintf("/%s must be an array of four numbers", name), map[string]interface{}{
+			"object": objectNumber,
+			"box":    name,
+			"found":  obj.String(),
+		})
+		return nil, false
+	}
+
+	box = normalizeBox(box)
+	if box[2]-box[0] <= 0 || box[3]-box[1] <= 0 {
+		w.addError(ErrorCodePDFPages006, fmt.Sprintf("/%s has zero or negative area", name), map[string]interface{}{
+			"object": objectNumber,
+			"box":    name,
+			"found":  box,
+		})
+		return nil, false
+	}
+	return box, true
+}
+
+func (w *pageTreeWalk) addError(code, message string, details map[string]interface{}) {
+	w.result.Errors = append(w.result.Errors, ValidationError{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// normalizeBox orders a rectangle as [llx lly urx ury]; PDF allows any two
+// diagonally opposite corners.
+func normalizeBox(box []float64) []float64 {
+	return []float64{
+		math.Min(box[0], box[2]), math.Min(box[1], box[3]),
+		math.Max(box[0], box[2]), math.Max(box[1], box[3]),
+	}
+}
+
+// boxWithin reports whether inner lies inside outer.
+func boxWithin(inner, outer []float64) bool {
+	return inner[0] >= outer[0] && inner[1] >= outer[1] &&
+		inner[2] <= outer[2] && inner[3] <= outer[3]
+}
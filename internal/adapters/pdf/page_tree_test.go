@@ -0,0 +1,206 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// buildPDF assembles a PDF from numbered object bodies, starting at object
+// 1, with a correct cross-reference table. Object 1 must be the catalog.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestStructureValidator_PageTree(t *testing.T) {
+	const catalog = "<< /Type /Catalog /Pages 2 0 R >>"
+	const resources = "/Resources << >>"
+
+	tests := []struct {
+		name         string
+		objects      []string
+		wantErrors   []string
+		wantWarnings []string
+		wantPages    int
+	}{
+		{
+			name: "inherited attributes",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Page /Parent 2 0 R /Rotate 90 >>",
+				"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R 6 0 R] /Count 2 /CropBox [10 10 600 780] >>",
+				"<< /Type /Page /Parent 4 0 R >>",
+				"<< /Type /Page /Parent 4 0 R /MediaBox [0 792 612 0] >>",
+			},
+			wantPages: 3,
+		},
+		{
+			name: "count mismatch",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R] /Count 2 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Page /Parent 2 0 R >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages002},
+			wantPages:  1,
+		},
+		{
+			name: "wrong parent",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Page /Parent 1 0 R >>",
+				"<< /Type /Page >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages003, ErrorCodePDFPages003},
+			wantPages:  2,
+		},
+		{
+			name: "cycle",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Pages /Parent 2 0 R /Kids [4 0 R 2 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 3 0 R >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages004},
+			wantPages:  1,
+		},
+		{
+			name: "invalid node type",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Pag /Parent 2 0 R >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages001},
+			wantPages:  1,
+		},
+		{
+			name: "missing media box and resources",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+				"<< /Type /Page /Parent 2 0 R >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+			},
+			wantErrors:   []string{ErrorCodePDFPages005},
+			wantWarnings: []string{ErrorCodePDFPages005},
+			wantPages:    2,
+		},
+		{
+			name: "invalid boxes",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612] >>",
+				"<< /Type /Page /Parent 2 0 R /CropBox [0 0 0 792] >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages006, ErrorCodePDFPages006},
+			wantPages:  2,
+		},
+		{
+			name: "crop box outside media box reported once",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] /CropBox [0 0 700 800] " + resources + " >>",
+				"<< /Type /Page /Parent 2 0 R >>",
+				"<< /Type /Page /Parent 2 0 R >>",
+			},
+			wantWarnings: []string{ErrorCodePDFPages006},
+			wantPages:    2,
+		},
+		{
+			name: "invalid rotation",
+			objects: []string{
+				catalog,
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] " + resources + " >>",
+				"<< /Type /Page /Parent 2 0 R /Rotate 45 >>",
+			},
+			wantErrors: []string{ErrorCodePDFPages007},
+			wantPages:  1,
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(buildPDF(tt.objects...))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s", e.Code, e.Message)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+			if result.Valid != (len(tt.wantErrors) == 0) {
+				t.Errorf("Valid = %v with errors %v", result.Valid, tt.wantErrors)
+			}
+			if result.PageCount != tt.wantPages {
+				t.Errorf("PageCount = %d, want %d", result.PageCount, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestStructureValidator_PageTree_MissingPagesListed(t *testing.T) {
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 /Resources << >> >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+	)
+
+	result, err := NewStructureValidator().ValidateBytes(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %v", result.Errors)
+	}
+	pages, ok := result.Errors[0].Details["pages"].([]int)
+	if !ok || len(pages) != 2 || pages[0] != 1 || pages[1] != 3 {
+		t.Errorf("Expected pages [1 3], got %v", result.Errors[0].Details["pages"])
+	}
+}
+
+func errorCodes(findings []ValidationError) []string {
+	codes := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		codes = append(codes, finding.Code)
+	}
+	return codes
+}
+
+func equalCodes(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
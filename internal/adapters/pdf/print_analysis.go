@@ -0,0 +1,589 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Print readiness error codes.
+const (
+	ErrorCodePDFPrint001 = "PDF-PRINT-001"
+	ErrorCodePDFPrint002 = "PDF-PRINT-002"
+	ErrorCodePDFPrint003 = "PDF-PRINT-003"
+	ErrorCodePDFPrint004 = "PDF-PRINT-004"
+	ErrorCodePDFPrint005 = "PDF-PRINT-005"
+	ErrorCodePDFPrint006 = "PDF-PRINT-006"
+)
+
+// CategoryPrint is the Details["category"] of print readiness findings,
+// for use with reporter.Filter.Categories.
+const CategoryPrint = "print"
+
+// Color families reported by the print analysis.
+const (
+	ColorGray = "gray"
+	ColorRGB  = "rgb"
+	ColorCMYK = "cmyk"
+	ColorLab  = "lab"
+	ColorSpot = "spot"
+)
+
+// maxFormDepth bounds the nesting of form XObjects followed by the print
+// analysis.
+const maxFormDepth = 12
+
+// PrintRules configures the print readiness analysis. It runs when any rule
+// is set; the "print" profile sets them all.
+type PrintRules struct {
+	// MinImagePPI is the lowest effective resolution allowed for placed
+	// images, in pixels per inch.
+	MinImagePPI float64 `yaml:"min_image_ppi,omitempty" json:"min_image_ppi,omitempty"`
+	// RequireCMYK reports RGB and Lab images and page content. Gray and
+	// spot colors are allowed.
+	RequireCMYK bool `yaml:"require_cmyk,omitempty" json:"require_cmyk,omitempty"`
+	// RequireTrimBox reports pages without a /TrimBox.
+	RequireTrimBox bool `yaml:"require_trim_box,omitempty" json:"require_trim_box,omitempty"`
+	// RequireBleedBox reports pages without a /BleedBox.
+	RequireBleedBox bool `yaml:"require_bleed_box,omitempty" json:"require_bleed_box,omitempty"`
+}
+
+func (r PrintRules) enabled() bool {
+	return r.MinImagePPI > 0 || r.RequireCMYK || r.RequireTrimBox || r.RequireBleedBox
+}
+
+// ProfileRules are the PDF rules a validation profile can set.
+type ProfileRules struct {
+	Fonts FontRules  `yaml:"fonts,omitempty" json:"fonts,omitempty"`
+	Print PrintRules `yaml:"print,omitempty" json:"print,omitempty"`
+}
+
+// imageUsage is an image XObject and where it is placed.
+type imageUsage struct {
+	name   string
+	object int64
+	width  int
+	height int
+	color  string
+	pages  []int
+	// minPPI is the lowest effective resolution of any placement.
+	minPPI float64
+	// lowPages are the pages where the image is below the minimum.
+	lowPages []int
+}
+
+// printAnalysis collects the images and colors used by each page.
+type printAnalysis struct {
+	rules  PrintRules
+	images map[*core.PdfObjectStream]*imageUsage
+	order  []*imageUsage
+	// colors maps a color family to the pages whose content, outside
+	// images, uses it.
+	colors map[string][]int
+	// imageColors maps a color family to the pages with images in it.
+	imageColors map[string][]int
+	// spots maps spot color names to the pages that use them.
+	spots map[string][]int
+	page  int
+}
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+var identityMatrix = matrix{1, 0, 0, 1, 0, 0}
+
+// multiply returns m × n, the transformation m applied in the space n.
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// validatePrint computes the effective resolution and color space of every
+// placed image, the color spaces and spot colors of page content, and the
+// page boxes, and reports them against v.Print.
+func (v *StructureValidator) validatePrint(pages []pageEntry, result *StructureValidationResult) {
+	if !v.Print.enabled() {
+		return
+	}
+	// Content streams cannot be decoded without the decryption key.
+	if result.Encryption != nil && !result.Encryption.Authenticated {
+		return
+	}
+
+	analysis := &printAnalysis{
+		rules:       v.Print,
+		images:      make(map[*core.PdfObjectStream]*imageUsage),
+		colors:      make(map[string][]int),
+		imageColors: make(map[string][]int),
+		spots:       make(map[string][]int),
+	}
+	var noTrim, noBleed []int
+	for i, page := range pages {
+		analysis.page = i + 1
+		if page.dict.Get("TrimBox") == nil {
+			noTrim = append(noTrim, i+1)
+		}
+		if page.dict.Get("BleedBox") == nil {
+			noBleed = append(noBleed, i+1)
+		}
+		content, err := pageContent(page.dict)
+		if err != nil {
+			continue
+		}
+		analysis.run(content, page.resources, identityMatrix, 0)
+	}
+
+	analysis.report(result)
+
+	if v.Print.RequireTrimBox && len(noTrim) > 0 {
+		addPrintFinding(result, false, ErrorCodePDFPrint004, fmt.Sprintf("%d page(s) have no /TrimBox", len(noTrim)), map[string]interface{}{
+			"box":   "TrimBox",
+			"pages": truncatePages(noTrim),
+			"count": len(noTrim),
+		})
+	}
+	if v.Print.RequireBleedBox && len(noBleed) > 0 {
+		addPrintFinding(result, true, ErrorCodePDFPrint005, fmt.Sprintf("%d page(s) have no /BleedBox", len(noBleed)), map[string]interface{}{
+			"box":   "BleedBox",
+			"pages": truncatePages(noBleed),
+			"count": len(noBleed),
+		})
+	}
+
+	colors := make(map[string]interface{})
+	for _, family := range []string{ColorGray, ColorRGB, ColorCMYK, ColorLab, ColorSpot} {
+		if usedOn := mergePages(analysis.colors[family], analysis.imageColors[family]); len(usedOn) > 0 {
+			colors[family] = truncatePages(usedOn)
+		}
+	}
+	spots := make([]string, 0, len(analysis.spots))
+	for name := range analysis.spots {
+		spots = append(spots, name)
+	}
+	sort.Strings(spots)
+	minPPI := 0.0
+	for _, image := range analysis.order {
+		if image.minPPI > 0 && (minPPI == 0 || image.minPPI < minPPI) {
+			minPPI = image.minPPI
+		}
+	}
+	addPrintInfo(result, fmt.Sprintf("Print analysis: %d image(s), %d spot color(s), %d of %d page(s) with a /TrimBox", len(analysis.order), len(spots), len(pages)-len(noTrim), len(pages)), map[string]interface{}{
+		"images":           len(analysis.order),
+		"min_image_ppi":    roundPPI(minPPI),
+		"colors":           colors,
+		"spot_colors":      spots,
+		"pages_with_trim":  len(pages) - len(noTrim),
+		"pages_with_bleed": len(pages) - len(noBleed),
+	})
+}
+
+// run interprets a content stream, following form XObjects, with ctm as
+// the initial transformation.
+func (a *printAnalysis) run(content []byte, resources core.PdfObject, ctm matrix, depth int) {
+	operations, err := contentstream.NewContentStreamParser(string(content)).Parse()
+	if err != nil {
+		return
+	}
+	resourceDict, _ := core.GetDict(core.TraceToDirectObject(resources))
+
+	var stack []matrix
+	for _, op := range *operations {
+		switch op.Operand {
+		case "q":
+			stack = append(stack, ctm)
+		case "Q":
+			if n := len(stack); n > 0 {
+				ctm, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			if m, ok := operandMatrix(op.Params); ok {
+				ctm = m.multiply(ctm)
+			}
+		case "g", "G":
+			a.useColor(ColorGray)
+		case "rg", "RG":
+			a.useColor(ColorRGB)
+		case "k", "K":
+			a.useColor(ColorCMYK)
+		case "cs", "CS":
+			if len(op.Params) == 1 {
+				a.useColorSpace(op.Params[0], resourceDict)
+			}
+		case "BI":
+			if len(op.Params) == 1 {
+				if inline, ok := op.Params[0].(*contentstream.ContentStreamInlineImage); ok {
+					a.useInlineImage(inline, resourceDict, ctm)
+				}
+			}
+		case "Do":
+			if len(op.Params) == 1 {
+				a.useXObject(op.Params[0], resourceDict, resources, ctm, depth)
+			}
+		}
+	}
+}
+
+// useXObject records an image placement or runs a form XObject.
+func (a *printAnalysis) useXObject(nameObj core.PdfObject, resources *core.PdfObjectDictionary, inherited core.PdfObject, ctm matrix, depth int) {
+	name, ok := core.GetNameVal(nameObj)
+	if !ok || resources == nil {
+		return
+	}
+	xobjects, ok := core.GetDict(core.TraceToDirectObject(resources.Get("XObject")))
+	if !ok {
+		return
+	}
+	obj := xobjects.Get(core.PdfObjectName(name))
+	stream, ok := core.GetStream(core.TraceToDirectObject(obj))
+	if !ok {
+		return
+	}
+
+	switch subtype, _ := core.GetNameVal(core.TraceToDirectObject(stream.Get("Subtype"))); subtype {
+	case "Image":
+		usage, ok := a.images[stream]
+		if !ok {
+			width, _ := core.GetIntVal(core.TraceToDirectObject(stream.Get("Width")))
+			height, _ := core.GetIntVal(core.TraceToDirectObject(stream.Get("Height")))
+			usage = &imageUsage{name: name, object: objectNumberOf(obj), width: width, height: height}
+			if mask, _ := core.GetBoolVal(core.TraceToDirectObject(stream.Get("ImageMask"))); !mask {
+				usage.color = a.colorFamily(stream.Get("ColorSpace"), resources, 0)
+			}
+			a.images[stream] = usage
+			a.order = append(a.order, usage)
+		}
+		a.place(usage, ctm)
+	case "Form":
+		if depth >= maxFormDepth {
+			return
+		}
+		content, err := core.DecodeStream(stream)
+		if err != nil {
+			return
+		}
+		formCTM := ctm
+		if m, ok := arrayMatrix(stream.Get("Matrix")); ok {
+			formCTM = m.multiply(ctm)
+		}
+		formResources := stream.Get("Resources")
+		if formResources == nil {
+			formResources = inherited
+		}
+		a.run(content, formResources, formCTM, depth+1)
+	}
+}
+
+// useInlineImage records an inline image placement.
+func (a *printAnalysis) useInlineImage(inline *contentstream.ContentStreamInlineImage, resources *core.PdfObjectDictionary, ctm matrix) {
+	width, _ := core.GetIntVal(core.TraceToDirectObject(inline.Width))
+	height, _ := core.GetIntVal(core.TraceToDirectObject(inline.Height))
+	usage := &imageUsage{name: "inline image", width: width, height: height}
+	if mask, _ := core.GetBoolVal(core.TraceToDirectObject(inline.ImageMask)); !mask {
+		usage.color = a.colorFamily(inline.ColorSpace, resources, 0)
+	}
+	a.order = append(a.order, usage)
+	a.place(usage, ctm)
+}
+
+// place records an image drawn with ctm, which maps the unit square to the
+// placed image.
+func (a *printAnalysis) place(usage *imageUsage, ctm matrix) {
+	if n := len(usage.pages); n == 0 || usage.pages[n-1] != a.page {
+		usage.pages = append(usage.pages, a.page)
+	}
+	if usage.color != "" {
+		a.addPage(a.imageColors, usage.color)
+	}
+
+	// Placed size in inches, 72 points to the inch.
+	placedWidth := math.Hypot(ctm[0], ctm[1]) / 72
+	placedHeight := math.Hypot(ctm[2], ctm[3]) / 72
+	if placedWidth == 0 || placedHeight == 0 || usage.width <= 0 || usage.height <= 0 {
+		return
+	}
+	ppi := math.Min(float64(usage.width)/placedWidth, float64(usage.height)/placedHeight)
+	if usage.minPPI == 0 || ppi < usage.minPPI {
+		usage.minPPI = ppi
+	}
+	if a.rules.MinImagePPI > 0 && ppi < a.rules.MinImagePPI {
+		if n := len(usage.lowPages); n == 0 || usage.lowPages[n-1] != a.page {
+			usage.lowPages = append(usage.lowPages, a.page)
+		}
+	}
+}
+
+// useColor records a color family used by page content.
+func (a *printAnalysis) useColor(family string) {
+	a.addPage(a.colors, family)
+}
+
+// useColorSpace records the color space selected by cs or CS.
+func (a *printAnalysis) useColorSpace(obj core.PdfObject, resources *core.PdfObjectDictionary) {
+	if family := a.colorFamily(obj, resources, 0); family != "" {
+		a.addPage(a.colors, family)
+	}
+}
+
+// colorFamily classifies a color space given by name or array, recording
+// the names of spot colors. It returns "" for patterns and unknown spaces.
+func (a *printAnalysis) colorFamily(obj core.PdfObject, resources *core.PdfObjectDictionary, depth int) string {
+	if depth > 4 {
+		return ""
+	}
+	direct := core.TraceToDirectObject(obj)
+	if name, ok := core.GetNameVal(direct); ok {
+		switch name {
+		case "DeviceGray", "G", "CalGray":
+			return ColorGray
+		case "DeviceRGB", "RGB", "CalRGB":
+			return ColorRGB
+		case "DeviceCMYK", "CMYK":
+			return ColorCMYK
+		case "Pattern":
+			return ""
+		}
+		if resources != nil {
+			if spaces, ok := core.GetDict(core.TraceToDirectObject(resources.Get("ColorSpace"))); ok {
+				if named := spaces.Get(core.PdfObjectName(name)); named != nil {
+					return a.colorFamily(named, resources, depth+1)
+				}
+			}
+		}
+		return ""
+	}
+
+	arr, ok := core.GetArray(direct)
+	if !ok || arr.Len() == 0 {
+		return ""
+	}
+	family, _ := core.GetNameVal(core.TraceToDirectObject(arr.Get(0)))
+	switch family {
+	case "CalGray":
+		return ColorGray
+	case "CalRGB":
+		return ColorRGB
+	case "Lab":
+		return ColorLab
+	case "ICCBased":
+		if profile, ok := core.GetStream(core.TraceToDirectObject(arr.Get(1))); ok {
+			switch components, _ := core.GetIntVal(core.TraceToDirectObject(profile.Get("N"))); components {
+			case 1:
+				return ColorGray
+			case 3:
+				return ColorRGB
+			case 4:
+				return ColorCMYK
+			}
+		}
+	case "Indexed", "I":
+		return a.colorFamily(arr.Get(1), resources, depth+1)
+	case "Separation":
+		name, _ := core.GetNameVal(core.TraceToDirectObject(arr.Get(1)))
+		if name == "All" || name == "None" {
+			return ColorCMYK
+		}
+		a.addPage(a.spots, name)
+		return ColorSpot
+	case "DeviceN":
+		spot := false
+		if names, ok := core.GetArray(core.TraceToDirectObject(arr.Get(1))); ok {
+			for _, element := range names.Elements() {
+				switch name, _ := core.GetNameVal(core.TraceToDirectObject(element)); name {
+				case "Cyan", "Magenta", "Yellow", "Black", "None", "":
+				default:
+					a.addPage(a.spots, name)
+					spot = true
+				}
+			}
+		}
+		if spot {
+			return ColorSpot
+		}
+		return ColorCMYK
+	}
+	return ""
+}
+
+func (a *printAnalysis) addPage(index map[string][]int, key string) {
+	if n := len(index[key]); n == 0 || index[key][n-1] != a.page {
+		index[key] = append(index[key], a.page)
+	}
+}
+
+// report adds the image and color findings.
+func (a *printAnalysis) report(result *StructureValidationResult) {
+	for _, image := range a.order {
+		if len(image.lowPages) > 0 {
+			addPrintFinding(result, false, ErrorCodePDFPrint001, fmt.Sprintf("Image %s is %.0f ppi on page %s; at least %.0f ppi is required", imageLabel(image), image.minPPI, pageList(image.lowPages), a.rules.MinImagePPI), imageDetails(image, map[string]interface{}{
+				"ppi":      roundPPI(image.minPPI),
+				"required": a.rules.MinImagePPI,
+				"pages":    truncatePages(image.lowPages),
+			}))
+		}
+		if a.rules.RequireCMYK && (image.color == ColorRGB || image.color == ColorLab) {
+			addPrintFinding(result, false, ErrorCodePDFPrint002, fmt.Sprintf("Image %s on page %s uses %s color; CMYK is required", imageLabel(image), pageList(image.pages), strings.ToUpper(image.color)), imageDetails(image, map[string]interface{}{
+				"color_space": image.color,
+				"pages":       truncatePages(image.pages),
+			}))
+		}
+	}
+
+	if a.rules.RequireCMYK {
+		for _, family := range []string{ColorRGB, ColorLab} {
+			if pages := a.colors[family]; len(pages) > 0 {
+				addPrintFinding(result, false, ErrorCodePDFPrint002, fmt.Sprintf("Page content uses %s color on %d page(s); CMYK is required", strings.ToUpper(family), len(pages)), map[string]interface{}{
+					"color_space": family,
+					"pages":       truncatePages(pages),
+				})
+			}
+		}
+	}
+
+	if len(a.spots) > 0 {
+		names := make([]string, 0, len(a.spots))
+		pages := make(map[string]interface{}, len(a.spots))
+		for name, usedOn := range a.spots {
+			names = append(names, name)
+			pages[name] = truncatePages(usedOn)
+		}
+		sort.Strings(names)
+		addPrintFinding(result, true, ErrorCodePDFPrint003, fmt.Sprintf("Document uses %d spot color(s): %s", len(names), strings.Join(names, ", ")), map[string]interface{}{
+			"spot_colors": names,
+			"pages":       pages,
+		})
+	}
+}
+
+// mergePages returns the sorted union of two ascending page lists.
+func mergePages(a, b []int) []int {
+	merged := make([]int, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0] < b[0]):
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+// pageContent returns the decoded, concatenated /Contents of a page.
+func pageContent(page *core.PdfObjectDictionary) ([]byte, error) {
+	contents := core.TraceToDirectObject(page.Get("Contents"))
+	var streams []core.PdfObject
+	if arr, ok := core.GetArray(contents); ok {
+		streams = arr.Elements()
+	} else if contents != nil {
+		streams = []core.PdfObject{contents}
+	}
+
+	var content bytes.Buffer
+	for _, obj := range streams {
+		stream, ok := core.GetStream(core.TraceToDirectObject(obj))
+		if !ok {
+			continue
+		}
+		decoded, err := core.DecodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		content.Write(decoded)
+		content.WriteByte('\n')
+	}
+	return content.Bytes(), nil
+}
+
+// operandMatrix reads the six numeric operands of cm.
+func operandMatrix(params []core.PdfObject) (matrix, bool) {
+	if len(params) != 6 {
+		return matrix{}, false
+	}
+	values, err := core.GetNumbersAsFloat(params)
+	if err != nil {
+		return matrix{}, false
+	}
+	return matrix{values[0], values[1], values[2], values[3], values[4], values[5]}, true
+}
+
+// arrayMatrix reads a six-number matrix array such as a form /Matrix.
+func arrayMatrix(obj core.PdfObject) (matrix, bool) {
+	arr, ok := core.GetArray(core.TraceToDirectObject(obj))
+	if !ok {
+		return matrix{}, false
+	}
+	elements := make([]core.PdfObject, 0, arr.Len())
+	for _, element := range arr.Elements() {
+		elements = append(elements, core.TraceToDirectObject(element))
+	}
+	return operandMatrix(elements)
+}
+
+func imageLabel(image *imageUsage) string {
+	if image.object != 0 {
+		return fmt.Sprintf("%s (object %d)", image.name, image.object)
+	}
+	return image.name
+}
+
+func imageDetails(image *imageUsage, details map[string]interface{}) map[string]interface{} {
+	details["image"] = image.name
+	details["width"] = image.width
+	details["height"] = image.height
+	if image.object != 0 {
+		details["object"] = image.object
+	}
+	return details
+}
+
+// pageList formats up to maxReportedPages page numbers for a message.
+func pageList(pages []int) string {
+	shown := truncatePages(pages)
+	parts := make([]string, len(shown))
+	for i, page := range shown {
+		parts[i] = fmt.Sprintf("%d", page)
+	}
+	list := strings.Join(parts, ", ")
+	if len(pages) > len(shown) {
+		list += fmt.Sprintf(" and %d more", len(pages)-len(shown))
+	}
+	return list
+}
+
+func truncatePages(pages []int) []int {
+	if len(pages) > maxReportedPages {
+		return pages[:maxReportedPages]
+	}
+	return pages
+}
+
+func roundPPI(ppi float64) float64 {
+	return math.Round(ppi*10) / 10
+}
+
+func addPrintFinding(result *StructureValidationResult, warning bool, code, message string, details map[string]interface{}) {
+	details["category"] = CategoryPrint
+	addFinding(result, warning, code, message, details)
+}
+
+func addPrintInfo(result *StructureValidationResult, message string, details map[string]interface{}) {
+	details["category"] = CategoryPrint
+	result.Info = append(result.Info, ValidationError{
+		Code:    ErrorCodePDFPrint006,
+		Message: message,
+		Details: details,
+	})
+}
@@ -0,0 +1,193 @@
+package pdf
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// contentStream formats a stream object with the given dictionary entries.
+func contentStream(entries, content string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", entries, len(content), content)
+}
+
+// printPDF builds a one-page PDF whose page (object 3) draws content with
+// resources. The content stream is object 4; extra objects are numbered
+// from 5.
+func printPDF(boxes, resources, content string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] " + boxes + " /Resources << " + resources + " >> /Contents 4 0 R >>",
+		contentStream("", content),
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+func TestStructureValidator_Print(t *testing.T) {
+	const boxes = "/TrimBox [9 9 603 783] /BleedBox [0 0 612 792]"
+	const images = "/XObject << /Im1 5 0 R >>"
+	cmykImage := contentStream("/Type /XObject /Subtype /Image /Width 600 /Height 300 /ColorSpace /DeviceCMYK /BitsPerComponent 8", "0")
+	rgbImage := contentStream("/Type /XObject /Subtype /Image /Width 600 /Height 300 /ColorSpace /DeviceRGB /BitsPerComponent 8", "0")
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErrors   []string
+		wantWarnings []string
+		wantMinPPI   float64
+	}{
+		{
+			name: "print ready",
+			// 600 × 300 pixels placed at 2 × 1 inches is 300 ppi.
+			data:       printPDF(boxes, images, "q 144 0 0 72 36 36 cm /Im1 Do Q 0 0 0 1 k", cmykImage),
+			wantMinPPI: 300,
+		},
+		{
+			name:       "low resolution image",
+			data:       printPDF(boxes, images, "q 288 0 0 144 36 36 cm /Im1 Do Q", cmykImage),
+			wantErrors: []string{ErrorCodePDFPrint001},
+			wantMinPPI: 150,
+		},
+		{
+			name:       "nested transformations",
+			data:       printPDF(boxes, images, "q 2 0 0 2 0 0 cm q 144 0 0 72 0 0 cm /Im1 Do Q Q", cmykImage),
+			wantErrors: []string{ErrorCodePDFPrint001},
+			wantMinPPI: 150,
+		},
+		{
+			name:       "rotated image",
+			data:       printPDF(boxes, images, "q 0 144 -72 0 100 100 cm /Im1 Do Q", cmykImage),
+			wantMinPPI: 300,
+		},
+		{
+			name:       "image in form XObject",
+			data:       printPDF(boxes, "/XObject << /Fm1 6 0 R >>", "q 2 0 0 2 0 0 cm /Fm1 Do Q", cmykImage, contentStream("/Type /XObject /Subtype /Form /BBox [0 0 200 200] /Resources << /XObject << /Im1 5 0 R >> >>", "144 0 0 72 0 0 cm /Im1 Do")),
+			wantErrors: []string{ErrorCodePDFPrint001},
+			wantMinPPI: 150,
+		},
+		{
+			name:       "RGB image",
+			data:       printPDF(boxes, images, "q 144 0 0 72 36 36 cm /Im1 Do Q", rgbImage),
+			wantErrors: []string{ErrorCodePDFPrint002},
+			wantMinPPI: 300,
+		},
+		{
+			name:       "RGB image and page content",
+			data:       printPDF(boxes, images, "q 144 0 0 72 36 36 cm /Im1 Do Q 1 0 0 RG", rgbImage),
+			wantErrors: []string{ErrorCodePDFPrint002, ErrorCodePDFPrint002},
+			wantMinPPI: 300,
+		},
+		{
+			name:       "RGB page content",
+			data:       printPDF(boxes, "", "1 0 0 rg 0 0 10 10 re f"),
+			wantErrors: []string{ErrorCodePDFPrint002},
+		},
+		{
+			name: "ICC based CMYK content",
+			data: printPDF(boxes, "/ColorSpace << /CS0 [/ICCBased 5 0 R] >>", "/CS0 cs 0 0 0 1 sc", contentStream("/N 4", "0")),
+		},
+		{
+			name:         "spot color",
+			data:         printPDF(boxes, "/ColorSpace << /CS0 [/Separation /PANTONE#20185#20C /DeviceCMYK 5 0 R] >>", "/CS0 cs 1 scn 0 0 10 10 re f", "<< /FunctionType 2 /Domain [0 1] /C0 [0 0 0 0] /C1 [0 1 0.8 0] /N 1 >>"),
+			wantWarnings: []string{ErrorCodePDFPrint003},
+		},
+		{
+			name:         "missing page boxes",
+			data:         printPDF("", "", "0 0 0 1 k"),
+			wantErrors:   []string{ErrorCodePDFPrint004},
+			wantWarnings: []string{ErrorCodePDFPrint005},
+		},
+	}
+
+	validator := NewStructureValidator()
+	validator.Print = PrintRules{MinImagePPI: 300, RequireCMYK: true, RequireTrimBox: true, RequireBleedBox: true}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+
+			var summary *ValidationError
+			for i := range result.Info {
+				if result.Info[i].Code == ErrorCodePDFPrint006 {
+					summary = &result.Info[i]
+				}
+			}
+			if summary == nil {
+				t.Fatalf("expected a %s summary, got %v", ErrorCodePDFPrint006, result.Info)
+			}
+			if ppi := summary.Details["min_image_ppi"]; ppi != tt.wantMinPPI {
+				t.Errorf("min_image_ppi = %v, want %v", ppi, tt.wantMinPPI)
+			}
+			for _, finding := range append(result.Errors, result.Warnings...) {
+				if finding.Details["category"] != CategoryPrint {
+					t.Errorf("%s category = %v, want %s", finding.Code, finding.Details["category"], CategoryPrint)
+				}
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Print_ImagePages(t *testing.T) {
+	image := contentStream("/Type /XObject /Subtype /Image /Width 100 /Height 100 /ColorSpace /DeviceGray /BitsPerComponent 8", "0")
+	page := "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im1 3 0 R >> >> /Contents %d 0 R >>"
+	data := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R 5 0 R 6 0 R] /Count 3 >>",
+		image,
+		fmt.Sprintf(page, 7),
+		fmt.Sprintf(page, 8),
+		fmt.Sprintf(page, 7),
+		// 100 pixels over one inch, then over a quarter inch.
+		contentStream("", "q 72 0 0 72 0 0 cm /Im1 Do Q"),
+		contentStream("", "q 18 0 0 18 0 0 cm /Im1 Do Q"),
+	)
+
+	validator := NewStructureValidator()
+	validator.Print = PrintRules{MinImagePPI: 300}
+	result, err := validator.ValidateBytes(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected one low resolution image, got %v", result.Errors)
+	}
+
+	details := result.Errors[0].Details
+	if pages, _ := details["pages"].([]int); !reflect.DeepEqual(pages, []int{1, 3}) {
+		t.Errorf("pages = %v, want [1 3]", details["pages"])
+	}
+	if ppi := details["ppi"]; ppi != 100.0 {
+		t.Errorf("ppi = %v, want 100", ppi)
+	}
+	if object := details["object"]; object != int64(3) {
+		t.Errorf("object = %v, want 3", object)
+	}
+}
+
+func TestStructureValidator_Print_Disabled(t *testing.T) {
+	result, err := NewStructureValidator().ValidateBytes(printPDF("", "", "1 0 0 rg"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, finding := range append(append(result.Errors, result.Warnings...), result.Info...) {
+		if finding.Details["category"] == CategoryPrint {
+			t.Errorf("unexpected print finding without print rules: %s", finding.Code)
+		}
+	}
+}
@@ -121,7 +121,9 @@ func (r *RepairServiceImpl) CanRepair(_ context.Context, err *domain.ValidationE
 	switch err.Code {
 	case ErrorCodePDFTrailer003,
 		ErrorCodePDFTrailer001,
-		ErrorCodePDFCatalog003:
+		ErrorCodePDFCatalog003,
+		ErrorCodePDFMeta001,
+		ErrorCodePDFMeta002:
 		return true
 	default:
 		return false
@@ -180,18 +182,83 @@ func (r *RepairServiceImpl) RepairStructure(ctx context.Context, filePath string
 	return r.Apply(ctx, filePath, preview)
 }
 
-// RepairMetadata runs metadata-focused repair steps.
-func (r *RepairServiceImpl) RepairMetadata(_ context.Context, _ string) (*ports.RepairResult, error) {
-	return &ports.RepairResult{
-		Success:        true,
-		ActionsApplied: make([]ports.RepairAction, 0),
-		BackupPath:     "",
-	}, nil
+// RepairMetadata synchronizes the Info dictionary with the XMP packet,
+// treating XMP as authoritative.
+func (r *RepairServiceImpl) RepairMetadata(ctx context.Context, filePath string) (*ports.RepairResult, error) {
+	data, err := os.ReadFile(filePath) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	validationResult, err := r.validator.ValidateBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Metadata findings are warnings, which Preview does not plan for.
+	report := &domain.ValidationReport{
+		FilePath: filePath,
+		FileType: "PDF",
+		IsValid:  validationResult.Valid,
+		Errors:   make([]domain.ValidationError, 0),
+	}
+	for _, finding := range validationResult.Warnings {
+		switch finding.Code {
+		case ErrorCodePDFMeta001, ErrorCodePDFMeta002:
+			report.Errors = append(report.Errors, domain.ValidationError{
+				Code:    finding.Code,
+				Message: finding.Message,
+				Details: finding.Details,
+			})
+		}
+	}
+	if len(report.Errors) == 0 {
+		return &ports.RepairResult{
+			Success:        true,
+			ActionsApplied: make([]ports.RepairAction, 0),
+		}, nil
+	}
+
+	preview, err := r.Preview(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Apply(ctx, filePath, preview)
+}
+
+// OptimizeFile writes an optimized copy of the PDF read from reader to
+// writer, using DefaultOptimizeOptions.
+func (r *RepairServiceImpl) OptimizeFile(ctx context.Context, reader io.Reader, writer io.Writer) error {
+	_, err := r.OptimizeFileWithOptions(ctx, reader, writer, DefaultOptimizeOptions())
+	return err
 }
 
-// OptimizeFile writes an optimized PDF stream to writer.
-func (r *RepairServiceImpl) OptimizeFile(_ context.Context, _ io.Reader, _ io.Writer) error {
-	return fmt.Errorf("PDF optimization not yet implemented")
+// OptimizeFileWithOptions rewrites the PDF read from reader to writer,
+// keeping only the objects reachable from the trailer and applying opts.
+// Nothing is written when it fails. Encrypted PDFs are rejected, and
+// rewriting invalidates digital signatures.
+func (r *RepairServiceImpl) OptimizeFileWithOptions(ctx context.Context, reader io.Reader, writer io.Writer, opts OptimizeOptions) (*OptimizeResult, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	optimized, result, err := optimize(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize PDF: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(optimized); err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return result, nil
 }
 
 func (r *RepairServiceImpl) generateRepairActions(err *domain.ValidationError) []ports.RepairAction {
@@ -236,6 +303,16 @@ func (r *RepairServiceImpl) generateRepairActions(err *domain.ValidationError) [
 			Automated:   true,
 		})
 
+	case ErrorCodePDFMeta001,
+		ErrorCodePDFMeta002:
+		actions = append(actions, ports.RepairAction{
+			Type:        "sync_metadata",
+			Description: "Synchronize /Info with the XMP metadata packet",
+			Target:      "metadata",
+			Details:     err.Details,
+			Automated:   true,
+		})
+
 	case ErrorCodePDFHeader001,
 		ErrorCodePDFHeader002:
 		actions = append(actions, ports.RepairAction{
@@ -250,7 +327,11 @@ func (r *RepairServiceImpl) generateRepairActions(err *domain.ValidationError) [
 
 	case ErrorCodePDFXref001,
 		ErrorCodePDFXref002,
-		ErrorCodePDFXref003:
+		ErrorCodePDFXref003,
+		ErrorCodePDFXref004,
+		ErrorCodePDFXref005,
+		ErrorCodePDFXref006,
+		ErrorCodePDFXref007:
 		actions = append(actions, ports.RepairAction{
 			Type:        "manual_xref_rebuild",
 			Description: "Cross-reference table rebuild requires manual intervention (unsafe)",
@@ -324,6 +405,16 @@ func (r *RepairServiceImpl) applyRepairs(_ context.Context, repairCtx *repairCon
 		repairCtx.applied = append(repairCtx.applied, action)
 	}
 
+	// One update synchronizes every metadata field.
+	if metadataActions := actionsByType["sync_metadata"]; len(metadataActions) > 0 {
+		data, _, err := syncMetadata(repairCtx.data)
+		if err != nil {
+			return fmt.Errorf("failed to synchronize metadata: %w", err)
+		}
+		repairCtx.data = data
+		repairCtx.applied = append(repairCtx.applied, metadataActions...)
+	}
+
 	return nil
 }
 
@@ -332,6 +332,8 @@ func TestCanRepair(t *testing.T) {
 		ErrorCodePDFTrailer003,
 		ErrorCodePDFTrailer001,
 		ErrorCodePDFCatalog003,
+		ErrorCodePDFMeta001,
+		ErrorCodePDFMeta002,
 	}
 
 	for _, code := range repairableCodes {
@@ -653,6 +655,73 @@ func TestApply_MultipleRepairs(t *testing.T) {
 	}
 }
 
+func TestRepairMetadata(t *testing.T) {
+	service := NewRepairService()
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	testPDF := filepath.Join(tempDir, "test.pdf")
+
+	pdfContent := metadataPDF("/Title (Untitled) /Author (H. Melville)", xmpTitle+xmpCreator+xmpCreated, "")
+	if err := os.WriteFile(testPDF, pdfContent, 0600); err != nil {
+		t.Fatalf("Failed to create test PDF: %v", err)
+	}
+
+	result, err := service.RepairMetadata(ctx, testPDF)
+	if err != nil {
+		t.Fatalf("RepairMetadata failed: %v", err)
+	}
+
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %v", result.Error)
+	}
+
+	if len(result.ActionsApplied) != 3 {
+		t.Errorf("Expected 3 actions applied, got %d", len(result.ActionsApplied))
+	}
+
+	repairedData, err := os.ReadFile(result.BackupPath)
+	if err != nil {
+		t.Fatalf("Failed to read repaired file: %v", err)
+	}
+
+	validationResult, err := NewStructureValidator().ValidateBytes(repairedData)
+	if err != nil {
+		t.Fatalf("Validation of repaired file failed: %v", err)
+	}
+	for _, warning := range validationResult.Warnings {
+		if strings.HasPrefix(warning.Code, "PDF-META-") {
+			t.Errorf("Unexpected warning after repair: %s - %s", warning.Code, warning.Message)
+		}
+	}
+}
+
+func TestRepairMetadata_Consistent(t *testing.T) {
+	service := NewRepairService()
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	testPDF := filepath.Join(tempDir, "test.pdf")
+
+	pdfContent := metadataPDF("/Title (Moby Dick) /Author (Herman Melville)", xmpTitle+xmpCreator, "")
+	if err := os.WriteFile(testPDF, pdfContent, 0600); err != nil {
+		t.Fatalf("Failed to create test PDF: %v", err)
+	}
+
+	result, err := service.RepairMetadata(ctx, testPDF)
+	if err != nil {
+		t.Fatalf("RepairMetadata failed: %v", err)
+	}
+
+	if !result.Success {
+		t.Errorf("Expected success, got error: %v", result.Error)
+	}
+
+	if len(result.ActionsApplied) != 0 {
+		t.Errorf("Expected 0 actions applied, got %d", len(result.ActionsApplied))
+	}
+}
+
 func TestCreateBackup(t *testing.T) {
 	service := NewRepairService()
 	ctx := context.Background()
@@ -0,0 +1,287 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Stream decoding error codes, reported in deep mode.
+const (
+	ErrorCodePDFStream001 = "PDF-STREAM-001"
+	ErrorCodePDFStream002 = "PDF-STREAM-002"
+	ErrorCodePDFStream003 = "PDF-STREAM-003"
+	ErrorCodePDFStream004 = "PDF-STREAM-004"
+)
+
+// streamFilters are the standard stream filters. The value reports whether
+// unipdf can decode the filter; JPX images and crypt filters are only
+// checked for /Length.
+var streamFilters = map[string]bool{
+	"FlateDecode":     true,
+	"LZWDecode":       true,
+	"ASCII85Decode":   true,
+	"ASCIIHexDecode":  true,
+	"RunLengthDecode": true,
+	"DCTDecode":       true,
+	"CCITTFaxDecode":  true,
+	"JBIG2Decode":     true,
+	"JPXDecode":       false,
+	"Crypt":           false,
+}
+
+// deprecatedFilters are filters that decode but that archival and print
+// standards reject, with the reason reported.
+var deprecatedFilters = map[string]string{
+	"LZWDecode": "LZWDecode is a legacy filter prohibited by PDF/A-1; use FlateDecode",
+}
+
+// inlineImageFilters are the abbreviated filter names that are only valid
+// in inline images.
+var inlineImageFilters = map[string]string{
+	"AHx": "ASCIIHexDecode",
+	"A85": "ASCII85Decode",
+	"LZW": "LZWDecode",
+	"Fl":  "FlateDecode",
+	"RL":  "RunLengthDecode",
+	"CCF": "CCITTFaxDecode",
+	"DCT": "DCTDecode",
+}
+
+// validateStreams decodes every stream object through its filter chain and
+// checks its /Length against the bytes between the stream and endstream
+// keywords.
+func (v *StructureValidator) validateStreams(data []byte, parser *core.PdfParser, result *StructureValidationResult) {
+	xrefTable := parser.GetXrefTable()
+	numbers := make([]int, 0, len(xrefTable.ObjectMap))
+	for objectNumber, entry := range xrefTable.ObjectMap {
+		// Streams cannot be stored in object streams.
+		if entry.XType == core.XrefTypeTableEntry {
+			numbers = append(numbers, objectNumber)
+		}
+	}
+	sort.Ints(numbers)
+
+	// Streams cannot be decoded without the decryption key.
+	decodable := true
+	if encrypted, err := parser.IsEncrypted(); err != nil || (encrypted && !parser.IsAuthenticated()) {
+		decodable = false
+	}
+
+	for _, objectNumber := range numbers {
+		entry := xrefTable.ObjectMap[objectNumber]
+		obj, err := parser.LookupByNumber(objectNumber)
+		if err != nil {
+			continue
+		}
+		stream, ok := core.GetStream(core.TraceToDirectObject(obj))
+		if !ok {
+			continue
+		}
+
+		location := &ObjectLocation{
+			Object:     int64(objectNumber),
+			Generation: int64(entry.Generation),
+			Offset:     entry.Offset,
+		}
+		v.checkStreamLength(data, stream, location, result)
+
+		filters, ok := v.checkStreamFilters(stream, location, result)
+		if !ok || !decodable || stream.Get("F") != nil {
+			continue
+		}
+		_, err = core.DecodeStream(stream)
+		if err == nil && len(filters) > 0 && filters[0] == "FlateDecode" {
+			err = checkFlateComplete(stream.Stream)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFStream002,
+				Message: fmt.Sprintf("Stream fails to decode with %s", strings.Join(filters, ", ")),
+				Details: map[string]interface{}{
+					"filters": filters,
+					"error":   err.Error(),
+				},
+				Location: location,
+			})
+		}
+	}
+}
+
+// checkFlateComplete reports Flate data that ends before the end of the
+// compressed stream. unipdf returns the data decoded so far in that case,
+// which hides truncated content streams. Checksum mismatches are ignored,
+// as readers ignore them too.
+func checkFlateComplete(data []byte) error {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil && !errors.Is(err, zlib.ErrChecksum) {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return errors.New("flate data is truncated")
+		}
+		return err
+	}
+	return nil
+}
+
+// checkStreamFilters reports unknown and deprecated filters. It returns the
+// filter chain and whether the stream can be decoded.
+func (v *StructureValidator) checkStreamFilters(stream *core.PdfObjectStream, location *ObjectLocation, result *StructureValidationResult) ([]string, bool) {
+	var filterObjects []core.PdfObject
+	switch filter := core.TraceToDirectObject(stream.Get("Filter")).(type) {
+	case nil:
+		return nil, true
+	case *core.PdfObjectArray:
+		filterObjects = filter.Elements()
+	default:
+		if core.IsNullObject(filter) {
+			return nil, true
+		}
+		filterObjects = []core.PdfObject{filter}
+	}
+
+	decodable := true
+	filters := make([]string, 0, len(filterObjects))
+	for _, obj := range filterObjects {
+		name, ok := core.GetNameVal(core.TraceToDirectObject(obj))
+		if !ok {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFStream003,
+				Message: "Stream /Filter entry is not a name",
+				Details: map[string]interface{}{
+					"found": obj.String(),
+				},
+				Location: location,
+			})
+			return filters, false
+		}
+		filters = append(filters, name)
+
+		supported, known := streamFilters[name]
+		switch {
+		case !known:
+			message := fmt.Sprintf("Stream uses unsupported filter %s", name)
+			if full, ok := inlineImageFilters[name]; ok {
+				message = fmt.Sprintf("Stream uses inline image abbreviation %s; streams must use %s", name, full)
+			}
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFStream003,
+				Message: message,
+				Details: map[string]interface{}{
+					"filter": name,
+				},
+				Location: location,
+			})
+			decodable = false
+		case !supported:
+			decodable = false
+		}
+		if reason, ok := deprecatedFilters[name]; ok {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFStream004,
+				Message: reason,
+				Details: map[string]interface{}{
+					"filter": name,
+				},
+				Location: location,
+			})
+		}
+	}
+	return filters, decodable
+}
+
+// checkStreamLength compares the declared /Length with the data between the
+// stream and endstream keywords. Streams whose xref offset is wrong are
+// skipped, since PDF-XREF-005 already reports them.
+func (v *StructureValidator) checkStreamLength(data []byte, stream *core.PdfObjectStream, location *ObjectLocation, result *StructureValidationResult) {
+	offset := location.Offset
+	if offset <= 0 || offset >= int64(len(data)) {
+		return
+	}
+	header := objectHeaderPattern.FindSubmatch(data[offset:])
+	if header == nil || string(header[1]) != fmt.Sprint(location.Object) {
+		return
+	}
+
+	body := data[offset:]
+	if end := bytes.Index(body, []byte("endobj")); end >= 0 {
+		body = body[:end]
+	}
+	keyword := bytes.Index(body, []byte("stream"))
+	if keyword < 0 {
+		return
+	}
+	start := offset + int64(keyword) + int64(len("stream"))
+	if bytes.HasPrefix(data[start:], []byte("\r\n")) {
+		start += 2
+	} else if start < int64(len(data)) && (data[start] == '\n' || data[start] == '\r') {
+		start++
+	}
+
+	declared, ok := core.GetIntVal(core.TraceToDirectObject(stream.Get("Length")))
+	if !ok {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:     ErrorCodePDFStream001,
+			Message:  "Stream is missing an integer /Length",
+			Details:  map[string]interface{}{},
+			Location: location,
+		})
+		return
+	}
+
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Code:    ErrorCodePDFStream001,
+			Message: "Stream has no endstream keyword; the file may be truncated",
+			Details: map[string]interface{}{
+				"declared": declared,
+			},
+			Location: location,
+		})
+		return
+	}
+
+	// The data must be followed by an optional end-of-line marker and
+	// endstream. Checking the bytes after the declared length accepts data
+	// that itself ends in \r or \n.
+	if declared >= 0 && declared <= end {
+		tail := data[start+int64(declared):]
+		if bytes.HasPrefix(tail, []byte("\r\n")) {
+			tail = tail[2:]
+		} else if len(tail) > 0 && (tail[0] == '\n' || tail[0] == '\r') {
+			tail = tail[1:]
+		}
+		if bytes.HasPrefix(tail, []byte("endstream")) {
+			return
+		}
+	}
+
+	// Report the length up to the end-of-line marker before endstream.
+	actual := end
+	raw := data[start : start+int64(end)]
+	switch {
+	case bytes.HasSuffix(raw, []byte("\r\n")):
+		actual -= 2
+	case bytes.HasSuffix(raw, []byte("\n")), bytes.HasSuffix(raw, []byte("\r")):
+		actual--
+	}
+
+	result.Errors = append(result.Errors, ValidationError{
+		Code:    ErrorCodePDFStream001,
+		Message: fmt.Sprintf("Stream /Length is %d but the stream holds %d bytes", declared, actual),
+		Details: map[string]interface{}{
+			"declared": declared,
+			"actual":   actual,
+		},
+		Location: location,
+	})
+}
@@ -0,0 +1,135 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// streamPDF builds a one-page PDF whose content stream, object 4, has the
+// given dictionary entries, declared length and data.
+func streamPDF(entries string, length int, data []byte) []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d %s >>\nstream\n%s\nendstream", length, entries, data),
+	)
+}
+
+func TestStructureValidator_DeepStreams(t *testing.T) {
+	content := []byte("BT /F1 12 Tf 100 700 Td (Hello World) Tj ET")
+
+	var flate bytes.Buffer
+	writer := zlib.NewWriter(&flate)
+	_, _ = writer.Write(content)
+	_ = writer.Close()
+
+	encoder := core.NewLZWEncoder()
+	encoder.EarlyChange = 0
+	lzw, err := encoder.EncodeBytes(content)
+	if err != nil {
+		t.Fatalf("failed to encode LZW data: %v", err)
+	}
+
+	binary := []byte{0x00, 0x9c, 0xff, 0x0a, 0x42, '\r'}
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name: "uncompressed",
+			data: streamPDF("", len(content), content),
+		},
+		{
+			name: "binary data ending in carriage return",
+			data: streamPDF("", len(binary), binary),
+		},
+		{
+			name: "flate",
+			data: streamPDF("/Filter /FlateDecode", flate.Len(), flate.Bytes()),
+		},
+		{
+			name: "filter chain",
+			data: streamPDF("/Filter [/ASCIIHexDecode /FlateDecode]", 2*flate.Len()+1, []byte(fmt.Sprintf("%x>", flate.Bytes()))),
+		},
+		{
+			name:       "wrong length",
+			data:       streamPDF("", len(content)+20, content),
+			wantErrors: []string{ErrorCodePDFStream001},
+		},
+		{
+			name:       "length short of binary data",
+			data:       streamPDF("", len(binary)-2, binary),
+			wantErrors: []string{ErrorCodePDFStream001},
+		},
+		{
+			name:       "corrupt flate data",
+			data:       streamPDF("/Filter /FlateDecode", len(content), content),
+			wantErrors: []string{ErrorCodePDFStream002},
+		},
+		{
+			name:       "truncated flate data",
+			data:       streamPDF("/Filter /FlateDecode", flate.Len()/2, flate.Bytes()[:flate.Len()/2]),
+			wantErrors: []string{ErrorCodePDFStream002},
+		},
+		{
+			name:       "unsupported filter",
+			data:       streamPDF("/Filter /BrotliDecode", len(content), content),
+			wantErrors: []string{ErrorCodePDFStream003},
+		},
+		{
+			name:       "inline image abbreviation",
+			data:       streamPDF("/Filter /Fl", flate.Len(), flate.Bytes()),
+			wantErrors: []string{ErrorCodePDFStream003},
+		},
+		{
+			name:         "deprecated filter",
+			data:         streamPDF("/Filter /LZWDecode /DecodeParms << /EarlyChange 0 >>", len(lzw), lzw),
+			wantWarnings: []string{ErrorCodePDFStream004},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewStructureValidator()
+			validator.Deep = true
+
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+			for _, finding := range append(result.Errors, result.Warnings...) {
+				if finding.Location == nil || finding.Location.Object != 4 || finding.Location.Offset == 0 {
+					t.Errorf("Expected a location for object 4, got %+v", finding.Location)
+				}
+			}
+		})
+	}
+}
+
+func TestStructureValidator_DeepStreams_Disabled(t *testing.T) {
+	result, err := NewStructureValidator().ValidateBytes(streamPDF("/Filter /BrotliDecode", 3, []byte("abc")))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected streams to be skipped without deep mode, got %v", result.Errors)
+	}
+}
@@ -29,19 +29,58 @@ const (
 
 // ValidationError captures PDF structure validation issues.
 type ValidationError struct {
-	Code    string
-	Message string
-	Details map[string]interface{}
+	Code     string
+	Message  string
+	Details  map[string]interface{}
+	Location *ObjectLocation
+}
+
+// ObjectLocation identifies the indirect object a finding applies to.
+type ObjectLocation struct {
+	Object     int64
+	Generation int64
+	// Offset is the byte offset of the object in the file.
+	Offset int64
 }
 
 // StructureValidationResult aggregates structure validation findings.
 type StructureValidationResult struct {
-	Valid  bool
-	Errors []ValidationError
+	Valid    bool
+	Errors   []ValidationError
+	Warnings []ValidationError
+	Info     []ValidationError
+	// PageCount is the number of leaf pages found in the page tree.
+	PageCount int
+	// Revisions lists the cross-reference sections in the /Prev chain,
+	// newest first. A file with incremental updates has more than one.
+	Revisions []XrefRevision
+	// Encryption describes the /Encrypt dictionary, or is nil when the
+	// document is not encrypted.
+	Encryption *EncryptionInfo
+	// Version is the declared PDF version: the header version, or the
+	// catalog /Version when that is later.
+	Version string
+	// RequiredVersion is the earliest PDF version that supports every
+	// feature the document uses.
+	RequiredVersion string
+	// Linearization describes the linearization dictionary, or is nil when
+	// the document is not linearized.
+	Linearization *LinearizationInfo
 }
 
 // StructureValidator validates basic PDF structure.
-type StructureValidator struct{}
+type StructureValidator struct {
+	// Fonts configures the font checks.
+	Fonts FontRules
+	// Print configures the print readiness analysis. It is off by default.
+	Print PrintRules
+	// Deep decodes every stream through its filter chain. It is off by
+	// default because it decompresses the whole file.
+	Deep bool
+	// Password opens encrypted documents that the empty user password does
+	// not. Either the user or the owner password works.
+	Password string
+}
 
 // NewStructureValidator returns a new PDF structure validator.
 func NewStructureValidator() *StructureValidator {
@@ -69,8 +108,10 @@ func (v *StructureValidator) ValidateReader(reader io.Reader) (*StructureValidat
 // ValidateBytes validates a PDF from in-memory data.
 func (v *StructureValidator) ValidateBytes(data []byte) (*StructureValidationResult, error) {
 	result := &StructureValidationResult{
-		Valid:  true,
-		Errors: make([]ValidationError, 0),
+		Valid:    true,
+		Errors:   make([]ValidationError, 0),
+		Warnings: make([]ValidationError, 0),
+		Info:     make([]ValidationError, 0),
 	}
 
 	v.validateHeader(data, result)
@@ -200,8 +241,17 @@ func (v *StructureValidator) validateWithUnipdf(data []byte, result *StructureVa
 	}
 
 	v.validateCrossReference(parser, result)
+	v.validateLinearization(data, parser, result)
+	v.validateRevisions(data, result)
+	v.validateEncryption(parser, result)
 	v.validateCatalog(parser, result)
+	v.validateActiveContent(parser, result)
+	v.validateMetadata(parser, result)
+	v.validateVersion(data, parser, result)
 	v.validateObjectNumbering(parser, result)
+	if v.Deep {
+		v.validateStreams(data, parser, result)
+	}
 }
 
 func (v *StructureValidator) validateCrossReference(parser *core.PdfParser, result *StructureValidationResult) {
@@ -320,14 +370,20 @@ func (v *StructureValidator) validateCatalog(parser *core.PdfParser, result *Str
 		return
 	}
 
-	pagesObj = core.TraceToDirectObject(pagesObj)
-	if _, ok := core.GetDict(pagesObj); !ok {
+	if _, ok := core.GetDict(core.TraceToDirectObject(pagesObj)); !ok {
 		result.Errors = append(result.Errors, ValidationError{
 			Code:    ErrorCodePDFCatalog003,
 			Message: "Catalog /Pages entry is invalid",
 			Details: map[string]interface{}{},
 		})
+		return
 	}
+
+	pages := v.validatePageTree(pagesObj, result)
+	result.PageCount = len(pages)
+	v.validateFonts(parser, pages, result)
+	v.validateNavigation(dict, pages, result)
+	v.validatePrint(pages, result)
 }
 
 func (v *StructureValidator) validateObjectNumbering(parser *core.PdfParser, result *StructureValidationResult) {
@@ -0,0 +1,256 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Version compliance error codes.
+const (
+	ErrorCodePDFVersion001 = "PDF-VERSION-001"
+	ErrorCodePDFVersion002 = "PDF-VERSION-002"
+)
+
+var (
+	headerVersionPattern  = regexp.MustCompile(`^%PDF-(\d)\.(\d)`)
+	catalogVersionPattern = regexp.MustCompile(`^(\d)\.(\d)$`)
+)
+
+// pdfVersion is a PDF version as major*10+minor, so 1.4 is 14.
+type pdfVersion int
+
+func (v pdfVersion) String() string {
+	return fmt.Sprintf("%d.%d", v/10, v%10)
+}
+
+// versionFeature is a feature that needs a minimum PDF version.
+type versionFeature struct {
+	name    string
+	version pdfVersion
+}
+
+// Features checked against the declared version.
+var (
+	featureRC4128        = versionFeature{"128-bit RC4 encryption", 14}
+	featureTransparency  = versionFeature{"transparency groups", 14}
+	featureSoftMasks     = versionFeature{"soft masks", 14}
+	featureJBIG2         = versionFeature{"JBIG2 images", 14}
+	featureXrefStreams   = versionFeature{"cross-reference streams", 15}
+	featureObjectStreams = versionFeature{"object streams", 15}
+	featureJPX           = versionFeature{"JPEG 2000 images", 15}
+	featureOptional      = versionFeature{"optional content", 15}
+	featureCryptFilters  = versionFeature{"crypt filters", 15}
+	featureAES128        = versionFeature{"AES-128 encryption", 16}
+	featureOpenTypeFonts = versionFeature{"OpenType font programs", 16}
+	featureAES256        = versionFeature{"AES-256 encryption", 17}
+)
+
+// versionScan collects the features used by a document.
+type versionScan struct {
+	features map[versionFeature]bool
+	visited  map[core.PdfObject]bool
+}
+
+// validateVersion reports features that need a later PDF version than the
+// document declares. The declared version is the header version, or the
+// catalog /Version when that is later.
+func (v *StructureValidator) validateVersion(data []byte, parser *core.PdfParser, result *StructureValidationResult) {
+	match := headerVersionPattern.FindSubmatch(data)
+	if match == nil {
+		return
+	}
+	header := pdfVersion(int(match[1][0]-'0')*10 + int(match[2][0]-'0'))
+	declared := header
+
+	scan := &versionScan{
+		features: make(map[versionFeature]bool),
+		visited:  make(map[core.PdfObject]bool),
+	}
+
+	details := map[string]interface{}{
+		"header_version": header.String(),
+	}
+	if trailer := parser.GetTrailer(); trailer != nil {
+		if catalog, ok := core.GetDict(core.TraceToDirectObject(trailer.Get("Root"))); ok {
+			if obj := catalog.Get("Version"); obj != nil {
+				version, ok := parseCatalogVersion(obj)
+				switch {
+				case !ok:
+					result.Warnings = append(result.Warnings, ValidationError{
+						Code:    ErrorCodePDFVersion002,
+						Message: "Catalog /Version is not a valid PDF version",
+						Details: map[string]interface{}{
+							"found":    obj.String(),
+							"expected": "a name such as /1.7",
+						},
+					})
+				default:
+					details["catalog_version"] = version.String()
+					if version > declared {
+						declared = version
+					}
+				}
+			}
+			if catalog.Get("OCProperties") != nil {
+				scan.features[featureOptional] = true
+			}
+		}
+	}
+
+	for _, revision := range result.Revisions {
+		if revision.Type == XrefTypeStream {
+			scan.features[featureXrefStreams] = true
+		}
+		// Readers older than 1.5 skip the objects a hybrid file compresses.
+		if revision.Compressed > 0 && revision.Type != XrefTypeHybrid {
+			scan.features[featureObjectStreams] = true
+		}
+	}
+
+	if info := result.Encryption; info != nil {
+		switch info.Algorithm {
+		case EncryptionAES256:
+			scan.features[featureAES256] = true
+		case EncryptionAES128:
+			scan.features[featureAES128] = true
+		case EncryptionRC4128:
+			scan.features[featureRC4128] = true
+		}
+		if info.Version == 4 {
+			scan.features[featureCryptFilters] = true
+		}
+	}
+
+	for _, number := range parser.GetObjectNums() {
+		obj, err := parser.LookupByNumber(number)
+		if err != nil {
+			continue
+		}
+		scan.walk(obj)
+	}
+
+	required := pdfVersion(10)
+	var features []versionFeature
+	for feature := range scan.features {
+		if feature.version > required {
+			required = feature.version
+		}
+		if feature.version > declared {
+			features = append(features, feature)
+		}
+	}
+	result.Version = declared.String()
+	result.RequiredVersion = required.String()
+	if len(features) == 0 {
+		return
+	}
+
+	sort.Slice(features, func(i, j int) bool {
+		if features[i].version != features[j].version {
+			return features[i].version > features[j].version
+		}
+		return features[i].name < features[j].name
+	})
+	names := make([]string, len(features))
+	featureVersions := make(map[string]string, len(features))
+	for i, feature := range features {
+		names[i] = feature.name
+		featureVersions[feature.name] = feature.version.String()
+	}
+	details["declared_version"] = declared.String()
+	details["required_version"] = features[0].version.String()
+	details["features"] = names
+	details["feature_versions"] = featureVersions
+	result.Warnings = append(result.Warnings, ValidationError{
+		Code:    ErrorCodePDFVersion001,
+		Message: fmt.Sprintf("Document declares PDF %s but uses features of PDF %s: %s", declared, features[0].version, strings.Join(names, ", ")),
+		Details: details,
+	})
+}
+
+// walk records the features of an indirect object and the direct objects
+// inside it. Referenced objects are walked through the object list.
+func (s *versionScan) walk(obj core.PdfObject) {
+	if _, ok := obj.(*core.PdfObjectReference); ok {
+		return
+	}
+	if indirect, ok := obj.(*core.PdfIndirectObject); ok {
+		obj = indirect.PdfObject
+	}
+	if obj == nil || s.visited[obj] {
+		return
+	}
+	s.visited[obj] = true
+
+	switch value := obj.(type) {
+	case *core.PdfObjectArray:
+		for _, element := range value.Elements() {
+			s.walk(element)
+		}
+	case *core.PdfObjectStream:
+		s.checkFilters(value.Get("Filter"))
+		s.walkDict(value.PdfObjectDictionary)
+	case *core.PdfObjectDictionary:
+		s.walkDict(value)
+	}
+}
+
+// walkDict records the features of a dictionary.
+func (s *versionScan) walkDict(dict *core.PdfObjectDictionary) {
+	if group, _ := core.GetNameVal(core.TraceToDirectObject(dict.Get("S"))); group == "Transparency" {
+		s.features[featureTransparency] = true
+	}
+	if mask := dict.Get("SMask"); mask != nil {
+		if name, isName := core.GetNameVal(core.TraceToDirectObject(mask)); !isName || name != "None" {
+			s.features[featureSoftMasks] = true
+		}
+	}
+	if dict.Get("FontFile3") != nil {
+		if font, ok := core.GetStream(core.TraceToDirectObject(dict.Get("FontFile3"))); ok {
+			if subtype, _ := core.GetNameVal(core.TraceToDirectObject(font.Get("Subtype"))); subtype == "OpenType" {
+				s.features[featureOpenTypeFonts] = true
+			}
+		}
+	}
+	for _, key := range dict.Keys() {
+		s.walk(dict.Get(key))
+	}
+}
+
+// checkFilters records the image filters of a stream's /Filter entry.
+func (s *versionScan) checkFilters(obj core.PdfObject) {
+	var filters []core.PdfObject
+	if arr, ok := core.GetArray(core.TraceToDirectObject(obj)); ok {
+		filters = arr.Elements()
+	} else if obj != nil {
+		filters = []core.PdfObject{obj}
+	}
+	for _, filter := range filters {
+		switch name, _ := core.GetNameVal(core.TraceToDirectObject(filter)); name {
+		case "JBIG2Decode":
+			s.features[featureJBIG2] = true
+		case "JPXDecode":
+			s.features[featureJPX] = true
+		}
+	}
+}
+
+// parseCatalogVersion parses the catalog /Version name.
+func parseCatalogVersion(obj core.PdfObject) (pdfVersion, bool) {
+	name, ok := core.GetNameVal(core.TraceToDirectObject(obj))
+	if !ok {
+		return 0, false
+	}
+	match := catalogVersionPattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return pdfVersion(major*10 + minor), true
+}
@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"testing"
+)
+
+// withHeader replaces the %PDF-1.x header of a test file. The header
+// length is unchanged, so offsets stay valid.
+func withHeader(data []byte, version string) []byte {
+	out := append([]byte(nil), data...)
+	copy(out, "%PDF-"+version)
+	return out
+}
+
+func TestStructureValidator_Version(t *testing.T) {
+	const catalog = "<< /Type /Catalog /Pages 2 0 R >>"
+	const pages = "<< /Type /Pages /Kids [3 0 R] /Count 1 >>"
+	const transparentPage = "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Group << /S /Transparency /CS /DeviceRGB >> >>"
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantWarnings []string
+		wantVersion  string
+		wantRequired string
+		wantFeatures []string
+	}{
+		{
+			name:         "no versioned features",
+			data:         buildPDF(revisionObjects...),
+			wantVersion:  "1.4",
+			wantRequired: "1.0",
+		},
+		{
+			name:         "transparency in PDF 1.3",
+			data:         withHeader(buildPDF(catalog, pages, transparentPage), "1.3"),
+			wantWarnings: []string{ErrorCodePDFVersion001},
+			wantVersion:  "1.3",
+			wantRequired: "1.4",
+			wantFeatures: []string{"transparency groups"},
+		},
+		{
+			name:         "catalog version override",
+			data:         withHeader(buildPDF("<< /Type /Catalog /Pages 2 0 R /Version /1.4 >>", pages, transparentPage), "1.3"),
+			wantVersion:  "1.4",
+			wantRequired: "1.4",
+		},
+		{
+			name:         "older catalog version is ignored",
+			data:         buildPDF("<< /Type /Catalog /Pages 2 0 R /Version /1.3 /OCProperties << /OCGs [] /D << >> >> >>", pages, revisionObjects[2]),
+			wantWarnings: []string{ErrorCodePDFVersion001},
+			wantVersion:  "1.4",
+			wantRequired: "1.5",
+			wantFeatures: []string{"optional content"},
+		},
+		{
+			name:         "invalid catalog version",
+			data:         buildPDF("<< /Type /Catalog /Pages 2 0 R /Version (1.7) >>", pages, revisionObjects[2]),
+			wantWarnings: []string{ErrorCodePDFVersion002},
+			wantVersion:  "1.4",
+			wantRequired: "1.0",
+		},
+		{
+			name:         "JPEG 2000 image",
+			data:         buildPDF(catalog, pages, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /XObject << /Im1 4 0 R >> >> >>", "<< /Type /XObject /Subtype /Image /Width 1 /Height 1 /Filter /JPXDecode /Length 1 >>\nstream\n0\nendstream"),
+			wantWarnings: []string{ErrorCodePDFVersion001},
+			wantVersion:  "1.4",
+			wantRequired: "1.5",
+			wantFeatures: []string{"JPEG 2000 images"},
+		},
+		{
+			name:         "object and cross-reference streams in PDF 1.4",
+			data:         withHeader(buildXrefStreamPDF(0), "1.4"),
+			wantWarnings: []string{ErrorCodePDFVersion001},
+			wantVersion:  "1.4",
+			wantRequired: "1.5",
+			wantFeatures: []string{"cross-reference streams", "object streams"},
+		},
+		{
+			name:         "object and cross-reference streams in PDF 1.5",
+			data:         buildXrefStreamPDF(0),
+			wantVersion:  "1.5",
+			wantRequired: "1.5",
+		},
+		{
+			name:         "AES in PDF 1.4",
+			data:         withHeader(encryptedPDF(t, 4, "AESV2", ""), "1.4"),
+			wantWarnings: []string{ErrorCodePDFVersion001},
+			wantVersion:  "1.4",
+			wantRequired: "1.6",
+			wantFeatures: []string{"AES-128 encryption", "crypt filters"},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+				for _, e := range result.Warnings {
+					t.Logf("Warning: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if result.Version != tt.wantVersion {
+				t.Errorf("version = %s, want %s", result.Version, tt.wantVersion)
+			}
+			if result.RequiredVersion != tt.wantRequired {
+				t.Errorf("required version = %s, want %s", result.RequiredVersion, tt.wantRequired)
+			}
+
+			for _, warning := range result.Warnings {
+				if warning.Code != ErrorCodePDFVersion001 {
+					continue
+				}
+				features, _ := warning.Details["features"].([]string)
+				if !equalCodes(features, tt.wantFeatures) {
+					t.Errorf("features = %v, want %v", features, tt.wantFeatures)
+				}
+				if required := warning.Details["required_version"]; required != tt.wantRequired {
+					t.Errorf("required_version = %v, want %s", required, tt.wantRequired)
+				}
+			}
+		})
+	}
+}
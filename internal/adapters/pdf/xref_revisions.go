@@ -0,0 +1,681 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Cross-reference revision validation error codes.
+const (
+	ErrorCodePDFXref004 = "PDF-XREF-004"
+	ErrorCodePDFXref005 = "PDF-XREF-005"
+	ErrorCodePDFXref006 = "PDF-XREF-006"
+	ErrorCodePDFXref007 = "PDF-XREF-007"
+	ErrorCodePDFXref008 = "PDF-XREF-008"
+	ErrorCodePDFXref009 = "PDF-XREF-009"
+)
+
+// Cross-reference section types reported in XrefRevision.Type.
+const (
+	XrefTypeTable  = "table"
+	XrefTypeStream = "stream"
+	// XrefTypeHybrid is a table whose trailer names a supplementary xref
+	// stream with /XRefStm.
+	XrefTypeHybrid = "hybrid"
+)
+
+// maxReportedOffsets caps the objects listed in a single PDF-XREF-005
+// finding.
+const maxReportedOffsets = 10
+
+var (
+	startxrefValuePattern = regexp.MustCompile(`startxref\s+(\d+)`)
+	xrefKeywordPattern    = regexp.MustCompile(`\bxref\s+\d+\s+\d+`)
+	objectHeaderPattern   = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+obj\b`)
+	subsectionPattern     = regexp.MustCompile(`^(\d+)\s+(\d+)[ \t]*\r?\n?`)
+	tableEntryPattern     = regexp.MustCompile(`^(\d{10})[ \t](\d{5})[ \t]([nf])`)
+)
+
+// XrefRevision describes one cross-reference section in the /Prev chain.
+type XrefRevision struct {
+	// Offset is the byte offset of the section or stream.
+	Offset int64
+	// Type is XrefTypeTable, XrefTypeStream or XrefTypeHybrid.
+	Type string
+	// InUse, Free and Compressed count the entries of each kind; Compressed
+	// entries are objects stored in object streams.
+	InUse      int
+	Free       int
+	Compressed int
+}
+
+// xrefEntry is a single cross-reference entry. For in-use entries field1 is
+// the byte offset and field2 the generation; for free entries they are the
+// next free object number and generation; for compressed entries they are
+// the object stream number and the index within it.
+type xrefEntry struct {
+	kind   byte
+	field1 int64
+	field2 int64
+}
+
+const (
+	xrefEntryFree       = 'f'
+	xrefEntryInUse      = 'n'
+	xrefEntryCompressed = 'c'
+)
+
+// xrefSection is a parsed cross-reference section with its trailer.
+type xrefSection struct {
+	revision XrefRevision
+	entries  map[int]xrefEntry
+	trailer  *core.PdfObjectDictionary
+	// headGeneration is the generation object 0 carries as the head of the
+	// free list: 65535 in tables, or the largest value the generation
+	// field of a stream can hold, which is 255 for /W [1 3 1].
+	headGeneration int64
+	// malformed describes entries that could not be parsed.
+	malformed string
+}
+
+// validateRevisions follows the startxref and /Prev chain through every
+// cross-reference section in the file, independently of the merged table
+// built by unipdf, which hides the individual revisions.
+func (v *StructureValidator) validateRevisions(data []byte, result *StructureValidationResult) {
+	matches := startxrefValuePattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return
+	}
+	offset, err := strconv.ParseInt(string(matches[len(matches)-1][1]), 10, 64)
+	if err != nil {
+		return
+	}
+
+	parser := core.NewParserFromString(string(data))
+	visited := make(map[int64]bool)
+	var sections []*xrefSection
+
+	for {
+		var section *xrefSection
+		if offset < 0 || offset >= int64(len(data)) || visited[offset] {
+			err = errors.New("offset is outside the file or already visited")
+		} else {
+			visited[offset] = true
+			section, err = readXrefSection(parser, data, offset)
+		}
+		if err != nil {
+			if len(sections) > 0 {
+				v.reportBrokenPrev(len(sections), offset, err, result)
+				break
+			}
+			// Readers locate the last section by scanning when startxref
+			// is wrong, so continue from there.
+			fallback, ok := lastXrefKeyword(data)
+			v.reportBrokenStartxref(offset, fallback, err, result)
+			if !ok || visited[fallback] {
+				break
+			}
+			offset = fallback
+			continue
+		}
+
+		if section.trailer != nil && section.revision.Type == XrefTypeTable {
+			if stmOffset, ok := core.GetIntVal(section.trailer.Get("XRefStm")); ok {
+				if err := mergeHybridStream(parser, data, int64(stmOffset), section); err != nil {
+					result.Errors = append(result.Errors, ValidationError{
+						Code:    ErrorCodePDFXref006,
+						Message: "Trailer /XRefStm does not point to a valid cross-reference stream",
+						Details: map[string]interface{}{
+							"revision": len(sections) + 1,
+							"offset":   stmOffset,
+							"error":    err.Error(),
+						},
+					})
+				}
+			}
+		}
+		if section.malformed != "" {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFXref006,
+				Message: "Malformed cross-reference section",
+				Details: map[string]interface{}{
+					"revision": len(sections) + 1,
+					"offset":   offset,
+					"error":    section.malformed,
+				},
+			})
+		}
+		sections = append(sections, section)
+
+		if section.trailer == nil {
+			break
+		}
+		prev := section.trailer.Get("Prev")
+		if prev == nil {
+			break
+		}
+		prevOffset, ok := core.GetIntVal(prev)
+		if !ok {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFXref004,
+				Message: "Trailer /Prev is not an integer offset",
+				Details: map[string]interface{}{
+					"revision": len(sections),
+					"found":    prev.String(),
+				},
+			})
+			break
+		}
+		offset = int64(prevOffset)
+	}
+
+	if len(sections) == 0 {
+		return
+	}
+
+	for i, section := range sections {
+		result.Revisions = append(result.Revisions, section.revision)
+		v.checkEntryOffsets(data, i+1, section, result)
+		v.checkFreeList(i+1, section, result)
+	}
+	v.checkObjectStreams(parser, data, sections, result)
+
+	updates := result.RevisionCount() - 1
+	if updates > 0 {
+		types := make([]string, 0, len(sections))
+		for _, section := range sections {
+			types = append(types, section.revision.Type)
+		}
+		result.Info = append(result.Info, ValidationError{
+			Code:    ErrorCodePDFXref009,
+			Message: fmt.Sprintf("Document has %d revisions (%d incremental update(s))", updates+1, updates),
+			Details: map[string]interface{}{
+				"revisions": updates + 1,
+				"types":     types,
+			},
+		})
+	}
+}
+
+// RevisionCount returns the number of revisions in the document: the
+// cross-reference sections in Revisions, counting the first-page and main
+// sections of a linearized file as one revision.
+func (r *StructureValidationResult) RevisionCount() int {
+	count := len(r.Revisions)
+	if r.Linearization != nil && count > 1 {
+		count--
+	}
+	return count
+}
+
+// reportBrokenStartxref reports a startxref offset that does not lead to a
+// cross-reference section. It is a warning because readers recover by
+// scanning for the section, and it is reported as PDF-TRAILER-001 so it can
+// be repaired by recomputing the offset.
+func (v *StructureValidator) reportBrokenStartxref(offset, fallback int64, err error, result *StructureValidationResult) {
+	result.Warnings = append(result.Warnings, ValidationError{
+		Code:    ErrorCodePDFTrailer001,
+		Message: "startxref does not point to a cross-reference section",
+		Details: map[string]interface{}{
+			"offset":   offset,
+			"expected": fallback,
+			"error":    err.Error(),
+		},
+	})
+}
+
+// reportBrokenPrev reports a /Prev offset that does not lead to an earlier
+// cross-reference section, which cuts off the revisions before it.
+func (v *StructureValidator) reportBrokenPrev(revision int, offset int64, err error, result *StructureValidationResult) {
+	result.Errors = append(result.Errors, ValidationError{
+		Code:    ErrorCodePDFXref004,
+		Message: "Trailer /Prev does not point to a previous cross-reference section",
+		Details: map[string]interface{}{
+			"revision": revision,
+			"offset":   offset,
+			"error":    err.Error(),
+		},
+	})
+}
+
+// lastXrefKeyword returns the offset of the last xref table in the file.
+func lastXrefKeyword(data []byte) (int64, bool) {
+	matches := xrefKeywordPattern.FindAllIndex(data, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	return int64(matches[len(matches)-1][0]), true
+}
+
+// readXrefSection parses the cross-reference table or stream at offset.
+func readXrefSection(parser *core.PdfParser, data []byte, offset int64) (*xrefSection, error) {
+	start := skipWhitespace(data, offset)
+	if bytes.HasPrefix(data[start:], []byte("xref")) {
+		return readXrefTable(parser, data, offset, start+int64(len("xref")))
+	}
+
+	stream, err := parseStreamAt(parser, data, offset)
+	if err != nil {
+		return nil, err
+	}
+	if name, _ := core.GetNameVal(stream.Get("Type")); name != "XRef" {
+		return nil, errors.New("object is not a cross-reference stream")
+	}
+	section := &xrefSection{
+		revision: XrefRevision{Offset: offset, Type: XrefTypeStream},
+		entries:  make(map[int]xrefEntry),
+		trailer:  stream.PdfObjectDictionary,
+	}
+	if err := readXrefStream(stream, section); err != nil {
+		section.malformed = err.Error()
+	}
+	section.count()
+	return section, nil
+}
+
+// readXrefTable parses a classic cross-reference table starting after the
+// xref keyword at pos, followed by its trailer dictionary.
+func readXrefTable(parser *core.PdfParser, data []byte, offset, pos int64) (*xrefSection, error) {
+	section := &xrefSection{
+		revision:       XrefRevision{Offset: offset, Type: XrefTypeTable},
+		entries:        make(map[int]xrefEntry),
+		headGeneration: 65535,
+	}
+
+	for {
+		pos = skipWhitespace(data, pos)
+		if pos >= int64(len(data)) {
+			return nil, errors.New("cross-reference table has no trailer")
+		}
+		if bytes.HasPrefix(data[pos:], []byte("trailer")) {
+			pos += int64(len("trailer"))
+			break
+		}
+
+		header := subsectionPattern.FindSubmatch(data[pos:])
+		if header == nil {
+			section.malformed = "invalid subsection header"
+			trailer := bytes.Index(data[pos:], []byte("trailer"))
+			if trailer < 0 {
+				return nil, errors.New("cross-reference table has no trailer")
+			}
+			pos += int64(trailer + len("trailer"))
+			break
+		}
+		pos += int64(len(header[0]))
+		first, _ := strconv.Atoi(string(header[1]))
+		count, _ := strconv.Atoi(string(header[2]))
+
+		for i := 0; i < count; i++ {
+			pos = skipWhitespace(data, pos)
+			entry := tableEntryPattern.FindSubmatch(data[pos:])
+			if entry == nil {
+				if section.malformed == "" {
+					section.malformed = fmt.Sprintf("subsection %d %d has %d valid entries", first, count, i)
+				}
+				break
+			}
+			pos += int64(len(entry[0]))
+			field1, _ := strconv.ParseInt(string(entry[1]), 10, 64)
+			field2, _ := strconv.ParseInt(string(entry[2]), 10, 64)
+			section.entries[first+i] = xrefEntry{kind: entry[3][0], field1: field1, field2: field2}
+		}
+	}
+
+	parser.SetFileOffset(skipWhitespace(data, pos))
+	trailer, err := parser.ParseDict()
+	if err != nil {
+		return nil, fmt.Errorf("invalid trailer dictionary: %w", err)
+	}
+	section.trailer = trailer
+	section.count()
+	return section, nil
+}
+
+// mergeHybridStream adds the entries of a hybrid file's /XRefStm stream to
+// a table section. Objects that are only in the stream are listed as free,
+// or not at all, in the table.
+func mergeHybridStream(parser *core.PdfParser, data []byte, offset int64, section *xrefSection) error {
+	stream, err := parseStreamAt(parser, data, offset)
+	if err != nil {
+		return err
+	}
+	if name, _ := core.GetNameVal(stream.Get("Type")); name != "XRef" {
+		return errors.New("object is not a cross-reference stream")
+	}
+	supplement := &xrefSection{entries: make(map[int]xrefEntry)}
+	if err := readXrefStream(stream, supplement); err != nil {
+		return err
+	}
+	for objectNumber, entry := range supplement.entries {
+		if existing, ok := section.entries[objectNumber]; !ok || existing.kind == xrefEntryFree {
+			section.entries[objectNumber] = entry
+			if objectNumber == 0 {
+				section.headGeneration = supplement.headGeneration
+			}
+		}
+	}
+	section.revision.Type = XrefTypeHybrid
+	section.count()
+	return nil
+}
+
+// readXrefStream decodes the entries of a cross-reference stream.
+func readXrefStream(stream *core.PdfObjectStream, section *xrefSection) error {
+	wArray, ok := core.GetArray(stream.Get("W"))
+	if !ok || wArray.Len() != 3 {
+		return errors.New("/W must be an array of three integers")
+	}
+	var widths [3]int
+	for i, element := range wArray.Elements() {
+		width, ok := core.GetIntVal(element)
+		if !ok || width < 0 || width > 8 {
+			return errors.New("/W must be an array of three integers")
+		}
+		widths[i] = width
+	}
+	// Wraps to -1 for 8-byte fields, as readField does.
+	section.headGeneration = int64(1)<<(8*widths[2]) - 1
+	size, ok := core.GetIntVal(stream.Get("Size"))
+	if !ok {
+		return errors.New("missing integer /Size")
+	}
+
+	ranges := []int{0, size}
+	if indexArray, ok := core.GetArray(stream.Get("Index")); ok {
+		ranges = ranges[:0]
+		for _, element := range indexArray.Elements() {
+			value, ok := core.GetIntVal(element)
+			if !ok {
+				return errors.New("/Index must be an array of integers")
+			}
+			ranges = append(ranges, value)
+		}
+		if len(ranges)%2 != 0 {
+			return errors.New("/Index must contain pairs of integers")
+		}
+	}
+
+	decoded, err := core.DecodeStream(stream)
+	if err != nil {
+		return fmt.Errorf("failed to decode stream: %w", err)
+	}
+
+	rowWidth := widths[0] + widths[1] + widths[2]
+	if rowWidth == 0 {
+		return errors.New("/W entries are all zero")
+	}
+	row := 0
+	for i := 0; i < len(ranges); i += 2 {
+		for objectNumber := ranges[i]; objectNumber < ranges[i]+ranges[i+1]; objectNumber++ {
+			if (row+1)*rowWidth > len(decoded) {
+				return fmt.Errorf("stream holds %d entries but /Index or /Size declares more", len(decoded)/rowWidth)
+			}
+			fields := decoded[row*rowWidth : (row+1)*rowWidth]
+			row++
+
+			kind := int64(1)
+			if widths[0] > 0 {
+				kind = readField(fields[:widths[0]])
+			}
+			field1 := readField(fields[widths[0] : widths[0]+widths[1]])
+			field2 := readField(fields[widths[0]+widths[1]:])
+			switch kind {
+			case 0:
+				section.entries[objectNumber] = xrefEntry{kind: xrefEntryFree, field1: field1, field2: field2}
+			case 1:
+				section.entries[objectNumber] = xrefEntry{kind: xrefEntryInUse, field1: field1, field2: field2}
+			case 2:
+				section.entries[objectNumber] = xrefEntry{kind: xrefEntryCompressed, field1: field1, field2: field2}
+			}
+		}
+	}
+	return nil
+}
+
+// readField decodes a big-endian cross-reference stream field.
+func readField(field []byte) int64 {
+	var value int64
+	for _, b := range field {
+		value = value<<8 | int64(b)
+	}
+	return value
+}
+
+// parseStreamAt parses the indirect stream object at offset.
+func parseStreamAt(parser *core.PdfParser, data []byte, offset int64) (*core.PdfObjectStream, error) {
+	if objectHeaderPattern.Find(data[offset:]) == nil {
+		return nil, errors.New("offset does not point to an xref keyword or an object")
+	}
+	parser.SetFileOffset(offset)
+	obj, err := parser.ParseIndirectObject()
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return nil, errors.New("object is not a stream")
+	}
+	return stream, nil
+}
+
+// count tallies the entries of the section by kind.
+func (s *xrefSection) count() {
+	s.revision.InUse, s.revision.Free, s.revision.Compressed = 0, 0, 0
+	for _, entry := range s.entries {
+		switch entry.kind {
+		case xrefEntryInUse:
+			s.revision.InUse++
+		case xrefEntryFree:
+			s.revision.Free++
+		case xrefEntryCompressed:
+			s.revision.Compressed++
+		}
+	}
+}
+
+// checkEntryOffsets reports in-use entries whose offset does not point to
+// the header of the object they describe. Readers rebuild the table when
+// offsets are wrong, so these are warnings.
+func (v *StructureValidator) checkEntryOffsets(data []byte, revision int, section *xrefSection, result *StructureValidationResult) {
+	var mismatched []map[string]interface{}
+	for _, objectNumber := range sortedObjectNumbers(section.entries) {
+		entry := section.entries[objectNumber]
+		if entry.kind != xrefEntryInUse {
+			continue
+		}
+		if entry.field1 > 0 && entry.field1 < int64(len(data)) {
+			header := objectHeaderPattern.FindSubmatch(data[entry.field1:])
+			if header != nil && string(header[1]) == strconv.Itoa(objectNumber) &&
+				string(header[2]) == strconv.FormatInt(entry.field2, 10) {
+				continue
+			}
+		}
+		mismatched = append(mismatched, map[string]interface{}{
+			"object":     objectNumber,
+			"generation": entry.field2,
+			"offset":     entry.field1,
+		})
+	}
+	if len(mismatched) == 0 {
+		return
+	}
+
+	count := len(mismatched)
+	if count > maxReportedOffsets {
+		mismatched = mismatched[:maxReportedOffsets]
+	}
+	result.Warnings = append(result.Warnings, ValidationError{
+		Code:    ErrorCodePDFXref005,
+		Message: fmt.Sprintf("%d cross-reference offset(s) do not point to their object", count),
+		Details: map[string]interface{}{
+			"revision": revision,
+			"count":    count,
+			"entries":  mismatched,
+		},
+	})
+}
+
+// checkFreeList follows the linked list of free entries from object 0 and
+// reports links to in-use objects and cycles. Links to objects outside the
+// section are not followed, since incremental updates only list the
+// objects they change.
+func (v *StructureValidator) checkFreeList(revision int, section *xrefSection, result *StructureValidationResult) {
+	head, ok := section.entries[0]
+	if !ok {
+		return
+	}
+	// Writers may use 65535 in wide stream fields too.
+	if head.kind != xrefEntryFree || (head.field2 != 65535 && head.field2 != section.headGeneration) {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Code:    ErrorCodePDFXref008,
+			Message: fmt.Sprintf("Object 0 must be the head of the free list with generation %d", section.headGeneration),
+			Details: map[string]interface{}{
+				"revision": revision,
+			},
+		})
+		return
+	}
+
+	seen := map[int64]bool{0: true}
+	for next := head.field1; next != 0; {
+		if seen[next] {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFXref008,
+				Message: "Free list contains a cycle",
+				Details: map[string]interface{}{
+					"revision": revision,
+					"object":   next,
+				},
+			})
+			return
+		}
+		seen[next] = true
+
+		entry, ok := section.entries[int(next)]
+		if !ok {
+			return
+		}
+		if entry.kind != xrefEntryFree {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Code:    ErrorCodePDFXref008,
+				Message: fmt.Sprintf("Free list links to object %d, which is in use", next),
+				Details: map[string]interface{}{
+					"revision": revision,
+					"object":   next,
+				},
+			})
+			return
+		}
+		next = entry.field1
+	}
+}
+
+// checkObjectStreams reports compressed entries whose object stream is not
+// an in-use /ObjStm stream holding enough objects. Each entry is checked
+// against the newest revision that defines its object stream.
+func (v *StructureValidator) checkObjectStreams(parser *core.PdfParser, data []byte, sections []*xrefSection, result *StructureValidationResult) {
+	merged := make(map[int]xrefEntry)
+	for _, section := range sections {
+		for objectNumber, entry := range section.entries {
+			if _, ok := merged[objectNumber]; !ok {
+				merged[objectNumber] = entry
+			}
+		}
+	}
+
+	capacity := make(map[int]int)
+	reported := make(map[int]bool)
+	for _, objectNumber := range sortedObjectNumbers(merged) {
+		entry := merged[objectNumber]
+		if entry.kind != xrefEntryCompressed {
+			continue
+		}
+		streamNumber := int(entry.field1)
+		if reported[streamNumber] {
+			continue
+		}
+
+		n, ok := capacity[streamNumber]
+		if !ok {
+			n = -1
+			if container, ok := merged[streamNumber]; ok && container.kind == xrefEntryInUse &&
+				container.field1 > 0 && container.field1 < int64(len(data)) {
+				n = objectStreamCapacity(parser, data, container.field1)
+			}
+			capacity[streamNumber] = n
+		}
+
+		if n < 0 {
+			reported[streamNumber] = true
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFXref007,
+				Message: fmt.Sprintf("Object %d is stored in object %d, which is not a valid object stream", objectNumber, streamNumber),
+				Details: map[string]interface{}{
+					"object":        objectNumber,
+					"object_stream": streamNumber,
+				},
+			})
+			continue
+		}
+		if entry.field2 >= int64(n) {
+			result.Errors = append(result.Errors, ValidationError{
+				Code:    ErrorCodePDFXref007,
+				Message: fmt.Sprintf("Object %d is at index %d of object stream %d, which holds %d objects", objectNumber, entry.field2, streamNumber, n),
+				Details: map[string]interface{}{
+					"object":        objectNumber,
+					"object_stream": streamNumber,
+					"index":         entry.field2,
+					"count":         n,
+				},
+			})
+		}
+	}
+}
+
+// objectStreamCapacity returns /N of the object stream at offset, or -1 if
+// the object there is not an object stream. Only the dictionary is parsed,
+// since the stream may be encrypted or use an indirect /Length.
+func objectStreamCapacity(parser *core.PdfParser, data []byte, offset int64) int {
+	header := objectHeaderPattern.Find(data[offset:])
+	if header == nil {
+		return -1
+	}
+	parser.SetFileOffset(skipWhitespace(data, offset+int64(len(header))))
+	dict, err := parser.ParseDict()
+	if err != nil {
+		return -1
+	}
+	if name, _ := core.GetNameVal(dict.Get("Type")); name != "ObjStm" {
+		return -1
+	}
+	n, ok := core.GetIntVal(dict.Get("N"))
+	if !ok || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// skipWhitespace returns the offset of the first non-whitespace byte at or
+// after pos.
+func skipWhitespace(data []byte, pos int64) int64 {
+	for pos < int64(len(data)) && core.IsWhiteSpace(data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func sortedObjectNumbers(entries map[int]xrefEntry) []int {
+	numbers := make([]int, 0, len(entries))
+	for objectNumber := range entries {
+		numbers = append(numbers, objectNumber)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
@@ -0,0 +1,230 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+var revisionObjects = []string{
+	"<< /Type /Catalog /Pages 2 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+	"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>",
+}
+
+// appendUpdate appends an incremental update replacing object 3 to a file
+// built by buildPDF, with the given /Prev value.
+func appendUpdate(base []byte, prev string) []byte {
+	var buf bytes.Buffer
+	buf.Write(base)
+	offset := buf.Len()
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << >> >>\nendobj\n")
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 1\n0000000000 65535 f \n3 1\n%010d 00000 n \n", offset)
+	fmt.Fprintf(&buf, "trailer\n<< /Size 4 /Root 1 0 R /Prev %s >>\nstartxref\n%d\n%%%%EOF\n", prev, xref)
+	return buf.Bytes()
+}
+
+// buildXrefStreamPDF builds a PDF 1.5 file whose page object is stored at
+// index of an object stream holding one object, with an uncompressed
+// cross-reference stream.
+func buildXrefStreamPDF(index int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	offsets := make(map[int]int)
+	write := func(number int, body string) {
+		offsets[number] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", number, body)
+	}
+
+	write(1, revisionObjects[0])
+	write(2, revisionObjects[1])
+	header := "3 0 "
+	content := header + revisionObjects[2]
+	write(4, fmt.Sprintf("<< /Type /ObjStm /N 1 /First %d /Length %d >>\nstream\n%s\nendstream", len(header), len(content), content))
+
+	xref := buf.Len()
+	var rows bytes.Buffer
+	row := func(kind byte, field1 uint32, field2 uint16) {
+		rows.WriteByte(kind)
+		_ = binary.Write(&rows, binary.BigEndian, field1)
+		_ = binary.Write(&rows, binary.BigEndian, field2)
+	}
+	row(0, 0, 65535)
+	row(1, uint32(offsets[1]), 0)
+	row(1, uint32(offsets[2]), 0)
+	row(2, 4, uint16(index))
+	row(1, uint32(offsets[4]), 0)
+	row(1, uint32(xref), 0)
+
+	fmt.Fprintf(&buf, "5 0 obj\n<< /Type /XRef /Size 6 /W [1 4 2] /Root 1 0 R /Length %d >>\nstream\n", rows.Len())
+	buf.Write(rows.Bytes())
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+func TestStructureValidator_Revisions(t *testing.T) {
+	base := buildPDF(revisionObjects...)
+	baseXref := bytes.LastIndex(base, []byte("xref\n0 "))
+
+	tests := []struct {
+		name          string
+		data          []byte
+		wantErrors    []string
+		wantWarnings  []string
+		wantInfo      []string
+		wantRevisions []string
+	}{
+		{
+			name:          "single revision",
+			data:          base,
+			wantRevisions: []string{XrefTypeTable},
+		},
+		{
+			name:          "incremental update",
+			data:          appendUpdate(base, fmt.Sprint(baseXref)),
+			wantInfo:      []string{ErrorCodePDFXref009},
+			wantRevisions: []string{XrefTypeTable, XrefTypeTable},
+		},
+		{
+			name:          "broken prev",
+			data:          appendUpdate(base, "5"),
+			wantErrors:    []string{ErrorCodePDFXref004, ErrorCodePDFCatalog001},
+			wantRevisions: []string{XrefTypeTable},
+		},
+		{
+			name:          "xref stream with object stream",
+			data:          buildXrefStreamPDF(0),
+			wantRevisions: []string{XrefTypeStream},
+		},
+		{
+			name:          "object stream index out of range",
+			data:          buildXrefStreamPDF(1),
+			wantErrors:    []string{ErrorCodePDFXref007},
+			wantRevisions: []string{XrefTypeStream},
+		},
+		{
+			name:          "wrong entry offset",
+			data:          bytes.Replace(base, []byte(fmt.Sprintf("%010d 00000 n", bytes.Index(base, []byte("2 0 obj")))), []byte("0000000011 00000 n"), 1),
+			wantWarnings:  []string{ErrorCodePDFXref005},
+			wantRevisions: []string{XrefTypeTable},
+		},
+		{
+			name:          "free list links to object in use",
+			data:          bytes.Replace(base, []byte("0000000000 65535 f"), []byte("0000000002 65535 f"), 1),
+			wantWarnings:  []string{ErrorCodePDFXref008},
+			wantRevisions: []string{XrefTypeTable},
+		},
+		{
+			name:          "wrong startxref",
+			data:          bytes.Replace(base, []byte(fmt.Sprintf("startxref\n%d", baseXref)), []byte(fmt.Sprintf("startxref\n%d", baseXref+10)), 1),
+			wantWarnings:  []string{ErrorCodePDFTrailer001},
+			wantRevisions: []string{XrefTypeTable},
+		},
+	}
+
+	validator := NewStructureValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := validator.ValidateBytes(tt.data)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if got := errorCodes(result.Errors); !equalCodes(got, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", got, tt.wantErrors)
+				for _, e := range result.Errors {
+					t.Logf("Error: %s - %s %v", e.Code, e.Message, e.Details)
+				}
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+			if got := errorCodes(result.Info); !equalCodes(got, tt.wantInfo) {
+				t.Errorf("info = %v, want %v", got, tt.wantInfo)
+			}
+
+			types := make([]string, 0, len(result.Revisions))
+			for _, revision := range result.Revisions {
+				types = append(types, revision.Type)
+			}
+			if !equalCodes(types, tt.wantRevisions) {
+				t.Errorf("revisions = %v, want %v", types, tt.wantRevisions)
+			}
+		})
+	}
+}
+
+func TestStructureValidator_Revisions_Counts(t *testing.T) {
+	result, err := NewStructureValidator().ValidateBytes(buildXrefStreamPDF(0))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Revisions) != 1 {
+		t.Fatalf("Expected 1 revision, got %d", len(result.Revisions))
+	}
+	revision := result.Revisions[0]
+	if revision.InUse != 4 || revision.Free != 1 || revision.Compressed != 1 {
+		t.Errorf("Expected 4 in use, 1 free and 1 compressed entry, got %+v", revision)
+	}
+	if result.PageCount != 1 {
+		t.Errorf("Expected the compressed page to be found, got %d pages", result.PageCount)
+	}
+}
+
+// buildNarrowXrefStreamPDF builds a file whose cross-reference stream uses
+// one-byte generation fields, as pdfTeX writes them, with object 0 at
+// headGeneration.
+func buildNarrowXrefStreamPDF(headGeneration byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	offsets := make([]int, 0, len(revisionObjects))
+	for i, body := range revisionObjects {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+
+	xref := buf.Len()
+	var rows bytes.Buffer
+	row := func(kind byte, field1 int, field2 byte) {
+		rows.Write([]byte{kind, byte(field1 >> 16), byte(field1 >> 8), byte(field1), field2})
+	}
+	row(0, 0, headGeneration)
+	for _, offset := range offsets {
+		row(1, offset, 0)
+	}
+	row(1, xref, 0)
+
+	size := len(revisionObjects) + 2
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XRef /Size %d /W [1 3 1] /Root 1 0 R /Length %d >>\nstream\n", size-1, size, rows.Len())
+	buf.Write(rows.Bytes())
+	fmt.Fprintf(&buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+func TestStructureValidator_Revisions_NarrowGenerationField(t *testing.T) {
+	tests := []struct {
+		name           string
+		headGeneration byte
+		wantWarnings   []string
+	}{
+		{name: "largest one-byte generation", headGeneration: 255},
+		{name: "other generation", headGeneration: 7, wantWarnings: []string{ErrorCodePDFXref008}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewStructureValidator().ValidateBytes(buildNarrowXrefStreamPDF(tt.headGeneration))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			for _, e := range result.Errors {
+				t.Errorf("Unexpected error: %s - %s %v", e.Code, e.Message, e.Details)
+			}
+			if got := errorCodes(result.Warnings); !equalCodes(got, tt.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
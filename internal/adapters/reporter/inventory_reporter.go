@@ -0,0 +1,323 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/internal/ports"
+)
+
+type jsonInventory struct {
+	FilePath           string              `json:"file_path"`
+	FileType           string              `json:"file_type"`
+	Title              string              `json:"title,omitempty"`
+	Version            string              `json:"version"`
+	Rendition          string              `json:"rendition"`
+	Languages          []string            `json:"languages"`
+	DetectedLanguages  []string            `json:"detected_languages"`
+	TotalWords         int                 `json:"total_words"`
+	TotalChars         int                 `json:"total_chars"`
+	ReadingTimeMinutes int                 `json:"reading_time_minutes"`
+	EstimatedPages     int                 `json:"estimated_pages"`
+	Documents          []jsonDocumentStats `json:"documents"`
+	ImageCount         int                 `json:"image_count"`
+	ImageBytes         int64               `json:"image_bytes"`
+	Images             []jsonMediaType     `json:"images"`
+	Fonts              []jsonResource      `json:"fonts"`
+	Largest            []jsonResource      `json:"largest_resources"`
+	TotalBytes         int64               `json:"total_bytes"`
+	TOC                jsonTOCStats        `json:"toc"`
+	InspectedAt        string              `json:"inspected_at"`
+}
+
+type jsonDocumentStats struct {
+	Path  string `json:"path"`
+	Words int    `json:"words"`
+	Chars int    `json:"chars"`
+}
+
+type jsonMediaType struct {
+	MediaType string `json:"media_type"`
+	Count     int    `json:"count"`
+	Bytes     int64  `json:"bytes"`
+}
+
+type jsonResource struct {
+	Path      string `json:"path"`
+	MediaType string `json:"media_type"`
+	Size      int64  `json:"size"`
+}
+
+type jsonTOCStats struct {
+	Entries int `json:"entries"`
+	Depth   int `json:"depth"`
+}
+
+// NewInventoryReporter returns the inventory reporter for format.
+func NewInventoryReporter(format ports.OutputFormat) (ports.InventoryReporter, error) {
+	switch format {
+	case ports.FormatJSON:
+		return &JSONReporter{}, nil
+	case ports.FormatText:
+		return &TextReporter{}, nil
+	case ports.FormatMarkdown:
+		return &MarkdownReporter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// FormatInventory renders an inventory as JSON.
+func (r *JSONReporter) FormatInventory(_ context.Context, inventory *domain.Inventory, _ *ports.ReportOptions) (string, error) {
+	out := jsonInventory{
+		FilePath:           inventory.FilePath,
+		FileType:           inventory.FileType,
+		Title:              inventory.Title,
+		Version:            inventory.Version,
+		Rendition:          inventory.Rendition,
+		Languages:          nonNilStrings(inventory.Languages),
+		DetectedLanguages:  nonNilStrings(inventory.DetectedLanguages),
+		TotalWords:         inventory.TotalWords,
+		TotalChars:         inventory.TotalChars,
+		ReadingTimeMinutes: readingMinutes(inventory.ReadingTime),
+		EstimatedPages:     inventory.EstimatedPages,
+		Documents:          make([]jsonDocumentStats, 0, len(inventory.Documents)),
+		ImageCount:         inventory.ImageCount,
+		ImageBytes:         inventory.ImageBytes,
+		Images:             make([]jsonMediaType, 0, len(inventory.Images)),
+		Fonts:              convertResources(inventory.Fonts),
+		Largest:            convertResources(inventory.Largest),
+		TotalBytes:         inventory.TotalBytes,
+		TOC:                jsonTOCStats{Entries: inventory.TOCEntries, Depth: inventory.TOCDepth},
+		InspectedAt:        inventory.InspectedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	for _, doc := range inventory.Documents {
+		out.Documents = append(out.Documents, jsonDocumentStats{Path: doc.Path, Words: doc.Words, Chars: doc.Chars})
+	}
+	for _, images := range inventory.Images {
+		out.Images = append(out.Images, jsonMediaType{MediaType: images.MediaType, Count: images.Count, Bytes: images.Bytes})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// WriteInventory writes a JSON inventory to the provided writer.
+func (r *JSONReporter) WriteInventory(ctx context.Context, inventory *domain.Inventory, writer io.Writer, options *ports.ReportOptions) error {
+	formatted, err := r.FormatInventory(ctx, inventory, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write([]byte(formatted))
+	return err
+}
+
+// FormatInventory renders an inventory as text.
+func (r *TextReporter) FormatInventory(_ context.Context, inventory *domain.Inventory, options *ports.ReportOptions) (string, error) {
+	var sb strings.Builder
+
+	colors := NewColorScheme(options != nil && options.ColorEnabled)
+
+	sb.WriteString(colors.ColorizeHeader("═══════════════════════════════════════════════════════════════\n"))
+	sb.WriteString(colors.ColorizeHeader(fmt.Sprintf("  INVENTORY: %s\n", inventory.FilePath)))
+	sb.WriteString(colors.ColorizeHeader("═══════════════════════════════════════════════════════════════\n\n"))
+
+	if inventory.Title != "" {
+		sb.WriteString(fmt.Sprintf("Title:           %s\n", inventory.Title))
+	}
+	sb.WriteString(fmt.Sprintf("File Type:       %s %s\n", inventory.FileType, inventory.Version))
+	sb.WriteString(fmt.Sprintf("Rendition:       %s\n", inventory.Rendition))
+	sb.WriteString(fmt.Sprintf("Languages:       %s\n", joinOrNone(inventory.Languages)))
+	sb.WriteString(fmt.Sprintf("Detected:        %s\n\n", joinOrNone(inventory.DetectedLanguages)))
+
+	sb.WriteString(colors.ColorizeHeader("TEXT\n"))
+	sb.WriteString(strings.Repeat("─", 63) + "\n")
+	sb.WriteString(fmt.Sprintf("Words:           %d\n", inventory.TotalWords))
+	sb.WriteString(fmt.Sprintf("Characters:      %d\n", inventory.TotalChars))
+	sb.WriteString(fmt.Sprintf("Reading Time:    %s\n", formatReadingTime(inventory.ReadingTime)))
+	sb.WriteString(fmt.Sprintf("Estimated Pages: %d\n", inventory.EstimatedPages))
+	sb.WriteString(fmt.Sprintf("TOC:             %d entries, depth %d\n\n", inventory.TOCEntries, inventory.TOCDepth))
+
+	if options != nil && options.Verbose && len(inventory.Documents) > 0 {
+		sb.WriteString(colors.ColorizeHeader("DOCUMENTS\n"))
+		sb.WriteString(strings.Repeat("─", 63) + "\n")
+		for _, doc := range inventory.Documents {
+			fmt.Fprintf(&sb, "%8d words %9d chars  %s\n", doc.Words, doc.Chars, colors.ColorizePath(doc.Path))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(colors.ColorizeHeader("RESOURCES\n"))
+	sb.WriteString(strings.Repeat("─", 63) + "\n")
+	sb.WriteString(fmt.Sprintf("Total Size:      %s\n", formatBytes(inventory.TotalBytes)))
+	sb.WriteString(fmt.Sprintf("Images:          %d (%s)\n", inventory.ImageCount, formatBytes(inventory.ImageBytes)))
+	for _, images := range inventory.Images {
+		fmt.Fprintf(&sb, "  %-15s %d (%s)\n", images.MediaType, images.Count, formatBytes(images.Bytes))
+	}
+	sb.WriteString(fmt.Sprintf("Fonts:           %d\n", len(inventory.Fonts)))
+	for _, font := range inventory.Fonts {
+		fmt.Fprintf(&sb, "  %s (%s)\n", colors.ColorizePath(font.Path), formatBytes(font.Size))
+	}
+	sb.WriteString("\n")
+
+	if len(inventory.Largest) > 0 {
+		sb.WriteString(colors.ColorizeHeader("LARGEST RESOURCES\n"))
+		sb.WriteString(strings.Repeat("─", 63) + "\n")
+		for _, resource := range inventory.Largest {
+			fmt.Fprintf(&sb, "%10s  %s\n", formatBytes(resource.Size), colors.ColorizePath(resource.Path))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(colors.ColorizeHeader("═══════════════════════════════════════════════════════════════\n"))
+
+	return sb.String(), nil
+}
+
+// WriteInventory writes a text inventory to the provided writer.
+func (r *TextReporter) WriteInventory(ctx context.Context, inventory *domain.Inventory, writer io.Writer, options *ports.ReportOptions) error {
+	formatted, err := r.FormatInventory(ctx, inventory, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write([]byte(formatted))
+	return err
+}
+
+// FormatInventory renders an inventory as Markdown.
+func (r *MarkdownReporter) FormatInventory(_ context.Context, inventory *domain.Inventory, _ *ports.ReportOptions) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Inventory: %s\n\n", inventory.FilePath))
+
+	if inventory.Title != "" {
+		sb.WriteString(fmt.Sprintf("- **Title:** %s\n", r.escapeMarkdown(inventory.Title)))
+	}
+	sb.WriteString(fmt.Sprintf("- **File Type:** %s %s\n", inventory.FileType, inventory.Version))
+	sb.WriteString(fmt.Sprintf("- **Rendition:** %s\n", inventory.Rendition))
+	sb.WriteString(fmt.Sprintf("- **Languages:** %s\n", joinOrNone(inventory.Languages)))
+	sb.WriteString(fmt.Sprintf("- **Detected Languages:** %s\n\n", joinOrNone(inventory.DetectedLanguages)))
+
+	sb.WriteString("## Text\n\n")
+	sb.WriteString(fmt.Sprintf("- **Words:** %d\n", inventory.TotalWords))
+	sb.WriteString(fmt.Sprintf("- **Characters:** %d\n", inventory.TotalChars))
+	sb.WriteString(fmt.Sprintf("- **Reading Time:** %s\n", formatReadingTime(inventory.ReadingTime)))
+	sb.WriteString(fmt.Sprintf("- **Estimated Pages:** %d\n", inventory.EstimatedPages))
+	sb.WriteString(fmt.Sprintf("- **TOC:** %d entries, depth %d\n\n", inventory.TOCEntries, inventory.TOCDepth))
+
+	if len(inventory.Documents) > 0 {
+		sb.WriteString("| Document | Words | Characters |\n")
+		sb.WriteString("|----------|------:|-----------:|\n")
+		for _, doc := range inventory.Documents {
+			sb.WriteString(fmt.Sprintf("| `%s` | %d | %d |\n", doc.Path, doc.Words, doc.Chars))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Resources\n\n")
+	sb.WriteString(fmt.Sprintf("- **Total Size:** %s\n", formatBytes(inventory.TotalBytes)))
+	sb.WriteString(fmt.Sprintf("- **Images:** %d (%s)\n", inventory.ImageCount, formatBytes(inventory.ImageBytes)))
+	sb.WriteString(fmt.Sprintf("- **Fonts:** %d\n\n", len(inventory.Fonts)))
+
+	if len(inventory.Images) > 0 {
+		sb.WriteString("| Media Type | Count | Size |\n")
+		sb.WriteString("|------------|------:|-----:|\n")
+		for _, images := range inventory.Images {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", images.MediaType, images.Count, formatBytes(images.Bytes)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(inventory.Fonts) > 0 {
+		sb.WriteString("### Fonts\n\n")
+		for _, font := range inventory.Fonts {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s, %s)\n", font.Path, font.MediaType, formatBytes(font.Size)))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(inventory.Largest) > 0 {
+		sb.WriteString("### Largest Resources\n\n")
+		sb.WriteString("| Resource | Media Type | Size |\n")
+		sb.WriteString("|----------|------------|-----:|\n")
+		for _, resource := range inventory.Largest {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", resource.Path, resource.MediaType, formatBytes(resource.Size)))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String(), nil
+}
+
+// WriteInventory writes a Markdown inventory to the provided writer.
+func (r *MarkdownReporter) WriteInventory(ctx context.Context, inventory *domain.Inventory, writer io.Writer, options *ports.ReportOptions) error {
+	formatted, err := r.FormatInventory(ctx, inventory, options)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write([]byte(formatted))
+	return err
+}
+
+func convertResources(resources []domain.ResourceInfo) []jsonResource {
+	result := make([]jsonResource, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, jsonResource{Path: resource.Path, MediaType: resource.MediaType, Size: resource.Size})
+	}
+	return result
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+// readingMinutes rounds a reading time up to whole minutes.
+func readingMinutes(d time.Duration) int {
+	return int(math.Ceil(d.Minutes()))
+}
+
+// formatReadingTime renders a reading time as "45 min" or "3 h 5 min".
+func formatReadingTime(d time.Duration) string {
+	minutes := readingMinutes(d)
+	if minutes < 60 {
+		return fmt.Sprintf("%d min", minutes)
+	}
+	return fmt.Sprintf("%d h %d min", minutes/60, minutes%60)
+}
+
+// formatBytes renders a size with a binary unit, e.g. "1.5 MiB".
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,170 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/internal/ports"
+)
+
+func createTestInventory() *domain.Inventory {
+	return &domain.Inventory{
+		FilePath:          "book.epub",
+		FileType:          "EPUB",
+		Title:             "Test Book",
+		Version:           "3.0",
+		Rendition:         "reflowable",
+		Languages:         []string{"en"},
+		DetectedLanguages: []string{"en", "fr"},
+		Documents: []domain.DocumentStats{
+			{Path: "OEBPS/ch1.xhtml", Words: 1200, Chars: 6000},
+			{Path: "OEBPS/ch2.xhtml", Words: 800, Chars: 4100},
+		},
+		TotalWords:     2000,
+		TotalChars:     10100,
+		ReadingTime:    8*time.Minute + 30*time.Second,
+		EstimatedPages: 8,
+		Images:         []domain.MediaTypeStats{{MediaType: "image/jpeg", Count: 2, Bytes: 3 << 20}},
+		ImageCount:     2,
+		ImageBytes:     3 << 20,
+		Fonts:          []domain.ResourceInfo{{Path: "OEBPS/fonts/serif.otf", MediaType: "font/otf", Size: 40 << 10}},
+		Largest:        []domain.ResourceInfo{{Path: "OEBPS/images/cover.jpg", MediaType: "image/jpeg", Size: 2 << 20}},
+		TotalBytes:     4 << 20,
+		TOCEntries:     12,
+		TOCDepth:       2,
+		InspectedAt:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNewInventoryReporter(t *testing.T) {
+	for _, format := range []ports.OutputFormat{ports.FormatJSON, ports.FormatText, ports.FormatMarkdown} {
+		if _, err := NewInventoryReporter(format); err != nil {
+			t.Errorf("NewInventoryReporter(%s) error = %v", format, err)
+		}
+	}
+	if _, err := NewInventoryReporter(ports.FormatHTML); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestJSONReporter_FormatInventory(t *testing.T) {
+	reporter := &JSONReporter{}
+	result, err := reporter.FormatInventory(context.Background(), createTestInventory(), nil)
+	if err != nil {
+		t.Fatalf("FormatInventory failed: %v", err)
+	}
+
+	var parsed jsonInventory
+	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if parsed.TotalWords != 2000 || parsed.ReadingTimeMinutes != 9 || parsed.EstimatedPages != 8 {
+		t.Errorf("unexpected text statistics: %+v", parsed)
+	}
+	if len(parsed.Documents) != 2 || parsed.Documents[1].Chars != 4100 {
+		t.Errorf("unexpected documents: %+v", parsed.Documents)
+	}
+	if parsed.TOC.Entries != 12 || parsed.TOC.Depth != 2 {
+		t.Errorf("unexpected TOC: %+v", parsed.TOC)
+	}
+	if len(parsed.Images) != 1 || parsed.Images[0].Bytes != 3<<20 {
+		t.Errorf("unexpected images: %+v", parsed.Images)
+	}
+
+	empty, err := reporter.FormatInventory(context.Background(), &domain.Inventory{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(empty, `"fonts": []`) || !strings.Contains(empty, `"detected_languages": []`) {
+		t.Errorf("expected empty lists rather than null:\n%s", empty)
+	}
+}
+
+func TestTextReporter_FormatInventory(t *testing.T) {
+	reporter := &TextReporter{}
+	inventory := createTestInventory()
+
+	result, err := reporter.FormatInventory(context.Background(), inventory, &ports.ReportOptions{})
+	if err != nil {
+		t.Fatalf("FormatInventory failed: %v", err)
+	}
+	for _, want := range []string{
+		"INVENTORY: book.epub",
+		"File Type:       EPUB 3.0",
+		"Detected:        en, fr",
+		"Words:           2000",
+		"Reading Time:    9 min",
+		"TOC:             12 entries, depth 2",
+		"Images:          2 (3.0 MiB)",
+		"OEBPS/fonts/serif.otf (40.0 KiB)",
+		"2.0 MiB  OEBPS/images/cover.jpg",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "OEBPS/ch1.xhtml") {
+		t.Error("expected per-document counts only in verbose output")
+	}
+
+	var buf bytes.Buffer
+	if err := reporter.WriteInventory(context.Background(), inventory, &buf, &ports.ReportOptions{Verbose: true}); err != nil {
+		t.Fatalf("WriteInventory failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "1200 words") || !strings.Contains(buf.String(), "OEBPS/ch1.xhtml") {
+		t.Errorf("expected per-document counts in verbose output:\n%s", buf.String())
+	}
+}
+
+func TestMarkdownReporter_FormatInventory(t *testing.T) {
+	reporter := &MarkdownReporter{}
+	result, err := reporter.FormatInventory(context.Background(), createTestInventory(), nil)
+	if err != nil {
+		t.Fatalf("FormatInventory failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Inventory: book.epub",
+		"- **Estimated Pages:** 8",
+		"| `OEBPS/ch2.xhtml` | 800 | 4100 |",
+		"| image/jpeg | 2 | 3.0 MiB |",
+		"- `OEBPS/fonts/serif.otf` (font/otf, 40.0 KiB)",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output:\n%s", want, result)
+		}
+	}
+}
+
+func TestFormatReadingTime(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                              "0 min",
+		90 * time.Second:               "2 min",
+		59 * time.Minute:               "59 min",
+		2*time.Hour + 5*time.Minute:    "2 h 5 min",
+		126*time.Hour + 41*time.Minute: "126 h 41 min",
+	} {
+		if got := formatReadingTime(d); got != want {
+			t.Errorf("formatReadingTime(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for size, want := range map[int64]string{
+		0:         "0 B",
+		1023:      "1023 B",
+		1024:      "1.0 KiB",
+		1536:      "1.5 KiB",
+		5 << 30:   "5.0 GiB",
+		100 << 20: "100.0 MiB",
+	} {
+		if got := formatBytes(size); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -37,11 +37,11 @@ func RunBatchValidate(ctx context.Context, targets []string, opts BatchOptions,
 
 	engineResult := batch.Run(ctx, items, batch.Config{Workers: opts.Workers, QueueSize: opts.QueueSize}, func(ctx context.Context, path string) batch.ItemResult {
 		start := time.Now()
-		report, err := ValidateFile(ctx, path)
+		report, err := ValidateFileWithOptions(ctx, path, opts.Validate)
 		return batch.ItemResult{Path: path, Value: report, Err: err, Duration: time.Since(start)}
 	}, progress)
 
-	result := summarizeBatch(engineResult.Items)
+	result := summarizeBatch(engineResult.Items, filter)
 	if result.InternalError != nil {
 		return result, result.InternalError
 	}
@@ -106,7 +106,7 @@ func RunBatchRepair(ctx context.Context, targets []string, opts BatchOptions, re
 		return batch.ItemResult{Path: path, Value: report, Err: err, Duration: time.Since(start)}
 	}, progress)
 
-	result := summarizeBatch(engineResult.Items)
+	result := summarizeBatch(engineResult.Items, filter)
 	if result.InternalError != nil {
 		return result, result.InternalError
 	}
@@ -150,7 +150,13 @@ func collectTargets(targets []string, opts BatchOptions) ([]string, error) {
 	return batch.DiscoverFiles(expanded, batch.DiscoverOptions{MaxDepth: opts.MaxDepth, Extensions: opts.Extensions, Ignore: opts.Ignore})
 }
 
-func summarizeBatch(items []batch.ItemResult) BatchResult {
+// summarizeBatch collects the reports. Their errors and warnings are only
+// counted in the categories of filter, when it names any.
+func summarizeBatch(items []batch.ItemResult, filter *reporter.Filter) BatchResult {
+	var categories []string
+	if filter != nil {
+		categories = filter.Categories
+	}
 	result := BatchResult{Reports: make([]*domain.ValidationReport, 0, len(items))}
 	for _, item := range items {
 		result.Total++
@@ -168,10 +174,11 @@ func summarizeBatch(items []batch.ItemResult) BatchResult {
 		}
 		result.Reports = append(result.Reports, report)
 		result.Processed++
-		if report.HasErrors() {
+		counted := FilterCategories(report, categories)
+		if counted.HasErrors() {
 			result.HasErrors = true
 		}
-		if report.HasWarnings() {
+		if counted.HasWarnings() {
 			result.HasWarnings = true
 		}
 	}
@@ -143,6 +143,22 @@ func ExitWithReport(report *domain.ValidationReport) error {
 	return ExitError{Code: ExitCodeOK}
 }
 
+// FilterCategories returns a copy of report holding only the findings in
+// categories, or report itself when categories is empty, so exit codes
+// follow the findings --category shows.
+func FilterCategories(report *domain.ValidationReport, categories []string) *domain.ValidationReport {
+	if report == nil || len(categories) == 0 {
+		return report
+	}
+	filter := &reporter.Filter{Categories: categories}
+	filtered := *report
+	filtered.Errors = filter.FilterErrors(report.Errors)
+	filtered.Warnings = filter.FilterErrors(report.Warnings)
+	filtered.Info = filter.FilterErrors(report.Info)
+	filtered.IsValid = len(filtered.Errors) == 0
+	return &filtered
+}
+
 // ExitWithBatchResult maps a batch result to a process exit code.
 func ExitWithBatchResult(result BatchResult) error {
 	if result.InternalError != nil {
@@ -159,12 +175,17 @@ func ExitWithBatchResult(result BatchResult) error {
 
 // ValidateFile validates a file based on its extension.
 func ValidateFile(ctx context.Context, path string) (*domain.ValidationReport, error) {
+	return ValidateFileWithOptions(ctx, path, ValidateOptions{})
+}
+
+// ValidateFileWithOptions validates a file based on its extension and applies options.
+func ValidateFileWithOptions(ctx context.Context, path string, opts ValidateOptions) (*domain.ValidationReport, error) {
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".epub":
-		return ebmlib.ValidateEPUBWithContext(ctx, path)
+		return ebmlib.ValidateEPUBWithOptions(ctx, path, opts.library())
 	case ".pdf":
-		return ebmlib.ValidatePDFWithContext(ctx, path)
+		return ebmlib.ValidatePDFWithOptions(ctx, path, opts.library())
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
@@ -172,11 +193,16 @@ func ValidateFile(ctx context.Context, path string) (*domain.ValidationReport, e
 
 // ValidateReader validates a reader given the file type.
 func ValidateReader(ctx context.Context, reader io.Reader, size int64, fileType string) (*domain.ValidationReport, error) {
+	return ValidateReaderWithOptions(ctx, reader, size, fileType, ValidateOptions{})
+}
+
+// ValidateReaderWithOptions validates a reader given the file type and applies options.
+func ValidateReaderWithOptions(ctx context.Context, reader io.Reader, size int64, fileType string, opts ValidateOptions) (*domain.ValidationReport, error) {
 	switch strings.ToLower(fileType) {
 	case "epub":
-		return ebmlib.ValidateEPUBReaderWithContext(ctx, reader, size)
+		return ebmlib.ValidateEPUBReaderWithOptions(ctx, reader, size, opts.library())
 	case "pdf":
-		return ebmlib.ValidatePDFReaderWithContext(ctx, reader)
+		return ebmlib.ValidatePDFReaderWithOptions(ctx, reader, opts.library())
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", fileType)
 	}
@@ -185,7 +211,7 @@ func ValidateReader(ctx context.Context, reader io.Reader, size int64, fileType
 // RepairFile validates and repairs a file according to options.
 func RepairFile(ctx context.Context, path string, opts RepairOptions) (*ports.RepairResult, *domain.ValidationReport, error) {
 	fileType := strings.ToLower(filepath.Ext(path))
-	report, err := ValidateFile(ctx, path)
+	report, err := ValidateFileWithOptions(ctx, path, opts.Validate)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -249,7 +275,7 @@ func RepairFile(ctx context.Context, path string, opts RepairOptions) (*ports.Re
 	if !opts.InPlace {
 		repairedPath = outputPath
 	}
-	finalReport, err := ValidateFile(ctx, repairedPath)
+	finalReport, err := ValidateFileWithOptions(ctx, repairedPath, opts.Validate)
 	if err != nil {
 		return result, report, err
 	}
@@ -262,6 +288,89 @@ func RepairFile(ctx context.Context, path string, opts RepairOptions) (*ports.Re
 	return result, finalReport, nil
 }
 
+// UpdateMetadata applies metadata changes to an EPUB, in place unless
+// opts.OutputPath is set. It returns the written path and the backup path.
+func UpdateMetadata(path string, changes ebmlib.MetadataChanges, opts MetadataOptions) (string, string, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".epub") {
+		return "", "", fmt.Errorf("metadata editing supports EPUB files only: %s", path)
+	}
+
+	if opts.OutputPath != "" {
+		if err := ebmlib.UpdateEPUBMetadataTo(path, opts.OutputPath, changes); err != nil {
+			return "", "", err
+		}
+		return opts.OutputPath, "", nil
+	}
+
+	backupPath := ""
+	if opts.Backup {
+		var err error
+		backupPath, err = backupFile(path, opts.BackupDir)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	if err := ebmlib.UpdateEPUBMetadata(path, changes); err != nil {
+		return "", backupPath, err
+	}
+	return path, backupPath, nil
+}
+
+// OptimizeFile writes an optimized copy of a PDF, to opts.OutputPath or the
+// default optimized path, or replaces it when opts.InPlace is set. It
+// returns the result, the written path and the backup path.
+func OptimizeFile(ctx context.Context, path string, opts OptimizeOptions) (*ebmlib.OptimizeResult, string, string, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+		return nil, "", "", fmt.Errorf("optimization supports PDF files only: %s", path)
+	}
+
+	if !opts.InPlace {
+		outputPath := opts.OutputPath
+		if outputPath == "" {
+			outputPath = DefaultOptimizedPath(path)
+		}
+		result, err := ebmlib.OptimizePDF(ctx, path, outputPath, opts.Optimize)
+		return result, outputPath, "", err
+	}
+
+	tempPath, err := createTempPath(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	result, err := ebmlib.OptimizePDF(ctx, path, tempPath, opts.Optimize)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return nil, "", "", err
+	}
+	backupPath := ""
+	if opts.Backup {
+		backupPath, err = backupFile(path, opts.BackupDir)
+		if err != nil {
+			_ = os.Remove(tempPath)
+			return result, "", "", err
+		}
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return result, "", backupPath, err
+	}
+	return result, path, backupPath, nil
+}
+
+// DefaultOptimizedPath returns the path OptimizeFile writes to by default,
+// such as "document.optimized.pdf".
+func DefaultOptimizedPath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".optimized" + ext
+}
+
+// InspectFile builds the inventory of an EPUB.
+func InspectFile(path string) (*domain.Inventory, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".epub") {
+		return nil, fmt.Errorf("inspection supports EPUB files only: %s", path)
+	}
+	return ebmlib.InspectEPUB(path)
+}
+
 func DefaultRepairedPath(path string) string {
 	return defaultRepairedPath(path)
 }
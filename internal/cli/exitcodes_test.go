@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/reporter"
+	"github.com/petergi/ebook-mechanic-lib/internal/batch"
 	"github.com/petergi/ebook-mechanic-lib/internal/domain"
 )
 
@@ -49,3 +51,30 @@ func TestExitWithBatchResult(t *testing.T) {
 		t.Fatalf("expected error exit code")
 	}
 }
+
+func TestExitWithReport_Categories(t *testing.T) {
+	report := &domain.ValidationReport{
+		Errors: []domain.ValidationError{{Code: "PDF-XREF-001", Details: map[string]interface{}{"category": "structure"}}},
+		Warnings: []domain.ValidationError{
+			{Code: "PDF-SAFETY-001", Details: map[string]interface{}{"category": "safety"}},
+		},
+	}
+
+	if code := exitCode(t, ExitWithReport(FilterCategories(report, nil))); code != ExitCodeError {
+		t.Fatalf("expected error exit code without categories, got %d", code)
+	}
+	if code := exitCode(t, ExitWithReport(FilterCategories(report, []string{"safety"}))); code != ExitCodeWarning {
+		t.Fatalf("expected warning exit code for safety findings, got %d", code)
+	}
+	if code := exitCode(t, ExitWithReport(FilterCategories(report, []string{"privacy"}))); code != ExitCodeOK {
+		t.Fatalf("expected OK exit code without privacy findings, got %d", code)
+	}
+	if len(report.Errors) != 1 || len(report.Warnings) != 1 {
+		t.Errorf("expected the report to be left unchanged, got %+v", report)
+	}
+
+	result := summarizeBatch([]batch.ItemResult{{Value: report}}, &reporter.Filter{Categories: []string{"safety"}})
+	if result.HasErrors || !result.HasWarnings || len(result.Reports) != 1 {
+		t.Errorf("expected only the safety warning to count, got %+v", result)
+	}
+}
@@ -1,6 +1,9 @@
 package cli
 
-import "github.com/petergi/ebook-mechanic-lib/internal/domain"
+import (
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/pkg/ebmlib"
+)
 
 // RepairOptions configures repair behavior.
 type RepairOptions struct {
@@ -8,6 +11,42 @@ type RepairOptions struct {
 	InPlace    bool
 	Backup     bool
 	BackupDir  string
+	Validate   ValidateOptions
+}
+
+// MetadataOptions configures metadata edits.
+type MetadataOptions struct {
+	OutputPath string
+	Backup     bool
+	BackupDir  string
+}
+
+// OptimizeOptions configures PDF optimization.
+type OptimizeOptions struct {
+	OutputPath string
+	InPlace    bool
+	Backup     bool
+	BackupDir  string
+	Optimize   ebmlib.OptimizeOptions
+}
+
+// ValidateOptions configures validation behavior.
+type ValidateOptions struct {
+	Profile    string
+	ConfigPath string
+	NoConfig   bool
+	Deep       bool
+	Password   string
+}
+
+func (o ValidateOptions) library() ebmlib.ValidateOptions {
+	return ebmlib.ValidateOptions{
+		Profile:    o.Profile,
+		ConfigPath: o.ConfigPath,
+		NoConfig:   o.NoConfig,
+		Deep:       o.Deep,
+		Password:   o.Password,
+	}
 }
 
 // BatchOptions configures batch execution.
@@ -20,6 +59,7 @@ type BatchOptions struct {
 	Progress    string
 	SummaryOnly bool
 	OutputPath  string
+	Validate    ValidateOptions
 	Repair      RepairOptions
 }
 
@@ -0,0 +1,63 @@
+package domain
+
+import "time"
+
+// Inventory describes what a publication contains: its text statistics,
+// resources, navigation depth and declared properties. Inventories are
+// produced by inspection, which does not validate the file.
+type Inventory struct {
+	FilePath string
+	FileType string
+	Title    string
+	// Version is the format version declared by the file, e.g. "3.0".
+	Version string
+	// Rendition is the rendition layout: "reflowable" or "pre-paginated".
+	Rendition string
+	// Languages are the languages declared in the package metadata.
+	Languages []string
+	// DetectedLanguages are the distinct lang values found in the content
+	// documents, in order of first use.
+	DetectedLanguages []string
+
+	// Documents lists the text statistics of the spine documents in
+	// reading order.
+	Documents      []DocumentStats
+	TotalWords     int
+	TotalChars     int
+	ReadingTime    time.Duration
+	EstimatedPages int
+
+	// Images groups image resources by media type.
+	Images      []MediaTypeStats
+	ImageCount  int
+	ImageBytes  int64
+	Fonts       []ResourceInfo
+	Largest     []ResourceInfo
+	TotalBytes  int64
+	TOCEntries  int
+	TOCDepth    int
+	InspectedAt time.Time
+}
+
+// DocumentStats holds the text statistics of one content document.
+type DocumentStats struct {
+	Path  string
+	Words int
+	// Chars counts the characters of the document text, excluding
+	// whitespace.
+	Chars int
+}
+
+// MediaTypeStats counts the resources of one media type.
+type MediaTypeStats struct {
+	MediaType string
+	Count     int
+	Bytes     int64
+}
+
+// ResourceInfo identifies a resource and its uncompressed size.
+type ResourceInfo struct {
+	Path      string
+	MediaType string
+	Size      int64
+}
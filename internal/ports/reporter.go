@@ -47,3 +47,9 @@ type MultiReporter interface {
 	WriteMultiple(ctx context.Context, reports []*domain.ValidationReport, writer io.Writer, options *ReportOptions) error
 	WriteSummary(ctx context.Context, reports []*domain.ValidationReport, writer io.Writer, options *ReportOptions) error
 }
+
+// InventoryReporter formats and writes publication inventories.
+type InventoryReporter interface {
+	FormatInventory(ctx context.Context, inventory *domain.Inventory, options *ReportOptions) (string, error)
+	WriteInventory(ctx context.Context, inventory *domain.Inventory, writer io.Writer, options *ReportOptions) error
+}
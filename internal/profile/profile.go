@@ -0,0 +1,307 @@
+// Package profile loads retailer validation profiles and applies them to
+// validation reports. Profiles are YAML or JSON documents; built-in profiles
+// ship embedded in the binary and custom profiles can be loaded from disk.
+package profile
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/pdf"
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
+)
+
+// Profile formats.
+const (
+	// FormatEPUB is the format of profiles that apply to EPUB files.
+	FormatEPUB = "epub"
+	// FormatPDF is the format of profiles that apply to PDF files.
+	FormatPDF = "pdf"
+)
+
+// PathEnv names the environment variable listing extra directories that are
+// searched for named profiles, separated by the OS path list separator.
+const PathEnv = "EBM_PROFILE_PATH"
+
+// maxExtendsDepth bounds profile inheritance chains.
+const maxExtendsDepth = 8
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// Profile is a named set of extra rules and severity overrides layered onto
+// the base validators.
+type Profile struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Format      string            `yaml:"format,omitempty" json:"format,omitempty"`
+	Extends     string            `yaml:"extends,omitempty" json:"extends,omitempty"`
+	EPUB        epub.ProfileRules `yaml:"epub,omitempty" json:"epub,omitempty"`
+	PDF         pdf.ProfileRules  `yaml:"pdf,omitempty" json:"pdf,omitempty"`
+	Severity    map[string]string `yaml:"severity,omitempty" json:"severity,omitempty"`
+
+	overrides rules.Overrides
+	// formatDeclared records whether Format was set by the document rather
+	// than defaulted, so a profile extending a PDF profile need not repeat it.
+	formatDeclared bool
+}
+
+// Builtin returns the names of the built-in profiles in sorted order.
+func Builtin() []string {
+	entries, err := builtinFS.ReadDir("builtin")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load resolves a profile by built-in name, by name in one of the
+// EBM_PROFILE_PATH directories, or by path to a .yaml, .yml or .json file.
+// Inherited profiles named by Extends are resolved the same way.
+func Load(nameOrPath string) (*Profile, error) {
+	return load(nameOrPath, 0)
+}
+
+// Parse decodes a profile document. Unknown fields are rejected so typos in
+// rule names do not silently disable a rule. Extends is not resolved.
+func Parse(data []byte) (*Profile, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	p := &Profile{}
+	if err := decoder.Decode(p); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("profile is empty")
+		}
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	p.formatDeclared = p.Format != ""
+	if p.Format == "" {
+		p.Format = FormatEPUB
+	}
+	p.Format = strings.ToLower(p.Format)
+	if p.Format != FormatEPUB && p.Format != FormatPDF {
+		return nil, fmt.Errorf("profile %q: unsupported format %q", p.Name, p.Format)
+	}
+
+	overrides, err := rules.ParseOverrides(p.Severity)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	p.overrides = overrides
+	return p, nil
+}
+
+func load(nameOrPath string, depth int) (*Profile, error) {
+	if depth > maxExtendsDepth {
+		return nil, fmt.Errorf("profile %q: extends chain is deeper than %d", nameOrPath, maxExtendsDepth)
+	}
+
+	data, source, err := readProfile(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath))
+	}
+	if p.Extends == "" {
+		return p, nil
+	}
+
+	base, err := load(p.Extends, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q extends %q: %w", p.Name, p.Extends, err)
+	}
+	if !p.formatDeclared {
+		p.Format = base.Format
+	}
+	if base.Format != p.Format {
+		return nil, fmt.Errorf("profile %q (%s) cannot extend %q (%s)", p.Name, p.Format, base.Name, base.Format)
+	}
+	return merge(base, p), nil
+}
+
+func readProfile(nameOrPath string) ([]byte, string, error) {
+	if isProfilePath(nameOrPath) {
+		data, err := os.ReadFile(nameOrPath) //nolint:gosec
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read profile: %w", err)
+		}
+		return data, nameOrPath, nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(nameOrPath))
+	if data, err := builtinFS.ReadFile("builtin/" + name + ".yaml"); err == nil {
+		return data, "builtin profile " + name, nil
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv(PathEnv)) {
+		if dir == "" {
+			continue
+		}
+		for _, ext := range []string{".yaml", ".yml", ".json"} {
+			candidate := filepath.Join(dir, name+ext)
+			data, err := os.ReadFile(candidate) //nolint:gosec
+			if err == nil {
+				return data, candidate, nil
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("unknown profile %q (built-in profiles: %s)", nameOrPath, strings.Join(Builtin(), ", "))
+}
+
+func isProfilePath(nameOrPath string) bool {
+	switch strings.ToLower(filepath.Ext(nameOrPath)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return strings.ContainsAny(nameOrPath, `/\`)
+}
+
+// merge layers child over base: non-zero scalar rules in child replace those
+// in base, list rules are combined, and severity overrides in child win.
+func merge(base, child *Profile) *Profile {
+	merged := &Profile{
+		Name:        child.Name,
+		Description: child.Description,
+		Format:      child.Format,
+		Extends:     child.Extends,
+		EPUB:        base.EPUB,
+		PDF:         base.PDF,
+		Severity:    make(map[string]string, len(base.Severity)+len(child.Severity)),
+		overrides:   make(rules.Overrides, len(base.overrides)+len(child.overrides)),
+	}
+	if merged.Description == "" {
+		merged.Description = base.Description
+	}
+
+	if child.EPUB.MaxFileSize != 0 {
+		merged.EPUB.MaxFileSize = child.EPUB.MaxFileSize
+	}
+	if child.EPUB.MaxResourceSize != 0 {
+		merged.EPUB.MaxResourceSize = child.EPUB.MaxResourceSize
+	}
+	if child.EPUB.Cover != nil {
+		merged.EPUB.Cover = child.EPUB.Cover
+	}
+	merged.EPUB.RequiredMetadata = appendUnique(base.EPUB.RequiredMetadata, child.EPUB.RequiredMetadata)
+	merged.EPUB.RequiredFiles = appendUnique(base.EPUB.RequiredFiles, child.EPUB.RequiredFiles)
+	merged.EPUB.RecommendedMetadata = appendUnique(base.EPUB.RecommendedMetadata, child.EPUB.RecommendedMetadata)
+	merged.EPUB.RecommendedFiles = appendUnique(base.EPUB.RecommendedFiles, child.EPUB.RecommendedFiles)
+	merged.EPUB.BannedProperties = appendUnique(base.EPUB.BannedProperties, child.EPUB.BannedProperties)
+	merged.EPUB.BannedMediaTypes = appendUnique(base.EPUB.BannedMediaTypes, child.EPUB.BannedMediaTypes)
+
+	if child.PDF.Fonts.EmbedStandard14 {
+		merged.PDF.Fonts.EmbedStandard14 = true
+	}
+	if child.PDF.Print.MinImagePPI != 0 {
+		merged.PDF.Print.MinImagePPI = child.PDF.Print.MinImagePPI
+	}
+	if child.PDF.Print.RequireCMYK {
+		merged.PDF.Print.RequireCMYK = true
+	}
+	if child.PDF.Print.RequireTrimBox {
+		merged.PDF.Print.RequireTrimBox = true
+	}
+	if child.PDF.Print.RequireBleedBox {
+		merged.PDF.Print.RequireBleedBox = true
+	}
+
+	for _, source := range []*Profile{base, child} {
+		for code, level := range source.Severity {
+			merged.Severity[code] = level
+		}
+		for code, level := range source.overrides {
+			merged.overrides[code] = level
+		}
+	}
+
+	return merged
+}
+
+func appendUnique(base, extra []string) []string {
+	if len(base) == 0 && len(extra) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(base)+len(extra))
+	out := make([]string, 0, len(base)+len(extra))
+	for _, list := range [][]string{base, extra} {
+		for _, value := range list {
+			key := strings.ToLower(value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+// Overrides returns the profile's severity overrides.
+func (p *Profile) Overrides() rules.Overrides {
+	return p.overrides
+}
+
+// ApplyEPUB runs the profile rules against EPUB data, adds the findings to
+// report, and applies the profile severity overrides to every finding.
+func (p *Profile) ApplyEPUB(reader io.ReaderAt, size int64, report *domain.ValidationReport) error {
+	if p.Format != FormatEPUB {
+		return fmt.Errorf("profile %q applies to %s files, not EPUB", p.Name, p.Format)
+	}
+
+	result, err := epub.NewProfileValidator().Validate(reader, size, p.EPUB)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	result.AppendTo(report)
+
+	p.ApplyOverrides(report)
+	return nil
+}
+
+// ApplyPDF configures validator with the profile's PDF rules. Call it before
+// validating.
+func (p *Profile) ApplyPDF(validator *pdf.StructureValidator) error {
+	if p.Format != FormatPDF {
+		return fmt.Errorf("profile %q applies to %s files, not PDF", p.Name, p.Format)
+	}
+	validator.Fonts = p.PDF.Fonts
+	validator.Print = p.PDF.Print
+	return nil
+}
+
+// ApplyOverrides applies the profile severity overrides to every finding in
+// report and records the profile name. ApplyEPUB does this itself; PDF
+// reports need it after validation.
+func (p *Profile) ApplyOverrides(report *domain.ValidationReport) {
+	p.overrides.Apply(report)
+	report.IsValid = len(report.Errors) == 0
+
+	if report.Metadata == nil {
+		report.Metadata = make(map[string]interface{})
+	}
+	report.Metadata["profile"] = p.Name
+}
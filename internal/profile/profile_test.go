@@ -0,0 +1,288 @@
+package profile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/pdf"
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
+)
+
+func TestBuiltinProfilesLoad(t *testing.T) {
+	names := Builtin()
+	want := []string{"apple", "google-play", "kindle", "kobo", "print"}
+	formats := map[string]string{"print": FormatPDF}
+	if len(names) != len(want) {
+		t.Fatalf("Builtin() = %v, want %v", names, want)
+	}
+
+	for i, name := range names {
+		if name != want[i] {
+			t.Errorf("Builtin()[%d] = %q, want %q", i, name, want[i])
+		}
+
+		p, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q) failed: %v", name, err)
+		}
+		if p.Name != name {
+			t.Errorf("profile %q declares name %q", name, p.Name)
+		}
+		format := formats[name]
+		if format == "" {
+			format = FormatEPUB
+		}
+		if p.Format != format {
+			t.Errorf("profile %q format = %q, want %q", name, p.Format, format)
+		}
+	}
+}
+
+func TestBuiltinProfiles_HardRequirementsStayErrors(t *testing.T) {
+	for _, name := range []string{"apple", "google-play", "kindle", "kobo"} {
+		p, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q) failed: %v", name, err)
+		}
+		for _, code := range []string{"EPUB-PROFILE-003", "EPUB-PROFILE-004", "EPUB-PROFILE-005", "EPUB-PROFILE-006"} {
+			if level, ok := p.Overrides().Lookup(code); ok {
+				t.Errorf("profile %q sets %s to %s; advisory checks belong in recommended rules", name, code, level)
+			}
+		}
+	}
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	if _, err := Load("nook"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestLoad_FileExtendsBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "house.yaml")
+	content := `name: house
+extends: apple
+epub:
+  max_file_size: 1048576
+  required_metadata:
+    - dc:publisher
+severity:
+  EPUB-PROFILE-006: off
+  EPUB-LANG-*: error
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if p.Name != "house" {
+		t.Errorf("Name = %q, want house", p.Name)
+	}
+	if p.EPUB.MaxFileSize != 1048576 {
+		t.Errorf("MaxFileSize = %d, want child override", p.EPUB.MaxFileSize)
+	}
+	if p.EPUB.Cover == nil || p.EPUB.Cover.MinWidth != 1400 {
+		t.Errorf("expected cover rule inherited from apple, got %+v", p.EPUB.Cover)
+	}
+
+	metadata := make(map[string]bool)
+	for _, m := range p.EPUB.RequiredMetadata {
+		metadata[m] = true
+	}
+	for _, m := range []string{"dc:creator", "dc:publisher"} {
+		if !metadata[m] {
+			t.Errorf("expected required metadata %s, got %v", m, p.EPUB.RequiredMetadata)
+		}
+	}
+	if len(p.EPUB.RecommendedMetadata) != 1 || p.EPUB.RecommendedMetadata[0] != "ibooks:version" {
+		t.Errorf("expected recommended metadata inherited from apple, got %v", p.EPUB.RecommendedMetadata)
+	}
+
+	overrides := p.Overrides()
+	if level, _ := overrides.Lookup("EPUB-PROFILE-006"); level != rules.LevelOff {
+		t.Errorf("EPUB-PROFILE-006 level = %q, want off", level)
+	}
+	if level, ok := overrides.Lookup("EPUB-PROFILE-005"); ok {
+		t.Errorf("EPUB-PROFILE-005 level = %q, want required metadata kept at error", level)
+	}
+	if level, _ := overrides.Lookup("EPUB-LANG-001"); level != rules.LevelError {
+		t.Errorf("EPUB-LANG-001 level = %q, want error", level)
+	}
+
+	imprint := filepath.Join(dir, "imprint.yaml")
+	if err := os.WriteFile(imprint, []byte("name: imprint\nextends: "+path+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	child, err := Load(imprint)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if level, _ := child.Overrides().Lookup("EPUB-PROFILE-006"); level != rules.LevelOff {
+		t.Errorf("EPUB-PROFILE-006 level = %q, want inherited off", level)
+	}
+}
+
+func TestLoad_ProfilePathEnv(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"name": "store", "epub": {"banned_media_types": ["video/mp4"]}}`
+	if err := os.WriteFile(filepath.Join(dir, "store.json"), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	t.Setenv(PathEnv, dir)
+
+	p, err := Load("store")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(p.EPUB.BannedMediaTypes) != 1 || p.EPUB.BannedMediaTypes[0] != "video/mp4" {
+		t.Errorf("unexpected banned media types %v", p.EPUB.BannedMediaTypes)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: ""},
+		{name: "unknown rule", data: "name: x\nepub:\n  max_cover_size: 10\n"},
+		{name: "unknown format", data: "name: x\nformat: mobi\n"},
+		{name: "unknown pdf rule", data: "name: x\nformat: pdf\npdf:\n  print:\n    min_dpi: 300\n"},
+		{name: "bad level", data: "name: x\nseverity:\n  EPUB-OPF-001: loud\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("expected parse error")
+			}
+		})
+	}
+}
+
+func TestLoad_ExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	if err := os.WriteFile(a, []byte("name: a\nextends: "+b+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("name: b\nextends: "+a+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(a); err == nil {
+		t.Error("expected error for cyclic extends")
+	}
+}
+
+func TestProfile_ApplyEPUBOverrides(t *testing.T) {
+	p, err := Parse([]byte(`name: strict
+severity:
+  EPUB-LANG-001: error
+  EPUB-OPF-005: off
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	report := &domain.ValidationReport{
+		IsValid: false,
+		Errors: []domain.ValidationError{
+			{Code: "EPUB-OPF-005", Severity: domain.SeverityError},
+		},
+		Warnings: []domain.ValidationError{
+			{Code: "EPUB-LANG-001", Severity: domain.SeverityWarning},
+		},
+	}
+
+	// Not a ZIP: container problems are left to the base validators, so only
+	// the severity overrides change the report.
+	data := []byte("not an epub")
+	if err := p.ApplyEPUB(bytes.NewReader(data), int64(len(data)), report); err != nil {
+		t.Fatalf("ApplyEPUB failed: %v", err)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].Code != "EPUB-LANG-001" {
+		t.Errorf("expected EPUB-LANG-001 promoted to error only, got %v", report.Errors)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", report.Warnings)
+	}
+	if report.Metadata["profile"] != "strict" {
+		t.Errorf("expected profile recorded in metadata, got %v", report.Metadata)
+	}
+}
+
+func TestLoad_PDFExtendsPrint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "press.yaml")
+	content := `name: press
+extends: print
+pdf:
+  print:
+    min_image_ppi: 400
+severity:
+  PDF-PRINT-005: error
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := pdf.ProfileRules{
+		Fonts: pdf.FontRules{EmbedStandard14: true},
+		Print: pdf.PrintRules{MinImagePPI: 400, RequireCMYK: true, RequireTrimBox: true, RequireBleedBox: true},
+	}
+	if p.PDF != want {
+		t.Errorf("PDF rules = %+v, want %+v", p.PDF, want)
+	}
+
+	validator := pdf.NewStructureValidator()
+	if err := p.ApplyPDF(validator); err != nil {
+		t.Fatalf("ApplyPDF failed: %v", err)
+	}
+	if validator.Print != want.Print || validator.Fonts != want.Fonts {
+		t.Errorf("validator rules = %+v %+v, want %+v", validator.Fonts, validator.Print, want)
+	}
+	if err := p.ApplyEPUB(bytes.NewReader(nil), 0, &domain.ValidationReport{}); err == nil {
+		t.Error("expected error applying a PDF profile to an EPUB")
+	}
+
+	report := &domain.ValidationReport{
+		Warnings: []domain.ValidationError{
+			{Code: "PDF-PRINT-005", Severity: domain.SeverityWarning},
+		},
+	}
+	p.ApplyOverrides(report)
+	if len(report.Errors) != 1 || report.IsValid {
+		t.Errorf("expected PDF-PRINT-005 promoted to error, got %+v", report)
+	}
+	if report.Metadata["profile"] != "press" {
+		t.Errorf("expected profile recorded in metadata, got %v", report.Metadata)
+	}
+}
+
+func TestLoad_ExtendsOtherFormat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mixed.yaml")
+	if err := os.WriteFile(path, []byte("name: mixed\nformat: pdf\nextends: kobo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("expected error extending an EPUB profile from a PDF profile")
+	}
+}
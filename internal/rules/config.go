@@ -0,0 +1,254 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// ConfigFileNames lists the rule configuration file names, in lookup order.
+var ConfigFileNames = []string{".ebmrc.yaml", ".ebmrc.yml"}
+
+// Config is a project rule configuration, usually loaded from .ebmrc.yaml.
+type Config struct {
+	// Profile names the retailer profile applied when the caller does not select one.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	// Severity maps error codes or code patterns to error, warning, info or off.
+	Severity map[string]string `yaml:"severity,omitempty" json:"severity,omitempty"`
+	// Ignore suppresses findings by code and location path.
+	Ignore []IgnoreRule `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+
+	path      string
+	overrides Overrides
+}
+
+// IgnoreRule suppresses findings whose code matches one of Codes and whose
+// location path matches one of Paths. An empty Codes matches every code and
+// an empty Paths matches every location.
+type IgnoreRule struct {
+	Codes []string `yaml:"codes,omitempty" json:"codes,omitempty"`
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// FindConfig looks for a configuration file in startDir and each of its
+// parents, then in the user's home directory. An empty startDir means the
+// working directory. It returns an empty path when no file exists.
+func FindConfig(startDir string) (string, error) {
+	if startDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		startDir = wd
+	}
+
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", startDir, err)
+	}
+	for {
+		if found := configIn(dir); found != "" {
+			return found, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if found := configIn(home); found != "" {
+			return found, nil
+		}
+	}
+	return "", nil
+}
+
+func configIn(dir string) string {
+	for _, name := range ConfigFileNames {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads and parses the configuration file at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule configuration: %w", err)
+	}
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.path = path
+	return cfg, nil
+}
+
+// ParseConfig decodes a YAML or JSON rule configuration. Unknown fields are rejected.
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := &Config{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid rule configuration: %w", err)
+	}
+
+	overrides, err := ParseOverrides(cfg.Severity)
+	if err != nil {
+		return nil, err
+	}
+	cfg.overrides = overrides
+
+	for i, rule := range cfg.Ignore {
+		if len(rule.Codes) == 0 && len(rule.Paths) == 0 {
+			return nil, fmt.Errorf("ignore rule %d: codes or paths is required", i+1)
+		}
+		for _, pattern := range append(append([]string{}, rule.Codes...), rule.Paths...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("ignore rule %d: invalid pattern %q: %w", i+1, pattern, err)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Path returns the file the configuration was loaded from, if any.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Dir returns the directory of the configuration file, used to resolve
+// relative paths declared in it.
+func (c *Config) Dir() string {
+	if c.path == "" {
+		return ""
+	}
+	return filepath.Dir(c.path)
+}
+
+// Overrides returns the configured severity overrides.
+func (c *Config) Overrides() Overrides {
+	return c.overrides
+}
+
+// Apply applies the severity overrides and ignore rules to report and
+// recomputes its validity. Suppressed findings are removed and counted in
+// report.Metadata["suppressed"]. A nil Config leaves the report unchanged.
+func (c *Config) Apply(report *domain.ValidationReport) {
+	if c == nil || report == nil {
+		return
+	}
+
+	c.overrides.Apply(report)
+
+	suppressed := 0
+	if len(c.Ignore) > 0 {
+		keep := func(findings []domain.ValidationError) []domain.ValidationError {
+			kept := findings[:0]
+			for _, finding := range findings {
+				if !Suppressed(finding) && c.Ignored(finding) {
+					suppressed++
+					continue
+				}
+				kept = append(kept, finding)
+			}
+			return kept
+		}
+		report.Errors = keep(report.Errors)
+		report.Warnings = keep(report.Warnings)
+		report.Info = keep(report.Info)
+	}
+
+	report.IsValid = len(report.Errors) == 0
+
+	if report.Metadata == nil {
+		report.Metadata = make(map[string]interface{})
+	}
+	if c.path != "" {
+		report.Metadata["rule_config"] = c.path
+	}
+	if suppressed > 0 {
+		report.Metadata["suppressed"] = suppressed
+	}
+}
+
+// Ignored reports whether an ignore rule matches finding.
+func (c *Config) Ignored(finding domain.ValidationError) bool {
+	location := ""
+	if finding.Location != nil {
+		location = finding.Location.Path
+	}
+
+	for _, rule := range c.Ignore {
+		if !matchesAny(rule.Codes, finding.Code, path.Match) {
+			continue
+		}
+		if len(rule.Paths) > 0 && (location == "" || !matchesAny(rule.Paths, location, MatchPath)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func matchesAny(patterns []string, value string, match func(pattern, name string) (bool, error)) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether a slash-separated path matches pattern. It
+// extends path.Match with "**", which matches any number of path segments.
+func MatchPath(pattern, name string) (bool, error) {
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, segments []string) (bool, error) {
+	if len(pattern) == 0 {
+		return len(segments) == 0, nil
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			matched, err := matchSegments(pattern[1:], segments[i:])
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	}
+
+	if len(segments) == 0 {
+		return false, nil
+	}
+	matched, err := path.Match(pattern[0], segments[0])
+	if err != nil || !matched {
+		return false, err
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,174 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "OEBPS/legacy/**", name: "OEBPS/legacy/ch1.xhtml", want: true},
+		{pattern: "OEBPS/legacy/**", name: "OEBPS/legacy/part1/ch1.xhtml", want: true},
+		{pattern: "OEBPS/legacy/**", name: "OEBPS/text/ch1.xhtml", want: false},
+		{pattern: "**/*.xhtml", name: "OEBPS/text/ch1.xhtml", want: true},
+		{pattern: "**/*.xhtml", name: "ch1.xhtml", want: true},
+		{pattern: "OEBPS/*.xhtml", name: "OEBPS/text/ch1.xhtml", want: false},
+		{pattern: "OEBPS/**/notes.xhtml", name: "OEBPS/notes.xhtml", want: true},
+		{pattern: "/OEBPS/content.opf", name: "OEBPS/content.opf", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got, err := MatchPath(tt.pattern, tt.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "empty", data: ""},
+		{name: "full", data: `
+profile: apple
+severity:
+  EPUB-OPF-005: warning
+ignore:
+  - codes: [EPUB-CONTENT-002]
+    paths: ["OEBPS/legacy/**"]
+`},
+		{name: "json", data: `{"severity": {"EPUB-A11Y-*": "off"}}`},
+		{name: "unknown key", data: "severities:\n  EPUB-OPF-005: warning\n", wantErr: true},
+		{name: "bad level", data: "severity:\n  EPUB-OPF-005: maybe\n", wantErr: true},
+		{name: "empty ignore rule", data: "ignore:\n  - {}\n", wantErr: true},
+		{name: "bad ignore pattern", data: "ignore:\n  - paths: [\"OEBPS/[\"]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+severity:
+  EPUB-OPF-005: warning
+  EPUB-NAV-*: off
+ignore:
+  - codes: [EPUB-CONTENT-002]
+    paths: ["OEBPS/legacy/**"]
+  - paths: ["OEBPS/vendor/**"]
+`))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	at := func(code, path string) domain.ValidationError {
+		return domain.ValidationError{
+			Code:     code,
+			Severity: domain.SeverityError,
+			Location: &domain.ErrorLocation{File: filepath.Base(path), Path: path},
+		}
+	}
+
+	report := &domain.ValidationReport{
+		IsValid: false,
+		Errors: []domain.ValidationError{
+			at("EPUB-CONTENT-002", "OEBPS/legacy/ch1.xhtml"),
+			at("EPUB-CONTENT-002", "OEBPS/text/ch1.xhtml"),
+			at("EPUB-CONTENT-003", "OEBPS/vendor/widget.xhtml"),
+			at("EPUB-OPF-005", "OEBPS/content.opf"),
+			at("EPUB-NAV-002", "OEBPS/nav.xhtml"),
+		},
+	}
+
+	cfg.Apply(report)
+
+	if len(report.Errors) != 1 || report.Errors[0].Location.Path != "OEBPS/text/ch1.xhtml" {
+		t.Errorf("expected only the non-legacy content error to remain, got %v", report.Errors)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Code != "EPUB-OPF-005" {
+		t.Errorf("expected EPUB-OPF-005 downgraded to warning, got %v", report.Warnings)
+	}
+	if report.IsValid {
+		t.Error("expected report to remain invalid")
+	}
+	if report.Metadata["suppressed"] != 2 {
+		t.Errorf("expected 2 suppressed findings, got %v", report.Metadata["suppressed"])
+	}
+}
+
+func TestConfig_ApplyNil(t *testing.T) {
+	var cfg *Config
+	report := &domain.ValidationReport{Errors: []domain.ValidationError{{Code: "EPUB-OPF-001"}}}
+	cfg.Apply(report)
+	if len(report.Errors) != 1 {
+		t.Errorf("nil config must not change the report, got %v", report.Errors)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := t.TempDir()
+	nested := filepath.Join(project, "books", "series")
+	if err := os.MkdirAll(nested, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := FindConfig(nested)
+	if err != nil {
+		t.Fatalf("FindConfig failed: %v", err)
+	}
+	if found != "" {
+		t.Fatalf("expected no config, found %s", found)
+	}
+
+	homeConfig := filepath.Join(home, ".ebmrc.yaml")
+	if err := os.WriteFile(homeConfig, []byte("severity: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	found, err = FindConfig(nested)
+	if err != nil || found != homeConfig {
+		t.Fatalf("expected home config %s, got %q (%v)", homeConfig, found, err)
+	}
+
+	projectConfig := filepath.Join(project, ".ebmrc.yml")
+	if err := os.WriteFile(projectConfig, []byte("severity: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	found, err = FindConfig(nested)
+	if err != nil || found != projectConfig {
+		t.Fatalf("expected project config %s, got %q (%v)", projectConfig, found, err)
+	}
+
+	cfg, err := LoadConfig(found)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.Path() != projectConfig || cfg.Dir() != project {
+		t.Errorf("unexpected config location %s (%s)", cfg.Path(), cfg.Dir())
+	}
+}
@@ -0,0 +1,6 @@
+// Package rules adjusts validation findings after validation has run:
+// severity overrides reclassify or disable findings by error code, and a
+// project configuration (.ebmrc.yaml) adds path-based suppression on top.
+// Findings that were already suppressed by an inline ebm-ignore comment in
+// the content are kept as info and are not reclassified again.
+package rules
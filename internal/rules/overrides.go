@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// DetailSuppressedBy is the finding detail set when an inline directive in
+// the validated content suppressed the finding. Such findings are already
+// reported as info and are left alone by overrides and ignore rules.
+const DetailSuppressedBy = "suppressed_by"
+
+// Level is the configured severity of a rule.
+type Level string
+
+const (
+	// LevelError reports matching findings as errors.
+	LevelError Level = "error"
+	// LevelWarning reports matching findings as warnings.
+	LevelWarning Level = "warning"
+	// LevelInfo reports matching findings as informational.
+	LevelInfo Level = "info"
+	// LevelOff removes matching findings from the report.
+	LevelOff Level = "off"
+)
+
+// ParseLevel parses a configured rule level.
+func ParseLevel(raw string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "error", "errors":
+		return LevelError, nil
+	case "warning", "warnings", "warn":
+		return LevelWarning, nil
+	case "info", "information":
+		return LevelInfo, nil
+	case "off", "disable", "disabled", "ignore":
+		return LevelOff, nil
+	default:
+		return "", fmt.Errorf("unsupported rule level %q", raw)
+	}
+}
+
+// Overrides maps error codes to configured levels. Keys may be exact codes
+// such as "EPUB-OPF-005" or path.Match patterns such as "EPUB-A11Y-*".
+// Exact codes take precedence over patterns.
+type Overrides map[string]Level
+
+// ParseOverrides converts raw code-to-level pairs into Overrides.
+func ParseOverrides(raw map[string]string) (Overrides, error) {
+	overrides := make(Overrides, len(raw))
+	for code, value := range raw {
+		if _, err := path.Match(code, ""); err != nil {
+			return nil, fmt.Errorf("invalid code pattern %q: %w", code, err)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("code %s: %w", code, err)
+		}
+		overrides[code] = level
+	}
+	return overrides, nil
+}
+
+// Lookup returns the level configured for code, if any.
+func (o Overrides) Lookup(code string) (Level, bool) {
+	if level, ok := o[code]; ok {
+		return level, true
+	}
+
+	patterns := make([]string, 0, len(o))
+	for pattern := range o {
+		if strings.ContainsAny(pattern, "*?[") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	// Prefer the most specific (longest) pattern so results do not depend on map order.
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, code); matched {
+			return o[pattern], true
+		}
+	}
+	return "", false
+}
+
+// Apply reclassifies the findings in report according to the overrides and
+// recomputes report validity. Findings suppressed inline are not changed.
+func (o Overrides) Apply(report *domain.ValidationReport) {
+	if report == nil || len(o) == 0 {
+		return
+	}
+
+	findings := make([]domain.ValidationError, 0, report.TotalIssues())
+	findings = append(findings, report.Errors...)
+	findings = append(findings, report.Warnings...)
+	findings = append(findings, report.Info...)
+
+	report.Errors = make([]domain.ValidationError, 0, len(report.Errors))
+	report.Warnings = make([]domain.ValidationError, 0, len(report.Warnings))
+	report.Info = make([]domain.ValidationError, 0, len(report.Info))
+
+	for _, finding := range findings {
+		if Suppressed(finding) {
+			Place(report, finding)
+			continue
+		}
+		if level, ok := o.Lookup(finding.Code); ok {
+			if level == LevelOff {
+				continue
+			}
+			finding.Severity = level.Severity()
+		}
+		Place(report, finding)
+	}
+
+	report.IsValid = len(report.Errors) == 0
+}
+
+// Suppressed reports whether finding was suppressed by an inline directive.
+func Suppressed(finding domain.ValidationError) bool {
+	_, ok := finding.Details[DetailSuppressedBy]
+	return ok
+}
+
+// Severity returns the domain severity for the level. LevelOff has no
+// severity and returns an empty value.
+func (l Level) Severity() domain.Severity {
+	switch l {
+	case LevelError:
+		return domain.SeverityError
+	case LevelWarning:
+		return domain.SeverityWarning
+	case LevelInfo:
+		return domain.SeverityInfo
+	case LevelOff:
+		return ""
+	default:
+		return ""
+	}
+}
+
+// Place appends finding to the report bucket matching its severity.
+func Place(report *domain.ValidationReport, finding domain.ValidationError) {
+	switch finding.Severity {
+	case domain.SeverityError:
+		report.Errors = append(report.Errors, finding)
+	case domain.SeverityWarning:
+		report.Warnings = append(report.Warnings, finding)
+	case domain.SeverityInfo:
+		report.Info = append(report.Info, finding)
+	default:
+		report.Errors = append(report.Errors, finding)
+	}
+}
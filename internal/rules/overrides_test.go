@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Level
+		wantErr bool
+	}{
+		{raw: "error", want: LevelError},
+		{raw: "Warning", want: LevelWarning},
+		{raw: "info", want: LevelInfo},
+		{raw: "off", want: LevelOff},
+		{raw: "disabled", want: LevelOff},
+		{raw: "fatal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseLevel(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrides_Lookup(t *testing.T) {
+	overrides := Overrides{
+		"EPUB-A11Y-*":   LevelInfo,
+		"EPUB-A11Y-00*": LevelWarning,
+		"EPUB-A11Y-001": LevelError,
+	}
+
+	tests := []struct {
+		code   string
+		want   Level
+		wantOK bool
+	}{
+		{code: "EPUB-A11Y-001", want: LevelError, wantOK: true},
+		{code: "EPUB-A11Y-005", want: LevelWarning, wantOK: true},
+		{code: "EPUB-A11Y-012", want: LevelInfo, wantOK: true},
+		{code: "EPUB-OPF-001", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, ok := overrides.Lookup(tt.code)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Lookup(%q) = %q, %v; want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestOverrides_Apply(t *testing.T) {
+	report := &domain.ValidationReport{
+		IsValid: false,
+		Errors: []domain.ValidationError{
+			{Code: "EPUB-OPF-001", Severity: domain.SeverityError},
+			{Code: "EPUB-PROFILE-005", Severity: domain.SeverityError},
+		},
+		Warnings: []domain.ValidationError{
+			{Code: "EPUB-LANG-001", Severity: domain.SeverityWarning},
+		},
+	}
+
+	overrides, err := ParseOverrides(map[string]string{
+		"EPUB-OPF-001":     "off",
+		"EPUB-PROFILE-*":   "warning",
+		"EPUB-LANG-001":    "info",
+		"EPUB-UNUSED-0001": "error",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	overrides.Apply(report)
+
+	if !report.IsValid {
+		t.Errorf("expected report to become valid, errors: %v", report.Errors)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("expected no errors, got %v", report.Errors)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Code != "EPUB-PROFILE-005" || report.Warnings[0].Severity != domain.SeverityWarning {
+		t.Errorf("expected EPUB-PROFILE-005 downgraded to warning, got %v", report.Warnings)
+	}
+	if len(report.Info) != 1 || report.Info[0].Code != "EPUB-LANG-001" || report.Info[0].Severity != domain.SeverityInfo {
+		t.Errorf("expected EPUB-LANG-001 downgraded to info, got %v", report.Info)
+	}
+}
+
+func TestParseOverrides_Invalid(t *testing.T) {
+	if _, err := ParseOverrides(map[string]string{"EPUB-OPF-001": "loud"}); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if _, err := ParseOverrides(map[string]string{"EPUB-[": "off"}); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+func TestOverrides_ApplyKeepsInlineSuppressed(t *testing.T) {
+	report := &domain.ValidationReport{
+		IsValid: true,
+		Info: []domain.ValidationError{
+			{
+				Code:     "EPUB-A11Y-006",
+				Severity: domain.SeverityInfo,
+				Details:  map[string]interface{}{DetailSuppressedBy: "ebm-ignore"},
+			},
+		},
+	}
+
+	overrides, err := ParseOverrides(map[string]string{"EPUB-A11Y-*": "error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	overrides.Apply(report)
+
+	if len(report.Errors) != 0 || len(report.Info) != 1 {
+		t.Errorf("expected inline-suppressed finding to stay info, got errors %v info %v", report.Errors, report.Info)
+	}
+	if !report.IsValid {
+		t.Error("expected report to remain valid")
+	}
+}
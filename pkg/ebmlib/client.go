@@ -1,15 +1,21 @@
 package ebmlib
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
 	"github.com/petergi/ebook-mechanic-lib/internal/adapters/pdf"
 	"github.com/petergi/ebook-mechanic-lib/internal/adapters/reporter"
 	"github.com/petergi/ebook-mechanic-lib/internal/domain"
 	"github.com/petergi/ebook-mechanic-lib/internal/ports"
+	"github.com/petergi/ebook-mechanic-lib/internal/profile"
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
 )
 
 // ValidationReport contains the results of ebook validation including errors, warnings, and metadata.
@@ -89,6 +95,8 @@ func ValidateEPUB(filePath string) (*ValidationReport, error) {
 
 // ValidateEPUBWithContext validates an EPUB file with a context for cancellation and timeout support.
 // The context can be used to cancel long-running validations or enforce timeouts.
+// A .ebmrc.yaml rule configuration found in the working directory, its parents or
+// the home directory is applied to the report (see ValidateOptions).
 //
 // Example:
 //
@@ -96,8 +104,7 @@ func ValidateEPUB(filePath string) (*ValidationReport, error) {
 //	defer cancel()
 //	report, err := ebmlib.ValidateEPUBWithContext(ctx, "book.epub")
 func ValidateEPUBWithContext(ctx context.Context, filePath string) (*ValidationReport, error) {
-	validator := epub.NewEPUBValidator()
-	return validator.ValidateFile(ctx, filePath)
+	return ValidateEPUBWithOptions(ctx, filePath, ValidateOptions{})
 }
 
 // ValidateEPUBReader validates an EPUB from an io.Reader.
@@ -117,8 +124,111 @@ func ValidateEPUBReader(reader io.Reader, size int64) (*ValidationReport, error)
 // ValidateEPUBReaderWithContext validates an EPUB from an io.Reader with context support.
 // Combines the benefits of ValidateEPUBReader and context-aware operations.
 func ValidateEPUBReaderWithContext(ctx context.Context, reader io.Reader, size int64) (*ValidationReport, error) {
+	return ValidateEPUBReaderWithOptions(ctx, reader, size, ValidateOptions{})
+}
+
+// ValidateEPUBWithOptions validates an EPUB file and then applies the options:
+// custom rules (see RegisterRule), a retailer profile that adds rules and
+// adjusts severities, and finally the project rule configuration (.ebmrc.yaml)
+// that overrides severities and suppresses findings.
+//
+// Example:
+//
+//	report, err := ebmlib.ValidateEPUBWithOptions(ctx, "book.epub", ebmlib.ValidateOptions{Profile: "apple"})
+func ValidateEPUBWithOptions(ctx context.Context, filePath string, opts ValidateOptions) (*ValidationReport, error) {
+	cfg, err := loadRuleConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadProfile(opts, cfg, profile.FormatEPUB)
+	if err != nil {
+		return nil, err
+	}
+
 	validator := epub.NewEPUBValidator()
-	return validator.ValidateReader(ctx, reader, size)
+	report, err := validator.ValidateFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleSet := customRules(opts)
+	if p != nil || len(ruleSet) > 0 {
+		file, err := os.Open(filePath) //nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("failed to open EPUB file: %w", err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat EPUB file: %w", err)
+		}
+
+		if err := runRules(ctx, file, info.Size(), ruleSet, report); err != nil {
+			return nil, err
+		}
+		if p != nil {
+			if err := p.ApplyEPUB(file, info.Size(), report); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cfg.Apply(report)
+	return report, nil
+}
+
+// ValidateEPUBReaderWithOptions validates an EPUB from an io.Reader and then applies the options.
+func ValidateEPUBReaderWithOptions(ctx context.Context, reader io.Reader, size int64, opts ValidateOptions) (*ValidationReport, error) {
+	cfg, err := loadRuleConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	p, err := loadProfile(opts, cfg, profile.FormatEPUB)
+	if err != nil {
+		return nil, err
+	}
+
+	validator := epub.NewEPUBValidator()
+	ruleSet := customRules(opts)
+	if p == nil && len(ruleSet) == 0 {
+		report, err := validator.ValidateReader(ctx, reader, size)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Apply(report)
+		return report, nil
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EPUB data: %w", err)
+	}
+
+	report, err := validator.ValidateReader(ctx, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runRules(ctx, bytes.NewReader(data), int64(len(data)), ruleSet, report); err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if err := p.ApplyEPUB(bytes.NewReader(data), int64(len(data)), report); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Apply(report)
+	return report, nil
+}
+
+// Profiles returns the names of the built-in retailer profiles.
+// Custom profiles can be passed to ValidateOptions.Profile as a file path,
+// or by name when their directory is listed in the EBM_PROFILE_PATH environment variable.
+func Profiles() []string {
+	return profile.Builtin()
 }
 
 // ValidatePDF validates a PDF file at the given path.
@@ -143,19 +253,15 @@ func ValidatePDF(filePath string) (*ValidationReport, error) {
 
 // ValidatePDFWithContext validates a PDF file with a context for cancellation and timeout support.
 // The context can be used to cancel long-running validations or enforce timeouts.
+// A discovered .ebmrc.yaml rule configuration is applied as for EPUB.
 //
 // Example:
 //
 //	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 //	defer cancel()
 //	report, err := ebmlib.ValidatePDFWithContext(ctx, "document.pdf")
-func ValidatePDFWithContext(_ context.Context, filePath string) (*ValidationReport, error) {
-	validator := pdf.NewStructureValidator()
-	result, err := validator.ValidateFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-	return convertPDFValidationResult(filePath, result), nil
+func ValidatePDFWithContext(ctx context.Context, filePath string) (*ValidationReport, error) {
+	return ValidatePDFWithOptions(ctx, filePath, ValidateOptions{})
 }
 
 // ValidatePDFReader validates a PDF from an io.Reader.
@@ -172,13 +278,136 @@ func ValidatePDFReader(reader io.Reader) (*ValidationReport, error) {
 
 // ValidatePDFReaderWithContext validates a PDF from an io.Reader with context support.
 // Combines the benefits of ValidatePDFReader and context-aware operations.
-func ValidatePDFReaderWithContext(_ context.Context, reader io.Reader) (*ValidationReport, error) {
-	validator := pdf.NewStructureValidator()
+func ValidatePDFReaderWithContext(ctx context.Context, reader io.Reader) (*ValidationReport, error) {
+	return ValidatePDFReaderWithOptions(ctx, reader, ValidateOptions{})
+}
+
+// ValidatePDFWithOptions validates a PDF file and then applies the options: a
+// PDF profile such as "print" that enables extra checks and adjusts
+// severities, and then the project rule configuration. Selecting an EPUB
+// profile is an error.
+//
+// Example:
+//
+//	report, err := ebmlib.ValidatePDFWithOptions(ctx, "book.pdf", ebmlib.ValidateOptions{Profile: "print"})
+func ValidatePDFWithOptions(_ context.Context, filePath string, opts ValidateOptions) (*ValidationReport, error) {
+	cfg, err := loadRuleConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	validator, p, err := newPDFValidator(opts, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := validator.ValidateFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := convertPDFValidationResult(filePath, result)
+	if p != nil {
+		p.ApplyOverrides(report)
+	}
+	cfg.Apply(report)
+	return report, nil
+}
+
+// ValidatePDFReaderWithOptions validates a PDF from an io.Reader and then applies the options.
+func ValidatePDFReaderWithOptions(_ context.Context, reader io.Reader, opts ValidateOptions) (*ValidationReport, error) {
+	cfg, err := loadRuleConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	validator, p, err := newPDFValidator(opts, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := validator.ValidateReader(reader)
 	if err != nil {
 		return nil, err
 	}
-	return convertPDFValidationResult("", result), nil
+
+	report := convertPDFValidationResult("", result)
+	if p != nil {
+		p.ApplyOverrides(report)
+	}
+	cfg.Apply(report)
+	return report, nil
+}
+
+// newPDFValidator returns a structure validator configured by opts and the
+// selected PDF profile, and the profile itself.
+func newPDFValidator(opts ValidateOptions, cfg *rules.Config) (*pdf.StructureValidator, *profile.Profile, error) {
+	p, err := loadProfile(opts, cfg, profile.FormatPDF)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validator := pdf.NewStructureValidator()
+	validator.Deep = opts.Deep
+	validator.Password = opts.Password
+	if p != nil {
+		if err := p.ApplyPDF(validator); err != nil {
+			return nil, nil, err
+		}
+	}
+	return validator, p, nil
+}
+
+// loadRuleConfig loads the rule configuration selected by opts, or nil when
+// no configuration applies.
+func loadRuleConfig(opts ValidateOptions) (*rules.Config, error) {
+	if opts.NoConfig {
+		return nil, nil
+	}
+	if opts.ConfigPath != "" {
+		return rules.LoadConfig(opts.ConfigPath)
+	}
+
+	path, err := rules.FindConfig("")
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return rules.LoadConfig(path)
+}
+
+// loadProfile loads the profile selected by opts, falling back to the profile
+// named in the rule configuration. Relative profile paths in a configuration
+// file are resolved against the file's directory. A configured profile for
+// another format is ignored, so one configuration can serve EPUB and PDF;
+// selecting one in opts is an error.
+func loadProfile(opts ValidateOptions, cfg *rules.Config, format string) (*profile.Profile, error) {
+	if opts.Profile != "" {
+		p, err := profile.Load(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		if p.Format != format {
+			return nil, fmt.Errorf("profile %q applies to %s files, not %s", p.Name, strings.ToUpper(p.Format), strings.ToUpper(format))
+		}
+		return p, nil
+	}
+
+	var name string
+	if cfg != nil && cfg.Profile != "" {
+		name = cfg.Profile
+		if !filepath.IsAbs(name) {
+			candidate := filepath.Join(cfg.Dir(), name)
+			if _, err := os.Stat(candidate); err == nil {
+				name = candidate
+			}
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+	p, err := profile.Load(name)
+	if err != nil || p.Format != format {
+		return nil, err
+	}
+	return p, nil
 }
 
 // RepairEPUB attempts to automatically repair an EPUB file.
@@ -524,9 +753,58 @@ func convertPDFValidationResult(filePath string, result *pdf.StructureValidation
 			Code:     err.Code,
 			Message:  err.Message,
 			Severity: SeverityError,
+			Location: pdfLocation(filePath, err.Location),
 			Details:  err.Details,
 		})
 	}
+	for _, warning := range result.Warnings {
+		report.Warnings = append(report.Warnings, ValidationError{
+			Code:     warning.Code,
+			Message:  warning.Message,
+			Severity: SeverityWarning,
+			Location: pdfLocation(filePath, warning.Location),
+			Details:  warning.Details,
+		})
+	}
+	for _, info := range result.Info {
+		report.Info = append(report.Info, ValidationError{
+			Code:     info.Code,
+			Message:  info.Message,
+			Severity: SeverityInfo,
+			Location: pdfLocation(filePath, info.Location),
+			Details:  info.Details,
+		})
+	}
+	report.Metadata["page_count"] = result.PageCount
+	report.Metadata["revision_count"] = result.RevisionCount()
+	report.Metadata["linearized"] = result.Linearization != nil && !result.Linearization.Updated
+	report.Metadata["pdf_version"] = result.Version
+	report.Metadata["required_version"] = result.RequiredVersion
+	report.Metadata["encrypted"] = result.Encryption != nil
+	if info := result.Encryption; info != nil {
+		report.Metadata["encryption"] = map[string]interface{}{
+			"handler":                info.Handler,
+			"revision":               info.Revision,
+			"algorithm":              info.Algorithm,
+			"key_length":             info.KeyLength,
+			"permissions":            info.Permissions,
+			"restricted":             info.Restricted,
+			"opens_without_password": info.OpensWithoutPassword,
+		}
+	}
 
 	return report
 }
+
+// pdfLocation converts the object a PDF finding applies to into a report
+// location, or nil when the finding has none.
+func pdfLocation(filePath string, location *pdf.ObjectLocation) *ErrorLocation {
+	if location == nil {
+		return nil
+	}
+	return &ErrorLocation{
+		File:    filePath,
+		Path:    fmt.Sprintf("%d %d obj", location.Object, location.Generation),
+		Context: fmt.Sprintf("offset %d", location.Offset),
+	}
+}
@@ -0,0 +1,132 @@
+package ebmlib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateEPUB_InlineSuppression(t *testing.T) {
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`,
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Suppression Test</dc:title>
+    <dc:identifier id="book-id">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>Contents</title></head>
+<body><nav epub:type="toc"><ol><li><a href="chapter1.xhtml">Chapter 1</a></li></ol></nav></body>
+</html>`,
+		"OEBPS/chapter1.xhtml": `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" lang="en" xml:lang="en">
+<head><title>Chapter 1</title></head>
+<body>
+<h1>Chapter 1</h1>
+<!-- ebm-ignore EPUB-LANG-003 quoted as printed -->
+<p lang="fr" xml:lang="fr-CA">Bonjour</p>
+<p lang="de" xml:lang="en">Hallo</p>
+</body>
+</html>`,
+	}
+
+	path := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(path, buildTestEPUB(t, files), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ValidateEPUB(path)
+	if err != nil {
+		t.Fatalf("ValidateEPUB failed: %v", err)
+	}
+
+	var reported, suppressed []ValidationError
+	for _, finding := range report.Errors {
+		if finding.Code == "EPUB-LANG-003" {
+			reported = append(reported, finding)
+		}
+	}
+	for _, finding := range report.Info {
+		if finding.Code == "EPUB-LANG-003" {
+			suppressed = append(suppressed, finding)
+		}
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0].Message, `lang="de"`) {
+		t.Errorf("expected the unmarked paragraph to stay an error, got %v", reported)
+	}
+	if len(suppressed) != 1 || suppressed[0].Details["suppressed_by"] != "ebm-ignore" || suppressed[0].Details["original_severity"] != "error" {
+		t.Errorf("expected the marked paragraph reported as info, got %v", suppressed)
+	}
+}
+
+func TestValidateEPUB_DiscoversConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".ebmrc.yaml"), []byte("severity:\n  EPUB-CONTAINER-013: \"off\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	epubPath := filepath.Join(project, "book.epub")
+	if err := os.WriteFile(epubPath, validTestEPUB(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	report, err := ValidateEPUB(epubPath)
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	if hasCode(report.Info, "EPUB-CONTAINER-013") || report.Metadata["rule_config"] == nil {
+		t.Errorf("expected the discovered .ebmrc.yaml to turn EPUB-CONTAINER-013 off, got info %v and metadata %v", report.Info, report.Metadata)
+	}
+
+	report, err = ValidateEPUBWithOptions(context.Background(), epubPath, ValidateOptions{NoConfig: true})
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+	if !hasCode(report.Info, "EPUB-CONTAINER-013") {
+		t.Errorf("expected NoConfig to skip .ebmrc.yaml, got %v", report.Info)
+	}
+}
+
+func hasCode(findings []ValidationError, code string) bool {
+	for _, finding := range findings {
+		if finding.Code == code {
+			return true
+		}
+	}
+	return false
+}
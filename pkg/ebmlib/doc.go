@@ -103,6 +103,57 @@
 //	}
 //	output, err := ebmlib.FormatReportWithOptions(ctx, report, options)
 //
+// # Custom Rules
+//
+// House-style checks can run inside ValidateEPUB. A Rule receives a parsed
+// Publication (container, OPF Package, manifest and spine, with lazy access to
+// resource content and parsed XHTML) and returns ValidationErrors:
+//
+//	err := ebmlib.RegisterRule(ebmlib.NewRule("house/copyright",
+//	    func(ctx context.Context, pub *ebmlib.Publication) ([]ebmlib.ValidationError, error) {
+//	        if pub.Resource("copyright") == nil {
+//	            return []ebmlib.ValidationError{{Code: "HOUSE-001", Message: "Copyright page is missing"}}, nil
+//	        }
+//	        return nil, nil
+//	    }))
+//
+// Rules can also be passed for a single call through ValidateOptions.Rules.
+// Custom findings are subject to retailer profiles and .ebmrc.yaml like any
+// built-in code.
+//
+// # Reading Publications
+//
+// OpenEPUB parses an EPUB into a read-only Publication without validating it.
+// Metadata is typed (titles, creators with roles, identifiers, subjects,
+// series), Manifest and Spine resolve each item to its archive path with lazy
+// content readers, and TOC is the table of contents tree from the navigation
+// document or the NCX:
+//
+//	pub, err := ebmlib.OpenEPUB("book.epub")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pub.Close()
+//
+//	fmt.Println(pub.Metadata.MainTitle(), pub.Metadata.Authors())
+//	for _, entry := range pub.TOC {
+//	    fmt.Println(entry.Title, entry.Path)
+//	}
+//
+// UpdateEPUBMetadata edits the package metadata in place. Only the fields set
+// in MetadataChanges change, dcterms:modified is refreshed, and the rest of the
+// OPF is preserved byte for byte:
+//
+//	title := "The Corrected Title"
+//	err := ebmlib.UpdateEPUBMetadata("book.epub", ebmlib.MetadataChanges{Title: &title})
+//
+// InspectEPUB returns an Inventory of word counts, reading time and page
+// estimates, images, fonts, the largest resources and the TOC shape, which
+// FormatInventory renders as JSON, text or Markdown:
+//
+//	inventory, err := ebmlib.InspectEPUB("book.epub")
+//	fmt.Println(inventory.TotalWords, inventory.EstimatedPages)
+//
 // # Working with Readers
 //
 // The library supports validation from io.Reader for both file and stream processing:
@@ -157,6 +208,8 @@
 //   - RepairResult: Contains repair operation results
 //   - RepairPreview: Preview of repair actions
 //   - ReportOptions: Configuration for report formatting
+//   - Publication, Resource, Metadata, TOCEntry: Parsed EPUB model
+//   - Inventory: EPUB content statistics and resources
 //
 // # Thread Safety
 //
@@ -0,0 +1,78 @@
+package ebmlib
+
+import (
+	"context"
+	"io"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/reporter"
+	"github.com/petergi/ebook-mechanic-lib/internal/domain"
+)
+
+// Inventory describes what a publication contains: word and character counts
+// per spine document, reading time and page estimates, images by media type,
+// fonts, the largest resources, TOC shape, languages, version and rendition.
+type Inventory = domain.Inventory
+
+// DocumentStats holds the text statistics of one spine document.
+type DocumentStats = domain.DocumentStats
+
+// MediaTypeStats counts the resources of one media type.
+type MediaTypeStats = domain.MediaTypeStats
+
+// ResourceInfo identifies a resource and its uncompressed size.
+type ResourceInfo = domain.ResourceInfo
+
+// InspectEPUB builds the inventory of the EPUB at filePath without validating
+// it. Reading time assumes 250 words per minute and page estimates 250 words
+// per page.
+//
+// Example:
+//
+//	inventory, err := ebmlib.InspectEPUB("book.epub")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d words, about %d pages\n", inventory.TotalWords, inventory.EstimatedPages)
+func InspectEPUB(filePath string) (*Inventory, error) {
+	pub, err := OpenEPUB(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = pub.Close()
+	}()
+
+	inventory := epub.NewInspector().Inspect(pub)
+	inventory.FilePath = filePath
+	return inventory, nil
+}
+
+// InspectEPUBReader builds the inventory of an EPUB read from an io.ReaderAt
+// of the given size.
+func InspectEPUBReader(reader io.ReaderAt, size int64) (*Inventory, error) {
+	pub, err := OpenEPUBReader(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	return epub.NewInspector().Inspect(pub), nil
+}
+
+// FormatInventory formats an inventory as JSON, text or Markdown, following
+// options.Format. Verbose text output lists every spine document.
+func FormatInventory(ctx context.Context, inventory *Inventory, options *ReportOptions) (string, error) {
+	rep, err := reporter.NewInventoryReporter(options.Format)
+	if err != nil {
+		return "", err
+	}
+	return rep.FormatInventory(ctx, inventory, options)
+}
+
+// WriteInventory writes a formatted inventory to writer.
+func WriteInventory(ctx context.Context, inventory *Inventory, writer io.Writer, options *ReportOptions) error {
+	rep, err := reporter.NewInventoryReporter(options.Format)
+	if err != nil {
+		return err
+	}
+	return rep.WriteInventory(ctx, inventory, writer, options)
+}
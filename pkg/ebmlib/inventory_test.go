@@ -0,0 +1,42 @@
+package ebmlib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInspectEPUB(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(filePath, validTestEPUB(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory, err := InspectEPUB(filePath)
+	if err != nil {
+		t.Fatalf("InspectEPUB failed: %v", err)
+	}
+	if inventory.FilePath != filePath || inventory.Title != "Rule Test" || inventory.Version != "3.0" {
+		t.Errorf("unexpected inventory header: %+v", inventory)
+	}
+	if len(inventory.Documents) != 1 || inventory.TotalWords != 4 || inventory.TOCEntries != 1 {
+		t.Errorf("unexpected statistics: %+v", inventory)
+	}
+
+	for _, format := range []OutputFormat{FormatJSON, FormatText, FormatMarkdown} {
+		output, err := FormatInventory(context.Background(), inventory, &ReportOptions{Format: format})
+		if err != nil {
+			t.Errorf("FormatInventory(%s) failed: %v", format, err)
+			continue
+		}
+		if !strings.Contains(output, "book.epub") {
+			t.Errorf("FormatInventory(%s) output misses the file path:\n%s", format, output)
+		}
+	}
+
+	if _, err := InspectEPUB(filepath.Join(t.TempDir(), "missing.epub")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
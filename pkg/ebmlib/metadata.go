@@ -0,0 +1,56 @@
+package ebmlib
+
+import (
+	"errors"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
+)
+
+// MetadataChanges describes edits to EPUB package metadata. Nil fields are
+// left untouched; a non-nil field replaces every existing entry of its kind,
+// except Title, which replaces only the main title.
+type MetadataChanges = epub.MetadataChanges
+
+// CoverImage is a cover image to store in an EPUB and mark as the cover.
+type CoverImage = epub.CoverImage
+
+// AccessibilityMetadata is the schema.org accessibility metadata of an EPUB.
+type AccessibilityMetadata = epub.AccessibilityMetadata
+
+// ReadEPUBMetadata returns the typed package metadata of the EPUB at filePath.
+func ReadEPUBMetadata(filePath string) (*Metadata, error) {
+	pub, err := OpenEPUB(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = pub.Close()
+	}()
+
+	metadata := pub.Metadata
+	return &metadata, nil
+}
+
+// UpdateEPUBMetadata applies changes to the EPUB at filePath in place.
+// dcterms:modified is refreshed, and XML outside the edited elements is
+// preserved.
+//
+// Example:
+//
+//	title := "The Corrected Title"
+//	err := ebmlib.UpdateEPUBMetadata("book.epub", ebmlib.MetadataChanges{
+//	    Title:    &title,
+//	    Creators: []ebmlib.Creator{{Name: "Jane Doe", FileAs: "Doe, Jane", Roles: []string{"aut"}}},
+//	})
+func UpdateEPUBMetadata(filePath string, changes MetadataChanges) error {
+	return UpdateEPUBMetadataTo(filePath, filePath, changes)
+}
+
+// UpdateEPUBMetadataTo applies changes to the EPUB at filePath and writes the
+// result to outputPath, leaving filePath unchanged.
+func UpdateEPUBMetadataTo(filePath, outputPath string, changes MetadataChanges) error {
+	if changes.IsEmpty() {
+		return errors.New("no metadata changes requested")
+	}
+	return epub.WriteMetadata(filePath, outputPath, changes)
+}
@@ -0,0 +1,52 @@
+package ebmlib
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateEPUBMetadata(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(filePath, validTestEPUB(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UpdateEPUBMetadata(filePath, MetadataChanges{}); err == nil {
+		t.Error("expected error for empty changes")
+	}
+
+	title := "Corrected Title"
+	err := UpdateEPUBMetadata(filePath, MetadataChanges{
+		Title:    &title,
+		Creators: []Creator{{Name: "Jane Doe", FileAs: "Doe, Jane", Roles: []string{"aut"}}},
+		Subjects: []string{"Fiction"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateEPUBMetadata failed: %v", err)
+	}
+
+	metadata, err := ReadEPUBMetadata(filePath)
+	if err != nil {
+		t.Fatalf("ReadEPUBMetadata failed: %v", err)
+	}
+	if metadata.MainTitle() != title {
+		t.Errorf("MainTitle = %q", metadata.MainTitle())
+	}
+	want := []Creator{{Name: "Jane Doe", FileAs: "Doe, Jane", Roles: []string{"aut"}}}
+	if !reflect.DeepEqual(metadata.Creators, want) {
+		t.Errorf("Creators = %+v", metadata.Creators)
+	}
+	if metadata.Modified == "2024-01-01T00:00:00Z" {
+		t.Error("expected dcterms:modified to be refreshed")
+	}
+
+	report, err := ValidateEPUB(filePath)
+	if err != nil {
+		t.Fatalf("ValidateEPUB failed: %v", err)
+	}
+	if !report.IsValid {
+		t.Errorf("expected edited EPUB to stay valid, got %v", report.Errors)
+	}
+}
@@ -0,0 +1,59 @@
+package ebmlib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/pdf"
+)
+
+// OptimizeOptions selects the optimizations OptimizePDF applies. Objects
+// unreachable from the trailer are always removed.
+type OptimizeOptions = pdf.OptimizeOptions
+
+// OptimizeResult reports the sizes before and after optimization and what
+// changed.
+type OptimizeResult = pdf.OptimizeResult
+
+// DefaultOptimizeOptions returns every lossless optimization: duplicate
+// streams and fonts are merged, uncompressed streams are Flate-compressed
+// and objects are packed into object streams.
+func DefaultOptimizeOptions() OptimizeOptions {
+	return pdf.DefaultOptimizeOptions()
+}
+
+// OptimizePDF rewrites the PDF at filePath to outputPath, which may be
+// filePath itself. outputPath is left untouched when optimization fails.
+// Encrypted PDFs are rejected, and rewriting invalidates digital signatures.
+//
+// Example:
+//
+//	opts := ebmlib.DefaultOptimizeOptions()
+//	opts.ImageDPI = 150
+//	result, err := ebmlib.OptimizePDF(ctx, "scan.pdf", "scan.optimized.pdf", opts)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("Saved %d bytes\n", result.Saved())
+func OptimizePDF(ctx context.Context, filePath, outputPath string, opts OptimizeOptions) (*OptimizeResult, error) {
+	input, err := os.Open(filePath) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	defer func() {
+		_ = input.Close()
+	}()
+
+	service := pdf.NewRepairService().(*pdf.RepairServiceImpl)
+	var out bytes.Buffer
+	result, err := service.OptimizeFileWithOptions(ctx, input, &out, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(outputPath, out.Bytes(), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write optimized PDF: %w", err)
+	}
+	return result, nil
+}
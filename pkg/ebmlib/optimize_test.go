@@ -0,0 +1,77 @@
+package ebmlib
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testPDF builds a one-page PDF whose uncompressed content stream is
+// repeated on a second, unreferenced object.
+func testPDF() []byte {
+	content := strings.Repeat("BT /F1 12 Tf 72 712 Td (Hello) Tj ET\n", 20)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestOptimizePDF(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "document.pdf")
+	outputPath := filepath.Join(dir, "document.optimized.pdf")
+	if err := os.WriteFile(filePath, testPDF(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := OptimizePDF(context.Background(), filePath, outputPath, DefaultOptimizeOptions())
+	if err != nil {
+		t.Fatalf("OptimizePDF failed: %v", err)
+	}
+	if result.ObjectsBefore != 5 || result.ObjectsAfter != 4 || result.StreamsCompressed != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		t.Fatalf("Expected the optimized file: %v", err)
+	}
+	if info.Size() != result.OptimizedSize || result.Saved() <= 0 {
+		t.Errorf("Optimized file is %d bytes, result %+v", info.Size(), result)
+	}
+
+	report, err := ValidatePDF(outputPath)
+	if err != nil {
+		t.Fatalf("ValidatePDF failed: %v", err)
+	}
+	if !report.IsValid {
+		t.Errorf("Expected the optimized file to be valid, got errors %+v", report.Errors)
+	}
+
+	if _, err := OptimizePDF(context.Background(), filePath, outputPath, OptimizeOptions{ObjectStreams: true, Linearize: true}); err == nil {
+		t.Error("Expected an error for object streams with linearization")
+	}
+	if _, err := OptimizePDF(context.Background(), filepath.Join(dir, "missing.pdf"), outputPath, DefaultOptimizeOptions()); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -5,3 +5,36 @@ type RepairOptions struct {
 	// Aggressive enables destructive, best-effort repairs that may alter structure.
 	Aggressive bool
 }
+
+// ValidateOptions configures optional validation behavior.
+type ValidateOptions struct {
+	// Profile selects a retailer profile by built-in name (see Profiles) or by
+	// path to a YAML/JSON profile file. The profile format must match the
+	// file: "print" is the built-in PDF profile, the others are EPUB. Empty
+	// uses the profile named in the rule configuration, if any.
+	Profile string
+
+	// ConfigPath loads the rule configuration from this file instead of
+	// searching for .ebmrc.yaml in the working directory, its parents and
+	// the home directory.
+	ConfigPath string
+
+	// NoConfig disables the rule configuration entirely.
+	NoConfig bool
+
+	// Rules are custom EPUB rules run for this call, after the rules added
+	// with RegisterRule.
+	Rules []Rule
+
+	// SkipRegisteredRules runs only Rules and ignores the registry.
+	SkipRegisteredRules bool
+
+	// Deep decodes every PDF stream through its filter chain and checks its
+	// /Length. It is slower, and catches corrupt content streams that pass
+	// the structural checks. It has no effect on EPUB files.
+	Deep bool
+
+	// Password opens encrypted PDFs that require one, so their content can
+	// be validated. Either the user or the owner password works.
+	Password string
+}
@@ -0,0 +1,50 @@
+package ebmlib
+
+import (
+	"io"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
+)
+
+// Metadata is the typed package metadata of a Publication.
+type Metadata = epub.PublicationMetadata
+
+// Title is a dc:title entry with its title-type refinement.
+type Title = epub.Title
+
+// Creator is a dc:creator or dc:contributor entry with its roles.
+type Creator = epub.Creator
+
+// Identifier is a dc:identifier entry with its scheme.
+type Identifier = epub.Identifier
+
+// Series is a series or collection the publication belongs to.
+type Series = epub.Series
+
+// TOCEntry is a node of the table of contents tree.
+type TOCEntry = epub.TOCEntry
+
+// OpenEPUB parses the EPUB at filePath into a read-only Publication without
+// validating it. The file stays open for lazy resource reads; call Close when done.
+//
+// Example:
+//
+//	pub, err := ebmlib.OpenEPUB("book.epub")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pub.Close()
+//
+//	fmt.Println(pub.Metadata.MainTitle(), pub.Metadata.Authors())
+//	for _, item := range pub.Spine {
+//	    fmt.Println(item.Path)
+//	}
+func OpenEPUB(filePath string) (*Publication, error) {
+	return epub.OpenPublication(filePath)
+}
+
+// OpenEPUBReader parses an EPUB from an io.ReaderAt of the given size. The
+// reader must remain usable while the Publication is in use.
+func OpenEPUBReader(reader io.ReaderAt, size int64) (*Publication, error) {
+	return epub.NewPublication(reader, size)
+}
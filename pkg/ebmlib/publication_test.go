@@ -0,0 +1,40 @@
+package ebmlib
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenEPUB(t *testing.T) {
+	data := validTestEPUB(t)
+	filePath := filepath.Join(t.TempDir(), "book.epub")
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := OpenEPUB(filePath)
+	if err != nil {
+		t.Fatalf("OpenEPUB failed: %v", err)
+	}
+	defer pub.Close()
+
+	if got := pub.Metadata.MainTitle(); got != "Rule Test" {
+		t.Errorf("MainTitle = %q", got)
+	}
+	if len(pub.Spine) != 1 || pub.Spine[0].Path != "OEBPS/chapter1.xhtml" {
+		t.Errorf("unexpected spine %v", pub.Spine)
+	}
+	if len(pub.TOC) != 1 || pub.TOC[0].Path != "OEBPS/chapter1.xhtml" {
+		t.Errorf("unexpected TOC %v", pub.TOC)
+	}
+
+	fromReader, err := OpenEPUBReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenEPUBReader failed: %v", err)
+	}
+	if fromReader.Metadata.UniqueIdentifier != pub.Metadata.UniqueIdentifier {
+		t.Error("expected the same publication from a reader")
+	}
+}
@@ -0,0 +1,188 @@
+package ebmlib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/petergi/ebook-mechanic-lib/internal/adapters/epub"
+	"github.com/petergi/ebook-mechanic-lib/internal/rules"
+)
+
+// ErrorCodeRuleFailed is reported when a custom rule returns an error or panics.
+const ErrorCodeRuleFailed = "EPUB-RULE-001"
+
+// Publication is a parsed, read-only EPUB passed to custom rules. It exposes
+// the container, the OPF Package, the manifest and the spine; manifest items
+// give lazy access to their content and to parsed XHTML documents.
+type Publication = epub.Publication
+
+// Resource is a manifest item of a Publication.
+type Resource = epub.Resource
+
+// Package is the parsed OPF package document.
+type Package = epub.Package
+
+// Rule is a custom validation check. Registered rules run inside ValidateEPUB
+// and its variants after the built-in validators, before retailer profiles and
+// the rule configuration are applied, so severity overrides and ignore rules
+// also apply to custom codes.
+type Rule interface {
+	// ID identifies the rule in the registry and in failure reports.
+	ID() string
+	// Check inspects pub and returns its findings. Findings without a
+	// severity are reported as errors. A returned error is reported as an
+	// ErrorCodeRuleFailed finding and does not stop validation.
+	Check(ctx context.Context, pub *Publication) ([]ValidationError, error)
+}
+
+// RuleFunc is the signature of a check implemented as a plain function.
+type RuleFunc func(ctx context.Context, pub *Publication) ([]ValidationError, error)
+
+type funcRule struct {
+	id    string
+	check RuleFunc
+}
+
+func (r funcRule) ID() string { return r.id }
+
+func (r funcRule) Check(ctx context.Context, pub *Publication) ([]ValidationError, error) {
+	return r.check(ctx, pub)
+}
+
+// NewRule returns a Rule with the given id that calls check.
+//
+// Example:
+//
+//	rule := ebmlib.NewRule("house/series", func(ctx context.Context, pub *ebmlib.Publication) ([]ebmlib.ValidationError, error) {
+//	    if pub.Resource("copyright") == nil {
+//	        return []ebmlib.ValidationError{{Code: "HOUSE-001", Message: "Copyright page is missing"}}, nil
+//	    }
+//	    return nil, nil
+//	})
+func NewRule(id string, check RuleFunc) Rule {
+	return funcRule{id: id, check: check}
+}
+
+var registry = struct {
+	sync.RWMutex
+	rules map[string]Rule
+}{rules: make(map[string]Rule)}
+
+// RegisterRule adds rule to the registry used by ValidateEPUB and its
+// variants. It returns an error when the rule has no ID or the ID is taken.
+func RegisterRule(rule Rule) error {
+	if rule == nil || rule.ID() == "" {
+		return errors.New("rule must have an ID")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.rules[rule.ID()]; exists {
+		return fmt.Errorf("rule %q is already registered", rule.ID())
+	}
+	registry.rules[rule.ID()] = rule
+	return nil
+}
+
+// UnregisterRule removes the rule with the given ID and reports whether it was registered.
+func UnregisterRule(id string) bool {
+	registry.Lock()
+	defer registry.Unlock()
+
+	_, exists := registry.rules[id]
+	delete(registry.rules, id)
+	return exists
+}
+
+// RegisteredRules returns the registered rules sorted by ID.
+func RegisteredRules() []Rule {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	registered := make([]Rule, 0, len(registry.rules))
+	for _, rule := range registry.rules {
+		registered = append(registered, rule)
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].ID() < registered[j].ID()
+	})
+	return registered
+}
+
+// customRules returns the registered rules followed by the per-call rules in opts.
+func customRules(opts ValidateOptions) []Rule {
+	if opts.SkipRegisteredRules {
+		return opts.Rules
+	}
+	return append(RegisteredRules(), opts.Rules...)
+}
+
+// runRules runs custom rules against the EPUB in reader and adds their
+// findings to report. Nothing runs when the package document cannot be
+// located; the built-in validators already report that.
+func runRules(ctx context.Context, reader io.ReaderAt, size int64, ruleSet []Rule, report *ValidationReport) error {
+	if len(ruleSet) == 0 {
+		return nil
+	}
+
+	pub, err := epub.NewPublication(reader, size)
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+
+	for _, rule := range ruleSet {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		findings, err := checkRule(ctx, rule, pub)
+		if err != nil {
+			rules.Place(report, ValidationError{
+				Code:      ErrorCodeRuleFailed,
+				Message:   fmt.Sprintf("Custom rule %s failed: %s", rule.ID(), err.Error()),
+				Severity:  SeverityError,
+				Timestamp: time.Now(),
+				Details: map[string]interface{}{
+					"rule":  rule.ID(),
+					"error": err.Error(),
+				},
+			})
+			continue
+		}
+
+		for _, finding := range findings {
+			if finding.Severity == "" {
+				finding.Severity = SeverityError
+			}
+			if finding.Timestamp.IsZero() {
+				finding.Timestamp = time.Now()
+			}
+			details := make(map[string]interface{}, len(finding.Details)+1)
+			for k, v := range finding.Details {
+				details[k] = v
+			}
+			details["rule"] = rule.ID()
+			finding.Details = details
+			rules.Place(report, finding)
+		}
+	}
+
+	report.IsValid = len(report.Errors) == 0
+	return nil
+}
+
+func checkRule(ctx context.Context, rule Rule, pub *Publication) (findings []ValidationError, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			findings = nil
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return rule.Check(ctx, pub)
+}
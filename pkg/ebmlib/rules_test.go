@@ -0,0 +1,208 @@
+package ebmlib
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func buildTestEPUB(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("application/epub+zip")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func validTestEPUB(t *testing.T) []byte {
+	t.Helper()
+
+	xhtml := func(title string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="en" xml:lang="en">
+<head><title>` + title + `</title></head>
+<body><h1>` + title + `</h1>
+<nav epub:type="toc"><ol><li><a href="chapter1.xhtml">Chapter 1</a></li></ol></nav>
+</body>
+</html>`
+	}
+
+	return buildTestEPUB(t, map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`,
+		"OEBPS/content.opf": `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>Rule Test</dc:title>
+    <dc:identifier id="book-id">urn:uuid:12345678-1234-1234-1234-123456789012</dc:identifier>
+    <dc:language>en</dc:language>
+    <meta property="dcterms:modified">2024-01-01T00:00:00Z</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="chapter1" href="chapter1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="chapter1"/>
+  </spine>
+</package>`,
+		"OEBPS/nav.xhtml":      xhtml("Contents"),
+		"OEBPS/chapter1.xhtml": xhtml("Chapter 1"),
+	})
+}
+
+func TestRegisterRule(t *testing.T) {
+	rule := NewRule("test/register", func(context.Context, *Publication) ([]ValidationError, error) {
+		return nil, nil
+	})
+
+	if err := RegisterRule(rule); err != nil {
+		t.Fatalf("RegisterRule failed: %v", err)
+	}
+	defer UnregisterRule("test/register")
+
+	if err := RegisterRule(rule); err == nil {
+		t.Error("expected error registering a duplicate ID")
+	}
+	if err := RegisterRule(NewRule("", nil)); err == nil {
+		t.Error("expected error registering a rule without ID")
+	}
+
+	found := false
+	for _, registered := range RegisteredRules() {
+		if registered.ID() == "test/register" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected rule in RegisteredRules")
+	}
+
+	if !UnregisterRule("test/register") || UnregisterRule("test/register") {
+		t.Error("UnregisterRule should report whether the rule was registered")
+	}
+}
+
+func TestValidateEPUB_CustomRules(t *testing.T) {
+	data := validTestEPUB(t)
+
+	copyrightPage := NewRule("house/copyright", func(_ context.Context, pub *Publication) ([]ValidationError, error) {
+		if pub.Resource("copyright") != nil {
+			return nil, nil
+		}
+		return []ValidationError{{
+			Code:     "HOUSE-001",
+			Message:  "Copyright page is missing",
+			Location: &ErrorLocation{Path: pub.PackagePath},
+		}}, nil
+	})
+
+	headings := NewRule("house/headings", func(_ context.Context, pub *Publication) ([]ValidationError, error) {
+		var findings []ValidationError
+		for _, item := range pub.Spine {
+			doc, err := item.Document()
+			if err != nil {
+				return nil, err
+			}
+			if !hasElement(doc, "h1") {
+				findings = append(findings, ValidationError{Code: "HOUSE-002", Severity: SeverityWarning})
+			}
+		}
+		findings = append(findings, ValidationError{Code: "HOUSE-003", Message: "Spine checked", Severity: SeverityInfo})
+		return findings, nil
+	})
+
+	broken := NewRule("house/broken", func(context.Context, *Publication) ([]ValidationError, error) {
+		return nil, errors.New("boom")
+	})
+
+	panicking := NewRule("house/panic", func(context.Context, *Publication) ([]ValidationError, error) {
+		panic("unexpected")
+	})
+
+	report, err := ValidateEPUBReaderWithOptions(context.Background(), bytes.NewReader(data), int64(len(data)), ValidateOptions{
+		NoConfig:            true,
+		SkipRegisteredRules: true,
+		Rules:               []Rule{copyrightPage, headings, broken, panicking},
+	})
+	if err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+
+	codes := make(map[string]ValidationError)
+	for _, finding := range report.Errors {
+		codes[finding.Code] = finding
+	}
+
+	house, ok := codes["HOUSE-001"]
+	if !ok {
+		t.Fatalf("expected HOUSE-001 error, got %v", report.Errors)
+	}
+	if house.Severity != SeverityError || house.Details["rule"] != "house/copyright" || house.Timestamp.IsZero() {
+		t.Errorf("custom finding was not normalized: %+v", house)
+	}
+
+	failures := 0
+	for _, finding := range report.Errors {
+		if finding.Code == ErrorCodeRuleFailed {
+			failures++
+		}
+	}
+	if failures != 2 {
+		t.Errorf("expected 2 rule failures, got %d: %v", failures, report.Errors)
+	}
+
+	// zip.Writer stores the mimetype with a data descriptor, which is
+	// reported as EPUB-CONTAINER-013 info.
+	if len(report.Info) != 2 || report.Info[0].Code != "EPUB-CONTAINER-013" || report.Info[1].Code != "HOUSE-003" {
+		t.Errorf("expected EPUB-CONTAINER-013 and HOUSE-003 info, got %v", report.Info)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("expected every chapter to have a heading, got %v", report.Warnings)
+	}
+	if report.IsValid {
+		t.Error("expected custom errors to invalidate the report")
+	}
+}
+
+func hasElement(n *html.Node, tag string) bool {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return true
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if hasElement(c, tag) {
+			return true
+		}
+	}
+	return false
+}